@@ -1,16 +1,31 @@
 package main
 
 import (
+	"ads-server/internal/adapters/mail"
 	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/clock"
+	"ads-server/internal/content"
+	"ads-server/internal/idempotency"
+	"ads-server/internal/ids"
+	"ads-server/internal/messages"
 	"ads-server/internal/ports/grpc"
 	"ads-server/internal/ports/httpgin"
+	"ads-server/internal/ratelimit"
+	"ads-server/internal/telemetry"
+	"ads-server/internal/uow"
+	"ads-server/internal/users"
+	"ads-server/internal/validation"
 	"context"
 	"fmt"
 	"golang.org/x/sync/errgroup"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 )
 
 const (
@@ -34,21 +49,237 @@ func captureSigQuit(ctx context.Context) func() error {
 	}
 }
 
+// staffFromEnv reads comma separated emails of moderators and admins
+func staffFromEnv() map[string]users.Role {
+	staff := make(map[string]users.Role)
+	for env, role := range map[string]users.Role{"MODERATOR_EMAILS": users.RoleModerator, "ADMIN_EMAILS": users.RoleAdmin} {
+		for _, email := range strings.Split(os.Getenv(env), ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				staff[email] = role
+			}
+		}
+	}
+	return staff
+}
+
+// expirationFromEnv reads ad lifetime settings, unset variables keep default values
+func expirationFromEnv() (app.ExpirationConfig, error) {
+	cfg := app.DefaultExpirationConfig
+	for env, d := range map[string]*time.Duration{
+		"AD_LIFETIME":              &cfg.Lifetime,
+		"AD_EXPIRY_WARNING":        &cfg.WarnBefore,
+		"AD_EXPIRY_CHECK_INTERVAL": &cfg.CheckInterval,
+	} {
+		v := os.Getenv(env)
+		if v == "" {
+			continue
+		}
+		var err error
+		// zero lifetime keeps ads forever and zero warning disables warnings
+		if *d, err = time.ParseDuration(v); err != nil || *d < 0 {
+			return cfg, fmt.Errorf("%s must be a non-negative duration, got %q", env, v)
+		}
+	}
+	if cfg.CheckInterval == 0 {
+		return cfg, fmt.Errorf("AD_EXPIRY_CHECK_INTERVAL must be positive")
+	}
+	if v := os.Getenv("AD_CATEGORY_LIFETIMES"); v != "" {
+		var err error
+		if cfg.Categories, err = app.ParseCategoryLifetimes(v); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// duplicatesFromEnv reads near-duplicate detection settings, unset variables keep default values
+func duplicatesFromEnv() (app.DuplicateConfig, error) {
+	cfg := app.DefaultDuplicateConfig
+	for env, p := range map[string]*app.DuplicatePolicy{
+		"DUPLICATES_AUTHOR_POLICY": &cfg.Author,
+		"DUPLICATES_GLOBAL_POLICY": &cfg.Global,
+	} {
+		if v := os.Getenv(env); v != "" {
+			var err error
+			if *p, err = app.ParseDuplicatePolicy(v); err != nil {
+				return cfg, fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+	if cfg.Global == app.DuplicatesMerge {
+		return cfg, fmt.Errorf("DUPLICATES_GLOBAL_POLICY: ads of other authors can't be merged")
+	}
+	if v := os.Getenv("DUPLICATES_MAX_DISTANCE"); v != "" {
+		var err error
+		if cfg.MaxDistance, err = strconv.Atoi(v); err != nil || cfg.MaxDistance < 0 || cfg.MaxDistance > 64 {
+			return cfg, fmt.Errorf("DUPLICATES_MAX_DISTANCE must be an integer from 0 to 64, got %q", v)
+		}
+	}
+	return cfg, nil
+}
+
+// idsFromEnv creates generator of ID_STRATEGY (sequential by default), Snowflake IDs use ID_NODE
+func idsFromEnv() (ids.Generator, error) {
+	strategy := os.Getenv("ID_STRATEGY")
+	if strategy == "" {
+		strategy = ids.StrategySequential
+	}
+	var node int64
+	if v := os.Getenv("ID_NODE"); v != "" {
+		var err error
+		if node, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("ID_NODE must be an integer, got %q", v)
+		}
+	}
+	return ids.New(strategy, node, clock.System)
+}
+
 func main() {
-	a := repo.NewAd()
-	u := repo.NewUser()
+	shutdownTracing, err := telemetry.Setup(context.Background(), telemetry.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("can't set up tracing: %v", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Printf("can't flush traces: %v", err)
+		}
+	}()
+
+	limits, err := validation.LimitsFromEnv()
+	if err != nil {
+		log.Fatalf("can't read validation limits: %v", err)
+	}
+
+	sender, err := mail.New(mail.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("can't set up mail sender: %v", err)
+	}
+	verification := app.DefaultVerificationConfig
+	verification.LinkURL = os.Getenv("VERIFICATION_URL")
+
+	opts := []app.Option{
+		app.WithLimits(limits),
+		app.WithMailSender(sender),
+		app.WithVerification(verification),
+		app.WithStaff(staffFromEnv()),
+		// gRPC and HTTP apps share repositories, so they must share transactions too
+		app.WithUnitOfWork(uow.New()),
+		app.WithAuditLog(repo.NewAudit()),
+		app.WithClock(clock.System),
+	}
+	if v := os.Getenv("USER_DELETION_POLICY"); v != "" {
+		policy, err := app.ParseDeletionPolicy(v)
+		if err != nil {
+			log.Fatalf("can't configure user deletion: %v", err)
+		}
+		opts = append(opts, app.WithDeletionPolicy(policy))
+	}
+
+	trash := app.DefaultTrashConfig
+	for env, d := range map[string]*time.Duration{"TRASH_RETENTION": &trash.Retention, "TRASH_PURGE_INTERVAL": &trash.PurgeInterval} {
+		if v := os.Getenv(env); v != "" {
+			if *d, err = time.ParseDuration(v); err != nil || *d <= 0 {
+				log.Fatalf("can't configure trash: %s must be a positive duration, got %q", env, v)
+			}
+		}
+	}
+	opts = append(opts, app.WithTrash(trash))
+
+	expiration, err := expirationFromEnv()
+	if err != nil {
+		log.Fatalf("can't configure ad expiration: %v", err)
+	}
+	opts = append(opts, app.WithExpiration(expiration))
+
+	schedule := app.DefaultScheduleConfig
+	if v := os.Getenv("SCHEDULE_CHECK_INTERVAL"); v != "" {
+		if schedule.CheckInterval, err = time.ParseDuration(v); err != nil || schedule.CheckInterval <= 0 {
+			log.Fatalf("can't configure scheduler: SCHEDULE_CHECK_INTERVAL must be a positive duration, got %q", v)
+		}
+	}
+	opts = append(opts, app.WithSchedule(schedule))
+
+	reports := app.DefaultReportConfig
+	if v := os.Getenv("REPORT_TAKEDOWN_THRESHOLD"); v != "" {
+		if reports.TakedownThreshold, err = strconv.Atoi(v); err != nil || reports.TakedownThreshold < 0 {
+			log.Fatalf("can't configure abuse reports: REPORT_TAKEDOWN_THRESHOLD must be a non-negative integer, got %q", v)
+		}
+	}
+	opts = append(opts, app.WithReports(reports))
+
+	contentCfg, err := content.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("can't configure content filters: %v", err)
+	}
+	filters, err := content.New(contentCfg)
+	if err != nil {
+		log.Fatalf("can't configure content filters: %v", err)
+	}
+	opts = append(opts, app.WithContentFilters(filters))
+
+	rateLimits, err := ratelimit.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("can't configure rate limits: %v", err)
+	}
+	// gRPC and HTTP apps share the limiter, so a client has the same quota whatever transport it uses
+	opts = append(opts, app.WithRateLimits(ratelimit.New(rateLimits, clock.System)))
+	if v := os.Getenv("AD_DAILY_QUOTA"); v != "" {
+		perDay, err := strconv.Atoi(v)
+		if err != nil || perDay < 0 {
+			log.Fatalf("can't configure ad quota: AD_DAILY_QUOTA must be a non-negative integer, got %q", v)
+		}
+		opts = append(opts, app.WithDailyAdQuota(perDay))
+	}
+
+	window := idempotency.DefaultWindow
+	if v := os.Getenv("IDEMPOTENCY_WINDOW"); v != "" {
+		if window, err = time.ParseDuration(v); err != nil || window <= 0 {
+			log.Fatalf("can't configure idempotency keys: IDEMPOTENCY_WINDOW must be a positive duration, got %q", v)
+		}
+	}
+	// gRPC and HTTP apps share the store, keys are scoped by HTTP route or gRPC method
+	opts = append(opts, app.WithIdempotency(idempotency.New(window, clock.System)))
+
+	duplicates, err := duplicatesFromEnv()
+	if err != nil {
+		log.Fatalf("can't configure duplicate detection: %v", err)
+	}
+	opts = append(opts, app.WithDuplicates(duplicates))
+
+	adIDs, err := idsFromEnv()
+	if err != nil {
+		log.Fatalf("can't configure IDs of ads: %v", err)
+	}
+	userIDs, err := idsFromEnv()
+	if err != nil {
+		log.Fatalf("can't configure IDs of users: %v", err)
+	}
+	messageIDs, err := idsFromEnv()
+	if err != nil {
+		log.Fatalf("can't configure IDs of conversations and messages: %v", err)
+	}
+	a := repo.NewAd(repo.WithClock(clock.System), repo.WithIDs(adIDs))
+	u := repo.NewUser(repo.WithClock(clock.System), repo.WithIDs(userIDs))
+	// gRPC and HTTP apps share the hub, so messages sent over HTTP reach gRPC chat streams
+	opts = append(opts, app.WithMessaging(repo.NewMessages(repo.WithClock(clock.System), repo.WithIDs(messageIDs)), messages.NewHub()))
 	eg, ctx := errgroup.WithContext(context.Background())
 
 	// capture signals to stop working
 	eg.Go(captureSigQuit(ctx))
 
 	// run gRPC server
-	eg.Go(grpc.Run(ctx, a, u, grpcPort))
+	eg.Go(grpc.Run(ctx, a, u, grpcPort, opts...))
 
 	// run HTTP server
-	eg.Go(httpgin.Run(ctx, a, u, httpPort))
+	eg.Go(httpgin.Run(ctx, a, u, httpPort, opts...))
+
+	// run background jobs: purge trash, archive expired ads and make scheduled status changes
+	background := app.NewApp(a, u, opts...)
+	eg.Go(background.RunPurger(ctx))
+	eg.Go(background.RunExpirer(ctx))
+	eg.Go(background.RunScheduler(ctx))
 
-	err := eg.Wait()
+	err = eg.Wait()
 	if err != nil {
 		fmt.Println(err)
 		return
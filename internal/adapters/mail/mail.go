@@ -0,0 +1,57 @@
+// Package mail delivers app.Mail messages by SMTP or writes them to a local sink for development
+package mail
+
+import (
+	"ads-server/internal/app"
+	"fmt"
+	"os"
+)
+
+// Config selects and configures the sender
+type Config struct {
+	// Sender is "smtp", "file" or "console" (default)
+	Sender   string
+	From     string
+	SMTPAddr string
+	Username string
+	Password string
+	FilePath string
+}
+
+const defaultFrom = "ads-server@localhost"
+
+// ConfigFromEnv reads sender configuration from MAIL_* and SMTP_* environment variables
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Sender:   os.Getenv("MAIL_SENDER"),
+		From:     os.Getenv("MAIL_FROM"),
+		SMTPAddr: os.Getenv("SMTP_ADDR"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		FilePath: os.Getenv("MAIL_FILE"),
+	}
+	if cfg.From == "" {
+		cfg.From = defaultFrom
+	}
+	if cfg.FilePath == "" {
+		cfg.FilePath = "mail.log"
+	}
+	return cfg
+}
+
+// New creates the sender selected by the config
+func New(cfg Config) (app.MailSender, error) {
+	switch cfg.Sender {
+	case "", "console":
+		return NewWriter(os.Stdout, cfg.From), nil
+	case "file":
+		return NewFile(cfg.FilePath, cfg.From), nil
+	case "smtp":
+		if cfg.SMTPAddr == "" {
+			return nil, fmt.Errorf("SMTP_ADDR must be set for smtp mail sender")
+		}
+		return NewSMTP(cfg.SMTPAddr, cfg.Username, cfg.Password, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail sender %q", cfg.Sender)
+	}
+}
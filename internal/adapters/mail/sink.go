@@ -0,0 +1,56 @@
+package mail
+
+import (
+	"ads-server/internal/app"
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Writer writes formatted messages to w instead of delivering them, it is meant for development
+type Writer struct {
+	mx   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+// NewWriter creates sink writing to w, e.g. os.Stdout
+func NewWriter(w io.Writer, from string) *Writer {
+	return &Writer{w: w, from: from}
+}
+
+func (s *Writer) Send(_ context.Context, m app.Mail) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	msg := append(format(s.from, m, time.Now()), "\r\n.\r\n"...)
+	_, err := s.w.Write(msg)
+	return err
+}
+
+// File appends formatted messages to a file, it is opened on every send so it can be rotated or removed
+type File struct {
+	mx   sync.Mutex
+	path string
+	from string
+}
+
+// NewFile creates sink appending to the file at path
+func NewFile(path, from string) *File {
+	return &File{path: path, from: from}
+}
+
+func (s *File) Send(ctx context.Context, m app.Mail) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if err = NewWriter(f, s.from).Send(ctx, m); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
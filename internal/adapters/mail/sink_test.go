@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"ads-server/internal/app"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewWriter(&buf, "noreply@example.com")
+
+	err := s.Send(context.Background(), app.Mail{To: "anna@example.com", Subject: "Подтвердите почту", Body: "code: 42\n"})
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "From: noreply@example.com\r\n")
+	assert.Contains(t, out, "To: anna@example.com\r\n")
+	assert.Contains(t, out, "Subject: =?utf-8?q?")
+	assert.Contains(t, out, "\r\n\r\ncode: 42\n")
+}
+
+func TestFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	s := NewFile(path, defaultFrom)
+
+	for _, to := range []string{"a@example.com", "b@example.com"} {
+		assert.NoError(t, s.Send(context.Background(), app.Mail{To: to, Subject: "hi", Body: "hello"}))
+	}
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "To: a@example.com")
+	assert.Contains(t, string(data), "To: b@example.com")
+}
+
+func TestNewUnknownSender(t *testing.T) {
+	_, err := New(Config{Sender: "pigeon"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Sender: "smtp"})
+	assert.Error(t, err, "smtp sender requires relay address")
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,94 +16,136 @@ import (
 
 type AdRepo struct {
 	storage map[int64]*ads.Ad
+	// revisions keeps history of every ad, oldest first
+	revisions map[int64][]*ads.Revision
+	// approvals keeps the latest moderator approval of every ad
+	approvals map[int64]*ads.Approval
+	// transitions keeps pending scheduled status changes by their IDs
+	transitions map[int64]*ads.Transition
+	// favorites keeps users who saved every ad by ad and user IDs
+	favorites map[int64]map[int64]*ads.Favorite
+	// reports keeps abuse reports of ads by their IDs, resolved ones included
+	reports map[int64]*ads.Report
+	// appeals keeps appeals against takedowns by their IDs, resolved ones included
+	appeals map[int64]*ads.Appeal
 	mx      *sync.Mutex
-	lastID  int64
+	config
 }
 
 // Create is a function to create a new ad
-func (ar *AdRepo) Create(_ context.Context, ad *ads.Ad) (id int64, err error) {
-	ar.mx.Lock()
+func (ar *AdRepo) Create(ctx context.Context, ad *ads.Ad) (id int64, err error) {
+	span := lockWithSpan(ctx, "AdRepo.Create", ar.mx)
+	defer span.End()
 	defer ar.mx.Unlock()
-	if _, ok := ar.storage[ar.lastID]; ok {
-		return -1, errs.AdNotFoundError
+	id, err = ar.nextID()
+	if err != nil {
+		return -1, err
+	}
+	if _, ok := ar.storage[id]; ok {
+		return -1, errs.AdExistsError.WithResource(errs.ResourceAd, id)
 	}
-	ad.ID = ar.lastID
-	ad.CDate = time.Now().UTC()
+	ad.ID = id
+	ad.Version = 1
+	ad.CDate = ar.clock.Now()
 	ad.UDate = ad.CDate
-	ar.storage[ar.lastID] = ad
-	ar.lastID++
+	ar.storage[id] = ad
+	onRollback(ctx, ar.mx, func() { delete(ar.storage, ad.ID) })
 
-	return ar.lastID - 1, nil
+	return id, nil
 }
 
-// Update is a function to update an existing ad
-func (ar *AdRepo) Update(_ context.Context, id int64, aID int64, title string, text string) (*ads.Ad, error) {
-	ar.mx.Lock()
+// Update is a function to update an existing ad, the ad must have the version given unless it is zero
+func (ar *AdRepo) Update(ctx context.Context, id int64, aID int64, title string, text string, version int64) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.Update", ar.mx)
+	defer span.End()
 	defer ar.mx.Unlock()
-	if _, ok := ar.storage[id]; !ok {
-		return nil, errs.AdNotFoundError
+	ad, ok := ar.live(id)
+	if !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, id)
+	}
+	if ad.AuthorID != aID {
+		return nil, errs.AccessError.WithResource(errs.ResourceAd, id)
 	}
-	if ar.storage[id].AuthorID != aID {
-		return nil, errs.AccessError
+	if err := checkVersion(errs.ResourceAd, id, ad.Version, version); err != nil {
+		return nil, err
 	}
-	ar.storage[id].Text = text
-	ar.storage[id].Title = title
-	ar.storage[id].UDate = time.Now().UTC()
-	return ar.storage[id], nil
+	ar.keepState(ctx, ad)
+	ad.Text = text
+	ad.Title = title
+	ad.UDate = ar.clock.Now()
+	ad.Version++
+	c := *ad
+	return &c, nil
 }
 
-// Delete deletes ad from storage
-func (ar *AdRepo) Delete(_ context.Context, id, uID int64) error {
-	ar.mx.Lock()
+// Delete moves ad to trash, the ad must have the version given unless it is zero
+func (ar *AdRepo) Delete(ctx context.Context, id, uID int64, version int64) error {
+	span := lockWithSpan(ctx, "AdRepo.Delete", ar.mx)
+	defer span.End()
 	defer ar.mx.Unlock()
-	ad, ok := ar.storage[id]
+	ad, ok := ar.live(id)
 	if !ok {
-		return errs.AdNotFoundError
+		return errs.AdNotFoundError.WithResource(errs.ResourceAd, id)
 	}
 
 	if ad.AuthorID != uID {
-		return errs.AccessError
+		return errs.AccessError.WithResource(errs.ResourceAd, id)
+	}
+	if err := checkVersion(errs.ResourceAd, id, ad.Version, version); err != nil {
+		return err
 	}
 
-	delete(ar.storage, id)
+	ar.keepState(ctx, ad)
+	ad.DeletedAt = ar.clock.Now()
+	ad.Version++
 	return nil
 }
 
-// Publish is a function to change ad status
-func (ar *AdRepo) Publish(_ context.Context, adID, aID int64, action bool) (*ads.Ad, error) {
-	ar.mx.Lock()
+// Publish is a function to change ad status, the ad must have the version given unless it is zero
+func (ar *AdRepo) Publish(ctx context.Context, adID, aID int64, action bool, version int64) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.Publish", ar.mx)
+	defer span.End()
 	defer ar.mx.Unlock()
-	_, ok := ar.storage[adID]
-	if !ok || ar.storage[adID].AuthorID != aID {
-		return nil, fmt.Errorf("access forbidden")
+	ad, ok := ar.live(adID)
+	if !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
 	}
-	ar.storage[adID].Published = action
-	ar.storage[adID].UDate = time.Now().UTC()
-	return ar.storage[adID], nil
-
-	//return nil, errors.NewAd(fmt.Sprintf("no such ad %d", adID))
+	if ad.AuthorID != aID {
+		return nil, errs.AccessError.WithResource(errs.ResourceAd, adID)
+	}
+	if err := checkVersion(errs.ResourceAd, adID, ad.Version, version); err != nil {
+		return nil, err
+	}
+	ar.keepState(ctx, ad)
+	ad.Published = action
+	ad.UDate = ar.clock.Now()
+	ad.Version++
+	c := *ad
+	return &c, nil
 }
 
 // GetByID is a function to find ad in storage using ID
-func (ar *AdRepo) GetByID(_ context.Context, id int64) (*ads.Ad, error) {
-	ar.mx.Lock()
+func (ar *AdRepo) GetByID(ctx context.Context, id int64) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.GetByID", ar.mx)
+	defer span.End()
 	defer ar.mx.Unlock()
-	for adID, ad := range ar.storage {
-		if adID == id {
-			return ad, nil
-		}
+	if ad, ok := ar.live(id); ok {
+		c := *ad
+		return &c, nil
 	}
-	return nil, errs.AdNotFoundError
+	return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, id)
 }
 
 // GetByName is a function to find ad in storage using name
-func (ar *AdRepo) GetByName(_ context.Context, title string) []*ads.Ad {
-	ar.mx.Lock()
+func (ar *AdRepo) GetByName(ctx context.Context, title string) []*ads.Ad {
+	span := lockWithSpan(ctx, "AdRepo.GetByName", ar.mx)
+	defer span.End()
 	defer ar.mx.Unlock()
 	var resAds []*ads.Ad
 	for _, val := range ar.storage {
-		if (title == "" || strings.Contains(val.Title, title)) && val.Published {
-			resAds = append(resAds, val)
+		if (title == "" || strings.Contains(val.Title, title)) && val.Published && !val.Deleted() {
+			c := *val
+			resAds = append(resAds, &c)
 		}
 	}
 	return resAds
@@ -118,6 +161,9 @@ type Date struct {
 // parseDate parses ISO format (yyyy-mm-dd) to Date
 func parseDate(dateISO string) (Date, error) {
 	splitted := strings.Split(dateISO, "-")
+	if len(splitted) != 3 {
+		return Date{}, fmt.Errorf("%q is not in yyyy-mm-dd format", dateISO)
+	}
 	Y, err := strconv.Atoi(splitted[0])
 	if err != nil {
 		return Date{}, err
@@ -138,8 +184,9 @@ func parseDate(dateISO string) (Date, error) {
 }
 
 // Filter returns ads satisfying filters
-func (ar *AdRepo) Filter(_ context.Context, params url.Values) ([]*ads.Ad, error) {
-	ar.mx.Lock()
+func (ar *AdRepo) Filter(ctx context.Context, params url.Values) ([]*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.Filter", ar.mx)
+	defer span.End()
 	defer ar.mx.Unlock()
 
 	author, mustAuthor := params["author"]
@@ -148,7 +195,8 @@ func (ar *AdRepo) Filter(_ context.Context, params url.Values) ([]*ads.Ad, error
 	if mustAuthor {
 		authorID, err = strconv.Atoi(author[0])
 		if err != nil {
-			return nil, err
+			return nil, errs.ValidationError.WithCause(err).WithFields(
+				errs.FieldViolation{Field: "author", Description: "must be an integer"})
 		}
 	}
 
@@ -157,7 +205,8 @@ func (ar *AdRepo) Filter(_ context.Context, params url.Values) ([]*ads.Ad, error
 	if mustDate {
 		parsedDate, err = parseDate(date[0])
 		if err != nil {
-			return nil, err
+			return nil, errs.ValidationError.WithCause(err).WithFields(
+				errs.FieldViolation{Field: "date", Description: "must be a date in yyyy-mm-dd format"})
 		}
 	}
 
@@ -168,6 +217,9 @@ func (ar *AdRepo) Filter(_ context.Context, params url.Values) ([]*ads.Ad, error
 	var allAds []*ads.Ad
 
 	for _, ad := range ar.storage {
+		if ad.Deleted() {
+			continue
+		}
 		if mustPublished && !ad.Published {
 			continue
 		}
@@ -181,17 +233,731 @@ func (ar *AdRepo) Filter(_ context.Context, params url.Values) ([]*ads.Ad, error
 		if mustTitle && ad.Title != title[0] {
 			continue
 		}
-		allAds = append(allAds, ad)
+		c := *ad
+		allAds = append(allAds, &c)
 	}
 
 	return allAds, nil
 }
 
+// DeleteByAuthor moves live ads of the author to trash marking them deleted with the author,
+// returning their previous state
+func (ar *AdRepo) DeleteByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.DeleteByAuthor", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var snapshot []*ads.Ad
+	now := ar.clock.Now()
+	for _, ad := range ar.storage {
+		if ad.AuthorID == uID && !ad.Deleted() {
+			prev := *ad
+			snapshot = append(snapshot, &prev)
+			ar.keepState(ctx, ad)
+			ad.DeletedAt = now
+			ad.DeletedWithAuthor = true
+			ad.Version++
+		}
+	}
+	return snapshot, nil
+}
+
+// RestoreByAuthor takes ads deleted together with the author out of trash, returning them
+func (ar *AdRepo) RestoreByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.RestoreByAuthor", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var restored []*ads.Ad
+	for _, ad := range ar.storage {
+		if ad.AuthorID == uID && ad.DeletedWithAuthor {
+			ar.restore(ctx, ad)
+			c := *ad
+			restored = append(restored, &c)
+		}
+	}
+	return restored, nil
+}
+
+// Restore takes the ad out of trash
+func (ar *AdRepo) Restore(ctx context.Context, id int64) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.Restore", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ad, ok := ar.storage[id]
+	if !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, id)
+	}
+	if !ad.Deleted() {
+		return nil, errs.NotDeletedError.WithResource(errs.ResourceAd, id)
+	}
+	ar.restore(ctx, ad)
+	c := *ad
+	return &c, nil
+}
+
+// GetDeleted returns the ad if it is in trash, NotDeletedError if it is live
+func (ar *AdRepo) GetDeleted(ctx context.Context, id int64) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.GetDeleted", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ad, ok := ar.storage[id]
+	if !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, id)
+	}
+	if !ad.Deleted() {
+		return nil, errs.NotDeletedError.WithResource(errs.ResourceAd, id)
+	}
+	c := *ad
+	return &c, nil
+}
+
+// Trash returns ads of the author in trash
+func (ar *AdRepo) Trash(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.Trash", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var trash []*ads.Ad
+	for _, ad := range ar.storage {
+		if ad.AuthorID == uID && ad.Deleted() {
+			c := *ad
+			trash = append(trash, &c)
+		}
+	}
+	return trash, nil
+}
+
+// Purge permanently removes ads moved to trash before the moment given with their history, returning their IDs
+func (ar *AdRepo) Purge(ctx context.Context, before time.Time) ([]int64, error) {
+	span := lockWithSpan(ctx, "AdRepo.Purge", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var purged []int64
+	for id, ad := range ar.storage {
+		if ad.Deleted() && ad.DeletedAt.Before(before) {
+			id, ad := id, ad
+			delete(ar.storage, id)
+			onRollback(ctx, ar.mx, func() { ar.storage[id] = ad })
+			ar.dropHistory(ctx, id)
+			purged = append(purged, id)
+		}
+	}
+	return purged, nil
+}
+
+// live returns the ad unless it is missing or in trash
+func (ar *AdRepo) live(id int64) (*ads.Ad, bool) {
+	ad, ok := ar.storage[id]
+	if !ok || ad.Deleted() {
+		return nil, false
+	}
+	return ad, true
+}
+
+// restore takes the ad out of trash
+func (ar *AdRepo) restore(ctx context.Context, ad *ads.Ad) {
+	ar.keepState(ctx, ad)
+	ad.DeletedAt = time.Time{}
+	ad.DeletedWithAuthor = false
+	ad.Version++
+}
+
+// AnonymizeByAuthor unpublishes ads of the author and detaches them from it, returning copies of the ads before the change
+func (ar *AdRepo) AnonymizeByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.AnonymizeByAuthor", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var snapshot []*ads.Ad
+	now := ar.clock.Now()
+	for _, ad := range ar.storage {
+		if ad.AuthorID == uID && !ad.Deleted() {
+			prev := *ad
+			snapshot = append(snapshot, &prev)
+			ar.keepState(ctx, ad)
+			ad.AuthorID = ads.AnonymousAuthorID
+			ad.Published = false
+			ad.UDate = now
+			ad.Version++
+		}
+	}
+	return snapshot, nil
+}
+
+// SetExpiry moves expiration of the ad of the author, taking it out of archive,
+// the ad must have the version given unless it is zero
+func (ar *AdRepo) SetExpiry(ctx context.Context, adID, uID int64, expiresAt time.Time, version int64) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.SetExpiry", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ad, ok := ar.live(adID)
+	if !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	if ad.AuthorID != uID {
+		return nil, errs.AccessError.WithResource(errs.ResourceAd, adID)
+	}
+	if err := checkVersion(errs.ResourceAd, adID, ad.Version, version); err != nil {
+		return nil, err
+	}
+	ar.keepState(ctx, ad)
+	ad.ExpiresAt = expiresAt
+	ad.ArchivedAt = time.Time{}
+	ad.ExpiryWarned = false
+	ad.UDate = ar.clock.Now()
+	ad.Version++
+	c := *ad
+	return &c, nil
+}
+
+// Archive unpublishes live ads expired by the moment given and marks them archived, returning them
+func (ar *AdRepo) Archive(ctx context.Context, now time.Time) ([]*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.Archive", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var archived []*ads.Ad
+	for _, ad := range ar.storage {
+		if ad.Deleted() || !ad.ExpiresBy(now) {
+			continue
+		}
+		ar.keepState(ctx, ad)
+		ad.ArchivedAt = now
+		ad.Published = false
+		ad.UDate = now
+		ad.Version++
+		c := *ad
+		archived = append(archived, &c)
+	}
+	return archived, nil
+}
+
+// Expiring returns copies of live ads expiring by the moment given whose authors were not warned yet
+func (ar *AdRepo) Expiring(ctx context.Context, before time.Time) ([]*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.Expiring", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var expiring []*ads.Ad
+	for _, ad := range ar.storage {
+		if !ad.Deleted() && !ad.ExpiryWarned && ad.ExpiresBy(before) {
+			c := *ad
+			expiring = append(expiring, &c)
+		}
+	}
+	return expiring, nil
+}
+
+// MarkExpiryWarned records that the author of the ad was warned about its expiration
+func (ar *AdRepo) MarkExpiryWarned(ctx context.Context, adID int64) error {
+	span := lockWithSpan(ctx, "AdRepo.MarkExpiryWarned", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ad, ok := ar.live(adID)
+	if !ok {
+		return errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	ar.keepState(ctx, ad)
+	ad.ExpiryWarned = true
+	return nil
+}
+
+// AddRevision appends revision to the history of its ad
+func (ar *AdRepo) AddRevision(ctx context.Context, r *ads.Revision) error {
+	span := lockWithSpan(ctx, "AdRepo.AddRevision", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.storage[r.AdID]; !ok {
+		return errs.AdNotFoundError.WithResource(errs.ResourceAd, r.AdID)
+	}
+	n := len(ar.revisions[r.AdID])
+	ar.revisions[r.AdID] = append(ar.revisions[r.AdID], r)
+	onRollback(ctx, ar.mx, func() { ar.revisions[r.AdID] = ar.revisions[r.AdID][:n] })
+	return nil
+}
+
+// Revisions returns history of the ad, oldest first
+func (ar *AdRepo) Revisions(ctx context.Context, adID int64) ([]*ads.Revision, error) {
+	span := lockWithSpan(ctx, "AdRepo.Revisions", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.storage[adID]; !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	return append([]*ads.Revision(nil), ar.revisions[adID]...), nil
+}
+
+// Approve stores approval replacing the previous one of the ad
+func (ar *AdRepo) Approve(ctx context.Context, ap *ads.Approval) error {
+	span := lockWithSpan(ctx, "AdRepo.Approve", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.storage[ap.AdID]; !ok {
+		return errs.AdNotFoundError.WithResource(errs.ResourceAd, ap.AdID)
+	}
+	prev, ok := ar.approvals[ap.AdID]
+	ar.approvals[ap.AdID] = ap
+	onRollback(ctx, ar.mx, func() {
+		if ok {
+			ar.approvals[ap.AdID] = prev
+			return
+		}
+		delete(ar.approvals, ap.AdID)
+	})
+	return nil
+}
+
+// LastApproval returns the latest approval of the ad, nil if it was never approved
+func (ar *AdRepo) LastApproval(ctx context.Context, adID int64) (*ads.Approval, error) {
+	span := lockWithSpan(ctx, "AdRepo.LastApproval", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.storage[adID]; !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	return ar.approvals[adID], nil
+}
+
+// ScheduleTransition stores a pending status change of a live ad assigning it an ID
+func (ar *AdRepo) ScheduleTransition(ctx context.Context, t *ads.Transition) error {
+	span := lockWithSpan(ctx, "AdRepo.ScheduleTransition", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.live(t.AdID); !ok {
+		return errs.AdNotFoundError.WithResource(errs.ResourceAd, t.AdID)
+	}
+	id, err := ar.nextID()
+	if err != nil {
+		return err
+	}
+	t.ID = id
+	ar.transitions[t.ID] = t
+	onRollback(ctx, ar.mx, func() { delete(ar.transitions, t.ID) })
+	return nil
+}
+
+// Transitions returns copies of pending status changes of the ad, earliest first
+func (ar *AdRepo) Transitions(ctx context.Context, adID int64) ([]*ads.Transition, error) {
+	span := lockWithSpan(ctx, "AdRepo.Transitions", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.storage[adID]; !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	return ar.transitionsMatching(func(t *ads.Transition) bool { return t.AdID == adID }), nil
+}
+
+// DueTransitions returns copies of pending status changes of all ads due by the moment given, earliest first
+func (ar *AdRepo) DueTransitions(ctx context.Context, now time.Time) ([]*ads.Transition, error) {
+	span := lockWithSpan(ctx, "AdRepo.DueTransitions", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	return ar.transitionsMatching(func(t *ads.Transition) bool { return t.Due(now) }), nil
+}
+
+// RemoveTransition removes the pending status change of the ad returning it
+func (ar *AdRepo) RemoveTransition(ctx context.Context, adID, id int64) (*ads.Transition, error) {
+	span := lockWithSpan(ctx, "AdRepo.RemoveTransition", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	t, ok := ar.transitions[id]
+	if !ok || t.AdID != adID {
+		return nil, errs.TransitionNotFoundError.WithResource(errs.ResourceTransition, id)
+	}
+	delete(ar.transitions, id)
+	onRollback(ctx, ar.mx, func() { ar.transitions[id] = t })
+	c := *t
+	return &c, nil
+}
+
+// transitionsMatching returns copies of transitions matching f ordered by time and then by ID
+func (ar *AdRepo) transitionsMatching(f func(*ads.Transition) bool) []*ads.Transition {
+	var res []*ads.Transition
+	for _, t := range ar.transitions {
+		if f(t) {
+			c := *t
+			res = append(res, &c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].At.Equal(res[j].At) {
+			return res[i].At.Before(res[j].At)
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// AddFavorite saves the live ad to favorites of the user, saving it again returns the original favorite
+func (ar *AdRepo) AddFavorite(ctx context.Context, f *ads.Favorite) (*ads.Favorite, error) {
+	span := lockWithSpan(ctx, "AdRepo.AddFavorite", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.live(f.AdID); !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, f.AdID)
+	}
+	if saved, ok := ar.favorites[f.AdID][f.UserID]; ok {
+		c := *saved
+		return &c, nil
+	}
+	if ar.favorites[f.AdID] == nil {
+		ar.favorites[f.AdID] = make(map[int64]*ads.Favorite)
+	}
+	ar.favorites[f.AdID][f.UserID] = f
+	onRollback(ctx, ar.mx, func() { delete(ar.favorites[f.AdID], f.UserID) })
+	c := *f
+	return &c, nil
+}
+
+// RemoveFavorite removes the ad from favorites of the user
+func (ar *AdRepo) RemoveFavorite(ctx context.Context, uID, adID int64) error {
+	span := lockWithSpan(ctx, "AdRepo.RemoveFavorite", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	f, ok := ar.favorites[adID][uID]
+	if !ok {
+		return errs.FavoriteNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	delete(ar.favorites[adID], uID)
+	onRollback(ctx, ar.mx, func() { ar.favorites[adID][uID] = f })
+	return nil
+}
+
+// Favorites returns copies of favorites of the user, latest first
+func (ar *AdRepo) Favorites(ctx context.Context, uID int64) ([]*ads.Favorite, error) {
+	span := lockWithSpan(ctx, "AdRepo.Favorites", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var res []*ads.Favorite
+	for _, watchers := range ar.favorites {
+		if f, ok := watchers[uID]; ok {
+			c := *f
+			res = append(res, &c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.After(res[j].CreatedAt)
+		}
+		return res[i].AdID > res[j].AdID
+	})
+	return res, nil
+}
+
+// Watchers returns IDs of users who saved the ad to favorites in ascending order
+func (ar *AdRepo) Watchers(ctx context.Context, adID int64) ([]int64, error) {
+	span := lockWithSpan(ctx, "AdRepo.Watchers", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.storage[adID]; !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	var res []int64
+	for uID := range ar.favorites[adID] {
+		res = append(res, uID)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res, nil
+}
+
+// RemoveAdFavorites removes the ad from favorites of all users, returning how many favorites were removed
+func (ar *AdRepo) RemoveAdFavorites(ctx context.Context, adID int64) (int, error) {
+	span := lockWithSpan(ctx, "AdRepo.RemoveAdFavorites", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	n := len(ar.favorites[adID])
+	ar.dropFavorites(ctx, adID)
+	return n, nil
+}
+
+// RemoveUserFavorites removes all favorites of the user
+func (ar *AdRepo) RemoveUserFavorites(ctx context.Context, uID int64) error {
+	span := lockWithSpan(ctx, "AdRepo.RemoveUserFavorites", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	for adID, watchers := range ar.favorites {
+		if f, ok := watchers[uID]; ok {
+			adID, f := adID, f
+			delete(watchers, uID)
+			onRollback(ctx, ar.mx, func() { ar.favorites[adID][uID] = f })
+		}
+	}
+	return nil
+}
+
+// dropFavorites forgets who saved the ad
+func (ar *AdRepo) dropFavorites(ctx context.Context, adID int64) {
+	watchers, ok := ar.favorites[adID]
+	if !ok {
+		return
+	}
+	delete(ar.favorites, adID)
+	onRollback(ctx, ar.mx, func() { ar.favorites[adID] = watchers })
+}
+
+// dropHistory forgets revisions, approval, pending transitions, favorites, reports and appeals of a deleted ad
+func (ar *AdRepo) dropHistory(ctx context.Context, id int64) {
+	ar.dropFavorites(ctx, id)
+	for rID, r := range ar.reports {
+		if r.AdID == id {
+			rID, r := rID, r
+			delete(ar.reports, rID)
+			onRollback(ctx, ar.mx, func() { ar.reports[rID] = r })
+		}
+	}
+	for apID, ap := range ar.appeals {
+		if ap.AdID == id {
+			apID, ap := apID, ap
+			delete(ar.appeals, apID)
+			onRollback(ctx, ar.mx, func() { ar.appeals[apID] = ap })
+		}
+	}
+	revisions, approval := ar.revisions[id], ar.approvals[id]
+	delete(ar.revisions, id)
+	delete(ar.approvals, id)
+	for tID, t := range ar.transitions {
+		if t.AdID == id {
+			tID, t := tID, t
+			delete(ar.transitions, tID)
+			onRollback(ctx, ar.mx, func() { ar.transitions[tID] = t })
+		}
+	}
+	onRollback(ctx, ar.mx, func() {
+		if revisions != nil {
+			ar.revisions[id] = revisions
+		}
+		if approval != nil {
+			ar.approvals[id] = approval
+		}
+	})
+}
+
+// SetTakenDown takes the live ad down at the moment given or lifts the takedown if the moment is zero
+func (ar *AdRepo) SetTakenDown(ctx context.Context, adID int64, at time.Time) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.SetTakenDown", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ad, ok := ar.live(adID)
+	if !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	ar.keepState(ctx, ad)
+	ad.TakenDownAt = at
+	ad.UDate = ar.clock.Now()
+	ad.Version++
+	c := *ad
+	return &c, nil
+}
+
+// CountCreated returns how many ads the author created since the moment given, ads in trash included
+func (ar *AdRepo) CountCreated(ctx context.Context, uID int64, since time.Time) (int, error) {
+	span := lockWithSpan(ctx, "AdRepo.CountCreated", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	n := 0
+	for _, ad := range ar.storage {
+		if ad.AuthorID == uID && !ad.CDate.Before(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// SetScreening records the outcome of content filters for the current revision of the live ad,
+// the version is kept as the ad itself doesn't change
+func (ar *AdRepo) SetScreening(ctx context.Context, adID int64, s ads.Screening) (*ads.Ad, error) {
+	span := lockWithSpan(ctx, "AdRepo.SetScreening", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ad, ok := ar.live(adID)
+	if !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	ar.keepState(ctx, ad)
+	ad.Screening = s
+	c := *ad
+	return &c, nil
+}
+
+// AddReport stores a report of the live ad assigning it an ID, a user may have one open report per ad
+func (ar *AdRepo) AddReport(ctx context.Context, r *ads.Report) (*ads.Report, error) {
+	span := lockWithSpan(ctx, "AdRepo.AddReport", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.live(r.AdID); !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, r.AdID)
+	}
+	for _, o := range ar.reports {
+		if o.AdID == r.AdID && o.ReporterID == r.ReporterID && o.Open() {
+			return nil, errs.ReportExistsError.WithResource(errs.ResourceAd, r.AdID)
+		}
+	}
+	id, err := ar.nextID()
+	if err != nil {
+		return nil, err
+	}
+	r.ID = id
+	ar.reports[r.ID] = r
+	onRollback(ctx, ar.mx, func() { delete(ar.reports, r.ID) })
+	c := *r
+	return &c, nil
+}
+
+// Reports returns copies of open reports of the ad, oldest first
+func (ar *AdRepo) Reports(ctx context.Context, adID int64) ([]*ads.Report, error) {
+	span := lockWithSpan(ctx, "AdRepo.Reports", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.storage[adID]; !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	return ar.reportsMatching(func(r *ads.Report) bool { return r.AdID == adID && r.Open() }), nil
+}
+
+// OpenReports returns copies of open reports of all live ads, oldest first
+func (ar *AdRepo) OpenReports(ctx context.Context) ([]*ads.Report, error) {
+	span := lockWithSpan(ctx, "AdRepo.OpenReports", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	return ar.reportsMatching(func(r *ads.Report) bool {
+		_, live := ar.live(r.AdID)
+		return live && r.Open()
+	}), nil
+}
+
+// ResolveReports closes open reports of the ad with the outcome given, returning them
+func (ar *AdRepo) ResolveReports(ctx context.Context, adID, moderatorID int64, outcome ads.ReportOutcome, at time.Time) ([]*ads.Report, error) {
+	span := lockWithSpan(ctx, "AdRepo.ResolveReports", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.live(adID); !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	var resolved []*ads.Report
+	for _, r := range ar.reports {
+		if r.AdID != adID || !r.Open() {
+			continue
+		}
+		r, prev := r, *r
+		onRollback(ctx, ar.mx, func() { *r = prev })
+		r.Outcome = outcome
+		r.ResolvedBy = moderatorID
+		r.ResolvedAt = at
+		c := *r
+		resolved = append(resolved, &c)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].ID < resolved[j].ID })
+	return resolved, nil
+}
+
+// reportsMatching returns copies of reports matching f in the order they were made
+func (ar *AdRepo) reportsMatching(f func(*ads.Report) bool) []*ads.Report {
+	var res []*ads.Report
+	for _, r := range ar.reports {
+		if f(r) {
+			c := *r
+			res = append(res, &c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// AddAppeal stores an appeal against the takedown of the live ad assigning it an ID,
+// an ad may have one pending appeal
+func (ar *AdRepo) AddAppeal(ctx context.Context, ap *ads.Appeal) (*ads.Appeal, error) {
+	span := lockWithSpan(ctx, "AdRepo.AddAppeal", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	if _, ok := ar.live(ap.AdID); !ok {
+		return nil, errs.AdNotFoundError.WithResource(errs.ResourceAd, ap.AdID)
+	}
+	for _, o := range ar.appeals {
+		if o.AdID == ap.AdID && o.Status == ads.AppealPending {
+			return nil, errs.AppealPendingError.WithResource(errs.ResourceAd, ap.AdID)
+		}
+	}
+	id, err := ar.nextID()
+	if err != nil {
+		return nil, err
+	}
+	ap.ID = id
+	ar.appeals[ap.ID] = ap
+	onRollback(ctx, ar.mx, func() { delete(ar.appeals, ap.ID) })
+	c := *ap
+	return &c, nil
+}
+
+// GetAppeal returns a copy of the appeal
+func (ar *AdRepo) GetAppeal(ctx context.Context, id int64) (*ads.Appeal, error) {
+	span := lockWithSpan(ctx, "AdRepo.GetAppeal", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ap, ok := ar.appeals[id]
+	if !ok {
+		return nil, errs.AppealNotFoundError.WithResource(errs.ResourceAppeal, id)
+	}
+	c := *ap
+	return &c, nil
+}
+
+// PendingAppeals returns copies of pending appeals of live ads, oldest first
+func (ar *AdRepo) PendingAppeals(ctx context.Context) ([]*ads.Appeal, error) {
+	span := lockWithSpan(ctx, "AdRepo.PendingAppeals", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	var res []*ads.Appeal
+	for _, ap := range ar.appeals {
+		if _, live := ar.live(ap.AdID); live && ap.Status == ads.AppealPending {
+			c := *ap
+			res = append(res, &c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+// ResolveAppeal accepts or rejects the pending appeal on behalf of the moderator
+func (ar *AdRepo) ResolveAppeal(ctx context.Context, id, moderatorID int64, accept bool, at time.Time) (*ads.Appeal, error) {
+	span := lockWithSpan(ctx, "AdRepo.ResolveAppeal", ar.mx)
+	defer span.End()
+	defer ar.mx.Unlock()
+	ap, ok := ar.appeals[id]
+	if !ok {
+		return nil, errs.AppealNotFoundError.WithResource(errs.ResourceAppeal, id)
+	}
+	if ap.Status != ads.AppealPending {
+		return nil, errs.AppealResolvedError.WithResource(errs.ResourceAppeal, id)
+	}
+	prev := *ap
+	onRollback(ctx, ar.mx, func() { *ap = prev })
+	ap.Status = ads.AppealRejected
+	if accept {
+		ap.Status = ads.AppealAccepted
+	}
+	ap.ModeratorID = moderatorID
+	ap.ResolvedAt = at
+	c := *ap
+	return &c, nil
+}
+
+// keepState makes rollback of the unit of work carried by ctx put the ad back in its current state
+func (ar *AdRepo) keepState(ctx context.Context, ad *ads.Ad) {
+	prev := *ad
+	onRollback(ctx, ar.mx, func() { *ad = prev })
+}
+
+// Ping reports storage availability, in-memory storage is always available
+func (ar *AdRepo) Ping(_ context.Context) error {
+	return nil
+}
+
 // NewAd is a constructor
-func NewAd() app.AdRepository {
+func NewAd(opts ...Option) app.AdRepository {
 	return &AdRepo{
-		mx:      &sync.Mutex{},
-		storage: make(map[int64]*ads.Ad, 1),
-		lastID:  0,
+		config:      newConfig(opts),
+		mx:          &sync.Mutex{},
+		storage:     make(map[int64]*ads.Ad, 1),
+		revisions:   make(map[int64][]*ads.Revision),
+		approvals:   make(map[int64]*ads.Approval),
+		transitions: make(map[int64]*ads.Transition),
+		favorites:   make(map[int64]map[int64]*ads.Favorite),
+		reports:     make(map[int64]*ads.Report),
+		appeals:     make(map[int64]*ads.Appeal),
 	}
 }
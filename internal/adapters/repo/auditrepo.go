@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"ads-server/internal/app"
+	"ads-server/internal/audit"
+	"context"
+	"sync"
+)
+
+// AuditRepo is an append-only audit log, entries can't be changed or removed once the unit of work commits
+type AuditRepo struct {
+	entries []*audit.Entry
+	mx      *sync.Mutex
+}
+
+// Append chains the entry to the last one and stores a copy of it
+func (r *AuditRepo) Append(ctx context.Context, e *audit.Entry) error {
+	span := lockWithSpan(ctx, "AuditRepo.Append", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+
+	var prev *audit.Entry
+	if n := len(r.entries); n > 0 {
+		prev = r.entries[n-1]
+	}
+	e.Chain(prev)
+	stored := *e
+	r.entries = append(r.entries, &stored)
+
+	n := len(r.entries) - 1
+	// entries of a rolled back unit of work were never committed, so dropping them keeps the chain intact
+	onRollback(ctx, r.mx, func() {
+		r.entries = r.entries[:n]
+	})
+	return nil
+}
+
+// Query returns copies of the entries matching the filter, oldest first
+func (r *AuditRepo) Query(ctx context.Context, f audit.Filter) ([]*audit.Entry, error) {
+	span := lockWithSpan(ctx, "AuditRepo.Query", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+
+	var res []*audit.Entry
+	for _, e := range r.entries {
+		if f.Match(e) {
+			c := *e
+			res = append(res, &c)
+		}
+	}
+	return res, nil
+}
+
+func NewAudit() app.AuditLog {
+	return &AuditRepo{
+		mx: &sync.Mutex{},
+	}
+}
@@ -0,0 +1,256 @@
+package repo
+
+import (
+	"ads-server/internal/app"
+	"ads-server/internal/errs"
+	"ads-server/internal/messages"
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// conversationKey identifies the only conversation a buyer may have about an ad
+type conversationKey struct {
+	adID    int64
+	buyerID int64
+}
+
+type MessageRepo struct {
+	conversations map[int64]*messages.Conversation
+	byAd          map[conversationKey]int64
+	// messages keeps messages of every conversation, oldest first
+	messages map[int64][]*messages.Message
+	// blocks keeps users blocked by every user
+	blocks map[int64]map[int64]*messages.Block
+	mx     *sync.Mutex
+	config
+}
+
+// OpenConversation stores the conversation unless the buyer already has one about the ad
+func (r *MessageRepo) OpenConversation(ctx context.Context, c *messages.Conversation) (*messages.Conversation, error) {
+	span := lockWithSpan(ctx, "MessageRepo.OpenConversation", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	key := conversationKey{adID: c.AdID, buyerID: c.BuyerID}
+	if id, ok := r.byAd[key]; ok {
+		stored := *r.conversations[id]
+		return &stored, nil
+	}
+	id, err := r.nextID()
+	if err != nil {
+		return nil, err
+	}
+	stored := *c
+	stored.ID = id
+	r.conversations[id] = &stored
+	r.byAd[key] = id
+	onRollback(ctx, r.mx, func() {
+		delete(r.conversations, id)
+		delete(r.byAd, key)
+	})
+	res := stored
+	return &res, nil
+}
+
+// GetConversation returns a copy of the conversation
+func (r *MessageRepo) GetConversation(ctx context.Context, id int64) (*messages.Conversation, error) {
+	span := lockWithSpan(ctx, "MessageRepo.GetConversation", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	c, ok := r.conversations[id]
+	if !ok {
+		return nil, errs.ConversationNotFoundError.WithResource(errs.ResourceConversation, id)
+	}
+	res := *c
+	return &res, nil
+}
+
+// FindConversation returns a copy of the conversation of the buyer about the ad
+func (r *MessageRepo) FindConversation(ctx context.Context, adID, buyerID int64) (*messages.Conversation, error) {
+	span := lockWithSpan(ctx, "MessageRepo.FindConversation", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	id, ok := r.byAd[conversationKey{adID: adID, buyerID: buyerID}]
+	if !ok {
+		return nil, errs.ConversationNotFoundError.WithResource(errs.ResourceAd, adID)
+	}
+	res := *r.conversations[id]
+	return &res, nil
+}
+
+// Conversations returns copies of conversations of the participant with the latest activity first
+func (r *MessageRepo) Conversations(ctx context.Context, uID int64) ([]*messages.Conversation, error) {
+	span := lockWithSpan(ctx, "MessageRepo.Conversations", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	var res []*messages.Conversation
+	for _, c := range r.conversations {
+		if c.Participant(uID) {
+			cp := *c
+			res = append(res, &cp)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		ai, aj := lastActivity(res[i]), lastActivity(res[j])
+		if !ai.Equal(aj) {
+			return ai.After(aj)
+		}
+		return res[i].ID > res[j].ID
+	})
+	return res, nil
+}
+
+// lastActivity returns when the last message was sent or the conversation was started if there are none
+func lastActivity(c *messages.Conversation) time.Time {
+	if c.LastMessageAt.IsZero() {
+		return c.CreatedAt
+	}
+	return c.LastMessageAt
+}
+
+// AddMessage stores the message counting it as unread for the other participant
+func (r *MessageRepo) AddMessage(ctx context.Context, m *messages.Message) (*messages.Message, error) {
+	span := lockWithSpan(ctx, "MessageRepo.AddMessage", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	c, ok := r.conversations[m.ConversationID]
+	if !ok {
+		return nil, errs.ConversationNotFoundError.WithResource(errs.ResourceConversation, m.ConversationID)
+	}
+	id, err := r.nextID()
+	if err != nil {
+		return nil, err
+	}
+	stored := *m
+	stored.ID = id
+	before := *c
+	r.messages[c.ID] = append(r.messages[c.ID], &stored)
+	c.Received(c.Peer(m.SenderID), m.CreatedAt)
+	n := len(r.messages[c.ID]) - 1
+	onRollback(ctx, r.mx, func() {
+		r.messages[c.ID] = r.messages[c.ID][:n]
+		*c = before
+	})
+	res := stored
+	return &res, nil
+}
+
+// Messages returns copies of a page of messages of the conversation, newest first
+func (r *MessageRepo) Messages(ctx context.Context, conversationID int64, p messages.Page) ([]*messages.Message, error) {
+	span := lockWithSpan(ctx, "MessageRepo.Messages", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	if _, ok := r.conversations[conversationID]; !ok {
+		return nil, errs.ConversationNotFoundError.WithResource(errs.ResourceConversation, conversationID)
+	}
+	all := r.messages[conversationID]
+	end := len(all)
+	if p.Before != 0 {
+		end = -1
+		for i, m := range all {
+			if m.ID == p.Before {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, errs.MessageNotFoundError.WithResource(errs.ResourceConversation, conversationID)
+		}
+	}
+	res := make([]*messages.Message, 0, p.Limit)
+	for i := end - 1; i >= 0 && len(res) < p.Limit; i-- {
+		c := *all[i]
+		res = append(res, &c)
+	}
+	return res, nil
+}
+
+// MarkRead resets the unread counter of the participant
+func (r *MessageRepo) MarkRead(ctx context.Context, conversationID, uID int64) error {
+	span := lockWithSpan(ctx, "MessageRepo.MarkRead", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	c, ok := r.conversations[conversationID]
+	if !ok {
+		return errs.ConversationNotFoundError.WithResource(errs.ResourceConversation, conversationID)
+	}
+	before := *c
+	c.Read(uID)
+	onRollback(ctx, r.mx, func() { *c = before })
+	return nil
+}
+
+// Block stores the block unless the user has already blocked the other one, the stored block is returned
+func (r *MessageRepo) Block(ctx context.Context, b *messages.Block) (*messages.Block, error) {
+	span := lockWithSpan(ctx, "MessageRepo.Block", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	if stored, ok := r.blocks[b.UserID][b.BlockedID]; ok {
+		c := *stored
+		return &c, nil
+	}
+	if r.blocks[b.UserID] == nil {
+		r.blocks[b.UserID] = make(map[int64]*messages.Block)
+	}
+	stored := *b
+	r.blocks[b.UserID][b.BlockedID] = &stored
+	onRollback(ctx, r.mx, func() { delete(r.blocks[b.UserID], b.BlockedID) })
+	c := stored
+	return &c, nil
+}
+
+// Unblock removes the block of the other user made by the user
+func (r *MessageRepo) Unblock(ctx context.Context, uID, blockedID int64) error {
+	span := lockWithSpan(ctx, "MessageRepo.Unblock", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	b, ok := r.blocks[uID][blockedID]
+	if !ok {
+		return errs.BlockNotFoundError.WithResource(errs.ResourceUser, blockedID)
+	}
+	delete(r.blocks[uID], blockedID)
+	onRollback(ctx, r.mx, func() { r.blocks[uID][blockedID] = b })
+	return nil
+}
+
+// Blocks returns copies of blocks made by the user, latest first
+func (r *MessageRepo) Blocks(ctx context.Context, uID int64) ([]*messages.Block, error) {
+	span := lockWithSpan(ctx, "MessageRepo.Blocks", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	res := make([]*messages.Block, 0, len(r.blocks[uID]))
+	for _, b := range r.blocks[uID] {
+		c := *b
+		res = append(res, &c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.After(res[j].CreatedAt)
+		}
+		return res[i].BlockedID > res[j].BlockedID
+	})
+	return res, nil
+}
+
+// Blocked reports whether either user blocked the other one
+func (r *MessageRepo) Blocked(ctx context.Context, uID, otherID int64) (bool, error) {
+	span := lockWithSpan(ctx, "MessageRepo.Blocked", r.mx)
+	defer span.End()
+	defer r.mx.Unlock()
+	_, blocked := r.blocks[uID][otherID]
+	_, blockedBy := r.blocks[otherID][uID]
+	return blocked || blockedBy, nil
+}
+
+// NewMessages creates in-memory message repository, conversations and messages share the ID generator
+func NewMessages(opts ...Option) app.MessageRepository {
+	return &MessageRepo{
+		conversations: make(map[int64]*messages.Conversation),
+		byAd:          make(map[conversationKey]int64),
+		messages:      make(map[int64][]*messages.Message),
+		blocks:        make(map[int64]map[int64]*messages.Block),
+		mx:            &sync.Mutex{},
+		config:        newConfig(opts),
+	}
+}
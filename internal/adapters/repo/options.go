@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"ads-server/internal/clock"
+	"ads-server/internal/errs"
+	"ads-server/internal/ids"
+)
+
+// Option configures in-memory repositories
+type Option func(*config)
+
+type config struct {
+	clock clock.Clock
+	ids   ids.Generator
+}
+
+// WithClock sets the clock creation, update and deletion times are taken from
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+// WithIDs sets the generator of IDs of new records, repositories must not share a sequential generator
+func WithIDs(g ids.Generator) Option {
+	return func(cfg *config) {
+		cfg.ids = g
+	}
+}
+
+// newConfig applies options to defaults: the system clock and IDs counted from zero
+func newConfig(opts []Option) config {
+	cfg := config{
+		clock: clock.System,
+		ids:   ids.NewSequential(0),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// nextID returns an ID for a new record
+func (cfg config) nextID() (int64, error) {
+	id, err := cfg.ids.Next()
+	if err != nil {
+		return 0, errs.Wrap(errs.Internal, "can't generate ID", err)
+	}
+	return id, nil
+}
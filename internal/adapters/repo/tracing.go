@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("ads-server/internal/adapters/repo")
+
+// lockWithSpan starts a span named after the repository call and acquires mx,
+// recording the time spent waiting for the lock so contention is visible in traces
+func lockWithSpan(ctx context.Context, name string, mx *sync.Mutex) trace.Span {
+	_, span := tracer.Start(ctx, name)
+	start := time.Now()
+	mx.Lock()
+	span.SetAttributes(attribute.Int64("repo.lock_wait_ns", time.Since(start).Nanoseconds()))
+	return span
+}
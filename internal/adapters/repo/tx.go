@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"context"
+	"sync"
+
+	"ads-server/internal/errs"
+	"ads-server/internal/uow"
+)
+
+// onRollback registers f to be run under mx if the unit of work carried by ctx is rolled back,
+// f must revert the change just made to the storage
+func onRollback(ctx context.Context, mx *sync.Mutex, f func()) {
+	uow.OnRollback(ctx, func() {
+		mx.Lock()
+		defer mx.Unlock()
+		f()
+	})
+}
+
+// checkVersion returns errs.VersionConflictError unless the resource has the version expected,
+// zero expected version skips the check
+func checkVersion(typ string, id, current, expected int64) error {
+	if expected != 0 && current != expected {
+		return errs.VersionConflictError.WithResource(typ, id)
+	}
+	return nil
+}
@@ -5,71 +5,389 @@ import (
 	"ads-server/internal/errs"
 	"ads-server/internal/users"
 	"context"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 type UsersRepo struct {
 	storage map[int64]*users.User
+	// emails is a unique index of normalized emails to user IDs
+	emails map[string]int64
+	// tokens keeps the only active verification token of each user
+	tokens map[int64]*users.VerificationToken
+	// reviews keeps reviews of sellers by their IDs
+	reviews map[int64]*users.Review
 	mx      *sync.Mutex
-	lastID  int64
+	config
+}
+
+// emailKey normalizes email for the unique index, addresses differing only in case are the same mailbox in practice
+func emailKey(email string) string {
+	return strings.ToLower(email)
+}
+
+// emailTaken reports whether the email belongs to a user other than the one given
+func (ur *UsersRepo) emailTaken(email string, id int64) bool {
+	owner, ok := ur.emails[emailKey(email)]
+	return ok && owner != id
 }
 
 // Create creates a new user
-func (ur *UsersRepo) Create(_ context.Context, u *users.User) (id int64, err error) {
-	ur.mx.Lock()
+func (ur *UsersRepo) Create(ctx context.Context, u *users.User) (id int64, err error) {
+	span := lockWithSpan(ctx, "UsersRepo.Create", ur.mx)
+	defer span.End()
 	defer ur.mx.Unlock()
-	if _, ok := ur.storage[ur.lastID]; ok {
-		return -1, errs.UserNotFoundError
+	if ur.emailTaken(u.Email, -1) {
+		return -1, errs.EmailTakenError.WithFields(errs.FieldViolation{Field: "email", Description: "is already registered"})
+	}
+	id, err = ur.nextID()
+	if err != nil {
+		return -1, err
 	}
-	u.ID = ur.lastID
+	if _, ok := ur.storage[id]; ok {
+		return -1, errs.UserExistsError.WithResource(errs.ResourceUser, id)
+	}
+	u.ID = id
+	u.Version = 1
 	ur.storage[u.ID] = u
-	ur.lastID++
+	ur.emails[emailKey(u.Email)] = u.ID
+	onRollback(ctx, ur.mx, func() {
+		delete(ur.emails, emailKey(u.Email))
+		delete(ur.storage, u.ID)
+	})
 
-	return ur.lastID - 1, nil
+	return id, nil
 }
 
-// Update updates an existing user
-func (ur *UsersRepo) Update(_ context.Context, id int64, name string, email string) (*users.User, error) {
-	ur.mx.Lock()
+// Update updates an existing user, changing email resets its verification.
+// The user must have the version given unless it is zero.
+func (ur *UsersRepo) Update(ctx context.Context, id int64, name string, email string, version int64) (*users.User, error) {
+	span := lockWithSpan(ctx, "UsersRepo.Update", ur.mx)
+	defer span.End()
 	defer ur.mx.Unlock()
-	if _, ok := ur.storage[id]; !ok {
-		return nil, errs.UserNotFoundError
+	u, ok := ur.live(id)
+	if !ok {
+		return nil, errs.UserNotFoundError.WithResource(errs.ResourceUser, id)
+	}
+	if err := checkVersion(errs.ResourceUser, id, u.Version, version); err != nil {
+		return nil, err
+	}
+	if ur.emailTaken(email, id) {
+		return nil, errs.EmailTakenError.WithFields(errs.FieldViolation{Field: "email", Description: "is already registered"})
 	}
 
-	ur.storage[id].Name = name
-	ur.storage[id].Email = email
-	return ur.storage[id], nil
+	ur.keepState(ctx, u)
+	if emailKey(u.Email) != emailKey(email) {
+		delete(ur.emails, emailKey(u.Email))
+		ur.emails[emailKey(email)] = id
+		u.Verified = false
+		delete(ur.tokens, id)
+	}
+	u.Name = name
+	u.Email = email
+	u.Version++
+	c := *u
+	return &c, nil
 }
 
-// Delete deletes user from storage
-func (ur *UsersRepo) Delete(_ context.Context, id int64) error {
-	ur.mx.Lock()
+// Delete moves user to trash, the user must have the version given unless it is zero.
+// The email stays taken until the user is purged, so the user can be restored.
+func (ur *UsersRepo) Delete(ctx context.Context, id int64, version int64) error {
+	span := lockWithSpan(ctx, "UsersRepo.Delete", ur.mx)
+	defer span.End()
 	defer ur.mx.Unlock()
 
-	if _, ok := ur.storage[id]; ok {
-		delete(ur.storage, id)
+	if u, ok := ur.live(id); ok {
+		if err := checkVersion(errs.ResourceUser, id, u.Version, version); err != nil {
+			return err
+		}
+		ur.keepState(ctx, u)
+		delete(ur.tokens, id)
+		u.DeletedAt = ur.clock.Now()
+		u.Version++
 		return nil
 	}
 
-	return errs.UserNotFoundError
+	return errs.UserNotFoundError.WithResource(errs.ResourceUser, id)
+}
 
+// Restore takes the user out of trash
+func (ur *UsersRepo) Restore(ctx context.Context, id int64) (*users.User, error) {
+	span := lockWithSpan(ctx, "UsersRepo.Restore", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	u, ok := ur.storage[id]
+	if !ok {
+		return nil, errs.UserNotFoundError.WithResource(errs.ResourceUser, id)
+	}
+	if !u.Deleted() {
+		return nil, errs.NotDeletedError.WithResource(errs.ResourceUser, id)
+	}
+	ur.keepState(ctx, u)
+	u.DeletedAt = time.Time{}
+	u.Version++
+	c := *u
+	return &c, nil
+}
+
+// Purge permanently removes users moved to trash before the moment given, returning their IDs
+func (ur *UsersRepo) Purge(ctx context.Context, before time.Time) ([]int64, error) {
+	span := lockWithSpan(ctx, "UsersRepo.Purge", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	var purged []int64
+	for id, u := range ur.storage {
+		if u.Deleted() && u.DeletedAt.Before(before) {
+			id, u := id, u
+			delete(ur.emails, emailKey(u.Email))
+			delete(ur.storage, id)
+			onRollback(ctx, ur.mx, func() {
+				ur.storage[id] = u
+				ur.emails[emailKey(u.Email)] = id
+			})
+			purged = append(purged, id)
+		}
+	}
+	return purged, nil
+}
+
+// live returns the user unless it is missing or in trash
+func (ur *UsersRepo) live(id int64) (*users.User, bool) {
+	u, ok := ur.storage[id]
+	if !ok || u.Deleted() {
+		return nil, false
+	}
+	return u, true
 }
 
 // Get returns a user by ID given
-func (ur *UsersRepo) Get(_ context.Context, id int64) (*users.User, error) {
-	ur.mx.Lock()
+func (ur *UsersRepo) Get(ctx context.Context, id int64) (*users.User, error) {
+	span := lockWithSpan(ctx, "UsersRepo.Get", ur.mx)
+	defer span.End()
 	defer ur.mx.Unlock()
-	if user, ok := ur.storage[id]; ok {
-		return user, nil
+	if user, ok := ur.live(id); ok {
+		c := *user
+		return &c, nil
+	}
+	return nil, errs.UserNotFoundError.WithResource(errs.ResourceUser, id)
+}
+
+// GetByEmail returns a user by email given, case is ignored
+func (ur *UsersRepo) GetByEmail(ctx context.Context, email string) (*users.User, error) {
+	span := lockWithSpan(ctx, "UsersRepo.GetByEmail", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	if id, ok := ur.emails[emailKey(email)]; ok {
+		if u, ok := ur.live(id); ok {
+			c := *u
+			return &c, nil
+		}
 	}
 	return nil, errs.UserNotFoundError
 }
 
+// SaveToken stores verification token replacing the previous one of the user
+func (ur *UsersRepo) SaveToken(ctx context.Context, t *users.VerificationToken) error {
+	span := lockWithSpan(ctx, "UsersRepo.SaveToken", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	if _, ok := ur.live(t.UserID); !ok {
+		return errs.UserNotFoundError.WithResource(errs.ResourceUser, t.UserID)
+	}
+	ur.keepToken(ctx, t.UserID)
+	ur.tokens[t.UserID] = t
+	return nil
+}
+
+// UserToken returns the active verification token of the user
+func (ur *UsersRepo) UserToken(ctx context.Context, uID int64) (*users.VerificationToken, error) {
+	span := lockWithSpan(ctx, "UsersRepo.UserToken", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	if t, ok := ur.tokens[uID]; ok {
+		return t, nil
+	}
+	return nil, errs.VerificationTokenError
+}
+
+// Verify marks the user owning the token as verified and consumes the token.
+// The token must not be expired at the moment given and must have been issued for the email the user currently has.
+func (ur *UsersRepo) Verify(ctx context.Context, hash string, now time.Time) (*users.User, error) {
+	span := lockWithSpan(ctx, "UsersRepo.Verify", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	for uID, t := range ur.tokens {
+		if t.Hash != hash {
+			continue
+		}
+		u, ok := ur.live(uID)
+		if !ok || t.Expired(now) || emailKey(u.Email) != emailKey(t.Email) {
+			break
+		}
+		ur.keepState(ctx, u)
+		u.Verified = true
+		u.Version++
+		delete(ur.tokens, uID)
+		c := *u
+		return &c, nil
+	}
+	return nil, errs.VerificationTokenError
+}
+
+// keepState makes rollback of the unit of work carried by ctx put the user,
+// its email index entry and verification token back in their current state
+func (ur *UsersRepo) keepState(ctx context.Context, u *users.User) {
+	prev := *u
+	onRollback(ctx, ur.mx, func() {
+		delete(ur.emails, emailKey(u.Email))
+		*u = prev
+		ur.emails[emailKey(u.Email)] = u.ID
+	})
+	ur.keepToken(ctx, u.ID)
+}
+
+// keepToken makes rollback of the unit of work carried by ctx put the current verification token of the user back
+func (ur *UsersRepo) keepToken(ctx context.Context, uID int64) {
+	t, ok := ur.tokens[uID]
+	onRollback(ctx, ur.mx, func() {
+		if ok {
+			ur.tokens[uID] = t
+			return
+		}
+		delete(ur.tokens, uID)
+	})
+}
+
+// AddReview stores a copy of the review counting it in the reputation of the seller, a buyer may review each ad once
+func (ur *UsersRepo) AddReview(ctx context.Context, r *users.Review) (*users.Review, error) {
+	span := lockWithSpan(ctx, "UsersRepo.AddReview", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	seller, ok := ur.live(r.SellerID)
+	if !ok {
+		return nil, errs.UserNotFoundError.WithResource(errs.ResourceUser, r.SellerID)
+	}
+	for _, other := range ur.reviews {
+		if other.AdID == r.AdID && other.AuthorID == r.AuthorID {
+			return nil, errs.ReviewExistsError.WithResource(errs.ResourceReview, other.ID)
+		}
+	}
+	id, err := ur.nextID()
+	if err != nil {
+		return nil, err
+	}
+	ur.keepState(ctx, seller)
+	stored := *r
+	stored.ID = id
+	ur.reviews[stored.ID] = &stored
+	if !stored.Hidden {
+		seller.Reputation.Add(stored.Rating)
+	}
+	onRollback(ctx, ur.mx, func() { delete(ur.reviews, stored.ID) })
+	c := stored
+	return &c, nil
+}
+
+// GetReview returns a copy of the review
+func (ur *UsersRepo) GetReview(ctx context.Context, id int64) (*users.Review, error) {
+	span := lockWithSpan(ctx, "UsersRepo.GetReview", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	r, ok := ur.reviews[id]
+	if !ok {
+		return nil, errs.ReviewNotFoundError.WithResource(errs.ResourceReview, id)
+	}
+	c := *r
+	return &c, nil
+}
+
+// Reviews returns copies of reviews of the seller, latest first, hidden ones only if asked
+func (ur *UsersRepo) Reviews(ctx context.Context, sellerID int64, withHidden bool) ([]*users.Review, error) {
+	span := lockWithSpan(ctx, "UsersRepo.Reviews", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	var res []*users.Review
+	for _, r := range ur.reviews {
+		if r.SellerID == sellerID && (withHidden || !r.Hidden) {
+			c := *r
+			res = append(res, &c)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.After(res[j].CreatedAt)
+		}
+		return res[i].ID > res[j].ID
+	})
+	return res, nil
+}
+
+// ReplyToReview stores the only reply of the seller
+func (ur *UsersRepo) ReplyToReview(ctx context.Context, id int64, reply string, at time.Time) (*users.Review, error) {
+	span := lockWithSpan(ctx, "UsersRepo.ReplyToReview", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	r, ok := ur.reviews[id]
+	if !ok {
+		return nil, errs.ReviewNotFoundError.WithResource(errs.ResourceReview, id)
+	}
+	if r.Replied() {
+		return nil, errs.ReviewRepliedError.WithResource(errs.ResourceReview, id)
+	}
+	prev := *r
+	r.Reply = reply
+	r.RepliedAt = at
+	onRollback(ctx, ur.mx, func() { *r = prev })
+	c := *r
+	return &c, nil
+}
+
+// HideReview hides or shows the review updating the reputation of the seller, it is a no-op if nothing changes
+func (ur *UsersRepo) HideReview(ctx context.Context, id int64, hidden bool, moderatorID int64) (*users.Review, error) {
+	span := lockWithSpan(ctx, "UsersRepo.HideReview", ur.mx)
+	defer span.End()
+	defer ur.mx.Unlock()
+	r, ok := ur.reviews[id]
+	if !ok {
+		return nil, errs.ReviewNotFoundError.WithResource(errs.ResourceReview, id)
+	}
+	if r.Hidden != hidden {
+		prev := *r
+		onRollback(ctx, ur.mx, func() { *r = prev })
+		// the seller may be in trash, its reputation is kept for restoring
+		if seller, ok := ur.storage[r.SellerID]; ok {
+			ur.keepState(ctx, seller)
+			if hidden {
+				seller.Reputation.Remove(r.Rating)
+			} else {
+				seller.Reputation.Add(r.Rating)
+			}
+		}
+		r.Hidden = hidden
+		r.HiddenBy = 0
+		if hidden {
+			r.HiddenBy = moderatorID
+		}
+	}
+	c := *r
+	return &c, nil
+}
+
+// Ping reports storage availability, in-memory storage is always available
+func (ur *UsersRepo) Ping(_ context.Context) error {
+	return nil
+}
+
 // NewUser is a constructor
-func NewUser() app.UserRepository {
+func NewUser(opts ...Option) app.UserRepository {
 	return &UsersRepo{
+		config:  newConfig(opts),
 		mx:      &sync.Mutex{},
 		storage: make(map[int64]*users.User, 1),
-		lastID:  0,
+		emails:  make(map[string]int64, 1),
+		tokens:  make(map[int64]*users.VerificationToken),
+		reviews: make(map[int64]*users.Review),
 	}
 }
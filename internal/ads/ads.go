@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// AnonymousAuthorID is the author of ads detached from their deleted author
+const AnonymousAuthorID int64 = -1
+
 type Ad struct {
 	ID        int64
 	Title     string
@@ -12,17 +15,56 @@ type Ad struct {
 	CDate     time.Time
 	UDate     time.Time
 	Published bool
+	// Version is incremented by every change of the ad, it is 1 once the ad is stored
+	Version int64
+	// DeletedAt is set when the ad is moved to trash, zero for live ads
+	DeletedAt time.Time
+	// DeletedWithAuthor marks ads moved to trash together with their author, they are restored with it
+	DeletedWithAuthor bool
+	// Category is optional, it may define how long the ad lives
+	Category string
+	// ExpiresAt is when the ad is archived unless renewed, zero for ads that never expire
+	ExpiresAt time.Time
+	// ArchivedAt is set when the ad expires, archived ads are unpublished until renewed
+	ArchivedAt time.Time
+	// ExpiryWarned is set once the author is warned about the coming expiration
+	ExpiryWarned bool
+	// TakenDownAt is set when the ad is unpublished because of abuse reports,
+	// such ads can't be published until the takedown is lifted
+	TakenDownAt time.Time
+	// Screening records objections of content filters to the current title and text
+	Screening Screening
+}
+
+// Deleted reports whether the ad is in trash
+func (ad *Ad) Deleted() bool {
+	return !ad.DeletedAt.IsZero()
+}
+
+// Archived reports whether the ad expired and was not renewed since
+func (ad *Ad) Archived() bool {
+	return !ad.ArchivedAt.IsZero()
+}
+
+// TakenDown reports whether the ad was taken down because of abuse reports
+func (ad *Ad) TakenDown() bool {
+	return !ad.TakenDownAt.IsZero()
+}
+
+// ExpiresBy reports whether the live ad expires at the moment given or earlier
+func (ad *Ad) ExpiresBy(t time.Time) bool {
+	return !ad.ExpiresAt.IsZero() && !ad.Archived() && !ad.ExpiresAt.After(t)
 }
 
-func New(aID int64, title string, text string) *Ad {
-	creationTime := time.Now().UTC()
+// New creates an unpublished ad of the author created at the moment given
+func New(aID int64, title string, text string, now time.Time) *Ad {
 	return &Ad{
 		ID:        0,
 		Title:     title,
 		Text:      text,
 		AuthorID:  aID,
-		CDate:     creationTime,
-		UDate:     creationTime,
+		CDate:     now,
+		UDate:     now,
 		Published: false,
 	}
 }
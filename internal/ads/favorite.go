@@ -0,0 +1,10 @@
+package ads
+
+import "time"
+
+// Favorite is an ad saved by a user, the user watches changes of the ad
+type Favorite struct {
+	UserID    int64
+	AdID      int64
+	CreatedAt time.Time
+}
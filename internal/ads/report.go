@@ -0,0 +1,80 @@
+package ads
+
+import "time"
+
+// ReportReason tells why an ad was reported
+type ReportReason string
+
+const (
+	ReasonSpam       ReportReason = "spam"
+	ReasonFraud      ReportReason = "fraud"
+	ReasonProhibited ReportReason = "prohibited"
+	ReasonOffensive  ReportReason = "offensive"
+	ReasonDuplicate  ReportReason = "duplicate"
+	// ReasonOther requires a comment explaining the reason
+	ReasonOther ReportReason = "other"
+)
+
+// ReportReasons lists reasons an ad can be reported for
+var ReportReasons = []ReportReason{ReasonSpam, ReasonFraud, ReasonProhibited, ReasonOffensive, ReasonDuplicate, ReasonOther}
+
+// ParseReportReason returns the reason named by s, ok is false for unknown names
+func ParseReportReason(s string) (_ ReportReason, ok bool) {
+	for _, r := range ReportReasons {
+		if string(r) == s {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// ReportOutcome is how a moderator resolved a report
+type ReportOutcome string
+
+const (
+	// OutcomeOpen is the outcome of reports nobody resolved yet
+	OutcomeOpen      ReportOutcome = ""
+	OutcomeTakenDown ReportOutcome = "taken_down"
+	OutcomeDismissed ReportOutcome = "dismissed"
+)
+
+// Report is a complaint of a user about an ad, a user may have one open report per ad
+type Report struct {
+	ID         int64
+	AdID       int64
+	ReporterID int64
+	Reason     ReportReason
+	Comment    string
+	CreatedAt  time.Time
+	Outcome    ReportOutcome
+	// ResolvedBy is the moderator who resolved the report
+	ResolvedBy int64
+	ResolvedAt time.Time
+}
+
+// Open reports whether the report still awaits a moderator
+func (r *Report) Open() bool {
+	return r.Outcome == OutcomeOpen
+}
+
+// AppealStatus is the state of an appeal against a takedown
+type AppealStatus string
+
+const (
+	AppealPending  AppealStatus = "pending"
+	AppealAccepted AppealStatus = "accepted"
+	AppealRejected AppealStatus = "rejected"
+)
+
+// Appeal is a request of the author to lift the takedown of an ad, an ad may have one pending appeal
+type Appeal struct {
+	ID       int64
+	AdID     int64
+	AuthorID int64
+	Comment  string
+	Status   AppealStatus
+	// ModeratorID is the moderator who resolved the appeal
+	ModeratorID int64
+	CreatedAt   time.Time
+	ResolvedAt  time.Time
+}
@@ -0,0 +1,95 @@
+package ads
+
+import (
+	"strconv"
+	"time"
+)
+
+// Action is the kind of change a revision was made by
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionPublish   Action = "publish"
+	ActionUnpublish Action = "unpublish"
+	ActionRollback  Action = "rollback"
+	ActionAnonymize Action = "anonymize"
+	ActionDelete    Action = "delete"
+	ActionRestore   Action = "restore"
+	ActionArchive   Action = "archive"
+	ActionRenew     Action = "renew"
+	ActionExtend    Action = "extend"
+	ActionTakedown  Action = "takedown"
+	ActionReinstate Action = "reinstate"
+)
+
+// FieldChange is a change of a single ad field, values are formatted as strings
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Revision is a snapshot of an ad taken after a change
+type Revision struct {
+	AdID int64
+	// Number is the version of the ad the revision captures
+	Number    int64
+	Action    Action
+	EditorID  int64
+	CreatedAt time.Time
+	// RestoredFrom is the number of the revision the ad was rolled back to, zero for other actions
+	RestoredFrom int64
+
+	Title     string
+	Text      string
+	AuthorID  int64
+	Published bool
+
+	// Changes lists fields differing from the previous revision
+	Changes []FieldChange
+}
+
+// NewRevision takes a snapshot of the ad, prev is the latest revision before the change or nil for a new ad
+func NewRevision(ad *Ad, action Action, editorID int64, prev *Revision, now time.Time) *Revision {
+	r := &Revision{
+		AdID:      ad.ID,
+		Number:    ad.Version,
+		Action:    action,
+		EditorID:  editorID,
+		CreatedAt: now,
+		Title:     ad.Title,
+		Text:      ad.Text,
+		AuthorID:  ad.AuthorID,
+		Published: ad.Published,
+	}
+	r.Changes = Diff(prev, r)
+	return r
+}
+
+// Diff lists fields of revision b differing from revision a, nil a stands for an empty ad
+func Diff(a, b *Revision) []FieldChange {
+	if a == nil {
+		a = &Revision{}
+	}
+	var changes []FieldChange
+	add := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	add("title", a.Title, b.Title)
+	add("text", a.Text, b.Text)
+	add("author_id", strconv.FormatInt(a.AuthorID, 10), strconv.FormatInt(b.AuthorID, 10))
+	add("published", strconv.FormatBool(a.Published), strconv.FormatBool(b.Published))
+	return changes
+}
+
+// Approval records that a moderator reviewed the ad as of a revision
+type Approval struct {
+	AdID        int64
+	Revision    int64
+	ModeratorID int64
+	At          time.Time
+}
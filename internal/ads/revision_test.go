@@ -0,0 +1,26 @@
+package ads
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRevisionDiff(t *testing.T) {
+	ad := New(3, "hello", "world", time.Now())
+	ad.Version = 1
+	first := NewRevision(ad, ActionCreate, 3, nil, time.Now())
+	assert.Equal(t, []FieldChange{
+		{Field: "title", Old: "", New: "hello"},
+		{Field: "text", Old: "", New: "world"},
+		{Field: "author_id", Old: "0", New: "3"},
+	}, first.Changes)
+
+	ad.Published = true
+	ad.Version = 2
+	second := NewRevision(ad, ActionPublish, 3, first, time.Now())
+	assert.Equal(t, int64(2), second.Number)
+	assert.Equal(t, []FieldChange{{Field: "published", Old: "false", New: "true"}}, second.Changes)
+	assert.Empty(t, Diff(second, second))
+}
@@ -0,0 +1,28 @@
+package ads
+
+import "time"
+
+// Transition is a status change of an ad scheduled for later
+type Transition struct {
+	ID   int64
+	AdID int64
+	// UserID is the user who scheduled the transition, it is made on behalf of that user
+	UserID int64
+	// Publish tells whether the ad is published or unpublished by the transition
+	Publish   bool
+	At        time.Time
+	CreatedAt time.Time
+}
+
+// Action returns the revision action the transition results in
+func (t *Transition) Action() Action {
+	if t.Publish {
+		return ActionPublish
+	}
+	return ActionUnpublish
+}
+
+// Due reports whether the transition has to be made by the moment given
+func (t *Transition) Due(now time.Time) bool {
+	return !t.At.After(now)
+}
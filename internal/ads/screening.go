@@ -0,0 +1,29 @@
+package ads
+
+// ContentFlag is an objection of a content filter to the ad
+type ContentFlag struct {
+	// Checker is the name of the filter
+	Checker string
+	// Field is "title" or "text"
+	Field  string
+	Reason string
+	// Masked is set when the objectionable fragment was masked, otherwise the ad is held for moderation
+	Masked bool
+}
+
+// Screening is the outcome of content filters for a revision of the ad
+type Screening struct {
+	// Revision is the version of the ad the filters looked at
+	Revision int64
+	Flags    []ContentFlag
+}
+
+// Held reports whether some flags need a moderator to approve the revision before the ad is published
+func (s Screening) Held() bool {
+	for _, f := range s.Flags {
+		if !f.Masked {
+			return true
+		}
+	}
+	return false
+}
@@ -1,154 +1,399 @@
 package app
 
 import (
-	"ads-server/internal/errs"
 	"context"
-	"errors"
-	"github.com/AntonShadrinNN/validatelength"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"net/url"
+	"strconv"
+	"time"
 
 	"ads-server/internal/ads"
+	"ads-server/internal/audit"
+	"ads-server/internal/clock"
+	"ads-server/internal/content"
+	"ads-server/internal/errs"
+	"ads-server/internal/idempotency"
+	"ads-server/internal/messages"
+	"ads-server/internal/ratelimit"
+	"ads-server/internal/uow"
 	"ads-server/internal/users"
+	"ads-server/internal/validation"
 )
 
-const (
-	titleConst = iota
-	textConst
-)
+var tracer = otel.Tracer("ads-server/internal/app")
+
+// endSpan records err on span (if any) and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
 
 type App struct {
-	adRepo   AdRepository
-	userRepo UserRepository
+	adRepo         AdRepository
+	userRepo       UserRepository
+	limits         validation.Limits
+	mail           MailSender
+	verification   VerificationConfig
+	deletionPolicy DeletionPolicy
+	uow            UnitOfWork
+	staff          map[string]users.Role
+	trash          TrashConfig
+	audit          AuditLog
+	expiration     ExpirationConfig
+	schedule       ScheduleConfig
+	clock          clock.Clock
+	messages       MessageRepository
+	hub            *messages.Hub
+	reports        ReportConfig
+	filters        *content.Pipeline
+	duplicates     DuplicateConfig
+	limiter        *ratelimit.Limiter
+	adQuota        int
+	idempotency    *idempotency.Store
 }
 
-func validate(s string, m int) error {
-	constraint := 0
-	if m == titleConst {
-		constraint = 100
-	} else if m == textConst {
-		constraint = 500
-	}
-	ok, err := validatelength.ValidateLen(s, 1, 0)
-	if err != nil || ok {
+// CreateAd creates new ad using repository, the category is optional and defines when the ad expires.
+// With DuplicatesMerge policy a near-duplicate of another ad of the author updates that ad instead,
+// merged reports it and the updated ad is returned.
+func (a App) CreateAd(ctx context.Context, uID int64, title string, text string, category string) (_ *ads.Ad, merged bool, err error) {
+	ctx, span := tracer.Start(ctx, "App.CreateAd")
+	defer func() { endSpan(span, err) }()
 
-		return errs.ValidationError
-	}
-	ok, err = validatelength.ValidateLen(s, 4, constraint)
-	if err != nil || ok {
-		return errs.ValidationError
+	if err = validation.Validate(append(a.limits.Ad(title, text), a.limits.Category(category))...); err != nil {
+		return nil, false, err
 	}
-	return nil
-}
 
-// CreateAd creates new ad using repository
-func (a App) CreateAd(ctx context.Context, uID int64, title string, text string) (*ads.Ad, error) {
-	err := validate(title, titleConst)
+	merge, dupFlags, err := a.checkDuplicates(ctx, uID, title, text, normalizeCategory(category))
 	if err != nil {
-		return nil, errs.ValidationError
+		return nil, false, err
+	}
+	if merge != nil {
+		// the author repeats the ad, so it is updated instead of creating another one,
+		// it is still a submission the quota applies to
+		if err = a.withinAdQuota(ctx, uID); err != nil {
+			return nil, false, err
+		}
+		ad, err := a.UpdateAd(ctx, merge.ID, uID, title, text, 0)
+		return ad, err == nil, err
 	}
-	err = validate(text, textConst)
+	title, text, flags, err := a.screen(ctx, title, text)
 	if err != nil {
-		return nil, errs.ValidationError
+		return nil, false, err
 	}
+	flags = append(flags, dupFlags...)
 
-	ad := ads.New(uID, title, text)
-	_, err = a.adRepo.Create(ctx, ad)
+	ad := ads.New(uID, title, text, a.clock.Now())
+	ad.Category = normalizeCategory(category)
+	ad.ExpiresAt = a.expiration.expiresAt(ad.Category, ad.CDate)
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		// counted in the same unit of work as the insert, so concurrent creates can't exceed the quota
+		if err = a.withinAdQuota(ctx, uID); err != nil {
+			return err
+		}
+		if _, err = a.adRepo.Create(ctx, ad); err != nil {
+			return err
+		}
+		if ad, err = a.setScreening(ctx, ad, flags); err != nil {
+			return err
+		}
+		if err = a.recordRevision(ctx, ad, ads.ActionCreate, uID, 0); err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionAdCreate, errs.ResourceAd, ad.ID, nil, ad)
+	})
 	if err != nil {
-		return nil, errs.AccessError
+		return nil, false, err
 	}
-	return ad, nil
+	return ad, false, nil
 }
 
-// UpdateAd updates ad using repository
-func (a App) UpdateAd(ctx context.Context, adID int64, uID int64, title string, text string) (*ads.Ad, error) {
-	err := validate(title, titleConst)
+// UpdateAd updates ad using repository, the ad must have the version given unless it is zero
+func (a App) UpdateAd(ctx context.Context, adID int64, uID int64, title string, text string, version int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.UpdateAd")
+	defer func() { endSpan(span, err) }()
+
+	if err = validation.Validate(a.limits.Ad(title, text)...); err != nil {
+		return nil, err
+	}
+
+	_, dupFlags, err := a.checkDuplicates(ctx, uID, title, text, "", adID)
+	if err != nil {
+		return nil, err
+	}
+	title, text, flags, err := a.screen(ctx, title, text)
 	if err != nil {
 		return nil, err
 	}
-	err = validate(text, textConst)
+	flags = append(flags, dupFlags...)
+
+	var ad *ads.Ad
+	var changed bool
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		old, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		before := *old
+		if ad, err = a.adRepo.Update(ctx, adID, uID, title, text, version); err != nil {
+			return err
+		}
+		if ad, err = a.setScreening(ctx, ad, flags); err != nil {
+			return err
+		}
+		changed = before.Title != ad.Title || before.Text != ad.Text
+		if err = a.recordRevision(ctx, ad, ads.ActionUpdate, uID, 0); err != nil {
+			return err
+		}
+		if err = a.record(ctx, uID, audit.ActionAdUpdate, errs.ResourceAd, adID, before, ad); err != nil {
+			return err
+		}
+		ad, err = a.hold(ctx, ad)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	if changed {
+		a.notifyWatchers(ctx, ad, "was changed")
+	}
+	return ad, nil
+}
+
+// DeleteAd deletes ad of the user, the ad must have the version given unless it is zero
+func (a App) DeleteAd(ctx context.Context, adID, uID int64, version int64) (err error) {
+	ctx, span := tracer.Start(ctx, "App.DeleteAd")
+	defer func() { endSpan(span, err) }()
+
+	return a.uow.Do(ctx, func(ctx context.Context) error {
+		old, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		before := *old
+		if err := a.adRepo.Delete(ctx, adID, uID, version); err != nil {
+			return err
+		}
+		ad, err := a.adRepo.GetDeleted(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if _, err = a.adRepo.RemoveAdFavorites(ctx, adID); err != nil {
+			return err
+		}
+		if err = a.recordRevision(ctx, ad, ads.ActionDelete, uID, 0); err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionAdDelete, errs.ResourceAd, adID, before, nil)
+	})
+}
+
+// PublishAd changes ad status using repository, the ad must have the version given unless it is zero
+func (a App) PublishAd(ctx context.Context, adID int64, uID int64, action bool, version int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.PublishAd")
+	defer func() { endSpan(span, err) }()
+
+	if action {
+		if err = a.verified(ctx, uID); err != nil {
+			return nil, err
+		}
+	}
 
-	ad, err := a.adRepo.Update(ctx, adID, uID, title, text)
+	act, auditAct := ads.ActionUnpublish, audit.ActionAdUnpublish
+	if action {
+		act, auditAct = ads.ActionPublish, audit.ActionAdPublish
+	}
+	var ad *ads.Ad
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		old, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if action && old.Archived() {
+			return errs.AdArchivedError.WithResource(errs.ResourceAd, adID)
+		}
+		if action && old.TakenDown() {
+			return errs.AdTakenDownError.WithResource(errs.ResourceAd, adID)
+		}
+		if action {
+			if err = a.released(ctx, old); err != nil {
+				return err
+			}
+		}
+		before := *old
+		if ad, err = a.adRepo.Publish(ctx, adID, uID, action, version); err != nil {
+			return err
+		}
+		if err = a.recordRevision(ctx, ad, act, uID, 0); err != nil {
+			return err
+		}
+		if err = a.record(ctx, uID, auditAct, errs.ResourceAd, adID, before, ad); err != nil {
+			return err
+		}
+		if before.Published && !action {
+			a.notifyUnpublished(ctx, ad)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errs.AccessError
+		return nil, err
 	}
 	return ad, nil
 }
 
-func (a App) DeleteAd(ctx context.Context, adID, uID int64) error {
-	err := a.adRepo.Delete(ctx, adID, uID)
+// unpublish unpublishes the ad on behalf of the actor, who isn't necessarily its author,
+// e.g. a moderator taking it down. It must be called in a unit of work.
+func (a App) unpublish(ctx context.Context, ad *ads.Ad, actorID int64) (*ads.Ad, error) {
+	before := *ad
+	after, err := a.adRepo.Publish(ctx, ad.ID, ad.AuthorID, false, 0)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	if err = a.recordRevision(ctx, after, ads.ActionUnpublish, actorID, 0); err != nil {
+		return nil, err
+	}
+	if err = a.record(ctx, actorID, audit.ActionAdUnpublish, errs.ResourceAd, ad.ID, before, after); err != nil {
+		return nil, err
+	}
+	a.notifyUnpublished(ctx, after)
+	return after, nil
+}
+
+// notifyUnpublished mails watchers of the ad once the unit of work unpublishing it commits
+func (a App) notifyUnpublished(ctx context.Context, ad *ads.Ad) {
+	afterCommit(ctx, func(ctx context.Context) {
+		a.notifyWatchers(ctx, ad, "was unpublished")
+	})
 }
 
-// PublishAd changes ad status using repository
-func (a App) PublishAd(ctx context.Context, adID int64, uID int64, action bool) (*ads.Ad, error) {
-	ad, err := a.adRepo.Publish(ctx, adID, uID, action)
+// verified returns EmailNotVerifiedError unless the user confirmed its email, only such users can publish ads
+func (a App) verified(ctx context.Context, uID int64) error {
+	u, err := a.userRepo.Get(ctx, uID)
 	if err != nil {
-		return nil, errs.AccessError
+		return err
 	}
-	return ad, nil
+	if !u.Verified {
+		return errs.EmailNotVerifiedError.WithResource(errs.ResourceUser, uID)
+	}
+	return nil
 }
 
 // GetAdByID returns ad by ID given using repository
-func (a App) GetAdByID(ctx context.Context, id int64) (*ads.Ad, error) {
+func (a App) GetAdByID(ctx context.Context, id int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.GetAdByID")
+	defer func() { endSpan(span, err) }()
+
 	ad, err := a.adRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, errs.AdNotFoundError
-
+		return nil, err
 	}
 	return ad, nil
 }
 
 // GetAdByName returns ad by name given using repository
 func (a App) GetAdByName(ctx context.Context, title string) []*ads.Ad {
+	ctx, span := tracer.Start(ctx, "App.GetAdByName")
+	defer span.End()
+
 	return a.adRepo.GetByName(ctx, title)
 }
 
 // FindUser returns user by ID given using repository
-func (a App) FindUser(ctx context.Context, id int64) (*users.User, error) {
-	if user, err := a.userRepo.Get(ctx, id); err == nil {
-		return user, nil
-	}
-	return nil, errs.UserNotFoundError
-}
+func (a App) FindUser(ctx context.Context, id int64) (_ *users.User, err error) {
+	ctx, span := tracer.Start(ctx, "App.FindUser")
+	defer func() { endSpan(span, err) }()
 
-func (a App) DeleteUser(ctx context.Context, id int64) error {
-	if err := a.userRepo.Delete(ctx, id); err != nil {
-		return err
+	user, err := a.userRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return user, nil
 }
 
-func (a App) UpdateUser(ctx context.Context, id int64, name, email string) (*users.User, error) {
-	if user, err := a.userRepo.Update(ctx, id, name, email); err == nil {
-		return user, nil
-	} else {
+// UpdateUser replaces name and email of the user, the user must have the version given unless it is zero
+func (a App) UpdateUser(ctx context.Context, id int64, name, email string, version int64) (_ *users.User, err error) {
+	ctx, span := tracer.Start(ctx, "App.UpdateUser")
+	defer func() { endSpan(span, err) }()
+
+	if err = validation.Validate(a.limits.User(name, email)...); err != nil {
 		return nil, err
 	}
 
+	var (
+		user *users.User
+		mail *Mail
+	)
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		old, err := a.userRepo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		before := *old
+
+		if user, err = a.userRepo.Update(ctx, id, name, email, version); err != nil {
+			return err
+		}
+		if !user.Verified && user.Email != before.Email {
+			// the new address has to be confirmed as well
+			if mail, err = a.issueVerification(ctx, user); err != nil {
+				return err
+			}
+		}
+		return a.record(ctx, id, audit.ActionUserUpdate, errs.ResourceUser, id, before, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mail != nil {
+		// the user can ask to resend if delivery fails
+		if sendErr := a.sendMail(ctx, *mail); sendErr != nil {
+			span.RecordError(sendErr)
+		}
+	}
+	return user, nil
 }
 
 // CreateUser creates a new user using repository
-func (a App) CreateUser(ctx context.Context, name string, email string) (*users.User, error) {
-	user := users.New(name, email)
-	_, err := a.userRepo.Create(ctx, user)
-	if errors.Is(err, errs.UserNotFoundError) {
-		return nil, errs.UserNotFoundError
+func (a App) CreateUser(ctx context.Context, name string, email string) (_ *users.User, err error) {
+	ctx, span := tracer.Start(ctx, "App.CreateUser")
+	defer func() { endSpan(span, err) }()
+
+	if err = validation.Validate(a.limits.User(name, email)...); err != nil {
+		return nil, err
 	}
+
+	user := users.New(name, email)
+	user.Role = a.roleFor(email)
+	var mail *Mail
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		if _, err = a.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		if mail, err = a.issueVerification(ctx, user); err != nil {
+			return err
+		}
+		// users register themselves
+		return a.record(ctx, user.ID, audit.ActionUserCreate, errs.ResourceUser, user.ID, nil, user)
+	})
 	if err != nil {
-		return nil, errs.AccessError
+		return nil, err
+	}
+	// the user is registered even if the email can't be delivered now, it can be resent later
+	if sendErr := a.sendMail(ctx, *mail); sendErr != nil {
+		span.RecordError(sendErr)
 	}
 	return user, nil
-
 }
 
 // Filter filters all ads by query params given
-func (a App) Filter(ctx context.Context, params url.Values) ([]*ads.Ad, error) {
+func (a App) Filter(ctx context.Context, params url.Values) (_ []*ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.Filter")
+	defer func() { endSpan(span, err) }()
+
 	allAds, err := a.adRepo.Filter(ctx, params)
 	if err != nil {
 		return nil, err
@@ -156,40 +401,255 @@ func (a App) Filter(ctx context.Context, params url.Values) ([]*ads.Ad, error) {
 	return allAds, nil
 }
 
+// ListUserAds returns ads of the user given. The user itself and moderators get both published ads and not,
+// anybody else, including an anonymous actor, gets published ones only.
+func (a App) ListUserAds(ctx context.Context, uID int64, actorID *int64) (_ []*ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListUserAds")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.userRepo.Get(ctx, uID); err != nil {
+		return nil, err
+	}
+	filter := url.Values{"author": {strconv.FormatInt(uID, 10)}, "published": {"true"}}
+	if actorID != nil {
+		actor, err := a.userRepo.Get(ctx, *actorID)
+		if err != nil {
+			return nil, err
+		}
+		if actor.ID == uID || actor.CanModerate() {
+			filter.Del("published")
+		}
+	}
+	return a.adRepo.Filter(ctx, filter)
+}
+
+// Ready reports whether every repository able to check its availability is reachable
+func (a App) Ready(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "App.Ready")
+	defer func() { endSpan(span, err) }()
+
+	for _, r := range []any{a.adRepo, a.userRepo} {
+		if p, ok := r.(Pinger); ok {
+			if err = p.Ping(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Pinger is implemented by repositories able to report their availability
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name UserRepository
 type UserRepository interface {
 	Create(ctx context.Context, u *users.User) (id int64, err error)
-	Update(ctx context.Context, id int64, name string, email string) (*users.User, error)
+	Update(ctx context.Context, id int64, name string, email string, version int64) (*users.User, error)
 	Get(ctx context.Context, id int64) (*users.User, error)
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, version int64) error
+	// Restore takes the user out of trash
+	Restore(ctx context.Context, id int64) (*users.User, error)
+	// Purge permanently removes users moved to trash before the moment given, returning their IDs
+	Purge(ctx context.Context, before time.Time) ([]int64, error)
+	GetByEmail(ctx context.Context, email string) (*users.User, error)
+	SaveToken(ctx context.Context, t *users.VerificationToken) error
+	UserToken(ctx context.Context, uID int64) (*users.VerificationToken, error)
+	Verify(ctx context.Context, hash string, now time.Time) (*users.User, error)
+	// AddReview stores the review counting it in the reputation of the seller,
+	// a buyer may review each ad once
+	AddReview(ctx context.Context, r *users.Review) (*users.Review, error)
+	GetReview(ctx context.Context, id int64) (*users.Review, error)
+	// Reviews returns reviews of the seller, latest first, hidden ones only if asked
+	Reviews(ctx context.Context, sellerID int64, withHidden bool) ([]*users.Review, error)
+	// ReplyToReview stores the only reply of the seller
+	ReplyToReview(ctx context.Context, id int64, reply string, at time.Time) (*users.Review, error)
+	// HideReview hides or shows the review updating the reputation of the seller
+	HideReview(ctx context.Context, id int64, hidden bool, moderatorID int64) (*users.Review, error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AdRepository
 type AdRepository interface {
 	Create(context.Context, *ads.Ad) (int64, error)
-	Publish(context.Context, int64, int64, bool) (*ads.Ad, error)
-	Update(context.Context, int64, int64, string, string) (*ads.Ad, error)
-	Delete(context.Context, int64, int64) error
+	// Publish, Update and Delete fail with errs.VersionConflictError unless the ad has the version given,
+	// zero version skips the check
+	Publish(ctx context.Context, adID, uID int64, action bool, version int64) (*ads.Ad, error)
+	Update(ctx context.Context, adID, uID int64, title, text string, version int64) (*ads.Ad, error)
+	Delete(ctx context.Context, adID, uID int64, version int64) error
 	GetByID(context.Context, int64) (*ads.Ad, error)
 	GetByName(context.Context, string) []*ads.Ad
 	Filter(ctx context.Context, params url.Values) ([]*ads.Ad, error)
+	// DeleteByAuthor moves live ads of the author to trash, returning their previous state
+	DeleteByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error)
+	// RestoreByAuthor takes ads deleted together with the author out of trash returning them
+	RestoreByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error)
+	// Restore takes the ad out of trash
+	Restore(ctx context.Context, id int64) (*ads.Ad, error)
+	// GetDeleted returns the ad if it is in trash
+	GetDeleted(ctx context.Context, id int64) (*ads.Ad, error)
+	// Trash returns ads of the author in trash
+	Trash(ctx context.Context, uID int64) ([]*ads.Ad, error)
+	// Purge permanently removes ads moved to trash before the moment given with their history, returning their IDs
+	Purge(ctx context.Context, before time.Time) ([]int64, error)
+	// AnonymizeByAuthor unpublishes ads of the author and detaches them from it, returning their previous state
+	AnonymizeByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error)
+	// AddRevision appends revision to the history of its ad
+	AddRevision(ctx context.Context, r *ads.Revision) error
+	// Revisions returns history of the ad, oldest first
+	Revisions(ctx context.Context, adID int64) ([]*ads.Revision, error)
+	// Approve stores approval replacing the previous one of the ad
+	Approve(ctx context.Context, ap *ads.Approval) error
+	// LastApproval returns the latest approval of the ad, nil if it was never approved
+	LastApproval(ctx context.Context, adID int64) (*ads.Approval, error)
+	// SetExpiry moves expiration of the ad of the author taking it out of archive,
+	// it fails with errs.VersionConflictError unless the ad has the version given, zero version skips the check
+	SetExpiry(ctx context.Context, adID, uID int64, expiresAt time.Time, version int64) (*ads.Ad, error)
+	// Archive unpublishes live ads expired by the moment given and marks them archived, returning them
+	Archive(ctx context.Context, now time.Time) ([]*ads.Ad, error)
+	// Expiring returns live ads expiring by the moment given whose authors were not warned yet
+	Expiring(ctx context.Context, before time.Time) ([]*ads.Ad, error)
+	// MarkExpiryWarned records that the author of the ad was warned about its expiration
+	MarkExpiryWarned(ctx context.Context, adID int64) error
+	// ScheduleTransition stores a pending status change of the ad assigning it an ID
+	ScheduleTransition(ctx context.Context, t *ads.Transition) error
+	// Transitions returns pending status changes of the ad, earliest first
+	Transitions(ctx context.Context, adID int64) ([]*ads.Transition, error)
+	// DueTransitions returns pending status changes of all ads due by the moment given, earliest first
+	DueTransitions(ctx context.Context, now time.Time) ([]*ads.Transition, error)
+	// RemoveTransition removes the pending status change of the ad returning it
+	RemoveTransition(ctx context.Context, adID, id int64) (*ads.Transition, error)
+	// AddFavorite saves the live ad to favorites of the user, saving it again keeps the original favorite
+	AddFavorite(ctx context.Context, f *ads.Favorite) (*ads.Favorite, error)
+	// RemoveFavorite removes the ad from favorites of the user
+	RemoveFavorite(ctx context.Context, uID, adID int64) error
+	// Favorites returns favorites of the user, latest first
+	Favorites(ctx context.Context, uID int64) ([]*ads.Favorite, error)
+	// Watchers returns IDs of users who saved the ad to favorites
+	Watchers(ctx context.Context, adID int64) ([]int64, error)
+	// RemoveAdFavorites removes the ad from favorites of all users, returning how many favorites were removed
+	RemoveAdFavorites(ctx context.Context, adID int64) (int, error)
+	// RemoveUserFavorites removes all favorites of the user
+	RemoveUserFavorites(ctx context.Context, uID int64) error
+	// SetTakenDown takes the ad down at the moment given or lifts the takedown if the moment is zero
+	SetTakenDown(ctx context.Context, adID int64, at time.Time) (*ads.Ad, error)
+	// CountCreated returns how many ads the author created since the moment given, ads in trash included
+	CountCreated(ctx context.Context, uID int64, since time.Time) (int, error)
+	// SetScreening records the outcome of content filters for the current revision of the ad keeping its version
+	SetScreening(ctx context.Context, adID int64, s ads.Screening) (*ads.Ad, error)
+	// AddReport stores a report of the ad assigning it an ID, it fails with errs.ReportExistsError
+	// if the user has an open report of the ad
+	AddReport(ctx context.Context, r *ads.Report) (*ads.Report, error)
+	// Reports returns open reports of the ad, oldest first
+	Reports(ctx context.Context, adID int64) ([]*ads.Report, error)
+	// OpenReports returns open reports of all live ads, oldest first
+	OpenReports(ctx context.Context) ([]*ads.Report, error)
+	// ResolveReports closes open reports of the ad with the outcome given, returning them
+	ResolveReports(ctx context.Context, adID, moderatorID int64, outcome ads.ReportOutcome, at time.Time) ([]*ads.Report, error)
+	// AddAppeal stores an appeal against the takedown of the ad assigning it an ID,
+	// it fails with errs.AppealPendingError if the ad has a pending appeal
+	AddAppeal(ctx context.Context, ap *ads.Appeal) (*ads.Appeal, error)
+	GetAppeal(ctx context.Context, id int64) (*ads.Appeal, error)
+	// PendingAppeals returns pending appeals of live ads, oldest first
+	PendingAppeals(ctx context.Context) ([]*ads.Appeal, error)
+	// ResolveAppeal accepts or rejects the pending appeal, it fails with errs.AppealResolvedError otherwise
+	ResolveAppeal(ctx context.Context, id, moderatorID int64, accept bool, at time.Time) (*ads.Appeal, error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name IApp
 type IApp interface {
-	CreateAd(ctx context.Context, uID int64, title string, text string) (*ads.Ad, error)
-	UpdateAd(ctx context.Context, adID int64, uID int64, title string, text string) (*ads.Ad, error)
-	DeleteAd(ctx context.Context, adID, uID int64) error
-	PublishAd(ctx context.Context, adID int64, uID int64, action bool) (*ads.Ad, error)
+	CreateAd(ctx context.Context, uID int64, title string, text string, category string) (*ads.Ad, bool, error)
+	UpdateAd(ctx context.Context, adID int64, uID int64, title string, text string, version int64) (*ads.Ad, error)
+	DeleteAd(ctx context.Context, adID, uID int64, version int64) error
+	PublishAd(ctx context.Context, adID int64, uID int64, action bool, version int64) (*ads.Ad, error)
 	GetAdByID(ctx context.Context, id int64) (*ads.Ad, error)
 	GetAdByName(ctx context.Context, title string) []*ads.Ad
 	FindUser(ctx context.Context, id int64) (*users.User, error)
-	DeleteUser(ctx context.Context, id int64) error
-	UpdateUser(ctx context.Context, id int64, name, email string) (*users.User, error)
+	DeleteUser(ctx context.Context, id int64, version int64) (DeletionReport, error)
+	UpdateUser(ctx context.Context, id int64, name, email string, version int64) (*users.User, error)
 	CreateUser(ctx context.Context, name string, email string) (*users.User, error)
 	Filter(ctx context.Context, params url.Values) ([]*ads.Ad, error)
+	ListUserAds(ctx context.Context, uID int64, actorID *int64) ([]*ads.Ad, error)
+	ConfirmEmail(ctx context.Context, token string) (*users.User, error)
+	ResendVerification(ctx context.Context, uID int64) error
+	ListRevisions(ctx context.Context, adID, uID int64) ([]*ads.Revision, error)
+	GetRevision(ctx context.Context, adID, uID, number int64) (*ads.Revision, error)
+	RollbackAd(ctx context.Context, adID, uID, number, version int64) (*ads.Ad, error)
+	ApproveAd(ctx context.Context, adID, uID, version int64) (*ads.Approval, error)
+	AdChangesSinceApproval(ctx context.Context, adID, uID int64) (AdChanges, error)
+	ListTrash(ctx context.Context, uID, actorID int64) ([]*ads.Ad, error)
+	RestoreAd(ctx context.Context, adID, uID int64) (*ads.Ad, error)
+	RestoreUser(ctx context.Context, id, actorID int64) (*users.User, error)
+	AuditLog(ctx context.Context, uID int64, f audit.Filter) ([]*audit.Entry, error)
+	VerifyAuditLog(ctx context.Context, uID int64) (audit.Verification, error)
+	RenewAd(ctx context.Context, adID, uID, version int64) (*ads.Ad, error)
+	ExtendAd(ctx context.Context, adID, uID int64, by time.Duration, version int64) (*ads.Ad, error)
+	ChangeAdStatus(ctx context.Context, adID, uID int64, published bool, publishAt, unpublishAt time.Time, version int64) (*ads.Ad, error)
+	ListTransitions(ctx context.Context, adID, uID int64) ([]*ads.Transition, error)
+	CancelTransition(ctx context.Context, adID, transitionID, uID int64) error
+	AddFavorite(ctx context.Context, uID, adID int64) (*ads.Favorite, error)
+	RemoveFavorite(ctx context.Context, uID, adID int64) error
+	ListFavorites(ctx context.Context, uID, actorID int64) ([]FavoriteAd, error)
+	FavoriteCount(ctx context.Context, adID, uID int64) (int, error)
+	StartConversation(ctx context.Context, adID, buyerID int64) (*messages.Conversation, error)
+	ListConversations(ctx context.Context, uID int64) ([]*messages.Conversation, error)
+	SendMessage(ctx context.Context, conversationID, uID int64, text string) (*messages.Message, error)
+	ListMessages(ctx context.Context, conversationID, uID int64, p messages.Page) ([]*messages.Message, error)
+	BlockUser(ctx context.Context, uID, blockedID int64) (*messages.Block, error)
+	UnblockUser(ctx context.Context, uID, blockedID int64) error
+	ListBlocked(ctx context.Context, uID, actorID int64) ([]*messages.Block, error)
+	SubscribeMessages(ctx context.Context, uID int64) (<-chan *messages.Message, func(), error)
+	ReviewSeller(ctx context.Context, adID, uID int64, rating int, text string) (*users.Review, error)
+	ListReviews(ctx context.Context, sellerID int64, actorID *int64) ([]*users.Review, error)
+	ReplyToReview(ctx context.Context, reviewID, uID int64, text string) (*users.Review, error)
+	HideReview(ctx context.Context, reviewID, uID int64, hidden bool) (*users.Review, error)
+	ReportAd(ctx context.Context, adID, uID int64, reason ads.ReportReason, comment string) (*ads.Report, error)
+	ReportInbox(ctx context.Context, uID int64) ([]ReportedAd, error)
+	ResolveReports(ctx context.Context, adID, uID int64, takedown bool) ([]*ads.Report, error)
+	AppealTakedown(ctx context.Context, adID, uID int64, comment string) (*ads.Appeal, error)
+	ListAppeals(ctx context.Context, uID int64) ([]*ads.Appeal, error)
+	ResolveAppeal(ctx context.Context, appealID, uID int64, accept bool) (*ads.Appeal, error)
+	HeldAds(ctx context.Context, uID int64) ([]*ads.Ad, error)
+	DuplicateClusters(ctx context.Context, uID int64) ([]DuplicateCluster, error)
+}
+
+// Option configures App
+type Option func(*App)
+
+// WithLimits overrides constraints inputs are validated against
+func WithLimits(l validation.Limits) Option {
+	return func(a *App) {
+		a.limits = l
+	}
 }
 
-func NewApp(repo AdRepository, userRepo UserRepository) App {
-	return App{adRepo: repo, userRepo: userRepo}
+// WithClock sets the clock the app takes the current time from
+func WithClock(c clock.Clock) Option {
+	return func(a *App) {
+		a.clock = c
+	}
+}
+
+func NewApp(repo AdRepository, userRepo UserRepository, opts ...Option) App {
+	a := App{
+		adRepo:         repo,
+		userRepo:       userRepo,
+		limits:         validation.DefaultLimits,
+		mail:           discardSender{},
+		verification:   DefaultVerificationConfig,
+		deletionPolicy: DeleteAnonymize,
+		uow:            uow.New(),
+		trash:          DefaultTrashConfig,
+		audit:          discardAudit{},
+		expiration:     DefaultExpirationConfig,
+		schedule:       DefaultScheduleConfig,
+		clock:          clock.System,
+		hub:            messages.NewHub(),
+		reports:        DefaultReportConfig,
+	}
+	for _, opt := range opts {
+		opt(&a)
+	}
+	a.uow = committing{a.uow}
+	return a
 }
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+)
+
+// AuditLog is an append-only store of audit entries
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name AuditLog
+type AuditLog interface {
+	// Append chains the entry to the last one setting its sequence number and hashes,
+	// the entry must be appended in the unit of work of the change it records
+	Append(ctx context.Context, e *audit.Entry) error
+	// Query returns entries matching the filter, oldest first
+	Query(ctx context.Context, f audit.Filter) ([]*audit.Entry, error)
+}
+
+// discardAudit drops every entry, it is used until a real audit log is configured
+type discardAudit struct{}
+
+func (discardAudit) Append(context.Context, *audit.Entry) error {
+	return nil
+}
+
+func (discardAudit) Query(context.Context, audit.Filter) ([]*audit.Entry, error) {
+	return nil, nil
+}
+
+// WithAuditLog sets the store every change made through the app is recorded to
+func WithAuditLog(l AuditLog) Option {
+	return func(a *App) {
+		a.audit = l
+	}
+}
+
+// record appends an entry about the change of the target made by the actor,
+// before and after are snapshots of the target or nil if it did not exist
+func (a App) record(ctx context.Context, actorID int64, action audit.Action, targetType string, targetID int64, before, after any) error {
+	e, err := audit.NewEntry(ctx, actorID, action, targetType, targetID, before, after, a.clock.Now())
+	if err != nil {
+		return errs.Wrap(errs.Internal, "can't snapshot audited resource", err)
+	}
+	return a.audit.Append(ctx, e)
+}
+
+// AuditLog returns entries matching the filter to an admin
+func (a App) AuditLog(ctx context.Context, uID int64, f audit.Filter) (_ []*audit.Entry, err error) {
+	ctx, span := tracer.Start(ctx, "App.AuditLog")
+	defer func() { endSpan(span, err) }()
+
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		return nil, errs.ValidationError.WithFields(errs.FieldViolation{Field: "to", Description: "must not be before from"})
+	}
+	if _, err = a.admin(ctx, uID); err != nil {
+		return nil, err
+	}
+	return a.audit.Query(ctx, f)
+}
+
+// VerifyAuditLog checks the hash chain of the whole audit log for an admin
+func (a App) VerifyAuditLog(ctx context.Context, uID int64) (_ audit.Verification, err error) {
+	ctx, span := tracer.Start(ctx, "App.VerifyAuditLog")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.admin(ctx, uID); err != nil {
+		return audit.Verification{}, err
+	}
+	entries, err := a.audit.Query(ctx, audit.Filter{})
+	if err != nil {
+		return audit.Verification{}, err
+	}
+	return audit.Verify(entries), nil
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/content"
+	"ads-server/internal/errs"
+)
+
+// WithContentFilters screens titles and texts of ads created, updated or rolled back with the pipeline given,
+// without it any content passing validation is accepted
+func WithContentFilters(p *content.Pipeline) Option {
+	return func(a *App) {
+		a.filters = p
+	}
+}
+
+// screen runs content filters over the title and text of an ad. It returns the title and text with objectionable
+// fragments masked together with the flags to record, rejected content fails with errs.ContentRejectedError.
+// Repeated ads are looked for by checkDuplicates.
+func (a App) screen(ctx context.Context, title, text string) (string, string, []ads.ContentFlag, error) {
+	if a.filters == nil {
+		return title, text, nil, nil
+	}
+	res, err := a.filters.Run(ctx, content.Input{
+		Title: title,
+		Text:  text,
+	})
+	if err != nil {
+		return "", "", nil, errs.Wrap(errs.Internal, "can't screen content", err)
+	}
+	if res.Action == content.Reject {
+		var violations []errs.FieldViolation
+		for _, f := range res.Findings {
+			if f.Action == content.Reject {
+				violations = append(violations, errs.FieldViolation{Field: f.Field, Description: f.Reason})
+			}
+		}
+		return "", "", nil, errs.ContentRejectedError.WithFields(violations...)
+	}
+	var flags []ads.ContentFlag
+	for _, f := range res.Findings {
+		if f.Action == content.Allow {
+			continue
+		}
+		flags = append(flags, ads.ContentFlag{
+			Checker: f.Checker,
+			Field:   f.Field,
+			Reason:  f.Reason,
+			Masked:  f.Action == content.Mask,
+		})
+	}
+	return res.Title, res.Text, flags, nil
+}
+
+// setScreening records the flags for the current revision of the ad returning it,
+// ads that neither have nor had flags are left alone
+func (a App) setScreening(ctx context.Context, ad *ads.Ad, flags []ads.ContentFlag) (*ads.Ad, error) {
+	if len(flags) == 0 && len(ad.Screening.Flags) == 0 {
+		return ad, nil
+	}
+	return a.adRepo.SetScreening(ctx, ad.ID, ads.Screening{Revision: ad.Version, Flags: flags})
+}
+
+// hold unpublishes the ad on behalf of its author if content filters hold it
+func (a App) hold(ctx context.Context, ad *ads.Ad) (*ads.Ad, error) {
+	if !ad.Published || !ad.Screening.Held() {
+		return ad, nil
+	}
+	return a.unpublish(ctx, ad, ad.AuthorID)
+}
+
+// released returns errs.AdHeldError if content filters hold the ad and no moderator approved
+// the revision they looked at or a later one
+func (a App) released(ctx context.Context, ad *ads.Ad) error {
+	if !ad.Screening.Held() {
+		return nil
+	}
+	ap, err := a.adRepo.LastApproval(ctx, ad.ID)
+	if err != nil {
+		return err
+	}
+	if ap != nil && ap.Revision >= ad.Screening.Revision {
+		return nil
+	}
+	return errs.AdHeldError.WithResource(errs.ResourceAd, ad.ID)
+}
+
+// HeldAds returns live ads held by content filters until approved to a moderator, oldest first
+func (a App) HeldAds(ctx context.Context, uID int64) (_ []*ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.HeldAds")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+	all, err := a.adRepo.Filter(ctx, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	var held []*ads.Ad
+	for _, ad := range all {
+		if err = a.released(ctx, ad); errors.Is(err, errs.AdHeldError) {
+			held = append(held, ad)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(held, func(i, j int) bool { return held[i].ID < held[j].ID })
+	return held, nil
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+)
+
+// DeletionPolicy defines what happens to ads of a deleted user
+type DeletionPolicy string
+
+const (
+	// DeleteCascade moves all ads of the user to trash, they are restored with the user
+	DeleteCascade DeletionPolicy = "cascade"
+	// DeleteAnonymize unpublishes ads of the user and detaches them from the author
+	DeleteAnonymize DeletionPolicy = "anonymize"
+	// DeleteBlock refuses to delete users having ads
+	DeleteBlock DeletionPolicy = "block"
+)
+
+// ParseDeletionPolicy converts policy name to DeletionPolicy
+func ParseDeletionPolicy(s string) (DeletionPolicy, error) {
+	switch p := DeletionPolicy(s); p {
+	case DeleteCascade, DeleteAnonymize, DeleteBlock:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown deletion policy %q", s)
+}
+
+// WithDeletionPolicy sets the policy applied to ads of deleted users
+func WithDeletionPolicy(p DeletionPolicy) Option {
+	return func(a *App) {
+		a.deletionPolicy = p
+	}
+}
+
+// DeletionReport describes the effects of deleting a user
+type DeletionReport struct {
+	UserID int64
+	Policy DeletionPolicy
+	// DeletedAds lists ads of the user in trash after a cascade deletion, ones deleted before it included
+	DeletedAds    []int64
+	AnonymizedAds []int64
+}
+
+func adIDs(list []*ads.Ad) []int64 {
+	ids := make([]int64, 0, len(list))
+	for _, ad := range list {
+		ids = append(ids, ad.ID)
+	}
+	return ids
+}
+
+// DeleteUser deletes the user applying the deletion policy to its ads.
+// Everything is done in a single unit of work, so the user is never left half deleted.
+// The user must have the version given unless it is zero.
+func (a App) DeleteUser(ctx context.Context, id int64, version int64) (_ DeletionReport, err error) {
+	ctx, span := tracer.Start(ctx, "App.DeleteUser")
+	defer func() { endSpan(span, err) }()
+
+	report := DeletionReport{UserID: id, Policy: a.deletionPolicy}
+	// anonymized ads are unpublished, their watchers are notified once the deletion is committed
+	var unpublished []*ads.Ad
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		u, err := a.userRepo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		// checked before ads are changed, the repository checks it again on delete
+		if version != 0 && u.Version != version {
+			return errs.VersionConflictError.WithResource(errs.ResourceUser, id)
+		}
+		before := *u
+
+		switch a.deletionPolicy {
+		case DeleteBlock:
+			userAds, err := a.adRepo.Filter(ctx, url.Values{"author": {strconv.FormatInt(id, 10)}})
+			if err != nil {
+				return err
+			}
+			// ads in trash could be restored without their author
+			trash, err := a.adRepo.Trash(ctx, id)
+			if err != nil {
+				return err
+			}
+			if len(userAds) > 0 || len(trash) > 0 {
+				return errs.UserHasAdsError.WithResource(errs.ResourceUser, id)
+			}
+		case DeleteCascade:
+			// ads deleted before stay in trash as they are, they are not restored with the user
+			trash, err := a.adRepo.Trash(ctx, id)
+			if err != nil {
+				return err
+			}
+			deleted, err := a.adRepo.DeleteByAuthor(ctx, id)
+			if err != nil {
+				return err
+			}
+			report.DeletedAds = append(adIDs(trash), adIDs(deleted)...)
+			for _, prev := range deleted {
+				if _, err = a.adRepo.RemoveAdFavorites(ctx, prev.ID); err != nil {
+					return err
+				}
+				ad, err := a.adRepo.GetDeleted(ctx, prev.ID)
+				if err != nil {
+					return err
+				}
+				if err = a.recordRevision(ctx, ad, ads.ActionDelete, id, 0); err != nil {
+					return err
+				}
+				if err = a.record(ctx, id, audit.ActionAdDelete, errs.ResourceAd, ad.ID, prev, nil); err != nil {
+					return err
+				}
+			}
+		case DeleteAnonymize:
+			anonymized, err := a.adRepo.AnonymizeByAuthor(ctx, id)
+			if err != nil {
+				return err
+			}
+			report.AnonymizedAds = adIDs(anonymized)
+			for _, prev := range anonymized {
+				if prev.Published {
+					unpublished = append(unpublished, prev)
+				}
+				ad, err := a.adRepo.GetByID(ctx, prev.ID)
+				if err != nil {
+					return err
+				}
+				if err = a.recordRevision(ctx, ad, ads.ActionAnonymize, id, 0); err != nil {
+					return err
+				}
+				if err = a.record(ctx, id, audit.ActionAdAnonymize, errs.ResourceAd, ad.ID, prev, ad); err != nil {
+					return err
+				}
+			}
+		default:
+			return errs.New(errs.Internal, fmt.Sprintf("unknown deletion policy %q", a.deletionPolicy))
+		}
+
+		if err = a.adRepo.RemoveUserFavorites(ctx, id); err != nil {
+			return err
+		}
+		if err = a.userRepo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return a.record(ctx, id, audit.ActionUserDelete, errs.ResourceUser, id, before, nil)
+	})
+	if err != nil {
+		return DeletionReport{}, err
+	}
+	for _, ad := range unpublished {
+		a.notifyWatchers(ctx, ad, "was unpublished")
+	}
+	return report, nil
+}
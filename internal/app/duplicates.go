@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/content"
+	"ads-server/internal/errs"
+)
+
+// DuplicatePolicy defines what happens to an ad resembling another live ad
+type DuplicatePolicy string
+
+const (
+	// DuplicatesIgnore doesn't look for near-duplicates
+	DuplicatesIgnore DuplicatePolicy = ""
+	// DuplicatesFlag holds the ad until a moderator approves it, the same way content filters do
+	DuplicatesFlag DuplicatePolicy = "flag"
+	// DuplicatesReject refuses the ad
+	DuplicatesReject DuplicatePolicy = "reject"
+	// DuplicatesMerge applies a new ad to the most similar live ad of the same author and category
+	// instead of creating it, other near-duplicates are flagged and updates are rejected
+	DuplicatesMerge DuplicatePolicy = "merge"
+)
+
+// ParseDuplicatePolicy converts policy name to DuplicatePolicy, "ignore" disables detection
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch p := DuplicatePolicy(s); p {
+	case "ignore":
+		return DuplicatesIgnore, nil
+	case DuplicatesFlag, DuplicatesReject, DuplicatesMerge:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown duplicate policy %q", s)
+}
+
+// DuplicateConfig configures detection of near-duplicate ads by SimHash of their titles and texts
+type DuplicateConfig struct {
+	// MaxDistance is how many bits fingerprints of near-duplicates may differ in
+	MaxDistance int
+	// Author applies to ads resembling ads of the same author
+	Author DuplicatePolicy
+	// Global applies to ads resembling ads of other authors, they can't be merged
+	Global DuplicatePolicy
+}
+
+// DefaultDuplicateConfig rejects near-duplicates of the same author and flags those of other authors.
+// Without WithDuplicates option near-duplicates are not looked for.
+var DefaultDuplicateConfig = DuplicateConfig{MaxDistance: 10, Author: DuplicatesReject, Global: DuplicatesFlag}
+
+// WithDuplicates enables detection of near-duplicate ads
+func WithDuplicates(cfg DuplicateConfig) Option {
+	return func(a *App) {
+		a.duplicates = cfg
+	}
+}
+
+// DuplicateCluster is a group of live ads resembling each other
+type DuplicateCluster struct {
+	Ads []*ads.Ad
+}
+
+// fingerprint is the SimHash of the title and text of an ad
+func fingerprint(title, text string) content.Fingerprint {
+	return content.SimHash(title + "\n" + text)
+}
+
+// nearest returns the live ad most similar to the title and text among ads matching the filter,
+// nil if none is within MaxDistance
+func (a App) nearest(ctx context.Context, title, text string, match func(*ads.Ad) bool) (*ads.Ad, error) {
+	all, err := a.adRepo.Filter(ctx, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	f := fingerprint(title, text)
+	var (
+		best     *ads.Ad
+		bestDist int
+	)
+	for _, ad := range all {
+		if !match(ad) {
+			continue
+		}
+		d := f.Distance(fingerprint(ad.Title, ad.Text))
+		if d > a.duplicates.MaxDistance {
+			continue
+		}
+		if best == nil || d < bestDist || d == bestDist && ad.ID < best.ID {
+			best, bestDist = ad, d
+		}
+	}
+	return best, nil
+}
+
+// checkDuplicates applies duplicate policies to the title and text of an ad of the author,
+// except lists ads not compared with, i.e. the ad being changed. A new ad has the category given
+// and may be merged, updates pass no category. It returns the ad of the author to merge into
+// and flags to hold the ad with.
+func (a App) checkDuplicates(ctx context.Context, uID int64, title, text, category string, except ...int64) (*ads.Ad, []ads.ContentFlag, error) {
+	excluded := func(ad *ads.Ad) bool {
+		for _, id := range except {
+			if ad.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+	var flags []ads.ContentFlag
+	if a.duplicates.Author != DuplicatesIgnore {
+		own, err := a.nearest(ctx, title, text, func(ad *ads.Ad) bool { return ad.AuthorID == uID && !excluded(ad) })
+		if err != nil {
+			return nil, nil, err
+		}
+		// updates are never merged, an archived ad, an ad taken down or of another category is not merged into
+		merging := a.duplicates.Author == DuplicatesMerge && len(except) == 0
+		switch {
+		case own == nil:
+		case merging && own.Category == category && !own.Archived() && own.TakenDownAt.IsZero():
+			return own, nil, nil
+		case merging || a.duplicates.Author == DuplicatesFlag:
+			flags = append(flags, ads.ContentFlag{Checker: "near-duplicates", Field: "text", Reason: fmt.Sprintf("resembles ad %d of the author", own.ID)})
+		default:
+			return nil, nil, errs.DuplicateAdError.WithResource(errs.ResourceAd, own.ID)
+		}
+	}
+	if a.duplicates.Global != DuplicatesIgnore {
+		other, err := a.nearest(ctx, title, text, func(ad *ads.Ad) bool { return ad.AuthorID != uID && !excluded(ad) })
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case other == nil:
+		case a.duplicates.Global == DuplicatesReject:
+			return nil, nil, errs.DuplicateAdError.WithResource(errs.ResourceAd, other.ID)
+		default:
+			flags = append(flags, ads.ContentFlag{Checker: "near-duplicates", Field: "text", Reason: fmt.Sprintf("resembles ad %d of another author", other.ID)})
+		}
+	}
+	return nil, flags, nil
+}
+
+// DuplicateClusters returns groups of live ads resembling each other to a moderator, ads of a group are ordered
+// by ID and groups by their first ad. Ads are grouped transitively, so ads of a group may differ more than
+// MaxDistance from each other.
+func (a App) DuplicateClusters(ctx context.Context, uID int64) (_ []DuplicateCluster, err error) {
+	ctx, span := tracer.Start(ctx, "App.DuplicateClusters")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+	all, err := a.adRepo.Filter(ctx, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	fingerprints := make([]content.Fingerprint, len(all))
+	for i, ad := range all {
+		fingerprints[i] = fingerprint(ad.Title, ad.Text)
+	}
+	// parent links ads into groups, a group is named by its first ad
+	parent := make([]int, len(all))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			if fingerprints[i].Distance(fingerprints[j]) > a.duplicates.MaxDistance {
+				continue
+			}
+			ri, rj := root(i), root(j)
+			if ri > rj {
+				ri, rj = rj, ri
+			}
+			parent[rj] = ri
+		}
+	}
+
+	var clusters []DuplicateCluster
+	byRoot := make(map[int]int)
+	for i, ad := range all {
+		r := root(i)
+		if r == i {
+			byRoot[i] = len(clusters)
+			clusters = append(clusters, DuplicateCluster{})
+		}
+		clusters[byRoot[r]].Ads = append(clusters[byRoot[r]].Ads, ad)
+	}
+	res := clusters[:0]
+	for _, c := range clusters {
+		if len(c.Ads) > 1 {
+			res = append(res, c)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,256 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+)
+
+// ExpirationConfig configures how long ads live before they are archived
+type ExpirationConfig struct {
+	// Lifetime is used for ads of categories missing in Categories, zero means such ads never expire
+	Lifetime time.Duration
+	// Categories overrides the lifetime of ads per category
+	Categories map[string]time.Duration
+	// WarnBefore is how long before the expiration authors are warned, zero disables warnings
+	WarnBefore time.Duration
+	// CheckInterval is how often the expirer looks for expired ads
+	CheckInterval time.Duration
+}
+
+// DefaultExpirationConfig is used unless WithExpiration option is given
+var DefaultExpirationConfig = ExpirationConfig{
+	Lifetime:      30 * 24 * time.Hour,
+	WarnBefore:    3 * 24 * time.Hour,
+	CheckInterval: time.Hour,
+}
+
+// WithExpiration overrides ad lifetime settings
+func WithExpiration(cfg ExpirationConfig) Option {
+	return func(a *App) {
+		a.expiration = cfg
+	}
+}
+
+// ParseCategoryLifetimes parses comma separated category=duration pairs, e.g. "jobs=336h,cars=1440h"
+func ParseCategoryLifetimes(s string) (map[string]time.Duration, error) {
+	res := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		category, lifetime, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("category lifetime %q must look like category=duration", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(lifetime))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("lifetime of category %q must be a positive duration, got %q", category, lifetime)
+		}
+		res[normalizeCategory(category)] = d
+	}
+	return res, nil
+}
+
+// normalizeCategory makes categories differing in case and surrounding spaces the same
+func normalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// lifetime returns how long ads of the category live, zero if they never expire
+func (c ExpirationConfig) lifetime(category string) time.Duration {
+	if d, ok := c.Categories[category]; ok {
+		return d
+	}
+	return c.Lifetime
+}
+
+// expiresAt returns expiration of an ad of the category renewed at the moment given
+func (c ExpirationConfig) expiresAt(category string, now time.Time) time.Time {
+	d := c.lifetime(category)
+	if d == 0 {
+		return time.Time{}
+	}
+	return now.Add(d)
+}
+
+// ExpiryReport lists ads changed by an expiration check
+type ExpiryReport struct {
+	Archived []int64
+	Warned   []int64
+}
+
+// RenewAd starts a new lifetime of the ad from now, taking it out of archive.
+// Only the author can renew, the ad must have the version given unless it is zero.
+// A renewed archived ad stays unpublished until the author publishes it again.
+func (a App) RenewAd(ctx context.Context, adID, uID, version int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.RenewAd")
+	defer func() { endSpan(span, err) }()
+
+	var ad *ads.Ad
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		old, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		before := *old
+		expiresAt := a.expiration.expiresAt(old.Category, a.clock.Now())
+		if ad, err = a.adRepo.SetExpiry(ctx, adID, uID, expiresAt, version); err != nil {
+			return err
+		}
+		if err = a.recordRevision(ctx, ad, ads.ActionRenew, uID, 0); err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionAdRenew, errs.ResourceAd, adID, before, ad)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// ExtendAd postpones expiration of the live ad, it can't be postponed beyond a full lifetime from now.
+// Only the author can extend, the ad must have the version given unless it is zero.
+func (a App) ExtendAd(ctx context.Context, adID, uID int64, by time.Duration, version int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.ExtendAd")
+	defer func() { endSpan(span, err) }()
+
+	if by <= 0 {
+		return nil, errs.ValidationError.WithFields(errs.FieldViolation{Field: "days", Description: "must be positive"})
+	}
+
+	var ad *ads.Ad
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		old, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if old.Archived() {
+			return errs.AdArchivedError.WithResource(errs.ResourceAd, adID)
+		}
+		if old.ExpiresAt.IsZero() {
+			return errs.New(errs.FailedPrecondition, "ad never expires").WithResource(errs.ResourceAd, adID)
+		}
+		now := a.clock.Now()
+		expiresAt := old.ExpiresAt.Add(by)
+		if limit := a.expiration.expiresAt(old.Category, now); !limit.IsZero() && expiresAt.After(limit) {
+			return errs.ValidationError.WithFields(errs.FieldViolation{
+				Field:       "days",
+				Description: fmt.Sprintf("must not extend the ad beyond %s from now", a.expiration.lifetime(old.Category)),
+			})
+		}
+		before := *old
+		if ad, err = a.adRepo.SetExpiry(ctx, adID, uID, expiresAt, version); err != nil {
+			return err
+		}
+		if err = a.recordRevision(ctx, ad, ads.ActionExtend, uID, 0); err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionAdExtend, errs.ResourceAd, adID, before, ad)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// Expire archives ads expired by the moment given and warns authors of ads expiring soon.
+// A warning that can't be delivered is retried by the next check.
+func (a App) Expire(ctx context.Context, now time.Time) (_ ExpiryReport, err error) {
+	ctx, span := tracer.Start(ctx, "App.Expire")
+	defer func() { endSpan(span, err) }()
+
+	var report ExpiryReport
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		archived, err := a.adRepo.Archive(ctx, now)
+		if err != nil {
+			return err
+		}
+		for _, ad := range archived {
+			report.Archived = append(report.Archived, ad.ID)
+			if err = a.recordRevision(ctx, ad, ads.ActionArchive, audit.SystemActorID, 0); err != nil {
+				return err
+			}
+			if err = a.record(ctx, audit.SystemActorID, audit.ActionAdArchive, errs.ResourceAd, ad.ID, nil, ad); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ExpiryReport{}, err
+	}
+	for _, adID := range report.Archived {
+		if ad, err := a.adRepo.GetByID(ctx, adID); err == nil {
+			a.notifyWatchers(ctx, ad, "expired and was unpublished")
+		}
+	}
+
+	if a.expiration.WarnBefore == 0 {
+		return report, nil
+	}
+	expiring, err := a.adRepo.Expiring(ctx, now.Add(a.expiration.WarnBefore))
+	if err != nil {
+		return report, err
+	}
+	for _, ad := range expiring {
+		if ad.AuthorID == ads.AnonymousAuthorID {
+			// nobody to warn
+			continue
+		}
+		if warnErr := a.warnExpiring(ctx, ad); warnErr != nil {
+			span.RecordError(warnErr)
+			continue
+		}
+		report.Warned = append(report.Warned, ad.ID)
+	}
+	return report, nil
+}
+
+// warnExpiring mails the author of the ad about its expiration and remembers the author was warned
+func (a App) warnExpiring(ctx context.Context, ad *ads.Ad) error {
+	author, err := a.userRepo.Get(ctx, ad.AuthorID)
+	if err != nil {
+		return err
+	}
+	m := Mail{
+		To:      author.Email,
+		Subject: "Your ad expires soon",
+		Body: fmt.Sprintf("Hello, %s!\n\nYour ad %q expires on %s and will be unpublished then.\nRenew or extend it to keep it published.\n",
+			author.Name, ad.Title, ad.ExpiresAt.Format(time.RFC1123)),
+	}
+	if err = a.mail.Send(ctx, m); err != nil {
+		return errs.Wrap(errs.Unavailable, "can't send expiration warning", err)
+	}
+	return a.adRepo.MarkExpiryWarned(ctx, ad.ID)
+}
+
+// RunExpirer returns function checking ad expiration every CheckInterval until ctx is done,
+// failed checks are logged and retried on the next tick
+func (a App) RunExpirer(ctx context.Context) func() error {
+	return func() error {
+		ticker := time.NewTicker(a.expiration.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				report, err := a.Expire(ctx, a.clock.Now())
+				if err != nil {
+					log.Printf("can't archive expired ads: %v", err)
+					continue
+				}
+				if len(report.Archived)+len(report.Warned) > 0 {
+					log.Printf("archived %d expired ads, warned authors of %d ads", len(report.Archived), len(report.Warned))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/errs"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// FavoriteAd is an ad saved by a user with the moment it was saved
+type FavoriteAd struct {
+	Ad      *ads.Ad
+	SavedAt time.Time
+}
+
+// AddFavorite saves a published ad of another user to favorites of the user, the user is notified about its changes.
+// Saving the ad again keeps the original favorite.
+func (a App) AddFavorite(ctx context.Context, uID, adID int64) (_ *ads.Favorite, err error) {
+	ctx, span := tracer.Start(ctx, "App.AddFavorite")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.userRepo.Get(ctx, uID); err != nil {
+		return nil, err
+	}
+	ad, err := a.adRepo.GetByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.AuthorID == uID {
+		return nil, errs.OwnAdFavoriteError.WithResource(errs.ResourceAd, adID)
+	}
+	if !ad.Published {
+		return nil, errs.AdNotPublishedError.WithResource(errs.ResourceAd, adID)
+	}
+	return a.adRepo.AddFavorite(ctx, &ads.Favorite{UserID: uID, AdID: adID, CreatedAt: a.clock.Now()})
+}
+
+// RemoveFavorite removes the ad from favorites of the user
+func (a App) RemoveFavorite(ctx context.Context, uID, adID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "App.RemoveFavorite")
+	defer func() { endSpan(span, err) }()
+
+	return a.adRepo.RemoveFavorite(ctx, uID, adID)
+}
+
+// ListFavorites returns ads saved by the user to the user itself or an admin, latest first
+func (a App) ListFavorites(ctx context.Context, uID, actorID int64) (_ []FavoriteAd, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListFavorites")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.ownerOrAdmin(ctx, uID, actorID, errs.ResourceUser, uID); err != nil {
+		return nil, err
+	}
+	favorites, err := a.adRepo.Favorites(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]FavoriteAd, 0, len(favorites))
+	for _, f := range favorites {
+		ad, err := a.adRepo.GetByID(ctx, f.AdID)
+		if errors.Is(err, errs.AdNotFoundError) {
+			// favorites of deleted ads are removed with them, the ad may be deleted just now
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, FavoriteAd{Ad: ad, SavedAt: f.CreatedAt})
+	}
+	return res, nil
+}
+
+// FavoriteCount returns how many users saved the ad to its author or an admin
+func (a App) FavoriteCount(ctx context.Context, adID, uID int64) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "App.FavoriteCount")
+	defer func() { endSpan(span, err) }()
+
+	ad, err := a.adRepo.GetByID(ctx, adID)
+	if err != nil {
+		return 0, err
+	}
+	if err = a.ownerOrAdmin(ctx, ad.AuthorID, uID, errs.ResourceAd, adID); err != nil {
+		return 0, err
+	}
+	watchers, err := a.adRepo.Watchers(ctx, adID)
+	if err != nil {
+		return 0, err
+	}
+	return len(watchers), nil
+}
+
+// notifyWatchers mails users who saved the ad that it changed.
+// Notifications are best effort: failures are recorded on the span and don't fail the change.
+func (a App) notifyWatchers(ctx context.Context, ad *ads.Ad, change string) {
+	span := trace.SpanFromContext(ctx)
+	watchers, err := a.adRepo.Watchers(ctx, ad.ID)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+	for _, uID := range watchers {
+		u, err := a.userRepo.Get(ctx, uID)
+		if err != nil {
+			span.RecordError(err)
+			continue
+		}
+		m := Mail{
+			To:      u.Email,
+			Subject: "An ad you saved " + change,
+			Body:    fmt.Sprintf("Hello, %s!\n\nThe ad %q you saved to favorites %s.\n", u.Name, ad.Title, change),
+		}
+		if err = a.mail.Send(ctx, m); err != nil {
+			span.RecordError(errs.Wrap(errs.Unavailable, "can't notify watcher", err))
+		}
+	}
+}
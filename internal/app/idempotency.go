@@ -0,0 +1,16 @@
+package app
+
+import "ads-server/internal/idempotency"
+
+// WithIdempotency makes transports of the app replay responses to ad creation retried with the same idempotency key
+// from the store given. Keys are scoped by the route or method, so a key sent over one transport isn't replayed over the other.
+func WithIdempotency(s *idempotency.Store) Option {
+	return func(a *App) {
+		a.idempotency = s
+	}
+}
+
+// Idempotency returns the store of responses transports of the app replay, nil if idempotency keys are ignored
+func (a App) Idempotency() *idempotency.Store {
+	return a.idempotency
+}
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"ads-server/internal/errs"
+	"ads-server/internal/messages"
+	"ads-server/internal/validation"
+)
+
+const (
+	// DefaultMessagePage is the number of messages listed when the page size is not given
+	DefaultMessagePage = 50
+	// MaxMessagePage limits the number of messages listed at once
+	MaxMessagePage = 100
+)
+
+// MessageRepository stores conversations, their messages and blocks between users
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name MessageRepository
+type MessageRepository interface {
+	// OpenConversation stores the conversation unless the buyer already has one about the ad, the stored one is returned
+	OpenConversation(ctx context.Context, c *messages.Conversation) (*messages.Conversation, error)
+	GetConversation(ctx context.Context, id int64) (*messages.Conversation, error)
+	// FindConversation returns the conversation of the buyer about the ad
+	FindConversation(ctx context.Context, adID, buyerID int64) (*messages.Conversation, error)
+	// Conversations returns conversations of the participant with the latest activity first
+	Conversations(ctx context.Context, uID int64) ([]*messages.Conversation, error)
+	// AddMessage stores the message counting it as unread for the other participant
+	AddMessage(ctx context.Context, m *messages.Message) (*messages.Message, error)
+	// Messages returns a page of messages of the conversation, newest first
+	Messages(ctx context.Context, conversationID int64, p messages.Page) ([]*messages.Message, error)
+	// MarkRead resets the unread counter of the participant
+	MarkRead(ctx context.Context, conversationID, uID int64) error
+	// Block is a no-op if the user has already blocked the other one
+	Block(ctx context.Context, b *messages.Block) (*messages.Block, error)
+	Unblock(ctx context.Context, uID, blockedID int64) error
+	// Blocks returns users blocked by the user, latest first
+	Blocks(ctx context.Context, uID int64) ([]*messages.Block, error)
+	// Blocked reports whether either user blocked the other one
+	Blocked(ctx context.Context, uID, otherID int64) (bool, error)
+}
+
+// WithMessaging enables conversations between authors and buyers, new messages are delivered through the hub.
+// Apps serving different transports must share both the repository and the hub.
+func WithMessaging(r MessageRepository, hub *messages.Hub) Option {
+	return func(a *App) {
+		a.messages = r
+		a.hub = hub
+	}
+}
+
+// messaging returns errs.MessagingDisabledError unless messaging is configured
+func (a App) messaging() error {
+	if a.messages == nil {
+		return errs.MessagingDisabledError
+	}
+	return nil
+}
+
+// StartConversation opens a conversation of the buyer with the author of a published ad.
+// The existing conversation is returned if the buyer has already started one.
+func (a App) StartConversation(ctx context.Context, adID, buyerID int64) (_ *messages.Conversation, err error) {
+	ctx, span := tracer.Start(ctx, "App.StartConversation")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.messaging(); err != nil {
+		return nil, err
+	}
+	if _, err = a.userRepo.Get(ctx, buyerID); err != nil {
+		return nil, err
+	}
+	ad, err := a.adRepo.GetByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.AuthorID == buyerID {
+		return nil, errs.OwnAdConversationError.WithResource(errs.ResourceAd, adID)
+	}
+	if !ad.Published {
+		return nil, errs.AdNotPublishedError.WithResource(errs.ResourceAd, adID)
+	}
+	if err = a.notBlocked(ctx, buyerID, ad.AuthorID); err != nil {
+		return nil, err
+	}
+	return a.messages.OpenConversation(ctx, &messages.Conversation{
+		AdID:      adID,
+		SellerID:  ad.AuthorID,
+		BuyerID:   buyerID,
+		CreatedAt: a.clock.Now(),
+	})
+}
+
+// ListConversations returns conversations of the user with the latest activity first
+func (a App) ListConversations(ctx context.Context, uID int64) (_ []*messages.Conversation, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListConversations")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.messaging(); err != nil {
+		return nil, err
+	}
+	return a.messages.Conversations(ctx, uID)
+}
+
+// SendMessage sends a message to the other participant of the conversation and delivers it to subscribers of both
+func (a App) SendMessage(ctx context.Context, conversationID, uID int64, text string) (_ *messages.Message, err error) {
+	ctx, span := tracer.Start(ctx, "App.SendMessage")
+	defer func() { endSpan(span, err) }()
+
+	if err = validation.Validate(a.limits.Message(text)); err != nil {
+		return nil, err
+	}
+	c, err := a.conversation(ctx, conversationID, uID)
+	if err != nil {
+		return nil, err
+	}
+	// the ad may be unpublished meanwhile, but not deleted
+	if _, err = a.adRepo.GetByID(ctx, c.AdID); err != nil {
+		return nil, err
+	}
+	if err = a.notBlocked(ctx, uID, c.Peer(uID)); err != nil {
+		return nil, err
+	}
+	m, err := a.messages.AddMessage(ctx, &messages.Message{
+		ConversationID: conversationID,
+		SenderID:       uID,
+		Text:           text,
+		CreatedAt:      a.clock.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	a.hub.Publish(m, c.SellerID, c.BuyerID)
+	return m, nil
+}
+
+// ListMessages returns a page of messages of the conversation to a participant, newest first.
+// Listing the latest page marks the conversation read.
+func (a App) ListMessages(ctx context.Context, conversationID, uID int64, p messages.Page) (_ []*messages.Message, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListMessages")
+	defer func() { endSpan(span, err) }()
+
+	if p.Limit < 0 || p.Limit > MaxMessagePage {
+		return nil, errs.ValidationError.WithFields(errs.FieldViolation{Field: "limit", Description: "must be between 0 and 100"})
+	}
+	if p.Limit == 0 {
+		p.Limit = DefaultMessagePage
+	}
+	if _, err = a.conversation(ctx, conversationID, uID); err != nil {
+		return nil, err
+	}
+	list, err := a.messages.Messages(ctx, conversationID, p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Before == 0 {
+		if err = a.messages.MarkRead(ctx, conversationID, uID); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// conversation returns the conversation if the user takes part in it
+func (a App) conversation(ctx context.Context, conversationID, uID int64) (*messages.Conversation, error) {
+	if err := a.messaging(); err != nil {
+		return nil, err
+	}
+	c, err := a.messages.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Participant(uID) {
+		return nil, errs.AccessError.WithResource(errs.ResourceConversation, conversationID)
+	}
+	return c, nil
+}
+
+// notBlocked returns errs.UserBlockedError if either user blocked the other one
+func (a App) notBlocked(ctx context.Context, uID, otherID int64) error {
+	blocked, err := a.messages.Blocked(ctx, uID, otherID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return errs.UserBlockedError.WithResource(errs.ResourceUser, otherID)
+	}
+	return nil
+}
+
+// BlockUser forbids messaging between the users, conversations are kept but no messages can be sent
+func (a App) BlockUser(ctx context.Context, uID, blockedID int64) (_ *messages.Block, err error) {
+	ctx, span := tracer.Start(ctx, "App.BlockUser")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.messaging(); err != nil {
+		return nil, err
+	}
+	if uID == blockedID {
+		return nil, errs.ValidationError.WithFields(errs.FieldViolation{Field: "id", Description: "users can't block themselves"})
+	}
+	if _, err = a.userRepo.Get(ctx, blockedID); err != nil {
+		return nil, err
+	}
+	return a.messages.Block(ctx, &messages.Block{UserID: uID, BlockedID: blockedID, CreatedAt: a.clock.Now()})
+}
+
+// UnblockUser allows messaging with the user blocked before, unless the other user blocked this one too
+func (a App) UnblockUser(ctx context.Context, uID, blockedID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "App.UnblockUser")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.messaging(); err != nil {
+		return err
+	}
+	return a.messages.Unblock(ctx, uID, blockedID)
+}
+
+// ListBlocked returns users blocked by the user to the user itself or an admin, latest first
+func (a App) ListBlocked(ctx context.Context, uID, actorID int64) (_ []*messages.Block, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListBlocked")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.messaging(); err != nil {
+		return nil, err
+	}
+	if err = a.ownerOrAdmin(ctx, uID, actorID, errs.ResourceUser, uID); err != nil {
+		return nil, err
+	}
+	return a.messages.Blocks(ctx, uID)
+}
+
+// SubscribeMessages returns a channel receiving messages sent to or by the user and a function to unsubscribe
+func (a App) SubscribeMessages(ctx context.Context, uID int64) (_ <-chan *messages.Message, _ func(), err error) {
+	ctx, span := tracer.Start(ctx, "App.SubscribeMessages")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.messaging(); err != nil {
+		return nil, nil, err
+	}
+	if _, err = a.userRepo.Get(ctx, uID); err != nil {
+		if errors.Is(err, errs.UserNotFoundError) {
+			return nil, nil, errs.AuthenticationError.WithCause(err)
+		}
+		return nil, nil, err
+	}
+	ch, cancel := a.hub.Subscribe(uID)
+	return ch, cancel, nil
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"ads-server/internal/errs"
+	"ads-server/internal/ratelimit"
+)
+
+// WithRateLimits makes transports of the app limit request rates of clients with the limiter given,
+// apps sharing a limiter share quotas of clients
+func WithRateLimits(l *ratelimit.Limiter) Option {
+	return func(a *App) {
+		a.limiter = l
+	}
+}
+
+// Limiter returns the limiter transports of the app enforce, nil if request rates are not limited
+func (a App) Limiter() *ratelimit.Limiter {
+	return a.limiter
+}
+
+// WithDailyAdQuota limits how many ads a user may create per day (UTC), zero means no limit
+func WithDailyAdQuota(n int) Option {
+	return func(a *App) {
+		a.adQuota = n
+	}
+}
+
+// withinAdQuota returns errs.AdQuotaError if the user has created as many ads today as the quota allows,
+// deleting ads doesn't give the quota back. It has to run in the unit of work creating the ad.
+func (a App) withinAdQuota(ctx context.Context, uID int64) error {
+	if a.adQuota == 0 {
+		return nil
+	}
+	today := a.clock.Now().Truncate(24 * time.Hour)
+	n, err := a.adRepo.CountCreated(ctx, uID, today)
+	if err != nil {
+		return err
+	}
+	if n >= a.adQuota {
+		return errs.AdQuotaError.WithResource(errs.ResourceUser, uID)
+	}
+	return nil
+}
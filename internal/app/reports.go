@@ -0,0 +1,346 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+	"ads-server/internal/validation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ReportConfig configures how abuse reports are handled
+type ReportConfig struct {
+	// TakedownThreshold is how many distinct users have to report an ad for it to be taken down
+	// until a moderator looks into it, zero disables automatic takedowns
+	TakedownThreshold int
+}
+
+// DefaultReportConfig is used unless WithReports option is given
+var DefaultReportConfig = ReportConfig{TakedownThreshold: 3}
+
+// WithReports overrides abuse report settings
+func WithReports(cfg ReportConfig) Option {
+	return func(a *App) {
+		a.reports = cfg
+	}
+}
+
+// ReportedAd is an ad with its open reports, one per reporter
+type ReportedAd struct {
+	Ad      *ads.Ad
+	Reports []*ads.Report
+}
+
+// ReportAd reports a published ad of another user to moderators. Once TakedownThreshold users
+// have open reports of the ad it is unpublished and taken down until a moderator resolves the reports.
+func (a App) ReportAd(ctx context.Context, adID, uID int64, reason ads.ReportReason, comment string) (_ *ads.Report, err error) {
+	ctx, span := tracer.Start(ctx, "App.ReportAd")
+	defer func() { endSpan(span, err) }()
+
+	if _, ok := ads.ParseReportReason(string(reason)); !ok {
+		return nil, errs.ValidationError.WithFields(errs.FieldViolation{
+			Field:       "reason",
+			Description: fmt.Sprintf("must be one of %v", ads.ReportReasons),
+		})
+	}
+	if err = validation.Validate(a.limits.Report(comment, reason == ads.ReasonOther)); err != nil {
+		return nil, err
+	}
+	if _, err = a.userRepo.Get(ctx, uID); err != nil {
+		return nil, err
+	}
+
+	var (
+		r         *ads.Report
+		takenDown *ads.Ad
+	)
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		ad, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if ad.AuthorID == uID {
+			return errs.OwnAdReportError.WithResource(errs.ResourceAd, adID)
+		}
+		if !ad.Published {
+			return errs.AdNotPublishedError.WithResource(errs.ResourceAd, adID)
+		}
+		r, err = a.adRepo.AddReport(ctx, &ads.Report{
+			AdID:       adID,
+			ReporterID: uID,
+			Reason:     reason,
+			Comment:    comment,
+			CreatedAt:  a.clock.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if err = a.record(ctx, uID, audit.ActionAdReport, errs.ResourceAd, adID, nil, r); err != nil {
+			return err
+		}
+		if a.reports.TakedownThreshold == 0 {
+			return nil
+		}
+		open, err := a.adRepo.Reports(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if len(open) < a.reports.TakedownThreshold {
+			return nil
+		}
+		takenDown, err = a.takeDown(ctx, ad, audit.SystemActorID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if takenDown != nil {
+		a.notifyAuthor(ctx, takenDown, "Your ad was taken down",
+			"Your ad %q was reported by several users and was unpublished until a moderator reviews it.\nYou can appeal the takedown.")
+	}
+	return r, nil
+}
+
+// takeDown unpublishes the ad and marks it taken down on behalf of the actor
+func (a App) takeDown(ctx context.Context, ad *ads.Ad, actorID int64) (*ads.Ad, error) {
+	before, err := a.adRepo.GetByID(ctx, ad.ID)
+	if err != nil {
+		return nil, err
+	}
+	if before.Published {
+		if before, err = a.unpublish(ctx, before, actorID); err != nil {
+			return nil, err
+		}
+	}
+	after, err := a.adRepo.SetTakenDown(ctx, ad.ID, a.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err = a.recordRevision(ctx, after, ads.ActionTakedown, actorID, 0); err != nil {
+		return nil, err
+	}
+	return after, a.record(ctx, actorID, audit.ActionAdTakedown, errs.ResourceAd, ad.ID, before, after)
+}
+
+// reinstate lifts the takedown of the ad on behalf of the actor, the author has to publish the ad again
+func (a App) reinstate(ctx context.Context, ad *ads.Ad, actorID int64) error {
+	after, err := a.adRepo.SetTakenDown(ctx, ad.ID, time.Time{})
+	if err != nil {
+		return err
+	}
+	if err = a.recordRevision(ctx, after, ads.ActionReinstate, actorID, 0); err != nil {
+		return err
+	}
+	return a.record(ctx, actorID, audit.ActionAdReinstate, errs.ResourceAd, ad.ID, ad, after)
+}
+
+// ReportInbox returns ads with open reports to a moderator, the most reported first
+func (a App) ReportInbox(ctx context.Context, uID int64) (_ []ReportedAd, err error) {
+	ctx, span := tracer.Start(ctx, "App.ReportInbox")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+	open, err := a.adRepo.OpenReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var inbox []ReportedAd
+	byAd := make(map[int64]int)
+	for _, r := range open {
+		i, ok := byAd[r.AdID]
+		if !ok {
+			ad, err := a.adRepo.GetByID(ctx, r.AdID)
+			if err != nil {
+				return nil, err
+			}
+			i = len(inbox)
+			byAd[r.AdID] = i
+			inbox = append(inbox, ReportedAd{Ad: ad})
+		}
+		inbox[i].Reports = append(inbox[i].Reports, r)
+	}
+	sort.SliceStable(inbox, func(i, j int) bool { return len(inbox[i].Reports) > len(inbox[j].Reports) })
+	return inbox, nil
+}
+
+// ResolveReports closes open reports of the ad on behalf of a moderator. Upheld reports take the ad down,
+// dismissed ones lift its takedown if any.
+func (a App) ResolveReports(ctx context.Context, adID, uID int64, takedown bool) (_ []*ads.Report, err error) {
+	ctx, span := tracer.Start(ctx, "App.ResolveReports")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+
+	var resolved []*ads.Report
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		ad, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		open, err := a.adRepo.Reports(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if len(open) == 0 {
+			return errs.ReportsNotFoundError.WithResource(errs.ResourceAd, adID)
+		}
+		resolved, err = a.resolveReports(ctx, ad, uID, takedown)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// resolveReports closes open reports of the ad taking it down or lifting its takedown, returning the reports
+func (a App) resolveReports(ctx context.Context, ad *ads.Ad, moderatorID int64, takedown bool) ([]*ads.Report, error) {
+	outcome := ads.OutcomeDismissed
+	switch {
+	case takedown:
+		outcome = ads.OutcomeTakenDown
+		if !ad.TakenDown() {
+			if _, err := a.takeDown(ctx, ad, moderatorID); err != nil {
+				return nil, err
+			}
+		}
+	case ad.TakenDown():
+		if err := a.reinstate(ctx, ad, moderatorID); err != nil {
+			return nil, err
+		}
+	}
+	resolved, err := a.adRepo.ResolveReports(ctx, ad.ID, moderatorID, outcome, a.clock.Now())
+	if err != nil || len(resolved) == 0 {
+		return resolved, err
+	}
+	return resolved, a.record(ctx, moderatorID, audit.ActionAdResolveReports, errs.ResourceAd, ad.ID, nil, resolved)
+}
+
+// AppealTakedown asks moderators to lift the takedown of the ad on behalf of its author
+func (a App) AppealTakedown(ctx context.Context, adID, uID int64, comment string) (_ *ads.Appeal, err error) {
+	ctx, span := tracer.Start(ctx, "App.AppealTakedown")
+	defer func() { endSpan(span, err) }()
+
+	if err = validation.Validate(a.limits.Report(comment, true)); err != nil {
+		return nil, err
+	}
+
+	var ap *ads.Appeal
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		ad, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if ad.AuthorID != uID {
+			return errs.AccessError.WithResource(errs.ResourceAd, adID)
+		}
+		if !ad.TakenDown() {
+			return errs.AdNotTakenDownError.WithResource(errs.ResourceAd, adID)
+		}
+		ap, err = a.adRepo.AddAppeal(ctx, &ads.Appeal{
+			AdID:      adID,
+			AuthorID:  uID,
+			Comment:   comment,
+			Status:    ads.AppealPending,
+			CreatedAt: a.clock.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionAppealCreate, errs.ResourceAppeal, ap.ID, nil, ap)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ap, nil
+}
+
+// ListAppeals returns pending appeals against takedowns to a moderator, oldest first
+func (a App) ListAppeals(ctx context.Context, uID int64) (_ []*ads.Appeal, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListAppeals")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+	return a.adRepo.PendingAppeals(ctx)
+}
+
+// ResolveAppeal accepts or rejects the appeal on behalf of a moderator. Accepting lifts the takedown and dismisses
+// open reports of the ad, the author has to publish it again. Rejecting upholds open reports.
+func (a App) ResolveAppeal(ctx context.Context, appealID, uID int64, accept bool) (_ *ads.Appeal, err error) {
+	ctx, span := tracer.Start(ctx, "App.ResolveAppeal")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+
+	action := audit.ActionAppealReject
+	if accept {
+		action = audit.ActionAppealAccept
+	}
+	var (
+		ap *ads.Appeal
+		ad *ads.Ad
+	)
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		before, err := a.adRepo.GetAppeal(ctx, appealID)
+		if err != nil {
+			return err
+		}
+		if ap, err = a.adRepo.ResolveAppeal(ctx, appealID, uID, accept, a.clock.Now()); err != nil {
+			return err
+		}
+		if err = a.record(ctx, uID, action, errs.ResourceAppeal, appealID, before, ap); err != nil {
+			return err
+		}
+		if ad, err = a.adRepo.GetByID(ctx, ap.AdID); err != nil {
+			return err
+		}
+		if !accept && !ad.TakenDown() {
+			// the takedown was lifted since, there is nothing to uphold
+			return nil
+		}
+		_, err = a.resolveReports(ctx, ad, uID, !accept)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if accept {
+		a.notifyAuthor(ctx, ad, "Your appeal was accepted",
+			"The takedown of your ad %q was lifted, you can publish it again.")
+	} else {
+		a.notifyAuthor(ctx, ad, "Your appeal was rejected", "Your ad %q stays taken down.")
+	}
+	return ap, nil
+}
+
+// notifyAuthor mails the author of the ad, format refers to the ad title.
+// Notifications are best effort: failures are recorded on the span and don't fail the change.
+func (a App) notifyAuthor(ctx context.Context, ad *ads.Ad, subject, format string) {
+	span := trace.SpanFromContext(ctx)
+	u, err := a.userRepo.Get(ctx, ad.AuthorID)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+	m := Mail{
+		To:      u.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf("Hello, %s!\n\n"+format+"\n", u.Name, ad.Title),
+	}
+	if err = a.mail.Send(ctx, m); err != nil {
+		span.RecordError(errs.Wrap(errs.Unavailable, "can't notify author", err))
+	}
+}
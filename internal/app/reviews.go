@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+	"ads-server/internal/users"
+	"ads-server/internal/validation"
+)
+
+// ReviewSeller rates the author of the ad on behalf of a buyer who messaged the author about it, once per ad
+func (a App) ReviewSeller(ctx context.Context, adID, uID int64, rating int, text string) (_ *users.Review, err error) {
+	ctx, span := tracer.Start(ctx, "App.ReviewSeller")
+	defer func() { endSpan(span, err) }()
+
+	if rating < users.MinRating || rating > users.MaxRating {
+		return nil, errs.ValidationError.WithFields(errs.FieldViolation{
+			Field:       "rating",
+			Description: fmt.Sprintf("must be between %d and %d", users.MinRating, users.MaxRating),
+		})
+	}
+	if err = validation.Validate(a.limits.Review("text", text)); err != nil {
+		return nil, err
+	}
+	if err = a.messaging(); err != nil {
+		return nil, err
+	}
+	c, err := a.messages.FindConversation(ctx, adID, uID)
+	if errors.Is(err, errs.ConversationNotFoundError) || err == nil && c.LastMessageAt.IsZero() {
+		return nil, errs.ReviewNotAllowedError.WithResource(errs.ResourceAd, adID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r *users.Review
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		r, err = a.userRepo.AddReview(ctx, &users.Review{
+			SellerID:  c.SellerID,
+			AuthorID:  uID,
+			AdID:      adID,
+			Rating:    rating,
+			Text:      text,
+			CreatedAt: a.clock.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionReviewCreate, errs.ResourceReview, r.ID, nil, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListReviews returns reviews of the seller, latest first. Hidden reviews are listed to moderators only,
+// the actor is optional.
+func (a App) ListReviews(ctx context.Context, sellerID int64, actorID *int64) (_ []*users.Review, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListReviews")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.userRepo.Get(ctx, sellerID); err != nil {
+		return nil, err
+	}
+	var withHidden bool
+	if actorID != nil {
+		actor, err := a.userRepo.Get(ctx, *actorID)
+		if err != nil {
+			return nil, err
+		}
+		withHidden = actor.CanModerate()
+	}
+	return a.userRepo.Reviews(ctx, sellerID, withHidden)
+}
+
+// ReplyToReview stores the only reply of the seller to a review
+func (a App) ReplyToReview(ctx context.Context, reviewID, uID int64, text string) (_ *users.Review, err error) {
+	ctx, span := tracer.Start(ctx, "App.ReplyToReview")
+	defer func() { endSpan(span, err) }()
+
+	if err = validation.Validate(a.limits.Review("text", text)); err != nil {
+		return nil, err
+	}
+
+	var r *users.Review
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		before, err := a.userRepo.GetReview(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+		if before.SellerID != uID {
+			return errs.AccessError.WithResource(errs.ResourceReview, reviewID)
+		}
+		if r, err = a.userRepo.ReplyToReview(ctx, reviewID, text, a.clock.Now()); err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionReviewReply, errs.ResourceReview, reviewID, before, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// HideReview hides an abusive review or shows it again on behalf of a moderator,
+// hidden reviews don't count in the reputation of the seller
+func (a App) HideReview(ctx context.Context, reviewID, uID int64, hidden bool) (_ *users.Review, err error) {
+	ctx, span := tracer.Start(ctx, "App.HideReview")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+
+	action := audit.ActionReviewShow
+	if hidden {
+		action = audit.ActionReviewHide
+	}
+	var r *users.Review
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		before, err := a.userRepo.GetReview(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+		if r, err = a.userRepo.HideReview(ctx, reviewID, hidden, uID); err != nil {
+			return err
+		}
+		if before.Hidden == hidden {
+			return nil
+		}
+		return a.record(ctx, uID, action, errs.ResourceReview, reviewID, before, r)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"errors"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+	"ads-server/internal/validation"
+)
+
+// AdChanges describes what changed in an ad since a moderator approved it
+type AdChanges struct {
+	AdID int64
+	// Approval is the latest approval of the ad, nil if it was never approved
+	Approval *ads.Approval
+	// Revision is the current revision of the ad
+	Revision int64
+	// Changes lists fields differing from the approved revision, or from an empty ad if there is none
+	Changes []ads.FieldChange
+}
+
+// recordRevision appends a snapshot of the ad taken after a change to its history,
+// restoredFrom is the revision the ad was rolled back to if the change is a rollback
+func (a App) recordRevision(ctx context.Context, ad *ads.Ad, action ads.Action, editorID, restoredFrom int64) error {
+	history, err := a.adRepo.Revisions(ctx, ad.ID)
+	if err != nil {
+		return err
+	}
+	var prev *ads.Revision
+	if len(history) > 0 {
+		prev = history[len(history)-1]
+	}
+	r := ads.NewRevision(ad, action, editorID, prev, a.clock.Now())
+	r.RestoredFrom = restoredFrom
+	return a.adRepo.AddRevision(ctx, r)
+}
+
+// history returns revisions of the ad if the user is its author or may moderate ads
+func (a App) history(ctx context.Context, adID, uID int64) ([]*ads.Revision, error) {
+	ad, err := a.adRepo.GetByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.AuthorID != uID {
+		_, err = a.moderator(ctx, uID)
+		if errors.Is(err, errs.AccessError) {
+			return nil, errs.AccessError.WithResource(errs.ResourceAd, adID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a.adRepo.Revisions(ctx, adID)
+}
+
+// findRevision returns the revision with the number given
+func findRevision(history []*ads.Revision, adID, number int64) (*ads.Revision, error) {
+	for _, r := range history {
+		if r.Number == number {
+			return r, nil
+		}
+	}
+	return nil, errs.RevisionNotFoundError.WithResource(errs.ResourceAd, adID)
+}
+
+// ListRevisions returns history of the ad to its author or a moderator, oldest first
+func (a App) ListRevisions(ctx context.Context, adID, uID int64) (_ []*ads.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListRevisions")
+	defer func() { endSpan(span, err) }()
+
+	return a.history(ctx, adID, uID)
+}
+
+// GetRevision returns a single revision of the ad to its author or a moderator
+func (a App) GetRevision(ctx context.Context, adID, uID, number int64) (_ *ads.Revision, err error) {
+	ctx, span := tracer.Start(ctx, "App.GetRevision")
+	defer func() { endSpan(span, err) }()
+
+	history, err := a.history(ctx, adID, uID)
+	if err != nil {
+		return nil, err
+	}
+	return findRevision(history, adID, number)
+}
+
+// RollbackAd restores title and text the ad had in the revision given, publication status is kept.
+// Only the author can roll back, the ad must have the version given unless it is zero.
+func (a App) RollbackAd(ctx context.Context, adID, uID, number, version int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.RollbackAd")
+	defer func() { endSpan(span, err) }()
+
+	var ad *ads.Ad
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		history, err := a.history(ctx, adID, uID)
+		if err != nil {
+			return err
+		}
+		target, err := findRevision(history, adID, number)
+		if err != nil {
+			return err
+		}
+		// limits may have been tightened since the revision was made
+		if err = validation.Validate(a.limits.Ad(target.Title, target.Text)...); err != nil {
+			return err
+		}
+		// and so may have content filters
+		title, text, flags, err := a.screen(ctx, target.Title, target.Text)
+		if err != nil {
+			return err
+		}
+		old, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		before := *old
+		if ad, err = a.adRepo.Update(ctx, adID, uID, title, text, version); err != nil {
+			return err
+		}
+		if ad, err = a.setScreening(ctx, ad, flags); err != nil {
+			return err
+		}
+		if err = a.recordRevision(ctx, ad, ads.ActionRollback, uID, number); err != nil {
+			return err
+		}
+		if err = a.record(ctx, uID, audit.ActionAdRollback, errs.ResourceAd, adID, before, ad); err != nil {
+			return err
+		}
+		ad, err = a.hold(ctx, ad)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// ApproveAd records that the moderator reviewed the current revision of the ad,
+// the ad must have the version given unless it is zero
+func (a App) ApproveAd(ctx context.Context, adID, uID, version int64) (_ *ads.Approval, err error) {
+	ctx, span := tracer.Start(ctx, "App.ApproveAd")
+	defer func() { endSpan(span, err) }()
+
+	if _, err = a.moderator(ctx, uID); err != nil {
+		return nil, err
+	}
+
+	var ap *ads.Approval
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		ad, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if version != 0 && ad.Version != version {
+			return errs.VersionConflictError.WithResource(errs.ResourceAd, adID)
+		}
+		prev, err := a.adRepo.LastApproval(ctx, adID)
+		if err != nil {
+			return err
+		}
+		ap = &ads.Approval{AdID: adID, Revision: ad.Version, ModeratorID: uID, At: a.clock.Now()}
+		if err = a.adRepo.Approve(ctx, ap); err != nil {
+			return err
+		}
+		// the ad itself doesn't change, so its approval is audited
+		return a.record(ctx, uID, audit.ActionAdApprove, errs.ResourceAd, adID, prev, ap)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ap, nil
+}
+
+// AdChangesSinceApproval returns what changed in the ad since a moderator approved it
+func (a App) AdChangesSinceApproval(ctx context.Context, adID, uID int64) (_ AdChanges, err error) {
+	ctx, span := tracer.Start(ctx, "App.AdChangesSinceApproval")
+	defer func() { endSpan(span, err) }()
+
+	history, err := a.history(ctx, adID, uID)
+	if err != nil {
+		return AdChanges{}, err
+	}
+	ap, err := a.adRepo.LastApproval(ctx, adID)
+	if err != nil {
+		return AdChanges{}, err
+	}
+
+	res := AdChanges{AdID: adID, Approval: ap}
+	if len(history) == 0 {
+		return res, nil
+	}
+	current := history[len(history)-1]
+	res.Revision = current.Number
+
+	var base *ads.Revision
+	if ap != nil {
+		if base, err = findRevision(history, adID, ap.Revision); err != nil {
+			return AdChanges{}, err
+		}
+	}
+	res.Changes = ads.Diff(base, current)
+	return res, nil
+}
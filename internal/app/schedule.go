@@ -0,0 +1,269 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+)
+
+// ScheduleConfig configures the scheduler making pending ad status changes
+type ScheduleConfig struct {
+	// CheckInterval is how often the scheduler looks for due transitions
+	CheckInterval time.Duration
+}
+
+// DefaultScheduleConfig is used unless WithSchedule option is given
+var DefaultScheduleConfig = ScheduleConfig{
+	CheckInterval: time.Minute,
+}
+
+// WithSchedule overrides scheduler settings
+func WithSchedule(cfg ScheduleConfig) Option {
+	return func(a *App) {
+		a.schedule = cfg
+	}
+}
+
+// TransitionReport lists IDs of transitions handled by a scheduler check
+type TransitionReport struct {
+	Done []int64
+	// Failed transitions can't be made anymore, e.g. their ad was deleted, so they are dropped
+	Failed []int64
+}
+
+// ChangeAdStatus publishes or unpublishes the ad now unless publishAt is given, then publication is scheduled instead.
+// Nonzero unpublishAt schedules unpublishing as well. A new transition replaces the pending one of the same kind.
+// Only the author can change the status, the ad must have the version given unless it is zero.
+func (a App) ChangeAdStatus(ctx context.Context, adID, uID int64, published bool, publishAt, unpublishAt time.Time, version int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.ChangeAdStatus")
+	defer func() { endSpan(span, err) }()
+
+	if publishAt.IsZero() && unpublishAt.IsZero() {
+		return a.PublishAd(ctx, adID, uID, published, version)
+	}
+	now := a.clock.Now()
+	if err = validateSchedule(published, publishAt, unpublishAt, now); err != nil {
+		return nil, err
+	}
+
+	var ad *ads.Ad
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		if publishAt.IsZero() {
+			// the ad is published now, only unpublishing is scheduled
+			ad, err = a.PublishAd(ctx, adID, uID, published, version)
+		} else {
+			ad, err = a.publishable(ctx, adID, uID, version)
+		}
+		if err != nil {
+			return err
+		}
+		for _, t := range []*ads.Transition{
+			{AdID: adID, UserID: uID, Publish: true, At: publishAt.UTC(), CreatedAt: now},
+			{AdID: adID, UserID: uID, Publish: false, At: unpublishAt.UTC(), CreatedAt: now},
+		} {
+			if t.At.IsZero() {
+				continue
+			}
+			if err = a.scheduleTransition(ctx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// validateSchedule checks that scheduled transitions are in the future and in order
+func validateSchedule(published bool, publishAt, unpublishAt, now time.Time) error {
+	var violations []errs.FieldViolation
+	switch {
+	case publishAt.IsZero():
+	case !published:
+		violations = append(violations, errs.FieldViolation{Field: "published", Description: "must be true to schedule publication"})
+	case !publishAt.After(now):
+		violations = append(violations, errs.FieldViolation{Field: "publish_at", Description: "must be in the future"})
+	}
+	switch {
+	case unpublishAt.IsZero():
+	case publishAt.IsZero() && !published:
+		violations = append(violations, errs.FieldViolation{Field: "unpublish_at", Description: "can't be given to unpublish the ad now"})
+	case !unpublishAt.After(now):
+		violations = append(violations, errs.FieldViolation{Field: "unpublish_at", Description: "must be in the future"})
+	case !publishAt.IsZero() && !unpublishAt.After(publishAt):
+		violations = append(violations, errs.FieldViolation{Field: "unpublish_at", Description: "must be after publish_at"})
+	}
+	if len(violations) > 0 {
+		return errs.ValidationError.WithFields(violations...)
+	}
+	return nil
+}
+
+// publishable returns the ad if its author could publish it now
+func (a App) publishable(ctx context.Context, adID, uID, version int64) (*ads.Ad, error) {
+	ad, err := a.adRepo.GetByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.AuthorID != uID {
+		return nil, errs.AccessError.WithResource(errs.ResourceAd, adID)
+	}
+	if version != 0 && ad.Version != version {
+		return nil, errs.VersionConflictError.WithResource(errs.ResourceAd, adID)
+	}
+	if ad.Archived() {
+		return nil, errs.AdArchivedError.WithResource(errs.ResourceAd, adID)
+	}
+	if ad.TakenDown() {
+		return nil, errs.AdTakenDownError.WithResource(errs.ResourceAd, adID)
+	}
+	if err = a.released(ctx, ad); err != nil {
+		return nil, err
+	}
+	if err = a.verified(ctx, uID); err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// scheduleTransition stores the transition replacing pending ones of the same kind
+func (a App) scheduleTransition(ctx context.Context, t *ads.Transition) error {
+	pending, err := a.adRepo.Transitions(ctx, t.AdID)
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if p.Publish != t.Publish {
+			continue
+		}
+		if err = a.cancelTransition(ctx, p, t.UserID); err != nil {
+			return err
+		}
+	}
+	if err = a.adRepo.ScheduleTransition(ctx, t); err != nil {
+		return err
+	}
+	return a.record(ctx, t.UserID, audit.ActionAdSchedule, errs.ResourceAd, t.AdID, nil, t)
+}
+
+// cancelTransition removes the pending transition on behalf of the actor
+func (a App) cancelTransition(ctx context.Context, t *ads.Transition, actorID int64) error {
+	removed, err := a.adRepo.RemoveTransition(ctx, t.AdID, t.ID)
+	if err != nil {
+		return err
+	}
+	return a.record(ctx, actorID, audit.ActionAdCancelSchedule, errs.ResourceAd, t.AdID, removed, nil)
+}
+
+// ListTransitions returns pending status changes of the ad to its author or an admin
+func (a App) ListTransitions(ctx context.Context, adID, uID int64) (_ []*ads.Transition, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListTransitions")
+	defer func() { endSpan(span, err) }()
+
+	ad, err := a.adRepo.GetByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.ownerOrAdmin(ctx, ad.AuthorID, uID, errs.ResourceAd, adID); err != nil {
+		return nil, err
+	}
+	return a.adRepo.Transitions(ctx, adID)
+}
+
+// CancelTransition drops the pending status change of the ad, only its author or an admin can cancel it
+func (a App) CancelTransition(ctx context.Context, adID, transitionID, uID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "App.CancelTransition")
+	defer func() { endSpan(span, err) }()
+
+	return a.uow.Do(ctx, func(ctx context.Context) error {
+		ad, err := a.adRepo.GetByID(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if err = a.ownerOrAdmin(ctx, ad.AuthorID, uID, errs.ResourceAd, adID); err != nil {
+			return err
+		}
+		return a.cancelTransition(ctx, &ads.Transition{ID: transitionID, AdID: adID}, uID)
+	})
+}
+
+// MakeDueTransitions changes status of ads whose transitions are due by the moment given, earliest first.
+// Every transition is made on behalf of the user who scheduled it in its own unit of work.
+// Transitions failing because of the ad state are dropped, the ones failing for other reasons are retried by the next check.
+func (a App) MakeDueTransitions(ctx context.Context, now time.Time) (_ TransitionReport, err error) {
+	ctx, span := tracer.Start(ctx, "App.MakeDueTransitions")
+	defer func() { endSpan(span, err) }()
+
+	due, err := a.adRepo.DueTransitions(ctx, now)
+	if err != nil {
+		return TransitionReport{}, err
+	}
+	var report TransitionReport
+	for _, t := range due {
+		t := t
+		err = a.uow.Do(ctx, func(ctx context.Context) error {
+			if _, err := a.adRepo.RemoveTransition(ctx, t.AdID, t.ID); err != nil {
+				return err
+			}
+			_, err := a.PublishAd(ctx, t.AdID, t.UserID, t.Publish, 0)
+			return err
+		})
+		if err == nil {
+			report.Done = append(report.Done, t.ID)
+			continue
+		}
+		span.RecordError(err)
+		if retryable(err) {
+			continue
+		}
+		err = a.uow.Do(ctx, func(ctx context.Context) error {
+			return a.cancelTransition(ctx, t, audit.SystemActorID)
+		})
+		if err != nil && !errors.Is(err, errs.TransitionNotFoundError) {
+			return report, err
+		}
+		report.Failed = append(report.Failed, t.ID)
+	}
+	return report, nil
+}
+
+// retryable reports whether the error is caused by the infrastructure rather than by the state of resources
+func retryable(err error) bool {
+	switch errs.From(err).Code {
+	case errs.Unavailable, errs.Internal, errs.Aborted:
+		return true
+	}
+	return false
+}
+
+// RunScheduler returns function making due transitions every CheckInterval until ctx is done.
+// Transitions are kept by the ad repository, so with persistent storage the ones missed while
+// the service was down are made by the first check after restart.
+func (a App) RunScheduler(ctx context.Context) func() error {
+	return func() error {
+		ticker := time.NewTicker(a.schedule.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				report, err := a.MakeDueTransitions(ctx, a.clock.Now())
+				if err != nil {
+					log.Printf("can't make scheduled transitions: %v", err)
+					continue
+				}
+				if len(report.Done)+len(report.Failed) > 0 {
+					log.Printf("made %d scheduled transitions, dropped %d failed ones", len(report.Done), len(report.Failed))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"strings"
+
+	"ads-server/internal/errs"
+	"ads-server/internal/users"
+)
+
+// WithStaff assigns roles to users registering with the emails given, emails are compared ignoring case.
+// Staff can act only once their email is verified.
+func WithStaff(staff map[string]users.Role) Option {
+	return func(a *App) {
+		a.staff = make(map[string]users.Role, len(staff))
+		for email, role := range staff {
+			a.staff[strings.ToLower(email)] = role
+		}
+	}
+}
+
+// roleFor returns the role of a user registering with the email given
+func (a App) roleFor(email string) users.Role {
+	if r, ok := a.staff[strings.ToLower(email)]; ok {
+		return r
+	}
+	return users.RoleUser
+}
+
+// moderator returns the acting user if it may moderate ads
+func (a App) moderator(ctx context.Context, uID int64) (*users.User, error) {
+	u, err := a.userRepo.Get(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.CanModerate() {
+		return nil, errs.AccessError.WithResource(errs.ResourceUser, uID)
+	}
+	return u, nil
+}
+
+// admin returns the acting user if it may manage other users
+func (a App) admin(ctx context.Context, uID int64) (*users.User, error) {
+	u, err := a.userRepo.Get(ctx, uID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsAdmin() {
+		return nil, errs.AccessError.WithResource(errs.ResourceUser, uID)
+	}
+	return u, nil
+}
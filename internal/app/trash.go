@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+	"ads-server/internal/users"
+)
+
+// TrashConfig configures how long deleted ads and users can be restored
+type TrashConfig struct {
+	// Retention is how long items stay in trash before they are purged
+	Retention time.Duration
+	// PurgeInterval is how often the purger looks for expired items
+	PurgeInterval time.Duration
+}
+
+// DefaultTrashConfig is used unless WithTrash option is given
+var DefaultTrashConfig = TrashConfig{
+	Retention:     30 * 24 * time.Hour,
+	PurgeInterval: time.Hour,
+}
+
+// WithTrash overrides trash retention settings
+func WithTrash(cfg TrashConfig) Option {
+	return func(a *App) {
+		a.trash = cfg
+	}
+}
+
+// PurgeReport lists items removed permanently by a purge
+type PurgeReport struct {
+	Ads   []int64
+	Users []int64
+}
+
+// ownerOrAdmin returns AccessError unless the actor is the owner or an admin
+func (a App) ownerOrAdmin(ctx context.Context, ownerID, actorID int64, typ string, id int64) error {
+	if ownerID == actorID {
+		return nil
+	}
+	_, err := a.admin(ctx, actorID)
+	if errors.Is(err, errs.AccessError) {
+		return errs.AccessError.WithResource(typ, id)
+	}
+	return err
+}
+
+// ListTrash returns deleted ads of the user to the user itself or an admin
+func (a App) ListTrash(ctx context.Context, uID, actorID int64) (_ []*ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.ListTrash")
+	defer func() { endSpan(span, err) }()
+
+	if err = a.ownerOrAdmin(ctx, uID, actorID, errs.ResourceUser, uID); err != nil {
+		return nil, err
+	}
+	return a.adRepo.Trash(ctx, uID)
+}
+
+// RestoreAd takes the ad out of trash, only its author or an admin can restore it
+func (a App) RestoreAd(ctx context.Context, adID, uID int64) (_ *ads.Ad, err error) {
+	ctx, span := tracer.Start(ctx, "App.RestoreAd")
+	defer func() { endSpan(span, err) }()
+
+	var ad *ads.Ad
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		deleted, err := a.adRepo.GetDeleted(ctx, adID)
+		if err != nil {
+			return err
+		}
+		if err = a.ownerOrAdmin(ctx, deleted.AuthorID, uID, errs.ResourceAd, adID); err != nil {
+			return err
+		}
+		if ad, err = a.adRepo.Restore(ctx, adID); err != nil {
+			return err
+		}
+		if err = a.recordRevision(ctx, ad, ads.ActionRestore, uID, 0); err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionAdRestore, errs.ResourceAd, adID, nil, ad)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+// RestoreUser takes the user out of trash with the ads deleted together with it.
+// The user itself or an admin can restore it, ads anonymized on deletion stay detached.
+func (a App) RestoreUser(ctx context.Context, id, actorID int64) (_ *users.User, err error) {
+	ctx, span := tracer.Start(ctx, "App.RestoreUser")
+	defer func() { endSpan(span, err) }()
+
+	var u *users.User
+	err = a.uow.Do(ctx, func(ctx context.Context) error {
+		if err := a.ownerOrAdmin(ctx, id, actorID, errs.ResourceUser, id); err != nil {
+			return err
+		}
+		var err error
+		if u, err = a.userRepo.Restore(ctx, id); err != nil {
+			return err
+		}
+		restored, err := a.adRepo.RestoreByAuthor(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, ad := range restored {
+			if err = a.recordRevision(ctx, ad, ads.ActionRestore, actorID, 0); err != nil {
+				return err
+			}
+			if err = a.record(ctx, actorID, audit.ActionAdRestore, errs.ResourceAd, ad.ID, nil, ad); err != nil {
+				return err
+			}
+		}
+		return a.record(ctx, actorID, audit.ActionUserRestore, errs.ResourceUser, id, nil, u)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Purge permanently removes ads and users kept in trash longer than the retention period
+func (a App) Purge(ctx context.Context, now time.Time) (_ PurgeReport, err error) {
+	ctx, span := tracer.Start(ctx, "App.Purge")
+	defer func() { endSpan(span, err) }()
+
+	before := now.Add(-a.trash.Retention)
+	var report PurgeReport
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		if report.Ads, err = a.adRepo.Purge(ctx, before); err != nil {
+			return err
+		}
+		if report.Users, err = a.userRepo.Purge(ctx, before); err != nil {
+			return err
+		}
+		for _, id := range report.Ads {
+			if err = a.record(ctx, audit.SystemActorID, audit.ActionAdPurge, errs.ResourceAd, id, nil, nil); err != nil {
+				return err
+			}
+		}
+		for _, id := range report.Users {
+			if err = a.record(ctx, audit.SystemActorID, audit.ActionUserPurge, errs.ResourceUser, id, nil, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return PurgeReport{}, err
+	}
+	return report, nil
+}
+
+// RunPurger returns function purging trash every PurgeInterval until ctx is done,
+// failed purges are logged and retried on the next tick
+func (a App) RunPurger(ctx context.Context) func() error {
+	return func() error {
+		ticker := time.NewTicker(a.trash.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				report, err := a.Purge(ctx, a.clock.Now())
+				if err != nil {
+					log.Printf("can't purge trash: %v", err)
+					continue
+				}
+				if len(report.Ads)+len(report.Users) > 0 {
+					log.Printf("purged %d ads and %d users from trash", len(report.Ads), len(report.Users))
+				}
+			}
+		}
+	}
+}
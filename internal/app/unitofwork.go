@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"sync"
+)
+
+// UnitOfWork runs a function in a transaction spanning all repositories.
+// The transaction travels in the context passed to fn, so repositories must be called with that context.
+// Implementations backed by a database keep the native transaction there,
+// the in-memory one (uow.UndoLog) keeps compensations of the changes made.
+//
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name UnitOfWork
+type UnitOfWork interface {
+	// Do commits changes made by fn if it returns nil and rolls them back otherwise
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// WithUnitOfWork sets the transaction manager of the repositories
+func WithUnitOfWork(u UnitOfWork) Option {
+	return func(a *App) {
+		a.uow = u
+	}
+}
+
+type afterCommitKey struct{}
+
+// afterCommitLog collects functions to run once the outermost unit of work commits
+type afterCommitLog struct {
+	mx  sync.Mutex
+	fns []func(ctx context.Context)
+}
+
+// committing runs functions registered with afterCommit once the outermost unit of work commits,
+// so side effects of nested calls such as mail aren't made before or without the commit
+type committing struct {
+	UnitOfWork
+}
+
+func (u committing) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(afterCommitKey{}).(*afterCommitLog); ok {
+		return u.UnitOfWork.Do(ctx, fn)
+	}
+	l := &afterCommitLog{}
+	if err := u.UnitOfWork.Do(context.WithValue(ctx, afterCommitKey{}, l), fn); err != nil {
+		return err
+	}
+	for _, f := range l.fns {
+		f(ctx)
+	}
+	return nil
+}
+
+// afterCommit registers f to be run once the unit of work carried by ctx commits,
+// f is run immediately outside of units of work
+func afterCommit(ctx context.Context, f func(ctx context.Context)) {
+	l, ok := ctx.Value(afterCommitKey{}).(*afterCommitLog)
+	if !ok {
+		f(ctx)
+		return
+	}
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.fns = append(l.fns, f)
+}
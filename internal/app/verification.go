@@ -0,0 +1,159 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+	"ads-server/internal/users"
+)
+
+// Mail is a plain text email message
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name MailSender
+type MailSender interface {
+	Send(ctx context.Context, m Mail) error
+}
+
+// discardSender drops every message, it is used until a real sender is configured
+type discardSender struct{}
+
+func (discardSender) Send(context.Context, Mail) error {
+	return nil
+}
+
+// VerificationConfig configures email verification tokens
+type VerificationConfig struct {
+	// TokenTTL is how long a token sent to the user stays valid
+	TokenTTL time.Duration
+	// ResendInterval is the minimum time between two verification emails sent to the same user
+	ResendInterval time.Duration
+	// LinkURL is the page users confirm emails at, the token is appended as "token" query parameter.
+	// The token is sent alone when it is empty.
+	LinkURL string
+}
+
+// DefaultVerificationConfig is used unless WithVerification option is given
+var DefaultVerificationConfig = VerificationConfig{
+	TokenTTL:       24 * time.Hour,
+	ResendInterval: time.Minute,
+}
+
+// WithMailSender sets the sender verification emails are delivered by
+func WithMailSender(s MailSender) Option {
+	return func(a *App) {
+		a.mail = s
+	}
+}
+
+// WithVerification overrides email verification settings
+func WithVerification(cfg VerificationConfig) Option {
+	return func(a *App) {
+		a.verification = cfg
+	}
+}
+
+// verificationMail builds the message carrying the token given
+func (a App) verificationMail(u *users.User, token string) Mail {
+	confirm := "Your confirmation code: " + token
+	if a.verification.LinkURL != "" {
+		confirm = "Follow the link to confirm it: " + a.verification.LinkURL + "?token=" + url.QueryEscape(token)
+	}
+	return Mail{
+		To:      u.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Hello, %s!\n\nPlease confirm %s is your email address.\n%s\n\nThe confirmation expires in %s.\n",
+			u.Name, u.Email, confirm, a.verification.TokenTTL),
+	}
+}
+
+// issueVerification stores a new token for the user replacing the previous one and returns the mail carrying it.
+// The mail is meant to be sent once the token is committed.
+func (a App) issueVerification(ctx context.Context, u *users.User) (*Mail, error) {
+	token, t, err := users.NewVerificationToken(u.ID, u.Email, a.clock.Now(), a.verification.TokenTTL)
+	if err != nil {
+		return nil, errs.Wrap(errs.Internal, "can't generate verification token", err)
+	}
+	if err = a.userRepo.SaveToken(ctx, t); err != nil {
+		return nil, err
+	}
+	m := a.verificationMail(u, token)
+	return &m, nil
+}
+
+// sendMail delivers verification email
+func (a App) sendMail(ctx context.Context, m Mail) error {
+	if err := a.mail.Send(ctx, m); err != nil {
+		return errs.Wrap(errs.Unavailable, "can't send verification email", err)
+	}
+	return nil
+}
+
+// ConfirmEmail verifies email of the user the token was sent to
+func (a App) ConfirmEmail(ctx context.Context, token string) (_ *users.User, err error) {
+	ctx, span := tracer.Start(ctx, "App.ConfirmEmail")
+	defer func() { endSpan(span, err) }()
+
+	if token == "" {
+		return nil, errs.ValidationError.WithFields(errs.FieldViolation{Field: "token", Description: "must not be empty"})
+	}
+	var u *users.User
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		if u, err = a.userRepo.Verify(ctx, users.HashToken(token), a.clock.Now()); err != nil {
+			return err
+		}
+		// the token proves the user holds the mailbox, so the user is the actor
+		return a.record(ctx, u.ID, audit.ActionUserVerify, errs.ResourceUser, u.ID, nil, u)
+	})
+	if errors.Is(err, errs.VerificationTokenError) {
+		return nil, errs.VerificationTokenError.WithFields(errs.FieldViolation{Field: "token", Description: "is unknown or expired"})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// ResendVerification issues a new verification token for the user unless one was sent recently
+func (a App) ResendVerification(ctx context.Context, uID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "App.ResendVerification")
+	defer func() { endSpan(span, err) }()
+
+	var m *Mail
+	// the interval is checked in the same unit of work as the token is issued in,
+	// so concurrent requests can't send more than one email
+	err = a.uow.Do(ctx, func(ctx context.Context) (err error) {
+		u, err := a.userRepo.Get(ctx, uID)
+		if err != nil {
+			return err
+		}
+		if u.Verified {
+			return errs.EmailAlreadyVerifiedError.WithResource(errs.ResourceUser, uID)
+		}
+		last, err := a.userRepo.UserToken(ctx, uID)
+		switch {
+		case errors.Is(err, errs.VerificationTokenError):
+		case err != nil:
+			return err
+		case a.clock.Now().Sub(last.SentAt) < a.verification.ResendInterval:
+			return errs.VerificationResendError.WithResource(errs.ResourceUser, uID)
+		}
+		if m, err = a.issueVerification(ctx, u); err != nil {
+			return err
+		}
+		return a.record(ctx, uID, audit.ActionUserResendVerification, errs.ResourceUser, uID, nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+	return a.sendMail(ctx, *m)
+}
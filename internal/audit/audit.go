@@ -0,0 +1,191 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Action is the kind of state change an entry records
+type Action string
+
+const (
+	ActionAdCreate    Action = "ad.create"
+	ActionAdUpdate    Action = "ad.update"
+	ActionAdPublish   Action = "ad.publish"
+	ActionAdUnpublish Action = "ad.unpublish"
+	ActionAdRollback  Action = "ad.rollback"
+	ActionAdApprove   Action = "ad.approve"
+	ActionAdAnonymize Action = "ad.anonymize"
+	ActionAdDelete    Action = "ad.delete"
+	ActionAdRestore   Action = "ad.restore"
+	ActionAdPurge     Action = "ad.purge"
+	ActionAdArchive   Action = "ad.archive"
+	ActionAdRenew     Action = "ad.renew"
+	ActionAdExtend    Action = "ad.extend"
+	// schedule entries record transitions of the ad, not its own state
+	ActionAdSchedule       Action = "ad.schedule"
+	ActionAdCancelSchedule Action = "ad.cancel_schedule"
+	// report entries record reports of the ad, not its own state
+	ActionAdReport         Action = "ad.report"
+	ActionAdResolveReports Action = "ad.resolve_reports"
+	ActionAdTakedown       Action = "ad.takedown"
+	ActionAdReinstate      Action = "ad.reinstate"
+
+	ActionAppealCreate Action = "appeal.create"
+	ActionAppealAccept Action = "appeal.accept"
+	ActionAppealReject Action = "appeal.reject"
+
+	ActionUserCreate             Action = "user.create"
+	ActionUserUpdate             Action = "user.update"
+	ActionUserVerify             Action = "user.verify"
+	ActionUserResendVerification Action = "user.resend_verification"
+	ActionUserDelete             Action = "user.delete"
+	ActionUserRestore            Action = "user.restore"
+	ActionUserPurge              Action = "user.purge"
+
+	ActionReviewCreate Action = "review.create"
+	ActionReviewReply  Action = "review.reply"
+	ActionReviewHide   Action = "review.hide"
+	ActionReviewShow   Action = "review.show"
+)
+
+// SystemActorID is the actor of changes made by the service itself, e.g. the trash purger
+const SystemActorID int64 = -1
+
+// Entry is a single record of the audit log. Entries are chained:
+// each one includes the hash of the previous one, so changing or removing an entry breaks the chain.
+type Entry struct {
+	// Seq is the position of the entry in the log, starting from 1
+	Seq        int64
+	At         time.Time
+	ActorID    int64
+	Action     Action
+	TargetType string
+	TargetID   int64
+	// Before and After are JSON snapshots of the target, unset when the target did not exist or was not read
+	Before json.RawMessage
+	After  json.RawMessage
+
+	RequestID string
+	Transport string
+
+	PrevHash string
+	Hash     string
+}
+
+// NewEntry describes a change of the target made in the request carried by ctx, before and after are snapshotted now
+func NewEntry(ctx context.Context, actorID int64, action Action, targetType string, targetID int64, before, after any, now time.Time) (*Entry, error) {
+	req := RequestFromContext(ctx)
+	e := &Entry{
+		At:         now,
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  req.ID,
+		Transport:  req.Transport,
+	}
+	var err error
+	if e.Before, err = snapshot(before); err != nil {
+		return nil, err
+	}
+	if e.After, err = snapshot(after); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// snapshot encodes v, nil (including typed nil pointers) has no snapshot
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil, err
+	}
+	return data, nil
+}
+
+// ComputeHash returns hash of the entry contents and the previous hash, the Hash field itself is not included
+func (e *Entry) ComputeHash() string {
+	c := *e
+	c.Hash = ""
+	c.At = c.At.UTC()
+	// fields of a struct are encoded in a fixed order, so the encoding is stable
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Chain links the entry to the previous one (nil for the first entry) and seals it with its hash
+func (e *Entry) Chain(prev *Entry) {
+	e.Seq, e.PrevHash = 1, ""
+	if prev != nil {
+		e.Seq, e.PrevHash = prev.Seq+1, prev.Hash
+	}
+	e.Hash = e.ComputeHash()
+}
+
+// Verification is the result of checking the hash chain of the log
+type Verification struct {
+	Entries int64
+	// Head is the hash of the last entry, it can be kept elsewhere to detect truncation later
+	Head  string
+	Valid bool
+	// BrokenAt is the sequence number of the first entry failing the check, zero if the chain is valid
+	BrokenAt int64
+}
+
+// Verify checks that entries, oldest first, form an unbroken hash chain starting from the first one
+func Verify(entries []*Entry) Verification {
+	v := Verification{Entries: int64(len(entries)), Valid: true}
+	var prev *Entry
+	for i, e := range entries {
+		ok := e.Seq == int64(i+1) && e.Hash == e.ComputeHash()
+		if prev == nil {
+			ok = ok && e.PrevHash == ""
+		} else {
+			ok = ok && e.PrevHash == prev.Hash
+		}
+		if !ok {
+			v.Valid, v.BrokenAt = false, int64(i+1)
+			return v
+		}
+		prev = e
+	}
+	if prev != nil {
+		v.Head = prev.Hash
+	}
+	return v
+}
+
+// Filter selects entries of the log, zero fields match everything
+type Filter struct {
+	ActorID    *int64
+	TargetType string
+	TargetID   *int64
+	// From and To bound the time of the change, both inclusive
+	From time.Time
+	To   time.Time
+}
+
+// Match reports whether the entry satisfies the filter
+func (f Filter) Match(e *Entry) bool {
+	switch {
+	case f.ActorID != nil && e.ActorID != *f.ActorID:
+		return false
+	case f.TargetType != "" && e.TargetType != f.TargetType:
+		return false
+	case f.TargetID != nil && e.TargetID != *f.TargetID:
+		return false
+	case !f.From.IsZero() && e.At.Before(f.From):
+		return false
+	case !f.To.IsZero() && e.At.After(f.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func chain(t *testing.T, n int) []*Entry {
+	t.Helper()
+	var entries []*Entry
+	var prev *Entry
+	for i := 0; i < n; i++ {
+		e, err := NewEntry(context.Background(), int64(i), ActionAdUpdate, "ad", 1,
+			map[string]string{"title": "old"}, map[string]string{"title": "new"}, time.Now())
+		assert.NoError(t, err)
+		e.Chain(prev)
+		entries = append(entries, e)
+		prev = e
+	}
+	return entries
+}
+
+func TestVerify(t *testing.T) {
+	entries := chain(t, 3)
+	v := Verify(entries)
+	assert.True(t, v.Valid)
+	assert.Equal(t, int64(3), v.Entries)
+	assert.Equal(t, entries[2].Hash, v.Head)
+	assert.Equal(t, entries[1].Hash, entries[2].PrevHash)
+
+	assert.True(t, Verify(nil).Valid, "empty log is valid")
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := map[string]struct {
+		tamper   func(entries []*Entry) []*Entry
+		brokenAt int64
+	}{
+		"changed": {func(entries []*Entry) []*Entry {
+			entries[1].ActorID = 42
+			return entries
+		}, 2},
+		"changed snapshot": {func(entries []*Entry) []*Entry {
+			entries[1].After = []byte(`{"title":"forged"}`)
+			return entries
+		}, 2},
+		"removed": {func(entries []*Entry) []*Entry {
+			return append(entries[:1], entries[2:]...)
+		}, 2},
+		"rehashed": {func(entries []*Entry) []*Entry {
+			// rewriting an entry with a fresh hash still breaks the link from the next one
+			entries[1].ActorID = 42
+			entries[1].Hash = entries[1].ComputeHash()
+			return entries
+		}, 3},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			v := Verify(tc.tamper(chain(t, 3)))
+			assert.False(t, v.Valid)
+			assert.Equal(t, tc.brokenAt, v.BrokenAt)
+		})
+	}
+}
+
+func TestNewEntryRequest(t *testing.T) {
+	var missing *struct{}
+	e, err := NewEntry(context.Background(), 1, ActionUserDelete, "user", 1, missing, nil, time.Now())
+	assert.NoError(t, err)
+	assert.Nil(t, e.Before, "typed nil has no snapshot")
+	assert.Nil(t, e.After)
+	assert.Equal(t, TransportInternal, e.Transport)
+
+	ctx := WithRequest(context.Background(), Request{ID: "req-1", Transport: TransportHTTP})
+	e, err = NewEntry(ctx, 1, ActionUserDelete, "user", 1, nil, nil, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", e.RequestID)
+	assert.Equal(t, TransportHTTP, e.Transport)
+}
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Now()
+	actor, target := int64(1), int64(7)
+	e := &Entry{At: now, ActorID: actor, TargetType: "ad", TargetID: target}
+
+	assert.True(t, Filter{}.Match(e))
+	assert.True(t, Filter{ActorID: &actor, TargetType: "ad", TargetID: &target, From: now, To: now}.Match(e))
+
+	other := int64(2)
+	assert.False(t, Filter{ActorID: &other}.Match(e))
+	assert.False(t, Filter{TargetType: "user"}.Match(e))
+	assert.False(t, Filter{TargetID: &other}.Match(e))
+	assert.False(t, Filter{From: now.Add(time.Second)}.Match(e))
+	assert.False(t, Filter{To: now.Add(-time.Second)}.Match(e))
+}
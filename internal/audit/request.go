@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Transports the changes can come from
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+	// TransportInternal marks changes made by the service itself outside of any request
+	TransportInternal = "internal"
+)
+
+// RequestIDHeader is the HTTP header and gRPC metadata key carrying the request ID
+const RequestIDHeader = "X-Request-ID"
+
+// Request identifies the request a change was made in
+type Request struct {
+	ID        string
+	Transport string
+}
+
+type requestKey struct{}
+
+// WithRequest returns context carrying the request
+func WithRequest(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// RequestFromContext returns the request carried by ctx, changes made outside of requests are internal
+func RequestFromContext(ctx context.Context) Request {
+	if r, ok := ctx.Value(requestKey{}).(Request); ok {
+		return r
+	}
+	return Request{Transport: TransportInternal}
+}
+
+// NewRequestID generates a random request ID for requests coming without one
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,54 @@
+// Package clock abstracts the current time, so code depending on it can be run at any moment in tests.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time
+type Clock interface {
+	// Now returns the current time in UTC
+	Now() time.Time
+}
+
+type system struct{}
+
+func (system) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// System is the wall clock
+var System Clock = system{}
+
+// Fake is a clock standing still until it is set or advanced, it is safe for concurrent use
+type Fake struct {
+	mx  sync.Mutex
+	now time.Time
+}
+
+// NewFake creates a clock frozen at the moment given
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now.UTC()}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	return f.now
+}
+
+// Set moves the clock to the moment given
+func (f *Fake) Set(now time.Time) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.now = now.UTC()
+}
+
+// Advance moves the clock forward by d returning the new time
+func (f *Fake) Advance(d time.Duration) time.Time {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.now = f.now.Add(d)
+	return f.now
+}
@@ -0,0 +1,28 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystem(t *testing.T) {
+	now := System.Now()
+	assert.Equal(t, time.UTC, now.Location())
+	assert.WithinDuration(t, time.Now(), now, time.Second)
+}
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	c := NewFake(start)
+	assert.True(t, start.Equal(c.Now()))
+	assert.Equal(t, time.UTC, c.Now().Location())
+	assert.Equal(t, c.Now(), c.Now(), "time stands still")
+
+	assert.True(t, start.Add(time.Hour).Equal(c.Advance(time.Hour)))
+	assert.True(t, start.Add(time.Hour).Equal(c.Now()))
+
+	c.Set(start)
+	assert.True(t, start.Equal(c.Now()))
+}
@@ -0,0 +1,245 @@
+package content
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// stars masks every character of the fragment
+func stars(fragment string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(fragment))
+}
+
+// Words objects to words of a list, e.g. profanity in several languages. Words are compared case-insensitively.
+type Words struct {
+	action Action
+	words  map[string]bool
+}
+
+// NewWords creates a checker of the words given
+func NewWords(action Action, words ...string) *Words {
+	w := &Words{action: action, words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			w.words[word] = true
+		}
+	}
+	return w
+}
+
+// ReadWords reads a word list with one word per line, empty lines and lines starting with # are skipped
+func ReadWords(r io.Reader) ([]string, error) {
+	var words []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, s.Err()
+}
+
+func (w *Words) Name() string { return "words" }
+
+func (w *Words) Check(_ context.Context, in Input) ([]Finding, error) {
+	var findings []Finding
+	for _, f := range in.fields() {
+		start := -1
+		flush := func(end int) {
+			if start < 0 {
+				return
+			}
+			if word := f.value[start:end]; w.words[strings.ToLower(word)] {
+				findings = append(findings, Finding{
+					Checker:     w.Name(),
+					Field:       f.name,
+					Reason:      fmt.Sprintf("contains banned word %q", word),
+					Action:      w.action,
+					Start:       start,
+					End:         end,
+					Replacement: stars(word),
+				})
+			}
+			start = -1
+		}
+		for i, r := range f.value {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			flush(i)
+		}
+		flush(len(f.value))
+	}
+	return findings, nil
+}
+
+// pattern objects to fragments matching a regular expression
+type pattern struct {
+	name   string
+	reason string
+	action Action
+	re     *regexp.Regexp
+	// accept filters matches, nil accepts every match
+	accept func(match string) bool
+}
+
+func (p *pattern) Name() string { return p.name }
+
+func (p *pattern) Check(_ context.Context, in Input) ([]Finding, error) {
+	var findings []Finding
+	for _, f := range in.fields() {
+		for _, loc := range p.re.FindAllStringIndex(f.value, -1) {
+			match := f.value[loc[0]:loc[1]]
+			if p.accept != nil && !p.accept(match) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Checker:     p.name,
+				Field:       f.name,
+				Reason:      fmt.Sprintf("%s %q", p.reason, match),
+				Action:      p.action,
+				Start:       loc[0],
+				End:         loc[1],
+				Replacement: stars(match),
+			})
+		}
+	}
+	return findings, nil
+}
+
+var linkRe = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+(?:com|net|org|info|biz|io|me|ru|su|ua|by|kz|xyz|top|site|online|shop|link|ly|gl)\b(?:/\S*)?`)
+
+// NewLinks creates a checker of URLs and bare domain names
+func NewLinks(action Action) Checker {
+	return &pattern{name: "links", reason: "contains link", action: action, re: linkRe}
+}
+
+var phoneRe = regexp.MustCompile(`\+?\d[\d ().-]{7,}\d`)
+
+// NewPhones creates a checker of phone numbers, that is 10 to 15 digits optionally separated by spaces,
+// dashes, dots and parentheses
+func NewPhones(action Action) Checker {
+	return &pattern{name: "phones", reason: "contains phone number", action: action, re: phoneRe, accept: func(match string) bool {
+		digits := 0
+		for _, r := range match {
+			if unicode.IsDigit(r) {
+				digits++
+			}
+		}
+		return digits >= 10 && digits <= 15
+	}}
+}
+
+// Repeats objects to a character repeated more than Max times in a row, e.g. "!!!!!!" or "sooooo".
+// Spaces and digits are not counted. Masking shortens the run to Max characters.
+type Repeats struct {
+	action Action
+	max    int
+}
+
+// NewRepeats creates a checker of characters repeated more than max times in a row
+func NewRepeats(action Action, max int) *Repeats {
+	return &Repeats{action: action, max: max}
+}
+
+func (c *Repeats) Name() string { return "repeats" }
+
+func (c *Repeats) Check(_ context.Context, in Input) ([]Finding, error) {
+	var findings []Finding
+	for _, f := range in.fields() {
+		var (
+			prev  rune
+			start int
+			n     int
+		)
+		flush := func(end int) {
+			if n > c.max && !unicode.IsSpace(prev) && !unicode.IsDigit(prev) {
+				findings = append(findings, Finding{
+					Checker:     c.Name(),
+					Field:       f.name,
+					Reason:      fmt.Sprintf("repeats %q %d times", prev, n),
+					Action:      c.action,
+					Start:       start,
+					End:         end,
+					Replacement: strings.Repeat(string(prev), c.max),
+				})
+			}
+		}
+		for i, r := range f.value {
+			if n > 0 && unicode.ToLower(r) == unicode.ToLower(prev) {
+				n++
+				continue
+			}
+			flush(i)
+			prev, start, n = r, i, 1
+		}
+		flush(len(f.value))
+	}
+	return findings, nil
+}
+
+// Caps objects to fields written in capital letters. A field is shouting when it has at least MinLetters letters
+// and Ratio of them are upper case. Masking turns the field to lower case keeping its first letter.
+type Caps struct {
+	action     Action
+	minLetters int
+	ratio      float64
+}
+
+// NewCaps creates a checker of fields with at least minLetters letters, ratio of them capital
+func NewCaps(action Action, minLetters int, ratio float64) *Caps {
+	return &Caps{action: action, minLetters: minLetters, ratio: ratio}
+}
+
+func (c *Caps) Name() string { return "caps" }
+
+func (c *Caps) Check(_ context.Context, in Input) ([]Finding, error) {
+	var findings []Finding
+	for _, f := range in.fields() {
+		letters, upper := 0, 0
+		for _, r := range f.value {
+			if unicode.IsLetter(r) {
+				letters++
+				if unicode.IsUpper(r) {
+					upper++
+				}
+			}
+		}
+		if letters < c.minLetters || float64(upper) < c.ratio*float64(letters) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Checker:     c.Name(),
+			Field:       f.name,
+			Reason:      "is written in capital letters",
+			Action:      c.action,
+			Start:       0,
+			End:         len(f.value),
+			Replacement: sentenceCase(f.value),
+		})
+	}
+	return findings, nil
+}
+
+// sentenceCase turns s to lower case keeping its first letter capital
+func sentenceCase(s string) string {
+	lower := []rune(strings.ToLower(s))
+	for i, r := range lower {
+		if unicode.IsLetter(r) {
+			lower[i] = unicode.ToUpper(r)
+			break
+		}
+	}
+	return string(lower)
+}
@@ -0,0 +1,96 @@
+package content
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config selects checkers of a pipeline and their actions, Allow disables a checker
+type Config struct {
+	// WordLists are files of banned words, e.g. one per language
+	WordLists []string
+	Words     Action
+	Links     Action
+	Phones    Action
+	Caps      Action
+	Repeats   Action
+	// MaxRepeats is how many times in a row a character may repeat
+	MaxRepeats int
+}
+
+// DefaultConfig masks banned words, phone numbers and repeated characters, flags links and shouting.
+// Repeated ads are not a matter of content, app.DuplicateConfig handles them.
+var DefaultConfig = Config{
+	Words:      Mask,
+	Links:      Flag,
+	Phones:     Mask,
+	Caps:       Flag,
+	Repeats:    Mask,
+	MaxRepeats: 4,
+}
+
+// ConfigFromEnv reads the configuration from environment, unset variables keep default values
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig
+	if v := os.Getenv("CONTENT_WORD_LISTS"); v != "" {
+		for _, path := range strings.Split(v, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				cfg.WordLists = append(cfg.WordLists, path)
+			}
+		}
+	}
+	for env, dst := range map[string]*Action{
+		"CONTENT_WORDS_ACTION":   &cfg.Words,
+		"CONTENT_LINKS_ACTION":   &cfg.Links,
+		"CONTENT_PHONES_ACTION":  &cfg.Phones,
+		"CONTENT_CAPS_ACTION":    &cfg.Caps,
+		"CONTENT_REPEATS_ACTION": &cfg.Repeats,
+	} {
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		a, err := ParseAction(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("%s: %w", env, err)
+		}
+		*dst = a
+	}
+	return cfg, nil
+}
+
+// New creates a pipeline of checkers enabled by the configuration, banned words are read from word lists
+func New(cfg Config) (*Pipeline, error) {
+	var checkers []Checker
+	// repeats go first, otherwise they would shorten stars of other masks
+	if cfg.Repeats != Allow {
+		checkers = append(checkers, NewRepeats(cfg.Repeats, cfg.MaxRepeats))
+	}
+	if cfg.Words != Allow && len(cfg.WordLists) > 0 {
+		var words []string
+		for _, path := range cfg.WordLists {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("can't open word list: %w", err)
+			}
+			list, err := ReadWords(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("can't read word list %s: %w", path, err)
+			}
+			words = append(words, list...)
+		}
+		checkers = append(checkers, NewWords(cfg.Words, words...))
+	}
+	if cfg.Links != Allow {
+		checkers = append(checkers, NewLinks(cfg.Links))
+	}
+	if cfg.Phones != Allow {
+		checkers = append(checkers, NewPhones(cfg.Phones))
+	}
+	if cfg.Caps != Allow {
+		checkers = append(checkers, NewCaps(cfg.Caps, 10, 0.8))
+	}
+	return NewPipeline(checkers...), nil
+}
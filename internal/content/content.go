@@ -0,0 +1,166 @@
+// Package content screens texts of ads with a pipeline of pluggable checkers.
+// Every checker objects to fragments of a field and tells whether the fragment is masked,
+// the whole ad is flagged for moderation or rejected.
+package content
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is what is done with content a checker objects to, a more severe action wins
+type Action int
+
+const (
+	// Allow disables a checker
+	Allow Action = iota
+	// Mask replaces the fragment with a harmless form, e.g. stars
+	Mask
+	// Flag keeps the content as is and holds the ad until a moderator approves it
+	Flag
+	// Reject refuses the content
+	Reject
+)
+
+var actionNames = []string{"allow", "mask", "flag", "reject"}
+
+func (a Action) String() string {
+	if a < 0 || int(a) >= len(actionNames) {
+		return fmt.Sprintf("action(%d)", int(a))
+	}
+	return actionNames[a]
+}
+
+// ParseAction returns the action named s
+func ParseAction(s string) (Action, error) {
+	for i, name := range actionNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return Action(i), nil
+		}
+	}
+	return Allow, fmt.Errorf("unknown content filter action %q, want one of %s", s, strings.Join(actionNames, ", "))
+}
+
+// Input is the content being screened
+type Input struct {
+	Title string
+	Text  string
+}
+
+// field is a named part of the input
+type field struct {
+	name  string
+	value string
+}
+
+// fields lists parts of the input checkers look at
+func (in Input) fields() []field {
+	return []field{{"title", in.Title}, {"text", in.Text}}
+}
+
+// Finding is an objection of a checker to a fragment of a field
+type Finding struct {
+	Checker string
+	Field   string
+	Reason  string
+	Action  Action
+	// Start and End are byte offsets of the fragment, findings about the whole content have no fragment
+	Start int
+	End   int
+	// Replacement is what the fragment is masked with
+	Replacement string
+}
+
+// Maskable reports whether the finding refers to a fragment that can be masked
+func (f Finding) Maskable() bool {
+	return f.End > f.Start
+}
+
+// Checker objects to content
+type Checker interface {
+	Name() string
+	Check(ctx context.Context, in Input) ([]Finding, error)
+}
+
+// Result is the outcome of screening
+type Result struct {
+	// Title and Text are the input with fragments masked
+	Title string
+	Text  string
+	// Action is the most severe action of the findings
+	Action   Action
+	Findings []Finding
+}
+
+// Pipeline runs checkers in order
+type Pipeline struct {
+	checkers []Checker
+}
+
+// NewPipeline creates a pipeline of the checkers given
+func NewPipeline(checkers ...Checker) *Pipeline {
+	return &Pipeline{checkers: checkers}
+}
+
+// Run screens the input with every checker, each checker looks at the content masked by the checkers before it,
+// so masks of different checkers never overlap. Findings asking to mask content they have no fragment of
+// or a fragment overlapping another one of the same checker flag it instead.
+// Masks are applied unless the content is rejected.
+func (p *Pipeline) Run(ctx context.Context, in Input) (Result, error) {
+	res := Result{Title: in.Title, Text: in.Text}
+	masked := in
+	for _, c := range p.checkers {
+		findings, err := c.Check(ctx, masked)
+		if err != nil {
+			return Result{}, fmt.Errorf("content checker %s: %w", c.Name(), err)
+		}
+		for i, f := range findings {
+			if f.Action == Mask && !f.Maskable() {
+				findings[i].Action = Flag
+			}
+		}
+		masked.Title = mask(masked.Title, "title", findings)
+		masked.Text = mask(masked.Text, "text", findings)
+		for _, f := range findings {
+			if f.Action > res.Action {
+				res.Action = f.Action
+			}
+			res.Findings = append(res.Findings, f)
+		}
+	}
+	if res.Action < Reject {
+		res.Title, res.Text = masked.Title, masked.Text
+	}
+	return res, nil
+}
+
+// mask replaces fragments of the field the findings ask to mask. A fragment overlapping an earlier one
+// can't be masked, so its finding is turned to Flag.
+func mask(value, name string, findings []Finding) string {
+	var fragments []int
+	for i, f := range findings {
+		if f.Field == name && f.Action == Mask && f.Maskable() {
+			fragments = append(fragments, i)
+		}
+	}
+	if len(fragments) == 0 {
+		return value
+	}
+	sort.SliceStable(fragments, func(i, j int) bool { return findings[fragments[i]].Start < findings[fragments[j]].Start })
+	var b strings.Builder
+	pos := 0
+	for _, i := range fragments {
+		f := findings[i]
+		if f.Start < pos {
+			findings[i].Action = Flag
+			continue
+		}
+		b.WriteString(value[pos:f.Start])
+		b.WriteString(f.Replacement)
+		pos = f.End
+	}
+	b.WriteString(value[pos:])
+	return b.String()
+}
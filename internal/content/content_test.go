@@ -0,0 +1,150 @@
+package content
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkerFunc is a checker with findings given by a function
+type checkerFunc func(in Input) []Finding
+
+func (f checkerFunc) Name() string { return "func" }
+
+func (f checkerFunc) Check(_ context.Context, in Input) ([]Finding, error) { return f(in), nil }
+
+func run(t *testing.T, p *Pipeline, title, text string) Result {
+	t.Helper()
+	res, err := p.Run(context.Background(), Input{Title: title, Text: text})
+	assert.NoError(t, err)
+	return res
+}
+
+func TestParseAction(t *testing.T) {
+	for _, a := range []Action{Allow, Mask, Flag, Reject} {
+		parsed, err := ParseAction(strings.ToUpper(a.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, a, parsed)
+	}
+	_, err := ParseAction("drop")
+	assert.Error(t, err)
+}
+
+func TestWords(t *testing.T) {
+	words, err := ReadWords(strings.NewReader("# english\nidiot\n\n# русский\n Дурак \n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"idiot", "Дурак"}, words)
+
+	res := run(t, NewPipeline(NewWords(Mask, words...)), "IDIOT bike", "дурак, not дураки; idiots are fine")
+	assert.Equal(t, Mask, res.Action)
+	assert.Equal(t, "***** bike", res.Title)
+	assert.Equal(t, "*****, not дураки; idiots are fine", res.Text)
+	assert.Len(t, res.Findings, 2)
+}
+
+func TestLinksAndPhones(t *testing.T) {
+	p := NewPipeline(NewLinks(Flag), NewPhones(Mask))
+	res := run(t, p, "bike", "call +7 (912) 345-67-89 or see https://example.com/bike, 15 000 000 rub")
+	assert.Equal(t, Flag, res.Action)
+	assert.Equal(t, "bike", res.Title)
+	assert.Equal(t, "call ****************** or see https://example.com/bike, 15 000 000 rub", res.Text,
+		"links are flagged, not masked")
+	if assert.Len(t, res.Findings, 2) {
+		assert.Equal(t, "links", res.Findings[0].Checker)
+		assert.Equal(t, "phones", res.Findings[1].Checker)
+	}
+
+	res = run(t, p, "bike", "write to t.me/seller or shop.example.ru")
+	assert.Len(t, res.Findings, 2)
+	res = run(t, p, "bike", "version 1.2, e.g. ok")
+	assert.Empty(t, res.Findings)
+}
+
+func TestRepeatsAndCaps(t *testing.T) {
+	p := NewPipeline(NewRepeats(Mask, 3), NewCaps(Mask, 10, 0.8))
+	res := run(t, p, "SELLING MY BIKE", "Sooooo cheap!!!!!! price 1000000")
+	assert.Equal(t, "Selling my bike", res.Title)
+	assert.Equal(t, "Sooo cheap!!! price 1000000", res.Text, "digits are not counted")
+
+	res = run(t, p, "BMX", "short CAPS are fine")
+	assert.Empty(t, res.Findings)
+}
+
+func TestPipelineActions(t *testing.T) {
+	whole := checkerFunc(func(Input) []Finding {
+		return []Finding{{Checker: "whole", Field: "text", Reason: "objectionable", Action: Mask}}
+	})
+	res := run(t, NewPipeline(NewWords(Mask, "idiot"), whole), "bike", "idiot")
+	assert.Equal(t, Flag, res.Action, "findings without a fragment can't be masked")
+	assert.Equal(t, "*****", res.Text)
+
+	res = run(t, NewPipeline(NewWords(Reject, "idiot"), NewRepeats(Mask, 2)), "bike!!!", "idiot")
+	assert.Equal(t, Reject, res.Action)
+	assert.Equal(t, "bike!!!", res.Title, "rejected content is not masked")
+
+	res = run(t, NewPipeline(NewRepeats(Mask, 2), NewCaps(Mask, 3, 0.8)), "BIKE!!!", "fine")
+	assert.Equal(t, "Bike!!", res.Title, "checkers look at content masked before them")
+	assert.Equal(t, Mask, res.Action)
+
+	overlapping := checkerFunc(func(Input) []Finding {
+		return []Finding{
+			{Checker: "func", Field: "text", Action: Mask, Start: 0, End: 4, Replacement: "----"},
+			{Checker: "func", Field: "text", Action: Mask, Start: 2, End: 6, Replacement: "++++"},
+		}
+	})
+	res = run(t, NewPipeline(overlapping), "bike", "red bike")
+	assert.Equal(t, "----bike", res.Text)
+	assert.Equal(t, Flag, res.Action, "a fragment that can't be masked flags the content")
+	if assert.Len(t, res.Findings, 2) {
+		assert.Equal(t, Mask, res.Findings[0].Action)
+		assert.Equal(t, Flag, res.Findings[1].Action)
+	}
+}
+
+func TestCapsAndPhones(t *testing.T) {
+	for _, p := range []*Pipeline{
+		NewPipeline(NewCaps(Mask, 10, 0.8), NewPhones(Mask)),
+		NewPipeline(NewPhones(Mask), NewCaps(Mask, 10, 0.8)),
+	} {
+		res := run(t, p, "bike", "CALL ME AT +7 912 345-67-89 TODAY")
+		assert.Equal(t, Mask, res.Action)
+		assert.Equal(t, "Call me at **************** today", res.Text, "both masks apply whatever the order")
+		if assert.Len(t, res.Findings, 2) {
+			assert.Equal(t, Mask, res.Findings[0].Action)
+			assert.Equal(t, Mask, res.Findings[1].Action)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.WordLists = []string{"testdata/missing.txt"}
+	_, err := New(cfg)
+	assert.Error(t, err)
+
+	t.Setenv("CONTENT_LINKS_ACTION", "allow")
+	cfg, err = ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, Allow, cfg.Links)
+	p, err := New(cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, run(t, p, "bike", "see example.com").Findings)
+
+	t.Setenv("CONTENT_CAPS_ACTION", "shout")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestSimHash(t *testing.T) {
+	text := "Selling a red mountain bike in good condition, 21 gears, new tyres, pick up in the city centre"
+	f := SimHash(text)
+	assert.Equal(t, f, SimHash(strings.ToUpper(text)+"!"), "case and punctuation are ignored")
+	assert.Equal(t, 0, f.Distance(f))
+
+	similar := SimHash("Selling red mountain bike in good condition, 21 gears, new tyres, pick up in the city center")
+	other := SimHash("Renting a two room flat near the railway station, furnished, long term only, no pets")
+	assert.Less(t, f.Distance(similar), f.Distance(other))
+	assert.Greater(t, f.Distance(other), 10)
+}
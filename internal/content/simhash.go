@@ -0,0 +1,49 @@
+package content
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// Fingerprint is a SimHash of a text, similar texts have fingerprints differing in few bits
+type Fingerprint uint64
+
+// SimHash fingerprints the text by its words and pairs of adjacent words, ignoring case and punctuation
+func SimHash(text string) Fingerprint {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var weights [64]int
+	add := func(feature string) {
+		h := fnv.New64a()
+		h.Write([]byte(feature))
+		sum := h.Sum64()
+		for i := range weights {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	for i, w := range words {
+		add(w)
+		if i > 0 {
+			add(words[i-1] + " " + w)
+		}
+	}
+	var f Fingerprint
+	for i, w := range weights {
+		if w > 0 {
+			f |= 1 << i
+		}
+	}
+	return f
+}
+
+// Distance returns how many bits of the fingerprints differ
+func (f Fingerprint) Distance(other Fingerprint) int {
+	return bits.OnesCount64(uint64(f ^ other))
+}
@@ -1,9 +1,213 @@
 package errs
 
-import "fmt"
+import (
+	"errors"
+	"net/http"
+	"strconv"
 
-var UserNotFoundError = fmt.Errorf("no such user")
-var ValidationError = fmt.Errorf("validation failed")
-var AccessError = fmt.Errorf("access forbiden")
-var AdNotFoundError = fmt.Errorf("no such ad")
-var WrongProtoBufDataError = fmt.Errorf("wrong field given")
+	"google.golang.org/grpc/codes"
+)
+
+// Code classifies domain errors independently of the transport they are reported by
+type Code int
+
+const (
+	Unknown Code = iota
+	InvalidArgument
+	NotFound
+	AlreadyExists
+	PermissionDenied
+	Unauthenticated
+	FailedPrecondition
+	ResourceExhausted
+	Aborted
+	Unavailable
+	Internal
+)
+
+// mapping describes how a code is reported by HTTP and gRPC transports
+type mapping struct {
+	name   string
+	title  string
+	status int
+	grpc   codes.Code
+}
+
+// mappings is the single source of truth for translating domain errors to transports
+var mappings = map[Code]mapping{
+	Unknown:            {"unknown", "Unknown error", http.StatusInternalServerError, codes.Unknown},
+	InvalidArgument:    {"invalid-argument", "Invalid argument", http.StatusBadRequest, codes.InvalidArgument},
+	NotFound:           {"not-found", "Resource not found", http.StatusNotFound, codes.NotFound},
+	AlreadyExists:      {"already-exists", "Resource already exists", http.StatusConflict, codes.AlreadyExists},
+	PermissionDenied:   {"permission-denied", "Permission denied", http.StatusForbidden, codes.PermissionDenied},
+	Unauthenticated:    {"unauthenticated", "Unauthenticated", http.StatusUnauthorized, codes.Unauthenticated},
+	FailedPrecondition: {"failed-precondition", "Failed precondition", http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	ResourceExhausted:  {"resource-exhausted", "Resource exhausted", http.StatusTooManyRequests, codes.ResourceExhausted},
+	Aborted:            {"aborted", "Precondition failed", http.StatusPreconditionFailed, codes.Aborted},
+	Unavailable:        {"unavailable", "Service unavailable", http.StatusServiceUnavailable, codes.Unavailable},
+	Internal:           {"internal", "Internal error", http.StatusInternalServerError, codes.Internal},
+}
+
+// String returns kebab-case name of the code
+func (c Code) String() string {
+	return mappings[c].name
+}
+
+// Title returns short human-readable summary of the code
+func (c Code) Title() string {
+	return mappings[c].title
+}
+
+// HTTPStatus returns HTTP status code the code is reported with
+func (c Code) HTTPStatus() int {
+	return mappings[c].status
+}
+
+// GRPCCode returns gRPC status code the code is reported with
+func (c Code) GRPCCode() codes.Code {
+	return mappings[c].grpc
+}
+
+// FieldViolation describes why a single input field is invalid
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// Resource identifies the entity an error refers to
+type Resource struct {
+	Type string
+	ID   string
+}
+
+// Error is a domain error with a code, optional details and a wrapped cause
+type Error struct {
+	Code     Code
+	Message  string
+	Fields   []FieldViolation
+	Resource *Resource
+	Err      error
+}
+
+// New creates an error with the code and message given
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap creates an error with the code and message given caused by err
+func Wrap(code Code, message string, err error) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is a domain error of the same kind,
+// so errors derived with With* methods still match the sentinel they were made from
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code && t.Message == e.Message
+}
+
+// WithFields returns a copy of the error with field violations appended
+func (e *Error) WithFields(fields ...FieldViolation) *Error {
+	c := *e
+	c.Fields = append(append([]FieldViolation(nil), e.Fields...), fields...)
+	return &c
+}
+
+// WithResource returns a copy of the error referring to the resource given
+func (e *Error) WithResource(typ string, id int64) *Error {
+	c := *e
+	c.Resource = &Resource{Type: typ, ID: strconv.FormatInt(id, 10)}
+	return &c
+}
+
+// WithCause returns a copy of the error wrapping err
+func (e *Error) WithCause(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// From converts any error to a domain error, errors not produced by this package become Internal
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Wrap(Internal, "internal error", err)
+}
+
+// Resource types errors may refer to
+const (
+	ResourceUser = "user"
+	ResourceAd   = "ad"
+	// ResourceTransition is a scheduled publication or unpublication of an ad
+	ResourceTransition = "transition"
+	// ResourceConversation is a messaging thread about an ad between its author and a buyer
+	ResourceConversation = "conversation"
+	ResourceReview       = "review"
+	// ResourceAppeal is a request of an author to lift the takedown of an ad
+	ResourceAppeal = "appeal"
+)
+
+var UserNotFoundError = New(NotFound, "no such user")
+var ValidationError = New(InvalidArgument, "validation failed")
+var AccessError = New(PermissionDenied, "access forbidden")
+var AdNotFoundError = New(NotFound, "no such ad")
+var WrongProtoBufDataError = New(InvalidArgument, "wrong field given")
+var AuthenticationError = New(Unauthenticated, "unknown acting user")
+var AdExistsError = New(AlreadyExists, "ad already exists")
+var UserExistsError = New(AlreadyExists, "user already exists")
+var EmailTakenError = New(AlreadyExists, "email already taken")
+var EmailNotVerifiedError = New(FailedPrecondition, "email is not verified")
+var EmailAlreadyVerifiedError = New(FailedPrecondition, "email is already verified")
+var VerificationTokenError = New(InvalidArgument, "verification token is invalid or expired")
+var VerificationResendError = New(ResourceExhausted, "verification email was sent recently")
+var UserHasAdsError = New(FailedPrecondition, "user has ads")
+var NotDeletedError = New(FailedPrecondition, "resource is not in trash")
+var AdArchivedError = New(FailedPrecondition, "ad is archived, it has to be renewed first")
+var RevisionNotFoundError = New(NotFound, "no such revision")
+var TransitionNotFoundError = New(NotFound, "no such scheduled transition")
+var FavoriteNotFoundError = New(NotFound, "ad is not in favorites")
+var AdNotPublishedError = New(FailedPrecondition, "ad is not published")
+var OwnAdFavoriteError = New(FailedPrecondition, "own ads can't be saved to favorites")
+var MessagingDisabledError = New(Unavailable, "messaging is not configured")
+var ConversationNotFoundError = New(NotFound, "no such conversation")
+var MessageNotFoundError = New(NotFound, "no such message in the conversation")
+var OwnAdConversationError = New(FailedPrecondition, "authors can't start conversations about own ads")
+var UserBlockedError = New(PermissionDenied, "messaging between the users is blocked")
+var BlockNotFoundError = New(NotFound, "user is not blocked")
+var ReviewNotFoundError = New(NotFound, "no such review")
+var ReviewExistsError = New(AlreadyExists, "the ad has already been reviewed by the user")
+var ReviewNotAllowedError = New(FailedPrecondition, "only buyers who messaged the seller about the ad can review it")
+var ReviewRepliedError = New(FailedPrecondition, "the review has already been replied to")
+var ReportExistsError = New(AlreadyExists, "the ad has already been reported by the user")
+var OwnAdReportError = New(FailedPrecondition, "authors can't report own ads")
+var AdTakenDownError = New(FailedPrecondition, "ad was taken down, it can't be published until the takedown is lifted")
+var AdNotTakenDownError = New(FailedPrecondition, "ad is not taken down")
+var ReportsNotFoundError = New(NotFound, "ad has no open reports")
+var AppealNotFoundError = New(NotFound, "no such appeal")
+var AppealPendingError = New(FailedPrecondition, "the takedown has already been appealed")
+var AppealResolvedError = New(FailedPrecondition, "the appeal has already been resolved")
+var ContentRejectedError = New(InvalidArgument, "content was rejected by filters")
+var DuplicateAdError = New(AlreadyExists, "a similar ad already exists")
+var AdHeldError = New(FailedPrecondition, "ad is held by content filters until a moderator approves it")
+var RateLimitError = New(ResourceExhausted, "too many requests, retry later")
+var AdQuotaError = New(ResourceExhausted, "daily quota of new ads is exhausted")
+var IdempotencyKeyError = New(InvalidArgument, "idempotency key must be 1 to 255 printable ASCII characters")
+var IdempotencyMismatchError = New(FailedPrecondition, "idempotency key was used with a different request")
+var IdempotencyInProgressError = New(AlreadyExists, "a request with the idempotency key is in progress")
+var VersionConflictError = New(Aborted, "resource was modified concurrently")
@@ -0,0 +1,51 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestMappings(t *testing.T) {
+	tests := []struct {
+		err    *Error
+		status int
+		code   codes.Code
+	}{
+		{ValidationError, http.StatusBadRequest, codes.InvalidArgument},
+		{UserNotFoundError, http.StatusNotFound, codes.NotFound},
+		{AdNotFoundError, http.StatusNotFound, codes.NotFound},
+		{AccessError, http.StatusForbidden, codes.PermissionDenied},
+		{AuthenticationError, http.StatusUnauthorized, codes.Unauthenticated},
+		{UserExistsError, http.StatusConflict, codes.AlreadyExists},
+		{VersionConflictError, http.StatusPreconditionFailed, codes.Aborted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err.Message, func(t *testing.T) {
+			assert.Equal(t, tt.status, tt.err.Code.HTTPStatus())
+			assert.Equal(t, tt.code, tt.err.Code.GRPCCode())
+		})
+	}
+}
+
+func TestDerivedErrorsMatchSentinel(t *testing.T) {
+	err := AdNotFoundError.WithResource(ResourceAd, 7).WithCause(errors.New("gone"))
+	wrapped := fmt.Errorf("handler: %w", err)
+
+	assert.ErrorIs(t, wrapped, AdNotFoundError)
+	assert.NotErrorIs(t, wrapped, UserNotFoundError)
+	assert.Equal(t, &Resource{Type: ResourceAd, ID: "7"}, From(wrapped).Resource)
+	assert.Nil(t, AdNotFoundError.Resource, "sentinel must not be modified")
+}
+
+func TestFrom(t *testing.T) {
+	assert.Nil(t, From(nil))
+
+	e := From(errors.New("disk failure"))
+	assert.Equal(t, Internal, e.Code)
+	assert.Equal(t, "internal error", e.Message)
+}
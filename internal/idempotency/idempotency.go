@@ -0,0 +1,137 @@
+// Package idempotency remembers responses to requests carrying idempotency keys, so retries of a create operation
+// get the first response instead of creating the resource again. Transports adapt it to their requests and responses.
+package idempotency
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"ads-server/internal/clock"
+	"ads-server/internal/errs"
+)
+
+const (
+	// Header carries the idempotency key of a request, gRPC metadata uses its lowercase form
+	Header = "Idempotency-Key"
+	// ReplayedHeader marks responses replayed from the store
+	ReplayedHeader = "Idempotent-Replayed"
+	// MaxKeyLen is the longest idempotency key accepted
+	MaxKeyLen = 255
+)
+
+// DefaultWindow is how long responses are remembered unless configured otherwise
+const DefaultWindow = 24 * time.Hour
+
+// Response is what a transport needs to replay a response
+type Response struct {
+	// Status is the HTTP status code, zero for gRPC
+	Status int
+	// Header holds HTTP headers or gRPC header metadata worth replaying
+	Header map[string]string
+	// Type is the full name of the protobuf message of gRPC responses
+	Type string
+	Body []byte
+}
+
+// entry is a request with an idempotency key, its response is unset while the request is in progress
+type entry struct {
+	payload  [sha256.Size]byte
+	response *Response
+	expires  time.Time
+}
+
+// Store remembers responses for a window, it is safe for concurrent use
+type Store struct {
+	window time.Duration
+	clock  clock.Clock
+
+	mx      sync.Mutex
+	entries map[string]*entry
+}
+
+// New creates a store remembering responses for the window given
+func New(window time.Duration, c clock.Clock) *Store {
+	return &Store{window: window, clock: c, entries: make(map[string]*entry)}
+}
+
+// validKey reports whether the key is non-empty printable ASCII no longer than MaxKeyLen
+func validKey(key string) bool {
+	if key == "" || len(key) > MaxKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// Scope returns the scope of keys a client sends to the route, so keys picked by different clients never collide.
+// The client is known by its API key if it sends one, otherwise by its address together with the user it acts for.
+func Scope(route, apiKey, userID, ip string) string {
+	if apiKey != "" {
+		return route + " key:" + apiKey
+	}
+	return route + " ip:" + ip + " user:" + userID
+}
+
+// Begin claims the key within the scope (see Scope) for a request with the payload given.
+// It returns the response to replay if the key was used with the same payload before, otherwise the request
+// has to be processed and then either finished or released. A key used with another payload fails with
+// errs.IdempotencyMismatchError, an invalid key with errs.IdempotencyKeyError, a key of a request in progress with errs.IdempotencyInProgressError.
+func (s *Store) Begin(scope, key string, payload []byte) (*Response, error) {
+	if !validKey(key) {
+		return nil, errs.IdempotencyKeyError.WithFields(errs.FieldViolation{Field: Header, Description: "must be 1 to 255 printable ASCII characters"})
+	}
+	sum := sha256.Sum256(payload)
+	now := s.clock.Now()
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.sweep(now)
+
+	id := scope + "\x00" + key
+	if e, ok := s.entries[id]; ok {
+		switch {
+		case e.payload != sum:
+			return nil, errs.IdempotencyMismatchError
+		case e.response == nil:
+			return nil, errs.IdempotencyInProgressError
+		}
+		r := *e.response
+		return &r, nil
+	}
+	s.entries[id] = &entry{payload: sum, expires: now.Add(s.window)}
+	return nil, nil
+}
+
+// Finish remembers the response to the request that claimed the key for the window
+func (s *Store) Finish(scope, key string, r Response) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if e, ok := s.entries[scope+"\x00"+key]; ok {
+		e.response = &r
+		e.expires = s.clock.Now().Add(s.window)
+	}
+}
+
+// Release forgets the key of a failed request, so it can be retried
+func (s *Store) Release(scope, key string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	id := scope + "\x00" + key
+	if e, ok := s.entries[id]; ok && e.response == nil {
+		delete(s.entries, id)
+	}
+}
+
+// sweep forgets expired entries
+func (s *Store) sweep(now time.Time) {
+	for id, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, id)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"testing"
+	"time"
+
+	"ads-server/internal/clock"
+	"ads-server/internal/errs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	s := New(time.Hour, c)
+	first := Response{Status: 200, Body: []byte(`{"id":1}`)}
+
+	replay, err := s.Begin("POST /ads", "k1", []byte("bike"))
+	assert.NoError(t, err)
+	assert.Nil(t, replay)
+	_, err = s.Begin("POST /ads", "k1", []byte("bike"))
+	assert.ErrorIs(t, err, errs.IdempotencyInProgressError)
+	s.Finish("POST /ads", "k1", first)
+
+	replay, err = s.Begin("POST /ads", "k1", []byte("bike"))
+	assert.NoError(t, err)
+	assert.Equal(t, &first, replay)
+	_, err = s.Begin("POST /ads", "k1", []byte("car"))
+	assert.ErrorIs(t, err, errs.IdempotencyMismatchError)
+	replay, err = s.Begin("/ad.AdService/CreateAd", "k1", []byte("car"))
+	assert.NoError(t, err, "scopes have own keys")
+	assert.Nil(t, replay)
+
+	c.Advance(time.Hour)
+	replay, err = s.Begin("POST /ads", "k1", []byte("car"))
+	assert.NoError(t, err, "keys expire after the window")
+	assert.Nil(t, replay)
+}
+
+func TestStoreRelease(t *testing.T) {
+	s := New(time.Hour, clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)))
+	_, err := s.Begin("POST /ads", "k1", []byte("bike"))
+	assert.NoError(t, err)
+	s.Release("POST /ads", "k1")
+	replay, err := s.Begin("POST /ads", "k1", []byte("bike"))
+	assert.NoError(t, err, "failed requests can be retried")
+	assert.Nil(t, replay)
+
+	s.Finish("POST /ads", "k1", Response{Status: 200})
+	s.Release("POST /ads", "k1")
+	replay, err = s.Begin("POST /ads", "k1", []byte("bike"))
+	assert.NoError(t, err)
+	assert.NotNil(t, replay, "finished requests are kept")
+}
+
+func TestStoreKeys(t *testing.T) {
+	s := New(time.Hour, clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)))
+	for _, key := range []string{"", string(make([]byte, MaxKeyLen+1)), "key\n", "ключ"} {
+		_, err := s.Begin("POST /ads", key, nil)
+		assert.ErrorIs(t, err, errs.IdempotencyKeyError, "%q", key)
+	}
+}
+
+func TestScope(t *testing.T) {
+	assert.Equal(t, Scope("POST /ads", "partner", "1", "10.0.0.1"), Scope("POST /ads", "partner", "2", "10.0.0.2"),
+		"clients with API keys are known by them")
+	assert.NotEqual(t, Scope("POST /ads", "partner", "", ""), Scope("POST /ads", "other", "", ""))
+	assert.NotEqual(t, Scope("POST /ads", "", "1", "10.0.0.1"), Scope("POST /ads", "", "2", "10.0.0.1"))
+	assert.NotEqual(t, Scope("POST /ads", "", "1", "10.0.0.1"), Scope("POST /ads", "", "1", "10.0.0.2"))
+}
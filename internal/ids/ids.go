@@ -0,0 +1,163 @@
+// Package ids generates IDs of new resources.
+//
+// Sequential IDs are compact and predictable, which suits tests and a single in-memory instance.
+// UUIDv7 and Snowflake IDs are time-ordered, unique across instances and can't be enumerated
+// by guessing the next number, which suits production.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"ads-server/internal/clock"
+)
+
+// Generator hands out unique IDs, it is safe for concurrent use
+type Generator interface {
+	Next() (int64, error)
+}
+
+// Sequential generates consecutive IDs
+type Sequential struct {
+	mx   sync.Mutex
+	next int64
+}
+
+// NewSequential creates generator starting at the ID given
+func NewSequential(start int64) *Sequential {
+	return &Sequential{next: start}
+}
+
+func (s *Sequential) Next() (int64, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	id := s.next
+	s.next++
+	return id, nil
+}
+
+// Snowflake layout: 41 bits of milliseconds since SnowflakeEpoch, 10 bits of node and 12 bits of sequence
+const (
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+
+	// MaxSnowflakeNode is the greatest node number, every instance sharing storage must have its own
+	MaxSnowflakeNode = 1<<snowflakeNodeBits - 1
+	snowflakeSeqMask = 1<<snowflakeSeqBits - 1
+)
+
+// SnowflakeEpoch is the moment Snowflake timestamps are counted from, it gives about 69 years of IDs
+var SnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates IDs made of time, node number and a sequence number within a millisecond
+type Snowflake struct {
+	mx    sync.Mutex
+	clock clock.Clock
+	node  int64
+	last  int64
+	seq   int64
+}
+
+// NewSnowflake creates generator for the node given
+func NewSnowflake(node int64, c clock.Clock) (*Snowflake, error) {
+	if node < 0 || node > MaxSnowflakeNode {
+		return nil, fmt.Errorf("snowflake node must be in [0, %d], got %d", MaxSnowflakeNode, node)
+	}
+	return &Snowflake{clock: c, node: node, last: -1}, nil
+}
+
+func (s *Snowflake) Next() (int64, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	ms := s.clock.Now().Sub(SnowflakeEpoch).Milliseconds()
+	if ms < 0 {
+		return 0, fmt.Errorf("clock is before snowflake epoch %s", SnowflakeEpoch)
+	}
+	switch {
+	case ms > s.last:
+		s.last, s.seq = ms, 0
+	case s.seq < snowflakeSeqMask:
+		// the same millisecond or the clock moved back, IDs keep growing anyway
+		s.seq++
+	default:
+		// the sequence is exhausted, borrow the next millisecond rather than wait for it
+		s.last, s.seq = s.last+1, 0
+	}
+	return s.last<<(snowflakeNodeBits+snowflakeSeqBits) | s.node<<snowflakeSeqBits | s.seq, nil
+}
+
+// UUIDv7 generates the most significant half of RFC 9562 UUIDv7: 48 bits of Unix milliseconds,
+// the version and 12 bits of rand_a. IDs are int64 all over the API, so the rest of the UUID is dropped.
+// rand_a is a counter seeded randomly every millisecond and incremented by random steps (method 2 of RFC 9562),
+// so IDs are ordered by time and following ones can't be guessed.
+type UUIDv7 struct {
+	mx      sync.Mutex
+	clock   clock.Clock
+	rand    io.Reader
+	last    int64
+	counter int64
+}
+
+const (
+	uuidVersion     = 7
+	uuidCounterBits = 12
+	uuidCounterMask = 1<<uuidCounterBits - 1
+	// seeds leave half of the counter for increments within a millisecond
+	uuidSeedMask = uuidCounterMask >> 1
+	uuidMaxStep  = 16
+)
+
+// NewUUIDv7 creates generator of random time-ordered IDs
+func NewUUIDv7(c clock.Clock) *UUIDv7 {
+	return &UUIDv7{clock: c, rand: rand.Reader, last: -1}
+}
+
+func (u *UUIDv7) Next() (int64, error) {
+	u.mx.Lock()
+	defer u.mx.Unlock()
+	r, err := u.random()
+	if err != nil {
+		return 0, fmt.Errorf("can't read random bits: %w", err)
+	}
+	ms := u.clock.Now().UnixMilli()
+	if ms > u.last {
+		u.last, u.counter = ms, r&uuidSeedMask
+	} else if u.counter += 1 + r%uuidMaxStep; u.counter > uuidCounterMask {
+		// the counter overflowed, borrow the next millisecond rather than wait for it
+		u.last, u.counter = u.last+1, r&uuidSeedMask
+	}
+	return u.last<<16 | uuidVersion<<uuidCounterBits | u.counter, nil
+}
+
+func (u *UUIDv7) random() (int64, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(u.rand, b[:]); err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint16(b[:])), nil
+}
+
+// Strategies accepted by New
+const (
+	StrategySequential = "sequential"
+	StrategyUUIDv7     = "uuidv7"
+	StrategySnowflake  = "snowflake"
+)
+
+// New creates generator of the strategy given, node is used by Snowflake only
+func New(strategy string, node int64, c clock.Clock) (Generator, error) {
+	switch strings.ToLower(strategy) {
+	case StrategySequential:
+		return NewSequential(0), nil
+	case StrategyUUIDv7:
+		return NewUUIDv7(c), nil
+	case StrategySnowflake:
+		return NewSnowflake(node, c)
+	}
+	return nil, fmt.Errorf("unknown ID strategy %q, expected %s, %s or %s", strategy, StrategySequential, StrategyUUIDv7, StrategySnowflake)
+}
@@ -0,0 +1,151 @@
+package ids
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"ads-server/internal/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequential(t *testing.T) {
+	g := NewSequential(5)
+	for want := int64(5); want < 8; want++ {
+		id, err := g.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, want, id)
+	}
+}
+
+// generate returns n IDs checking they grow
+func generate(t *testing.T, g Generator, n int) []int64 {
+	t.Helper()
+	res := make([]int64, n)
+	for i := range res {
+		id, err := g.Next()
+		assert.NoError(t, err)
+		assert.Positive(t, id)
+		if i > 0 {
+			assert.Greater(t, id, res[i-1], "IDs must grow")
+		}
+		res[i] = id
+	}
+	return res
+}
+
+func TestSnowflake(t *testing.T) {
+	c := clock.NewFake(SnowflakeEpoch.Add(time.Hour))
+	g, err := NewSnowflake(3, c)
+	assert.NoError(t, err)
+
+	// the sequence of a frozen millisecond overflows into the following ones
+	generated := generate(t, g, 3*(snowflakeSeqMask+1))
+	first := generated[0]
+	assert.Equal(t, time.Hour.Milliseconds(), first>>22)
+	assert.Equal(t, int64(3), first>>12&MaxSnowflakeNode)
+	assert.Equal(t, int64(0), first&snowflakeSeqMask)
+
+	c.Advance(-time.Minute)
+	last := generate(t, g, 2)
+	assert.Greater(t, last[0], generated[len(generated)-1], "IDs grow when the clock moves back")
+
+	c.Advance(time.Hour)
+	id, err := g.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, c.Now().Sub(SnowflakeEpoch).Milliseconds(), id>>22)
+
+	other, err := NewSnowflake(4, c)
+	assert.NoError(t, err)
+	otherID, err := other.Next()
+	assert.NoError(t, err)
+	assert.NotEqual(t, id, otherID, "nodes don't collide")
+
+	_, err = NewSnowflake(MaxSnowflakeNode+1, c)
+	assert.Error(t, err)
+}
+
+func TestSnowflakeBeforeEpoch(t *testing.T) {
+	g, err := NewSnowflake(0, clock.NewFake(SnowflakeEpoch.Add(-time.Second)))
+	assert.NoError(t, err)
+	_, err = g.Next()
+	assert.Error(t, err)
+}
+
+func TestUUIDv7(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	c := clock.NewFake(now)
+	g := NewUUIDv7(c)
+
+	generated := generate(t, g, 1000)
+	for _, id := range generated[:10] {
+		assert.Equal(t, int64(uuidVersion), id>>12&0xf)
+		assert.GreaterOrEqual(t, id>>16, now.UnixMilli())
+	}
+	assert.Equal(t, now.UnixMilli(), generated[0]>>16)
+
+	c.Advance(time.Second)
+	id, err := g.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, c.Now().UnixMilli(), id>>16)
+}
+
+func TestUUIDv7NotEnumerable(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	a, b := NewUUIDv7(c), NewUUIDv7(c)
+	a.rand = bytes.NewReader(bytes.Repeat([]byte{0, 1}, 10))
+	b.rand = bytes.NewReader(bytes.Repeat([]byte{0, 2}, 10))
+	ids := append(generate(t, a, 3), generate(t, b, 3)...)
+	assert.NotEqual(t, ids[:3], ids[3:], "the counter depends on random bits")
+	assert.Equal(t, int64(2), ids[1]-ids[0], "steps depend on random bits")
+
+	a.rand = bytes.NewReader(nil)
+	_, err := a.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestConcurrentUnique(t *testing.T) {
+	c := clock.NewFake(SnowflakeEpoch.Add(time.Hour))
+	snowflake, err := NewSnowflake(1, c)
+	assert.NoError(t, err)
+	for name, g := range map[string]Generator{
+		StrategySequential: NewSequential(0),
+		StrategyUUIDv7:     NewUUIDv7(c),
+		StrategySnowflake:  snowflake,
+	} {
+		g := g
+		t.Run(name, func(t *testing.T) {
+			var mx sync.Mutex
+			seen := make(map[int64]bool)
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 500; j++ {
+						id, err := g.Next()
+						assert.NoError(t, err)
+						mx.Lock()
+						assert.False(t, seen[id], "duplicate ID %d", id)
+						seen[id] = true
+						mx.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, s := range []string{StrategySequential, StrategyUUIDv7, "Snowflake"} {
+		_, err := New(s, 1, clock.System)
+		assert.NoError(t, err, s)
+	}
+	_, err := New("random", 0, clock.System)
+	assert.Error(t, err)
+	_, err = New(StrategySnowflake, -1, clock.System)
+	assert.Error(t, err)
+}
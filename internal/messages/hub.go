@@ -0,0 +1,62 @@
+package messages
+
+import "sync"
+
+// subscriberBuffer is how many messages a subscriber may lag behind before new ones are dropped for it
+const subscriberBuffer = 16
+
+type subscription struct {
+	ch chan *Message
+}
+
+// Hub delivers new messages to subscribed participants of conversations.
+// It is in-process, so apps serving different transports must share it.
+type Hub struct {
+	mx   sync.Mutex
+	subs map[int64]map[*subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[int64]map[*subscription]struct{})}
+}
+
+// Subscribe returns a channel receiving messages sent to or by the user and a function to unsubscribe.
+// Messages are dropped for a subscriber that doesn't keep up, it has to list them instead.
+func (h *Hub) Subscribe(uID int64) (<-chan *Message, func()) {
+	s := &subscription{ch: make(chan *Message, subscriberBuffer)}
+
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	if h.subs[uID] == nil {
+		h.subs[uID] = make(map[*subscription]struct{})
+	}
+	h.subs[uID][s] = struct{}{}
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			h.mx.Lock()
+			defer h.mx.Unlock()
+			delete(h.subs[uID], s)
+			if len(h.subs[uID]) == 0 {
+				delete(h.subs, uID)
+			}
+			close(s.ch)
+		})
+	}
+}
+
+// Publish delivers the message to every subscription of the users given
+func (h *Hub) Publish(m *Message, uIDs ...int64) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	for _, uID := range uIDs {
+		for s := range h.subs[uID] {
+			c := *m
+			select {
+			case s.ch <- &c:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubDelivery(t *testing.T) {
+	h := NewHub()
+	seller, cancelSeller := h.Subscribe(1)
+	defer cancelSeller()
+	other, cancelOther := h.Subscribe(3)
+	defer cancelOther()
+
+	h.Publish(&Message{ID: 10, SenderID: 2, Text: "hi"}, 1, 2)
+
+	m := <-seller
+	assert.Equal(t, int64(10), m.ID)
+	assert.Empty(t, other)
+}
+
+func TestHubDropsForSlowSubscriber(t *testing.T) {
+	h := NewHub()
+	ch, cancel := h.Subscribe(1)
+	for i := 0; i < subscriberBuffer+5; i++ {
+		h.Publish(&Message{ID: int64(i)}, 1)
+	}
+	assert.Len(t, ch, subscriberBuffer)
+
+	cancel()
+	cancel()
+	n := 0
+	for range ch {
+		n++
+	}
+	assert.Equal(t, subscriberBuffer, n, "channel is closed once unsubscribed")
+	h.Publish(&Message{ID: 100}, 1)
+}
+
+func TestConversationUnread(t *testing.T) {
+	c := &Conversation{SellerID: 1, BuyerID: 2}
+	assert.True(t, c.Participant(2))
+	assert.False(t, c.Participant(3))
+	assert.Equal(t, int64(1), c.Peer(2))
+
+	c.Received(1, c.CreatedAt)
+	c.Received(1, c.CreatedAt)
+	c.Received(2, c.CreatedAt)
+	assert.Equal(t, 2, c.Unread(1))
+	assert.Equal(t, 1, c.Unread(2))
+
+	c.Read(1)
+	assert.Equal(t, 0, c.Unread(1))
+	assert.Equal(t, 1, c.Unread(2))
+}
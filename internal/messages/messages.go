@@ -0,0 +1,82 @@
+// Package messages declares conversations between authors of ads and buyers
+// and a hub delivering new messages to connected participants
+package messages
+
+import "time"
+
+// Conversation is a thread about an ad between its author and one buyer
+type Conversation struct {
+	ID       int64
+	AdID     int64
+	SellerID int64
+	BuyerID  int64
+	// SellerUnread and BuyerUnread count messages the participant has not read yet
+	SellerUnread int
+	BuyerUnread  int
+	CreatedAt    time.Time
+	// LastMessageAt is zero until the first message is sent
+	LastMessageAt time.Time
+}
+
+// Participant reports whether the user takes part in the conversation
+func (c *Conversation) Participant(uID int64) bool {
+	return uID == c.SellerID || uID == c.BuyerID
+}
+
+// Peer returns the other participant of the conversation
+func (c *Conversation) Peer(uID int64) int64 {
+	if uID == c.SellerID {
+		return c.BuyerID
+	}
+	return c.SellerID
+}
+
+// Unread returns the number of messages the participant has not read yet
+func (c *Conversation) Unread(uID int64) int {
+	if uID == c.SellerID {
+		return c.SellerUnread
+	}
+	return c.BuyerUnread
+}
+
+// Received counts a new message for the recipient as unread
+func (c *Conversation) Received(recipientID int64, at time.Time) {
+	if recipientID == c.SellerID {
+		c.SellerUnread++
+	} else {
+		c.BuyerUnread++
+	}
+	c.LastMessageAt = at
+}
+
+// Read marks all messages read by the participant
+func (c *Conversation) Read(uID int64) {
+	if uID == c.SellerID {
+		c.SellerUnread = 0
+	} else {
+		c.BuyerUnread = 0
+	}
+}
+
+// Message is sent by a participant of a conversation
+type Message struct {
+	ID             int64
+	ConversationID int64
+	SenderID       int64
+	Text           string
+	CreatedAt      time.Time
+}
+
+// Block forbids messaging between the user and the one it blocked in both directions
+type Block struct {
+	UserID    int64
+	BlockedID int64
+	CreatedAt time.Time
+}
+
+// Page selects messages of a conversation, newest first
+type Page struct {
+	// Before is the ID of the message the page ends before, zero starts from the latest message
+	Before int64
+	Limit  int
+}
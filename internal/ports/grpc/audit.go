@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"ads-server/internal/audit"
+	proto "ads-server/proto"
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// auditEntryResponse converts audit entry to its protobuf representation
+func auditEntryResponse(e *audit.Entry) *proto.AuditEntry {
+	return &proto.AuditEntry{
+		Seq:        e.Seq,
+		At:         timestamppb.New(e.At),
+		ActorId:    e.ActorID,
+		Action:     string(e.Action),
+		TargetType: e.TargetType,
+		TargetId:   e.TargetID,
+		Before:     string(e.Before),
+		After:      string(e.After),
+		RequestId:  e.RequestID,
+		Transport:  e.Transport,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+}
+
+// timeBound converts optional timestamp, unset one is an open bound
+func timeBound(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func (a *AdService) ListAuditEntries(ctx context.Context, request *proto.ListAuditEntriesRequest) (*proto.ListAuditEntriesResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	entries, err := a.app.AuditLog(ctx, request.UserId, audit.Filter{
+		ActorID:    request.ActorId,
+		TargetType: request.TargetType,
+		TargetID:   request.TargetId,
+		From:       timeBound(request.From),
+		To:         timeBound(request.To),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	list := make([]*proto.AuditEntry, len(entries))
+	for i, e := range entries {
+		list[i] = auditEntryResponse(e)
+	}
+	return &proto.ListAuditEntriesResponse{List: list}, nil
+}
+
+func (a *AdService) VerifyAuditLog(ctx context.Context, request *proto.VerifyAuditLogRequest) (*proto.AuditVerification, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	v, err := a.app.VerifyAuditLog(ctx, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.AuditVerification{Entries: v.Entries, Head: v.Head, Valid: v.Valid, BrokenAt: v.BrokenAt}, nil
+}
@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+
+	"ads-server/internal/ports/presenter"
+	proto "ads-server/proto"
+)
+
+func (a *AdService) ListHeldAds(ctx context.Context, request *proto.ListHeldAdsRequest) (*proto.ListAdResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	held, err := a.app.HeldAds(ctx, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.ListAdResponse{List: presenter.AdsProto(held)}, nil
+}
+
+func (a *AdService) ListDuplicateClusters(ctx context.Context, request *proto.ListDuplicateClustersRequest) (*proto.ListDuplicateClustersResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	clusters, err := a.app.DuplicateClusters(ctx, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := make([]*proto.DuplicateCluster, len(clusters))
+	for i, c := range clusters {
+		res[i] = &proto.DuplicateCluster{Ads: presenter.AdsProto(c.Ads)}
+	}
+	return &proto.ListDuplicateClustersResponse{List: res}, nil
+}
@@ -0,0 +1,25 @@
+package grpc
+
+import (
+	"ads-server/internal/app"
+	"ads-server/internal/errs"
+	"ads-server/internal/ports/grpc/pkg/grpcerr"
+	"context"
+	"errors"
+)
+
+// toStatus converts err to gRPC status error with field violations and resource details
+func toStatus(err error) error {
+	return grpcerr.ToStatus(err)
+}
+
+// checkActor verifies the acting user exists, unknown users are reported as unauthenticated
+func checkActor(ctx context.Context, a app.IApp, id int64) error {
+	if _, err := a.FindUser(ctx, id); err != nil {
+		if errors.Is(err, errs.UserNotFoundError) {
+			return toStatus(errs.AuthenticationError.WithCause(err))
+		}
+		return toStatus(err)
+	}
+	return nil
+}
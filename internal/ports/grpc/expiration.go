@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"ads-server/internal/ports/presenter"
+	proto "ads-server/proto"
+	"context"
+	"time"
+)
+
+func (a *AdService) RenewAd(ctx context.Context, request *proto.RenewAdRequest) (*proto.AdResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	ad, err := a.app.RenewAd(ctx, request.AdId, request.UserId, request.ExpectedVersion)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return presenter.AdProto(ad), nil
+}
+
+func (a *AdService) ExtendAd(ctx context.Context, request *proto.ExtendAdRequest) (*proto.AdResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	by := time.Duration(request.Days) * 24 * time.Hour
+	ad, err := a.app.ExtendAd(ctx, request.AdId, request.UserId, by, request.ExpectedVersion)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return presenter.AdProto(ad), nil
+}
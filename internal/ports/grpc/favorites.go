@@ -0,0 +1,65 @@
+package grpc
+
+import (
+	"ads-server/internal/ports/presenter"
+	proto "ads-server/proto"
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (a *AdService) AddFavorite(ctx context.Context, request *proto.FavoriteRequest) (*proto.FavoriteResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	f, err := a.app.AddFavorite(ctx, request.UserId, request.AdId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.FavoriteResponse{
+		UserId:    f.UserID,
+		AdId:      f.AdID,
+		CreatedAt: timestamppb.New(f.CreatedAt),
+	}, nil
+}
+
+func (a *AdService) RemoveFavorite(ctx context.Context, request *proto.FavoriteRequest) (*proto.RemoveFavoriteResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := a.app.RemoveFavorite(ctx, request.UserId, request.AdId); err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.RemoveFavoriteResponse{Success: true}, nil
+}
+
+func (a *AdService) ListFavorites(ctx context.Context, request *proto.ListFavoritesRequest) (*proto.ListFavoritesResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	favorites, err := a.app.ListFavorites(ctx, request.Id, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	list := make([]*proto.FavoriteAd, len(favorites))
+	for i, f := range favorites {
+		list[i] = &proto.FavoriteAd{Ad: presenter.AdProto(f.Ad), SavedAt: timestamppb.New(f.SavedAt)}
+	}
+	return &proto.ListFavoritesResponse{List: list}, nil
+}
+
+func (a *AdService) CountFavorites(ctx context.Context, request *proto.CountFavoritesRequest) (*proto.CountFavoritesResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	count, err := a.app.FavoriteCount(ctx, request.AdId, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.CountFavoritesResponse{AdId: request.AdId, Count: int64(count)}, nil
+}
@@ -3,11 +3,16 @@ package grpc
 import (
 	"ads-server/internal/app"
 	"ads-server/internal/errs"
+	"ads-server/internal/ports/presenter"
+	"ads-server/internal/users"
 	proto "ads-server/proto"
 	"context"
-	"errors"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.20.2 --name IAdService
@@ -20,6 +25,46 @@ type IAdService interface {
 	GetUser(ctx context.Context, request *proto.GetUserRequest) (*proto.UserResponse, error)
 	UpdateUser(ctx context.Context, request *proto.UpdateUserRequest) (*proto.UserResponse, error)
 	DeleteAd(ctx context.Context, request *proto.DeleteAdRequest) (*proto.DeleteAdResponse, error)
+	ConfirmEmail(ctx context.Context, request *proto.ConfirmEmailRequest) (*proto.UserResponse, error)
+	ResendVerification(ctx context.Context, request *proto.ResendVerificationRequest) (*proto.ResendVerificationResponse, error)
+	ListAdRevisions(ctx context.Context, request *proto.ListAdRevisionsRequest) (*proto.ListAdRevisionsResponse, error)
+	GetAdRevision(ctx context.Context, request *proto.GetAdRevisionRequest) (*proto.AdRevision, error)
+	RollbackAd(ctx context.Context, request *proto.RollbackAdRequest) (*proto.AdResponse, error)
+	ApproveAd(ctx context.Context, request *proto.ApproveAdRequest) (*proto.AdApproval, error)
+	GetAdChanges(ctx context.Context, request *proto.GetAdChangesRequest) (*proto.AdChangesResponse, error)
+	ListTrash(ctx context.Context, request *proto.ListTrashRequest) (*proto.ListAdResponse, error)
+	RestoreAd(ctx context.Context, request *proto.RestoreAdRequest) (*proto.AdResponse, error)
+	RestoreUser(ctx context.Context, request *proto.RestoreUserRequest) (*proto.UserResponse, error)
+	ListAuditEntries(ctx context.Context, request *proto.ListAuditEntriesRequest) (*proto.ListAuditEntriesResponse, error)
+	VerifyAuditLog(ctx context.Context, request *proto.VerifyAuditLogRequest) (*proto.AuditVerification, error)
+	RenewAd(ctx context.Context, request *proto.RenewAdRequest) (*proto.AdResponse, error)
+	ExtendAd(ctx context.Context, request *proto.ExtendAdRequest) (*proto.AdResponse, error)
+	ListScheduledTransitions(ctx context.Context, request *proto.ListScheduledTransitionsRequest) (*proto.ListScheduledTransitionsResponse, error)
+	CancelScheduledTransition(ctx context.Context, request *proto.CancelScheduledTransitionRequest) (*proto.CancelScheduledTransitionResponse, error)
+	AddFavorite(ctx context.Context, request *proto.FavoriteRequest) (*proto.FavoriteResponse, error)
+	RemoveFavorite(ctx context.Context, request *proto.FavoriteRequest) (*proto.RemoveFavoriteResponse, error)
+	ListFavorites(ctx context.Context, request *proto.ListFavoritesRequest) (*proto.ListFavoritesResponse, error)
+	CountFavorites(ctx context.Context, request *proto.CountFavoritesRequest) (*proto.CountFavoritesResponse, error)
+	StartConversation(ctx context.Context, request *proto.StartConversationRequest) (*proto.Conversation, error)
+	ListConversations(ctx context.Context, request *proto.ListConversationsRequest) (*proto.ListConversationsResponse, error)
+	SendMessage(ctx context.Context, request *proto.SendMessageRequest) (*proto.ChatMessage, error)
+	ListMessages(ctx context.Context, request *proto.ListMessagesRequest) (*proto.ListMessagesResponse, error)
+	BlockUser(ctx context.Context, request *proto.BlockUserRequest) (*proto.UserBlock, error)
+	UnblockUser(ctx context.Context, request *proto.BlockUserRequest) (*proto.UnblockUserResponse, error)
+	ListBlockedUsers(ctx context.Context, request *proto.ListBlockedUsersRequest) (*proto.ListBlockedUsersResponse, error)
+	Chat(stream proto.AdService_ChatServer) error
+	ReviewSeller(ctx context.Context, request *proto.ReviewSellerRequest) (*proto.Review, error)
+	ListReviews(ctx context.Context, request *proto.ListReviewsRequest) (*proto.ListReviewsResponse, error)
+	ReplyToReview(ctx context.Context, request *proto.ReplyToReviewRequest) (*proto.Review, error)
+	HideReview(ctx context.Context, request *proto.HideReviewRequest) (*proto.Review, error)
+	ReportAd(ctx context.Context, request *proto.ReportAdRequest) (*proto.AdReport, error)
+	ListReportedAds(ctx context.Context, request *proto.ListReportedAdsRequest) (*proto.ListReportedAdsResponse, error)
+	ResolveReports(ctx context.Context, request *proto.ResolveReportsRequest) (*proto.ResolveReportsResponse, error)
+	AppealTakedown(ctx context.Context, request *proto.AppealTakedownRequest) (*proto.Appeal, error)
+	ListAppeals(ctx context.Context, request *proto.ListAppealsRequest) (*proto.ListAppealsResponse, error)
+	ResolveAppeal(ctx context.Context, request *proto.ResolveAppealRequest) (*proto.Appeal, error)
+	ListHeldAds(ctx context.Context, request *proto.ListHeldAdsRequest) (*proto.ListAdResponse, error)
+	ListDuplicateClusters(ctx context.Context, request *proto.ListDuplicateClustersRequest) (*proto.ListDuplicateClustersResponse, error)
 }
 type AdService struct {
 	app app.IApp
@@ -29,162 +74,165 @@ func NewAdService(a app.App) *AdService {
 	return &AdService{app: a}
 }
 
-func (a *AdService) CreateAd(ctx context.Context, request *proto.CreateAdRequest) (*proto.AdResponse, error) {
-	if _, err := a.app.FindUser(ctx, request.UserId); errors.Is(err, errs.UserNotFoundError) {
-		return nil, status.Error(codes.Unauthenticated, err.Error())
+// userResponse converts user to its protobuf representation
+func userResponse(user *users.User) *proto.UserResponse {
+	return &proto.UserResponse{
+		Id:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Verified:  user.Verified,
+		Version:   user.Version,
+		Role:      string(user.Role),
+		DeletedAt: optionalTimestamp(user.DeletedAt),
+		Reputation: &proto.Reputation{
+			Rating:  user.Reputation.Rating(),
+			Reviews: int64(user.Reputation.Reviews),
+		},
 	}
+}
 
-	ad, err := a.app.CreateAd(ctx, request.UserId, request.Title, request.Text)
-	if errors.Is(err, errs.ValidationError) {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+// optionalTimestamp converts time to protobuf timestamp, zero time stays unset
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
 	}
+	return timestamppb.New(t)
+}
 
-	if errors.Is(err, errs.AccessError) {
-		return nil, status.Error(codes.PermissionDenied, err.Error())
+// timeOf converts optional timestamp to time, unset timestamps become zero time
+func timeOf(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
 	}
-
-	return &proto.AdResponse{
-		Id:        ad.ID,
-		Title:     ad.Title,
-		Text:      ad.Text,
-		AuthorId:  ad.AuthorID,
-		Published: ad.Published,
-	}, nil
+	return ts.AsTime()
 }
 
-func (a *AdService) ChangeAdStatus(ctx context.Context, request *proto.ChangeAdStatusRequest) (*proto.AdResponse, error) {
+func (a *AdService) CreateAd(ctx context.Context, request *proto.CreateAdRequest) (*proto.AdResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
 
-	if _, err := a.app.FindUser(ctx, request.UserId); errors.Is(err, errs.UserNotFoundError) {
-		return nil, status.Error(codes.Unauthenticated, err.Error())
+	ad, merged, err := a.app.CreateAd(ctx, request.UserId, request.Title, request.Text, request.Category)
+	if err != nil {
+		return nil, toStatus(err)
 	}
+	if merged {
+		// metadata.Pairs lowercases the key, SetHeader fails only outside of a server stream
+		_ = grpc.SetHeader(ctx, metadata.Pairs(presenter.MergedIntoHeader, strconv.FormatInt(ad.ID, 10)))
+	}
+
+	return presenter.AdProto(ad), nil
+}
 
-	ad, err := a.app.PublishAd(ctx, request.AdId, request.UserId, request.Published)
+func (a *AdService) ChangeAdStatus(ctx context.Context, request *proto.ChangeAdStatusRequest) (*proto.AdResponse, error) {
 
-	if errors.Is(err, errs.AccessError) {
-		return nil, status.Error(codes.PermissionDenied, err.Error())
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
 	}
+
+	ad, err := a.app.ChangeAdStatus(ctx, request.AdId, request.UserId, request.Published,
+		timeOf(request.PublishAt), timeOf(request.UnpublishAt), request.ExpectedVersion)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, err.Error())
-	}
-	return &proto.AdResponse{
-		Id:        ad.ID,
-		Title:     ad.Title,
-		Text:      ad.Text,
-		AuthorId:  ad.AuthorID,
-		Published: ad.Published,
-	}, nil
+		return nil, toStatus(err)
+	}
+	return presenter.AdProto(ad), nil
 }
 
 func (a *AdService) UpdateAd(ctx context.Context, request *proto.UpdateAdRequest) (*proto.AdResponse, error) {
 
-	if _, err := a.app.FindUser(ctx, request.UserId); errors.Is(err, errs.UserNotFoundError) {
-		return nil, status.Error(codes.Unauthenticated, err.Error())
-	}
-
-	ad, err := a.app.UpdateAd(ctx, request.AdId, request.UserId, request.Title, request.Text)
-
-	if errors.Is(err, errs.ValidationError) {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
 	}
 
-	if errors.Is(err, errs.AccessError) {
-		return nil, status.Error(codes.PermissionDenied, err.Error())
+	ad, err := a.app.UpdateAd(ctx, request.AdId, request.UserId, request.Title, request.Text, request.ExpectedVersion)
+	if err != nil {
+		return nil, toStatus(err)
 	}
 
-	return &proto.AdResponse{
-		Id:        ad.ID,
-		Title:     ad.Title,
-		Text:      ad.Text,
-		AuthorId:  ad.AuthorID,
-		Published: ad.Published,
-	}, nil
+	return presenter.AdProto(ad), nil
 }
 
 func (a *AdService) ListAds(ctx context.Context, request *proto.ListAdRequest) (*proto.ListAdResponse, error) {
 	ads := a.app.GetAdByName(ctx, request.Title)
 	if len(ads) == 0 {
-		return nil, status.Error(codes.NotFound, errs.AdNotFoundError.Error())
-	}
-
-	list := make([]*proto.AdResponse, len(ads))
-	for i, ad := range ads {
-		list[i] = &proto.AdResponse{
-			Id:        ad.ID,
-			Title:     ad.Title,
-			Text:      ad.Text,
-			AuthorId:  ad.AuthorID,
-			Published: ad.Published,
-		}
+		return nil, toStatus(errs.AdNotFoundError)
 	}
 
 	return &proto.ListAdResponse{
-		List: list,
+		List: presenter.AdsProto(ads),
 	}, nil
 }
 
 func (a *AdService) CreateUser(ctx context.Context, request *proto.CreateUserRequest) (*proto.UserResponse, error) {
 	user, err := a.app.CreateUser(ctx, request.Name, request.Email)
 	if err != nil {
-		return nil, status.Error(codes.NotFound, err.Error())
+		return nil, toStatus(err)
 	}
 
-	return &proto.UserResponse{
-		Id:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+	return userResponse(user), nil
 }
 
 func (a *AdService) GetUser(ctx context.Context, request *proto.GetUserRequest) (*proto.UserResponse, error) {
 	if request.Id == nil {
-		return nil, errs.WrongProtoBufDataError
+		return nil, toStatus(errs.WrongProtoBufDataError.WithFields(
+			errs.FieldViolation{Field: "id", Description: "must be set"}))
 	}
 	user, err := a.app.FindUser(ctx, request.GetId())
 	if err != nil {
-		return nil, status.Error(codes.NotFound, err.Error())
+		return nil, toStatus(err)
 	}
 
-	return &proto.UserResponse{
-		Id:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+	return userResponse(user), nil
 }
 
 func (a *AdService) DeleteUser(ctx context.Context, request *proto.DeleteUserRequest) (*proto.DeleteUserResponse, error) {
 
-	err := a.app.DeleteUser(ctx, request.Id)
+	report, err := a.app.DeleteUser(ctx, request.Id, request.ExpectedVersion)
 	if err != nil {
-		return &proto.DeleteUserResponse{Success: false}, status.Error(codes.NotFound, err.Error())
+		return &proto.DeleteUserResponse{Success: false}, toStatus(err)
 	}
 
-	return &proto.DeleteUserResponse{Success: true}, nil
+	return &proto.DeleteUserResponse{
+		Success:         true,
+		Policy:          string(report.Policy),
+		DeletedAdIds:    report.DeletedAds,
+		AnonymizedAdIds: report.AnonymizedAds,
+	}, nil
 
 }
 
 func (a *AdService) UpdateUser(ctx context.Context, request *proto.UpdateUserRequest) (*proto.UserResponse, error) {
-	user, err := a.app.UpdateUser(ctx, request.Id, request.Name, request.Email)
+	user, err := a.app.UpdateUser(ctx, request.Id, request.Name, request.Email, request.ExpectedVersion)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
 
-	return &proto.UserResponse{
-		Id:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+	return userResponse(user), nil
 }
 
 func (a *AdService) DeleteAd(ctx context.Context, request *proto.DeleteAdRequest) (*proto.DeleteAdResponse, error) {
 
-	err := a.app.DeleteAd(ctx, request.AdId, request.AuthorId)
-	if errors.Is(err, errs.AdNotFoundError) {
-		return &proto.DeleteAdResponse{Success: false}, status.Error(codes.NotFound, err.Error())
+	err := a.app.DeleteAd(ctx, request.AdId, request.AuthorId, request.ExpectedVersion)
+	if err != nil {
+		return &proto.DeleteAdResponse{Success: false}, toStatus(err)
 	}
 
-	if errors.Is(err, errs.AccessError) {
-		return &proto.DeleteAdResponse{Success: false}, status.Error(codes.PermissionDenied, err.Error())
+	return &proto.DeleteAdResponse{Success: true}, nil
+}
+
+func (a *AdService) ConfirmEmail(ctx context.Context, request *proto.ConfirmEmailRequest) (*proto.UserResponse, error) {
+	user, err := a.app.ConfirmEmail(ctx, request.Token)
+	if err != nil {
+		return nil, toStatus(err)
 	}
 
-	return &proto.DeleteAdResponse{Success: true}, nil
+	return userResponse(user), nil
+}
+
+func (a *AdService) ResendVerification(ctx context.Context, request *proto.ResendVerificationRequest) (*proto.ResendVerificationResponse, error) {
+	if err := a.app.ResendVerification(ctx, request.UserId); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &proto.ResendVerificationResponse{}, nil
 }
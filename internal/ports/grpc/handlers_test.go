@@ -106,8 +106,8 @@ func TestAdService_ChangeAdStatus(t *testing.T) {
 				Return(nil, tt.userExist).
 				Maybe()
 			fakeApp.
-				On("PublishAd", tt.args.ctx, tt.args.request.AdId, tt.args.request.UserId,
-					tt.args.request.Published).
+				On("ChangeAdStatus", tt.args.ctx, tt.args.request.AdId, tt.args.request.UserId,
+					tt.args.request.Published, time.Time{}, time.Time{}, tt.args.request.ExpectedVersion).
 				Return(&ads.Ad{
 					ID:        0,
 					Title:     "example",
@@ -227,7 +227,7 @@ func TestAdService_CreateAd(t *testing.T) {
 				Maybe()
 			fakeApp.
 				On("CreateAd", tt.args.ctx, tt.args.request.UserId,
-					tt.args.request.Title, tt.args.request.Text).
+					tt.args.request.Title, tt.args.request.Text, tt.args.request.Category).
 				Return(&ads.Ad{
 					ID:        0,
 					Title:     tt.args.request.Title,
@@ -236,7 +236,7 @@ func TestAdService_CreateAd(t *testing.T) {
 					CDate:     time.Time{},
 					UDate:     time.Time{},
 					Published: false,
-				}, tt.adExist).
+				}, false, tt.adExist).
 				Maybe()
 			a := &AdService{
 				app: fakeApp,
@@ -276,9 +276,10 @@ func TestAdService_CreateUser(t *testing.T) {
 				},
 			},
 			want: &proto.UserResponse{
-				Id:    0,
-				Name:  "James",
-				Email: "Ostin",
+				Id:         0,
+				Name:       "James",
+				Email:      "Ostin",
+				Reputation: &proto.Reputation{},
 			},
 			wantErr:   false,
 			userExist: nil,
@@ -383,7 +384,7 @@ func TestAdService_DeleteAd(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			fakeApp := mocks.NewIApp(t)
 			fakeApp.
-				On("DeleteAd", tt.args.ctx, tt.args.request.AdId, tt.args.request.AuthorId).
+				On("DeleteAd", tt.args.ctx, tt.args.request.AdId, tt.args.request.AuthorId, tt.args.request.ExpectedVersion).
 				Return(tt.adExist).
 				Maybe()
 			a := &AdService{
@@ -410,6 +411,7 @@ func TestAdService_DeleteUser(t *testing.T) {
 		name      string
 		args      args
 		want      *proto.DeleteUserResponse
+		report    app.DeletionReport
 		userExist error
 		wantErr   bool
 	}{
@@ -419,7 +421,28 @@ func TestAdService_DeleteUser(t *testing.T) {
 				ctx:     context.Background(),
 				request: &proto.DeleteUserRequest{Id: 0},
 			},
-			want:      &proto.DeleteUserResponse{Success: true},
+			want: &proto.DeleteUserResponse{
+				Success:         true,
+				Policy:          "anonymize",
+				AnonymizedAdIds: []int64{3, 5},
+			},
+			report:    app.DeletionReport{UserID: 0, Policy: app.DeleteAnonymize, AnonymizedAds: []int64{3, 5}},
+			wantErr:   false,
+			userExist: nil,
+		},
+
+		{
+			name: "cascade",
+			args: args{
+				ctx:     context.Background(),
+				request: &proto.DeleteUserRequest{Id: 1},
+			},
+			want: &proto.DeleteUserResponse{
+				Success:      true,
+				Policy:       "cascade",
+				DeletedAdIds: []int64{7},
+			},
+			report:    app.DeletionReport{UserID: 1, Policy: app.DeleteCascade, DeletedAds: []int64{7}},
 			wantErr:   false,
 			userExist: nil,
 		},
@@ -434,13 +457,24 @@ func TestAdService_DeleteUser(t *testing.T) {
 			wantErr:   true,
 			userExist: errs.UserNotFoundError,
 		},
+
+		{
+			name: "blocked by ads",
+			args: args{
+				ctx:     context.Background(),
+				request: &proto.DeleteUserRequest{Id: 3},
+			},
+			want:      &proto.DeleteUserResponse{Success: false},
+			wantErr:   true,
+			userExist: errs.UserHasAdsError,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			fakeApp := mocks.NewIApp(t)
 			fakeApp.
-				On("DeleteUser", tt.args.ctx, tt.args.request.Id).
-				Return(tt.userExist).
+				On("DeleteUser", tt.args.ctx, tt.args.request.Id, tt.args.request.ExpectedVersion).
+				Return(tt.report, tt.userExist).
 				Maybe()
 			a := &AdService{
 				app: fakeApp,
@@ -476,7 +510,7 @@ func TestAdService_GetUser(t *testing.T) {
 				ctx:     context.Background(),
 				request: &proto.GetUserRequest{Id: &ident},
 			},
-			want:      &proto.UserResponse{Id: int64(0)},
+			want:      &proto.UserResponse{Id: int64(0), Reputation: &proto.Reputation{}},
 			wantErr:   false,
 			userExist: nil,
 		},
@@ -722,7 +756,7 @@ func TestAdService_UpdateAd(t *testing.T) {
 				Maybe()
 			fakeApp.
 				On("UpdateAd", tt.args.ctx, tt.args.request.AdId, tt.args.request.UserId,
-					tt.args.request.Title, tt.args.request.Text).
+					tt.args.request.Title, tt.args.request.Text, tt.args.request.ExpectedVersion).
 				Return(&ads.Ad{
 					ID:        0,
 					Title:     tt.args.request.Title,
@@ -771,9 +805,10 @@ func TestAdService_UpdateUser(t *testing.T) {
 				},
 			},
 			want: &proto.UserResponse{
-				Id:    0,
-				Name:  "N",
-				Email: "J",
+				Id:         0,
+				Name:       "N",
+				Email:      "J",
+				Reputation: &proto.Reputation{},
 			},
 			wantErr:   false,
 			userExist: nil,
@@ -799,7 +834,7 @@ func TestAdService_UpdateUser(t *testing.T) {
 			fakeApp := mocks.NewIApp(t)
 			fakeApp.
 				On("UpdateUser", tt.args.ctx, tt.args.request.Id,
-					tt.args.request.Name, tt.args.request.Email).
+					tt.args.request.Name, tt.args.request.Email, tt.args.request.ExpectedVersion).
 				Return(func() *users.User {
 					if tt.userExist != nil {
 						return nil
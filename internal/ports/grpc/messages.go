@@ -0,0 +1,212 @@
+package grpc
+
+import (
+	"ads-server/internal/errs"
+	"ads-server/internal/messages"
+	proto "ads-server/proto"
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// conversationResponse converts conversation to its protobuf representation with unread messages of the user
+func conversationResponse(c *messages.Conversation, uID int64) *proto.Conversation {
+	res := &proto.Conversation{
+		Id:        c.ID,
+		AdId:      c.AdID,
+		SellerId:  c.SellerID,
+		BuyerId:   c.BuyerID,
+		Unread:    int64(c.Unread(uID)),
+		CreatedAt: timestamppb.New(c.CreatedAt),
+	}
+	if !c.LastMessageAt.IsZero() {
+		res.LastMessageAt = timestamppb.New(c.LastMessageAt)
+	}
+	return res
+}
+
+// messageResponse converts message to its protobuf representation
+func messageResponse(m *messages.Message) *proto.ChatMessage {
+	return &proto.ChatMessage{
+		Id:             m.ID,
+		ConversationId: m.ConversationID,
+		SenderId:       m.SenderID,
+		Text:           m.Text,
+		CreatedAt:      timestamppb.New(m.CreatedAt),
+	}
+}
+
+// blockResponse converts block to its protobuf representation
+func blockResponse(b *messages.Block) *proto.UserBlock {
+	return &proto.UserBlock{
+		UserId:    b.UserID,
+		BlockedId: b.BlockedID,
+		CreatedAt: timestamppb.New(b.CreatedAt),
+	}
+}
+
+func (a *AdService) StartConversation(ctx context.Context, request *proto.StartConversationRequest) (*proto.Conversation, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	c, err := a.app.StartConversation(ctx, request.AdId, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return conversationResponse(c, request.UserId), nil
+}
+
+func (a *AdService) ListConversations(ctx context.Context, request *proto.ListConversationsRequest) (*proto.ListConversationsResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	list, err := a.app.ListConversations(ctx, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := make([]*proto.Conversation, len(list))
+	for i, c := range list {
+		res[i] = conversationResponse(c, request.UserId)
+	}
+	return &proto.ListConversationsResponse{List: res}, nil
+}
+
+func (a *AdService) SendMessage(ctx context.Context, request *proto.SendMessageRequest) (*proto.ChatMessage, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	m, err := a.app.SendMessage(ctx, request.ConversationId, request.UserId, request.Text)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return messageResponse(m), nil
+}
+
+func (a *AdService) ListMessages(ctx context.Context, request *proto.ListMessagesRequest) (*proto.ListMessagesResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	p := messages.Page{Before: request.Before, Limit: int(request.Limit)}
+	list, err := a.app.ListMessages(ctx, request.ConversationId, request.UserId, p)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := make([]*proto.ChatMessage, len(list))
+	for i, m := range list {
+		res[i] = messageResponse(m)
+	}
+	return &proto.ListMessagesResponse{List: res}, nil
+}
+
+func (a *AdService) BlockUser(ctx context.Context, request *proto.BlockUserRequest) (*proto.UserBlock, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	b, err := a.app.BlockUser(ctx, request.UserId, request.Id)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return blockResponse(b), nil
+}
+
+func (a *AdService) UnblockUser(ctx context.Context, request *proto.BlockUserRequest) (*proto.UnblockUserResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := a.app.UnblockUser(ctx, request.UserId, request.Id); err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.UnblockUserResponse{Success: true}, nil
+}
+
+func (a *AdService) ListBlockedUsers(ctx context.Context, request *proto.ListBlockedUsersRequest) (*proto.ListBlockedUsersResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	list, err := a.app.ListBlocked(ctx, request.Id, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := make([]*proto.UserBlock, len(list))
+	for i, b := range list {
+		res[i] = blockResponse(b)
+	}
+	return &proto.ListBlockedUsersResponse{List: res}, nil
+}
+
+// Chat sends messages of the requests and streams messages sent to or by the user until the client cancels the call.
+// Closing the sending side keeps the stream open to receive messages, a failed send ends the call with its status.
+func (a *AdService) Chat(stream proto.AdService_ChatServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	uID := first.UserId
+	if err = checkActor(ctx, a.app, uID); err != nil {
+		return err
+	}
+
+	// subscribe before sending the first message, so the sender receives it too
+	events, unsubscribe, err := a.app.SubscribeMessages(ctx, uID)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer unsubscribe()
+
+	failed := make(chan error, 1)
+	go func() {
+		req := first
+		for {
+			if req.UserId != uID {
+				failed <- toStatus(errs.ValidationError.WithFields(errs.FieldViolation{
+					Field:       "user_id",
+					Description: "must be the same in every request of the stream",
+				}))
+				return
+			}
+			if req.Text != "" {
+				if _, err := a.app.SendMessage(ctx, req.ConversationId, uID, req.Text); err != nil {
+					failed <- toStatus(err)
+					return
+				}
+			}
+			var err error
+			if req, err = stream.Recv(); err != nil {
+				if !errors.Is(err, io.EOF) {
+					failed <- err
+				}
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case err := <-failed:
+			return err
+		case m := <-events:
+			if err := stream.Send(messageResponse(m)); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package grpcerr converts domain errors to gRPC statuses, it is shared by handlers and interceptors
+package grpcerr
+
+import (
+	"ads-server/internal/errs"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// ToStatus converts err to gRPC status error, field violations and the resource
+// the error refers to are attached as BadRequest and ResourceInfo details
+func ToStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	e := errs.From(err)
+	st := status.New(e.Code.GRPCCode(), e.Message)
+
+	if len(e.Fields) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, f := range e.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Description,
+			})
+		}
+		if withDetails, dErr := st.WithDetails(br); dErr == nil {
+			st = withDetails
+		}
+	}
+	if e.Resource != nil {
+		ri := &errdetails.ResourceInfo{ResourceType: e.Resource.Type, ResourceName: e.Resource.ID}
+		if withDetails, dErr := st.WithDetails(ri); dErr == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
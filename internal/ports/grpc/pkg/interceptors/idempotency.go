@@ -0,0 +1,132 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+
+	"ads-server/internal/errs"
+	"ads-server/internal/idempotency"
+	"ads-server/internal/ports/grpc/pkg/grpcerr"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+var (
+	idempotencyKey = strings.ToLower(idempotency.Header)
+	replayedKey    = strings.ToLower(idempotency.ReplayedHeader)
+)
+
+// Idempotency replays the first successful response of the methods given to calls of the same client retrying them
+// with the same idempotency-key metadata and request, replayed responses carry idempotent-replayed header metadata.
+// Calls without the key, calls of other methods and a nil store are passed through.
+func Idempotency(s *idempotency.Store, methods ...string) grpc.UnaryServerInterceptor {
+	idempotent := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		idempotent[m] = true
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md := metadataCarrier(metadataFrom(ctx))
+		key := md.Get(idempotencyKey)
+		msg, ok := req.(proto.Message)
+		if s == nil || key == "" || !idempotent[info.FullMethod] || !ok {
+			return handler(ctx, req)
+		}
+		payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+		if err != nil {
+			return nil, grpcerr.ToStatus(errs.WrongProtoBufDataError)
+		}
+		scope := idempotency.Scope(info.FullMethod, md.Get(apiKeyKey), md.Get(userKey), clientIP(ctx))
+		replay, err := s.Begin(scope, key, payload)
+		if err != nil {
+			return nil, grpcerr.ToStatus(err)
+		}
+		if replay != nil {
+			resp, err := unmarshalReplay(replay)
+			if err != nil {
+				return nil, grpcerr.ToStatus(errs.Wrap(errs.Internal, "can't replay response", err))
+			}
+			header := metadata.Pairs(replayedKey, "true")
+			for k, v := range replay.Header {
+				header.Append(k, v)
+			}
+			// fails only outside of a real server stream
+			_ = grpc.SetHeader(ctx, header)
+			return resp, nil
+		}
+
+		rec := &headerRecorder{}
+		if stream := grpc.ServerTransportStreamFromContext(ctx); stream != nil {
+			rec.ServerTransportStream = stream
+			ctx = grpc.NewContextWithServerTransportStream(ctx, rec)
+		}
+
+		finished := false
+		// a panicking handler must not keep the key busy
+		defer func() {
+			if !finished {
+				s.Release(scope, key)
+			}
+		}()
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if m, ok := resp.(proto.Message); ok {
+			if body, mErr := proto.Marshal(m); mErr == nil {
+				s.Finish(scope, key, idempotency.Response{Header: rec.replayed(), Type: string(proto.MessageName(m)), Body: body})
+				finished = true
+			}
+		}
+		return resp, nil
+	}
+}
+
+// headerRecorder keeps a copy of the header metadata the handler sets, so it is replayed along with the response
+type headerRecorder struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (r *headerRecorder) SetHeader(md metadata.MD) error {
+	if err := r.ServerTransportStream.SetHeader(md); err != nil {
+		return err
+	}
+	r.header = metadata.Join(r.header, md)
+	return nil
+}
+
+func (r *headerRecorder) SendHeader(md metadata.MD) error {
+	if err := r.ServerTransportStream.SendHeader(md); err != nil {
+		return err
+	}
+	r.header = metadata.Join(r.header, md)
+	return nil
+}
+
+// replayed returns the first value of every header metadata key set
+func (r *headerRecorder) replayed() map[string]string {
+	header := make(map[string]string, len(r.header))
+	for k, v := range r.header {
+		if len(v) > 0 {
+			header[k] = v[0]
+		}
+	}
+	return header
+}
+
+// unmarshalReplay restores the message of a replayed response
+func unmarshalReplay(r *idempotency.Response) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(r.Type))
+	if err != nil {
+		return nil, err
+	}
+	m := mt.New().Interface()
+	if err := proto.Unmarshal(r.Body, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,56 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"ads-server/internal/errs"
+	"ads-server/internal/ports/grpc/pkg/grpcerr"
+	"ads-server/internal/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+var (
+	apiKeyKey = strings.ToLower(ratelimit.APIKeyHeader)
+	userKey   = strings.ToLower(ratelimit.UserHeader)
+)
+
+// RateLimit spends a token of the client for the method, calls over the quota fail with codes.ResourceExhausted.
+// Limited calls return RateLimit-* headers in lowercase response metadata, a nil limiter doesn't limit anything.
+func RateLimit(l *ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if l == nil {
+			return handler(ctx, req)
+		}
+		md := metadataCarrier(metadataFrom(ctx))
+		d := l.Allow(info.FullMethod, l.Key(md.Get(apiKeyKey), md.Get(userKey), clientIP(ctx)))
+		if h := d.Headers(); len(h) > 0 {
+			header := metadata.MD{}
+			for name, v := range h {
+				header.Set(name, v)
+			}
+			// fails only outside of a real server stream
+			_ = grpc.SetHeader(ctx, header)
+		}
+		if !d.Allowed {
+			return nil, grpcerr.ToStatus(errs.RateLimitError)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// clientIP returns the address of the peer without the port
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
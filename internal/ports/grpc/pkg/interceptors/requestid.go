@@ -0,0 +1,36 @@
+package interceptors
+
+import (
+	"ads-server/internal/audit"
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDKey is the metadata key of the request ID, gRPC metadata keys are lowercase
+var requestIDKey = strings.ToLower(audit.RequestIDHeader)
+
+// RequestID tags the call with the ID sent in the metadata or a generated one and returns it in the response header
+func RequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+
+	id := metadataCarrier(metadataFrom(ctx)).Get(requestIDKey)
+	if id == "" {
+		id = audit.NewRequestID()
+	}
+	// fails only outside of a real server stream, the ID is still used for auditing then
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+
+	ctx = audit.WithRequest(ctx, audit.Request{ID: id, Transport: audit.TransportGRPC})
+	return handler(ctx, req)
+}
+
+// metadataFrom returns incoming metadata of the call, empty if there is none
+func metadataFrom(ctx context.Context) metadata.MD {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		return md
+	}
+	return metadata.MD{}
+}
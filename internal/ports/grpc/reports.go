@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"ads-server/internal/ads"
+	"ads-server/internal/ports/presenter"
+	proto "ads-server/proto"
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// reportReasons maps protobuf report reasons to domain ones, unspecified reasons are missing
+var reportReasons = map[proto.ReportReason]ads.ReportReason{
+	proto.ReportReason_REPORT_REASON_SPAM:       ads.ReasonSpam,
+	proto.ReportReason_REPORT_REASON_FRAUD:      ads.ReasonFraud,
+	proto.ReportReason_REPORT_REASON_PROHIBITED: ads.ReasonProhibited,
+	proto.ReportReason_REPORT_REASON_OFFENSIVE:  ads.ReasonOffensive,
+	proto.ReportReason_REPORT_REASON_DUPLICATE:  ads.ReasonDuplicate,
+	proto.ReportReason_REPORT_REASON_OTHER:      ads.ReasonOther,
+}
+
+// reportReasonProto converts domain report reason to its protobuf representation
+func reportReasonProto(reason ads.ReportReason) proto.ReportReason {
+	for p, r := range reportReasons {
+		if r == reason {
+			return p
+		}
+	}
+	return proto.ReportReason_REPORT_REASON_UNSPECIFIED
+}
+
+// reportResponse converts report to its protobuf representation
+func reportResponse(r *ads.Report) *proto.AdReport {
+	return &proto.AdReport{
+		Id:         r.ID,
+		AdId:       r.AdID,
+		ReporterId: r.ReporterID,
+		Reason:     reportReasonProto(r.Reason),
+		Comment:    r.Comment,
+		CreatedAt:  timestamppb.New(r.CreatedAt),
+		Outcome:    string(r.Outcome),
+		ResolvedBy: r.ResolvedBy,
+		ResolvedAt: optionalTimestamp(r.ResolvedAt),
+	}
+}
+
+// reportsResponse converts reports to their protobuf representation keeping their order
+func reportsResponse(list []*ads.Report) []*proto.AdReport {
+	res := make([]*proto.AdReport, len(list))
+	for i, r := range list {
+		res[i] = reportResponse(r)
+	}
+	return res
+}
+
+// appealResponse converts appeal to its protobuf representation
+func appealResponse(ap *ads.Appeal) *proto.Appeal {
+	return &proto.Appeal{
+		Id:          ap.ID,
+		AdId:        ap.AdID,
+		AuthorId:    ap.AuthorID,
+		Comment:     ap.Comment,
+		Status:      string(ap.Status),
+		ModeratorId: ap.ModeratorID,
+		CreatedAt:   timestamppb.New(ap.CreatedAt),
+		ResolvedAt:  optionalTimestamp(ap.ResolvedAt),
+	}
+}
+
+func (a *AdService) ReportAd(ctx context.Context, request *proto.ReportAdRequest) (*proto.AdReport, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	r, err := a.app.ReportAd(ctx, request.AdId, request.UserId, reportReasons[request.Reason], request.Comment)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return reportResponse(r), nil
+}
+
+func (a *AdService) ListReportedAds(ctx context.Context, request *proto.ListReportedAdsRequest) (*proto.ListReportedAdsResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	inbox, err := a.app.ReportInbox(ctx, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := make([]*proto.ReportedAd, len(inbox))
+	for i, r := range inbox {
+		res[i] = &proto.ReportedAd{Ad: presenter.AdProto(r.Ad), Reports: reportsResponse(r.Reports)}
+	}
+	return &proto.ListReportedAdsResponse{List: res}, nil
+}
+
+func (a *AdService) ResolveReports(ctx context.Context, request *proto.ResolveReportsRequest) (*proto.ResolveReportsResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	resolved, err := a.app.ResolveReports(ctx, request.AdId, request.UserId, request.Takedown)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.ResolveReportsResponse{List: reportsResponse(resolved)}, nil
+}
+
+func (a *AdService) AppealTakedown(ctx context.Context, request *proto.AppealTakedownRequest) (*proto.Appeal, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	ap, err := a.app.AppealTakedown(ctx, request.AdId, request.UserId, request.Comment)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return appealResponse(ap), nil
+}
+
+func (a *AdService) ListAppeals(ctx context.Context, request *proto.ListAppealsRequest) (*proto.ListAppealsResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	list, err := a.app.ListAppeals(ctx, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := make([]*proto.Appeal, len(list))
+	for i, ap := range list {
+		res[i] = appealResponse(ap)
+	}
+	return &proto.ListAppealsResponse{List: res}, nil
+}
+
+func (a *AdService) ResolveAppeal(ctx context.Context, request *proto.ResolveAppealRequest) (*proto.Appeal, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	ap, err := a.app.ResolveAppeal(ctx, request.AppealId, request.UserId, request.Accept)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return appealResponse(ap), nil
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"ads-server/internal/users"
+	proto "ads-server/proto"
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// reviewResponse converts review to its protobuf representation
+func reviewResponse(r *users.Review) *proto.Review {
+	return &proto.Review{
+		Id:        r.ID,
+		SellerId:  r.SellerID,
+		AuthorId:  r.AuthorID,
+		AdId:      r.AdID,
+		Rating:    int32(r.Rating),
+		Text:      r.Text,
+		Reply:     r.Reply,
+		RepliedAt: optionalTimestamp(r.RepliedAt),
+		Hidden:    r.Hidden,
+		CreatedAt: timestamppb.New(r.CreatedAt),
+	}
+}
+
+func (a *AdService) ReviewSeller(ctx context.Context, request *proto.ReviewSellerRequest) (*proto.Review, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	r, err := a.app.ReviewSeller(ctx, request.AdId, request.UserId, int(request.Rating), request.Text)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return reviewResponse(r), nil
+}
+
+func (a *AdService) ListReviews(ctx context.Context, request *proto.ListReviewsRequest) (*proto.ListReviewsResponse, error) {
+	if request.UserId != nil {
+		if err := checkActor(ctx, a.app, *request.UserId); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := a.app.ListReviews(ctx, request.SellerId, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	res := make([]*proto.Review, len(list))
+	for i, r := range list {
+		res[i] = reviewResponse(r)
+	}
+	return &proto.ListReviewsResponse{List: res}, nil
+}
+
+func (a *AdService) ReplyToReview(ctx context.Context, request *proto.ReplyToReviewRequest) (*proto.Review, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	r, err := a.app.ReplyToReview(ctx, request.ReviewId, request.UserId, request.Text)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return reviewResponse(r), nil
+}
+
+func (a *AdService) HideReview(ctx context.Context, request *proto.HideReviewRequest) (*proto.Review, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	r, err := a.app.HideReview(ctx, request.ReviewId, request.UserId, request.Hidden)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return reviewResponse(r), nil
+}
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+	"ads-server/internal/ads"
+	"ads-server/internal/ports/presenter"
+	proto "ads-server/proto"
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fieldChanges converts field changes to their protobuf representation
+func fieldChanges(changes []ads.FieldChange) []*proto.FieldChange {
+	res := make([]*proto.FieldChange, len(changes))
+	for i, c := range changes {
+		res[i] = &proto.FieldChange{Field: c.Field, Old: c.Old, New: c.New}
+	}
+	return res
+}
+
+// revisionResponse converts revision to its protobuf representation
+func revisionResponse(r *ads.Revision) *proto.AdRevision {
+	return &proto.AdRevision{
+		AdId:         r.AdID,
+		Number:       r.Number,
+		Action:       string(r.Action),
+		EditorId:     r.EditorID,
+		CreatedAt:    timestamppb.New(r.CreatedAt),
+		RestoredFrom: r.RestoredFrom,
+		Title:        r.Title,
+		Text:         r.Text,
+		AuthorId:     r.AuthorID,
+		Published:    r.Published,
+		Changes:      fieldChanges(r.Changes),
+	}
+}
+
+// approvalResponse converts approval to its protobuf representation, nil stays nil
+func approvalResponse(ap *ads.Approval) *proto.AdApproval {
+	if ap == nil {
+		return nil
+	}
+	return &proto.AdApproval{
+		AdId:        ap.AdID,
+		Revision:    ap.Revision,
+		ModeratorId: ap.ModeratorID,
+		ApprovedAt:  timestamppb.New(ap.At),
+	}
+}
+
+func (a *AdService) ListAdRevisions(ctx context.Context, request *proto.ListAdRevisionsRequest) (*proto.ListAdRevisionsResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	history, err := a.app.ListRevisions(ctx, request.AdId, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	list := make([]*proto.AdRevision, len(history))
+	for i, r := range history {
+		list[i] = revisionResponse(r)
+	}
+	return &proto.ListAdRevisionsResponse{List: list}, nil
+}
+
+func (a *AdService) GetAdRevision(ctx context.Context, request *proto.GetAdRevisionRequest) (*proto.AdRevision, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	r, err := a.app.GetRevision(ctx, request.AdId, request.UserId, request.Number)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return revisionResponse(r), nil
+}
+
+func (a *AdService) RollbackAd(ctx context.Context, request *proto.RollbackAdRequest) (*proto.AdResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	ad, err := a.app.RollbackAd(ctx, request.AdId, request.UserId, request.Number, request.ExpectedVersion)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return presenter.AdProto(ad), nil
+}
+
+func (a *AdService) ApproveAd(ctx context.Context, request *proto.ApproveAdRequest) (*proto.AdApproval, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	ap, err := a.app.ApproveAd(ctx, request.AdId, request.UserId, request.ExpectedVersion)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return approvalResponse(ap), nil
+}
+
+func (a *AdService) GetAdChanges(ctx context.Context, request *proto.GetAdChangesRequest) (*proto.AdChangesResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	changes, err := a.app.AdChangesSinceApproval(ctx, request.AdId, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.AdChangesResponse{
+		AdId:     changes.AdID,
+		Approval: approvalResponse(changes.Approval),
+		Revision: changes.Revision,
+		Changes:  fieldChanges(changes.Changes),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"ads-server/internal/ads"
+	proto "ads-server/proto"
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// transitionResponse converts scheduled transition to its protobuf representation
+func transitionResponse(t *ads.Transition) *proto.ScheduledTransition {
+	return &proto.ScheduledTransition{
+		Id:        t.ID,
+		AdId:      t.AdID,
+		UserId:    t.UserID,
+		Published: t.Publish,
+		At:        timestamppb.New(t.At),
+		CreatedAt: timestamppb.New(t.CreatedAt),
+	}
+}
+
+func (a *AdService) ListScheduledTransitions(ctx context.Context, request *proto.ListScheduledTransitionsRequest) (*proto.ListScheduledTransitionsResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	transitions, err := a.app.ListTransitions(ctx, request.AdId, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	list := make([]*proto.ScheduledTransition, len(transitions))
+	for i, t := range transitions {
+		list[i] = transitionResponse(t)
+	}
+	return &proto.ListScheduledTransitionsResponse{List: list}, nil
+}
+
+func (a *AdService) CancelScheduledTransition(ctx context.Context, request *proto.CancelScheduledTransitionRequest) (*proto.CancelScheduledTransitionResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := a.app.CancelTransition(ctx, request.AdId, request.TransitionId, request.UserId); err != nil {
+		return nil, toStatus(err)
+	}
+	return &proto.CancelScheduledTransitionResponse{Success: true}, nil
+}
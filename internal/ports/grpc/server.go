@@ -8,31 +8,77 @@ import (
 	"fmt"
 	grpcrecovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"log"
 	"net"
+	"time"
+)
+
+const (
+	// readinessInterval is how often repository availability is re-checked for the health service
+	readinessInterval = 5 * time.Second
+	// shutdownDrainDelay is how long the server keeps serving after health turns NOT_SERVING
+	shutdownDrainDelay = 5 * time.Second
 )
 
 func NewGRPCServer(a app.AdRepository, u app.UserRepository) *grpc.Server {
+	server, _ := newGRPCServer(app.NewApp(a, u))
+	return server
+}
 
+// newGRPCServer builds gRPC server with registered AdService and standard health service
+func newGRPCServer(a app.App) (*grpc.Server, *health.Server) {
 	service := &AdService{
-		app: app.NewApp(a, u),
+		app: a,
 	}
 
 	recoveryOpt := []grpcrecovery.Option{
 		grpcrecovery.WithRecoveryHandler(interceptors.RecoveryFunc),
 	}
 
-	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors.Logger,
-		grpcrecovery.UnaryServerInterceptor(recoveryOpt...)))
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors.Tracing, interceptors.RequestID, interceptors.RateLimit(a.Limiter()),
+		interceptors.Idempotency(a.Idempotency(), proto.AdService_CreateAd_FullMethodName), interceptors.Logger,
+		grpcrecovery.UnaryServerInterceptor(recoveryOpt...)),
+		grpc.ChainStreamInterceptor(grpcrecovery.StreamServerInterceptor(recoveryOpt...)))
 	proto.RegisterAdServiceServer(server, service)
 
-	return server
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	updateReadiness(context.Background(), a, healthServer)
+
+	return server, healthServer
+}
+
+// updateReadiness sets serving status of the whole server and AdService according to repository availability
+func updateReadiness(ctx context.Context, a app.App, hs *health.Server) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if err := a.Ready(ctx); err != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	hs.SetServingStatus("", status)
+	hs.SetServingStatus(proto.AdService_ServiceDesc.ServiceName, status)
+}
+
+// watchReadiness periodically refreshes serving status until ctx is done
+func watchReadiness(ctx context.Context, a app.App, hs *health.Server) {
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			updateReadiness(ctx, a, hs)
+		}
+	}
 }
 
 // Run returns function to start gRPC server on a port given and implements graceful shutdown principle
-func Run(ctx context.Context, a app.AdRepository, u app.UserRepository, grpcPort string) func() error {
+func Run(ctx context.Context, a app.AdRepository, u app.UserRepository, grpcPort string, opts ...app.Option) func() error {
 	return func() error {
-		grpcServer := NewGRPCServer(a, u)
+		application := app.NewApp(a, u, opts...)
+		grpcServer, healthServer := newGRPCServer(application)
 
 		lis, err := net.Listen("tcp", grpcPort)
 		if err != nil {
@@ -48,6 +94,8 @@ func Run(ctx context.Context, a app.AdRepository, u app.UserRepository, grpcPort
 			close(errCh)
 		}()
 
+		go watchReadiness(ctx, application, healthServer)
+
 		go func() {
 			if err = grpcServer.Serve(lis); err != nil {
 				errCh <- err
@@ -56,6 +104,10 @@ func Run(ctx context.Context, a app.AdRepository, u app.UserRepository, grpcPort
 
 		select {
 		case <-ctx.Done():
+			// report NOT_SERVING first and give load balancers time to stop sending traffic,
+			// a server that failed to serve has no traffic to drain
+			healthServer.Shutdown()
+			time.Sleep(shutdownDrainDelay)
 			return ctx.Err()
 		case err = <-errCh:
 			return fmt.Errorf("grpc server can't listen and serve requests: %w", err)
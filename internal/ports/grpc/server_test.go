@@ -2,11 +2,23 @@ package grpc
 
 import (
 	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
 	"ads-server/internal/ports/grpc/pkg/interceptors"
+	proto "ads-server/proto"
+	"context"
 	grpcrecovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewGRPCServer(t *testing.T) {
@@ -25,3 +37,106 @@ func TestNewGRPCServer(t *testing.T) {
 	})
 
 }
+
+func TestHealthService(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv, healthServer := newGRPCServer(app.NewApp(repo.NewAd(), repo.NewUser()))
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := healthpb.NewHealthClient(conn)
+	for _, service := range []string{"", proto.AdService_ServiceDesc.ServiceName} {
+		res, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		assert.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+	}
+
+	// shutdown begins: load balancers must see the server as not serving
+	healthServer.Shutdown()
+	res, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, res.Status)
+}
+
+func TestErrorDetails(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv, _ := newGRPCServer(app.NewApp(repo.NewAd(), repo.NewUser()))
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := proto.NewAdServiceClient(conn)
+	user, err := client.CreateUser(ctx, &proto.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+
+	_, err = client.CreateAd(ctx, &proto.CreateAdRequest{UserId: user.Id, Title: "", Text: "world"})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		br, ok := st.Details()[0].(*errdetails.BadRequest)
+		assert.True(t, ok)
+		assert.Equal(t, "title", br.GetFieldViolations()[0].GetField())
+	}
+
+	_, err = client.ChangeAdStatus(ctx, &proto.ChangeAdStatusRequest{AdId: 9, UserId: user.Id, Published: false})
+	st = status.Convert(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		ri, ok := st.Details()[0].(*errdetails.ResourceInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "ad", ri.GetResourceType())
+		assert.Equal(t, "9", ri.GetResourceName())
+	}
+
+	_, err = client.CreateAd(ctx, &proto.CreateAdRequest{UserId: 100, Title: "hello", Text: "world"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"ads-server/internal/ports/presenter"
+	proto "ads-server/proto"
+	"context"
+)
+
+func (a *AdService) ListTrash(ctx context.Context, request *proto.ListTrashRequest) (*proto.ListAdResponse, error) {
+	if err := checkActor(ctx, a.app, request.ActorId); err != nil {
+		return nil, err
+	}
+
+	trash, err := a.app.ListTrash(ctx, request.UserId, request.ActorId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &proto.ListAdResponse{List: presenter.AdsProto(trash)}, nil
+}
+
+func (a *AdService) RestoreAd(ctx context.Context, request *proto.RestoreAdRequest) (*proto.AdResponse, error) {
+	if err := checkActor(ctx, a.app, request.UserId); err != nil {
+		return nil, err
+	}
+
+	ad, err := a.app.RestoreAd(ctx, request.AdId, request.UserId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return presenter.AdProto(ad), nil
+}
+
+func (a *AdService) RestoreUser(ctx context.Context, request *proto.RestoreUserRequest) (*proto.UserResponse, error) {
+	// a deleted user can't act, but may restore itself
+	if request.ActorId != request.Id {
+		if err := checkActor(ctx, a.app, request.ActorId); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := a.app.RestoreUser(ctx, request.Id, request.ActorId)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return userResponse(user), nil
+}
@@ -0,0 +1,116 @@
+package httpgin
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"ads-server/internal/app"
+	"ads-server/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+type auditEntryResponse struct {
+	Seq        int64           `json:"seq"`
+	At         time.Time       `json:"at"`
+	ActorID    int64           `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   int64           `json:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Transport  string          `json:"transport"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+type auditVerificationResponse struct {
+	Entries  int64  `json:"entries"`
+	Head     string `json:"head"`
+	Valid    bool   `json:"valid"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+}
+
+func AuditEntriesSuccessResponse(entries []*audit.Entry) *gin.H {
+	res := make([]auditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, auditEntryResponse{
+			Seq:        e.Seq,
+			At:         e.At,
+			ActorID:    e.ActorID,
+			Action:     string(e.Action),
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			Before:     e.Before,
+			After:      e.After,
+			RequestID:  e.RequestID,
+			Transport:  e.Transport,
+			PrevHash:   e.PrevHash,
+			Hash:       e.Hash,
+		})
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+func AuditVerificationSuccessResponse(v audit.Verification) *gin.H {
+	return &gin.H{
+		"data":  auditVerificationResponse{Entries: v.Entries, Head: v.Head, Valid: v.Valid, BrokenAt: v.BrokenAt},
+		"error": nil,
+	}
+}
+
+// listAuditEntries handles route for an admin to query the audit log
+func listAuditEntries(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			f  audit.Filter
+			ok bool
+		)
+		if f.ActorID, ok = optionalQueryID(c, "actor_id"); !ok {
+			return
+		}
+		if f.TargetID, ok = optionalQueryID(c, "target_id"); !ok {
+			return
+		}
+		if f.From, ok = queryTime(c, "from"); !ok {
+			return
+		}
+		if f.To, ok = queryTime(c, "to"); !ok {
+			return
+		}
+		f.TargetType = c.Query("target_type")
+
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		entries, err := a.AuditLog(c, uID, f)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AuditEntriesSuccessResponse(entries))
+	}
+}
+
+// verifyAuditLog handles route for an admin to check the hash chain of the audit log
+func verifyAuditLog(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		v, err := a.VerifyAuditLog(c, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AuditVerificationSuccessResponse(v))
+	}
+}
@@ -0,0 +1,58 @@
+package httpgin
+
+import (
+	"net/http"
+
+	"ads-server/internal/app"
+	"ads-server/internal/ports/presenter"
+	"github.com/gin-gonic/gin"
+)
+
+type duplicateClusterResponse struct {
+	Ads []presenter.Ad `json:"ads"`
+}
+
+func DuplicateClustersSuccessResponse(clusters []app.DuplicateCluster) *gin.H {
+	res := make([]duplicateClusterResponse, 0, len(clusters))
+	for _, c := range clusters {
+		res = append(res, duplicateClusterResponse{Ads: presenter.NewAds(c.Ads)})
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+// listHeldAds handles route to return ads held by content filters to a moderator
+func listHeldAds(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		held, err := a.HeldAds(c, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AdsSuccessResponse(held))
+	}
+}
+
+// listDuplicateClusters handles route to return groups of near-duplicate ads to a moderator
+func listDuplicateClusters(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		clusters, err := a.DuplicateClusters(c, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, DuplicateClustersSuccessResponse(clusters))
+	}
+}
@@ -0,0 +1,142 @@
+package httpgin
+
+import (
+	"ads-server/internal/app"
+	"ads-server/internal/errs"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// problemContentType is the media type of RFC 7807 problem details
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes problem type URIs, the suffix is the error code name
+const problemTypePrefix = "urn:ads-server:problem:"
+
+type fieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+type problemResource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// problem is RFC 7807 problem details body with code, field violations and resource extensions
+type problem struct {
+	Type     string           `json:"type"`
+	Title    string           `json:"title"`
+	Status   int              `json:"status"`
+	Detail   string           `json:"detail,omitempty"`
+	Instance string           `json:"instance,omitempty"`
+	Code     string           `json:"code"`
+	Errors   []fieldViolation `json:"errors,omitempty"`
+	Resource *problemResource `json:"resource,omitempty"`
+}
+
+// ProblemResponse builds problem details for the error given
+func ProblemResponse(err error, instance string) problem {
+	e := errs.From(err)
+	p := problem{
+		Type:     problemTypePrefix + e.Code.String(),
+		Title:    e.Code.Title(),
+		Status:   e.Code.HTTPStatus(),
+		Detail:   e.Error(),
+		Instance: instance,
+		Code:     e.Code.String(),
+	}
+	if e.Code == errs.Internal || e.Code == errs.Unknown {
+		// causes of unexpected failures are not exposed to clients
+		p.Detail = e.Message
+	}
+	for _, f := range e.Fields {
+		p.Errors = append(p.Errors, fieldViolation{Field: f.Field, Description: f.Description})
+	}
+	if e.Resource != nil {
+		p.Resource = &problemResource{Type: e.Resource.Type, ID: e.Resource.ID}
+	}
+	return p
+}
+
+// respondError aborts the request with problem details mapped from the error
+func respondError(c *gin.Context, err error) {
+	p := ProblemResponse(err, c.Request.URL.Path)
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// bindError wraps request body decoding failure
+func bindError(err error) error {
+	return errs.Wrap(errs.InvalidArgument, "malformed request body", err)
+}
+
+// pathID parses int64 route parameter, reporting a field violation on failure
+func pathID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil {
+		respondError(c, errs.ValidationError.WithFields(errs.FieldViolation{
+			Field:       name,
+			Description: "must be an integer",
+		}))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryID parses required int64 query parameter, reporting a field violation on failure
+func queryID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Query(name), 10, 64)
+	if err != nil {
+		respondError(c, errs.ValidationError.WithFields(errs.FieldViolation{
+			Field:       name,
+			Description: "must be an integer",
+		}))
+		return 0, false
+	}
+	return id, true
+}
+
+// optionalQueryID parses int64 query parameter if it is present, reporting a field violation on failure
+func optionalQueryID(c *gin.Context, name string) (*int64, bool) {
+	if c.Query(name) == "" {
+		return nil, true
+	}
+	id, ok := queryID(c, name)
+	if !ok {
+		return nil, false
+	}
+	return &id, true
+}
+
+// queryTime parses RFC 3339 query parameter if it is present, reporting a field violation on failure
+func queryTime(c *gin.Context, name string) (time.Time, bool) {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		respondError(c, errs.ValidationError.WithFields(errs.FieldViolation{
+			Field:       name,
+			Description: "must be an RFC 3339 time",
+		}))
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// actorExists checks that the user acting in the request is known
+func actorExists(c *gin.Context, a app.App, id int64) bool {
+	if _, err := a.FindUser(c, id); err != nil {
+		if errors.Is(err, errs.UserNotFoundError) {
+			err = errs.AuthenticationError.WithCause(err)
+		}
+		respondError(c, err)
+		return false
+	}
+	return true
+}
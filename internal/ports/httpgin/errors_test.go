@@ -0,0 +1,53 @@
+package httpgin
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doRequest(t *testing.T, h http.Handler, method, path, body string) (*httptest.ResponseRecorder, problem) {
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var p problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	return rec, p
+}
+
+func TestProblemDetails(t *testing.T) {
+	s, _ := newHTTPServer(":18080", app.NewApp(repo.NewAd(), repo.NewUser()))
+
+	t.Run("not found with resource", func(t *testing.T) {
+		rec, p := doRequest(t, s.Handler, http.MethodGet, "/api/v1/ads/42/info", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, problemContentType, rec.Header().Get("Content-Type"))
+		assert.Equal(t, "urn:ads-server:problem:not-found", p.Type)
+		assert.Equal(t, http.StatusNotFound, p.Status)
+		assert.Equal(t, "/api/v1/ads/42/info", p.Instance)
+		assert.Equal(t, &problemResource{Type: "ad", ID: "42"}, p.Resource)
+	})
+
+	t.Run("field violation", func(t *testing.T) {
+		rec, p := doRequest(t, s.Handler, http.MethodGet, "/api/v1/users/abc", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid-argument", p.Code)
+		assert.Equal(t, []fieldViolation{{Field: "id", Description: "must be an integer"}}, p.Errors)
+	})
+
+	t.Run("unknown acting user", func(t *testing.T) {
+		rec, p := doRequest(t, s.Handler, http.MethodPost, "/api/v1/ads",
+			`{"user_id": 5, "title": "hello", "text": "world"}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Equal(t, "unauthenticated", p.Code)
+	})
+}
@@ -0,0 +1,68 @@
+package httpgin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ads-server/internal/errs"
+	"github.com/gin-gonic/gin"
+)
+
+// etag formats resource version as a strong entity tag
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseETag extracts version from an entity tag, weak tags are accepted only if weak is set
+func parseETag(tag string, weak bool) (int64, bool) {
+	if weak {
+		tag = strings.TrimPrefix(tag, "W/")
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	return v, err == nil && v > 0
+}
+
+// setETag reports resource version in the ETag header
+func setETag(c *gin.Context, version int64) {
+	c.Header("ETag", etag(version))
+}
+
+// ifMatch returns version required by If-Match header, zero if the header is absent or "*".
+// Only a single strong entity tag is supported, otherwise a field violation is reported.
+func ifMatch(c *gin.Context) (int64, bool) {
+	h := strings.TrimSpace(c.GetHeader("If-Match"))
+	if h == "" || h == "*" {
+		return 0, true
+	}
+	v, ok := parseETag(h, false)
+	if !ok {
+		respondError(c, errs.ValidationError.WithFields(errs.FieldViolation{
+			Field:       "If-Match",
+			Description: `must be "*" or a single entity tag returned in ETag header`,
+		}))
+		return 0, false
+	}
+	return v, true
+}
+
+// notModified responds 304 if If-None-Match header lists the current version of the resource,
+// entity tags are compared weakly as RFC 9110 requires
+func notModified(c *gin.Context, version int64) bool {
+	h := c.GetHeader("If-None-Match")
+	if h == "" {
+		return false
+	}
+	for _, tag := range strings.Split(h, ",") {
+		tag = strings.TrimSpace(tag)
+		if v, ok := parseETag(tag, true); tag == "*" || ok && v == version {
+			setETag(c, version)
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package httpgin
+
+import (
+	"net/http"
+	"time"
+
+	"ads-server/internal/app"
+	"github.com/gin-gonic/gin"
+)
+
+type extendAdRequest struct {
+	UserID int64 `json:"user_id"`
+	Days   int   `json:"days"`
+}
+
+// renewAd handles route for the author to start a new lifetime of the ad, taking it out of archive
+func renewAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		ad, err := a.RenewAd(c, adID, reqBody.UserID, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, ad.Version)
+		c.JSON(http.StatusOK, AdSuccessResponse(ad))
+	}
+}
+
+// extendAd handles route for the author to postpone expiration of the ad by some days
+func extendAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody extendAdRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		by := time.Duration(reqBody.Days) * 24 * time.Hour
+		ad, err := a.ExtendAd(c, adID, reqBody.UserID, by, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, ad.Version)
+		c.JSON(http.StatusOK, AdSuccessResponse(ad))
+	}
+}
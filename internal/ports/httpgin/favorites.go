@@ -0,0 +1,139 @@
+package httpgin
+
+import (
+	"net/http"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/app"
+	"ads-server/internal/ports/presenter"
+	"github.com/gin-gonic/gin"
+)
+
+type favoriteResponse struct {
+	UserID    int64     `json:"user_id"`
+	AdID      int64     `json:"ad_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type favoriteAdResponse struct {
+	Ad      presenter.Ad `json:"ad"`
+	SavedAt time.Time    `json:"saved_at"`
+}
+
+type favoriteCountResponse struct {
+	AdID  int64 `json:"ad_id"`
+	Count int   `json:"count"`
+}
+
+func FavoriteSuccessResponse(f *ads.Favorite) *gin.H {
+	return &gin.H{
+		"data": favoriteResponse{
+			UserID:    f.UserID,
+			AdID:      f.AdID,
+			CreatedAt: f.CreatedAt,
+		},
+		"error": nil,
+	}
+}
+
+func FavoritesSuccessResponse(favorites []app.FavoriteAd) *gin.H {
+	res := make([]favoriteAdResponse, 0, len(favorites))
+	for _, f := range favorites {
+		res = append(res, favoriteAdResponse{Ad: presenter.NewAd(f.Ad), SavedAt: f.SavedAt})
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+// addFavorite handles route to save the ad to favorites of the user
+func addFavorite(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		f, err := a.AddFavorite(c, reqBody.UserID, adID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, FavoriteSuccessResponse(f))
+	}
+}
+
+// removeFavorite handles route to remove the ad from favorites of the user
+func removeFavorite(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		if err := a.RemoveFavorite(c, reqBody.UserID, adID); err != nil {
+			respondError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listFavorites handles route to return ads saved by the user to the user itself or an admin
+func listFavorites(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		actorID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, actorID) {
+			return
+		}
+
+		favorites, err := a.ListFavorites(c, uID, actorID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, FavoritesSuccessResponse(favorites))
+	}
+}
+
+// countFavorites handles route to return how many users saved the ad to its author or an admin
+func countFavorites(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		count, err := a.FavoriteCount(c, adID, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, &gin.H{
+			"data":  favoriteCountResponse{AdID: adID, Count: count},
+			"error": nil,
+		})
+	}
+}
@@ -2,8 +2,7 @@ package httpgin
 
 import (
 	"ads-server/internal/errs"
-	"errors"
-	"fmt"
+	"ads-server/internal/ports/presenter"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
@@ -15,19 +14,18 @@ import (
 func createUser(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var reqBody userRequest
-		if err := c.Bind(&reqBody); err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
 			return
 		}
 
 		user, err := a.CreateUser(c, reqBody.Name, reqBody.Email)
 		if err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, err)
+			respondError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, UserSuccessResponse(user))
+		setETag(c, user.Version)
+		c.JSON(http.StatusCreated, UserSuccessResponse(user))
 	}
 }
 
@@ -37,8 +35,7 @@ func getAdsByName(a app.App) gin.HandlerFunc {
 		title := c.Param("title")
 		ads := a.GetAdByName(c, title)
 		if len(ads) == 0 {
-			c.Status(http.StatusNotFound)
-			c.JSON(http.StatusNotFound, AdErrorResponse(fmt.Errorf("no ads with such name")))
+			respondError(c, errs.AdNotFoundError)
 			return
 		}
 		c.JSON(http.StatusOK, AdsSuccessResponse(ads))
@@ -48,26 +45,20 @@ func getAdsByName(a app.App) gin.HandlerFunc {
 // getAdByID handles route to get ad by ID given
 func getAdByID(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-
-		id, err := strconv.Atoi(c.Param("ad_id"))
-		if err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		id, ok := pathID(c, "ad_id")
+		if !ok {
 			return
 		}
-		ad, err := a.GetAdByID(c, int64(id))
 
+		ad, err := a.GetAdByID(c, id)
 		if err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, err)
+			respondError(c, err)
 			return
 		}
-
-		if errors.Is(err, errs.AdNotFoundError) {
-			c.Status(http.StatusNotFound)
-			c.JSON(http.StatusNotFound, err)
+		if notModified(c, ad.Version) {
 			return
 		}
+		setETag(c, ad.Version)
 		c.JSON(http.StatusOK, AdSuccessResponse(ad))
 	}
 }
@@ -76,30 +67,27 @@ func getAdByID(a app.App) gin.HandlerFunc {
 func createAd(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var reqBody createAdRequest
-		err := c.Bind(&reqBody)
-		if err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
-			return
-		}
-		if _, err = a.FindUser(c, reqBody.UserID); errors.Is(err, errs.UserNotFoundError) {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
 			return
 		}
-		ad, err := a.CreateAd(c, reqBody.UserID, reqBody.Title, reqBody.Text)
-		if errors.Is(err, errs.ValidationError) {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		if !actorExists(c, a, reqBody.UserID) {
 			return
 		}
 
-		if errors.Is(err, errs.AccessError) {
-			c.Status(http.StatusForbidden)
-			c.JSON(http.StatusForbidden, AdErrorResponse(err))
+		ad, merged, err := a.CreateAd(c, reqBody.UserID, reqBody.Title, reqBody.Text, reqBody.Category)
+		if err != nil {
+			respondError(c, err)
 			return
 		}
-		c.JSON(http.StatusOK, AdSuccessResponse(ad))
+		status := http.StatusCreated
+		if merged {
+			// nothing was created, the existing ad was updated
+			status = http.StatusOK
+			c.Header(presenter.MergedIntoHeader, strconv.FormatInt(ad.ID, 10))
+		}
+		setETag(c, ad.Version)
+		c.JSON(status, AdSuccessResponse(ad))
 	}
 }
 
@@ -107,37 +95,29 @@ func createAd(a app.App) gin.HandlerFunc {
 func changeAdStatus(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var reqBody changeAdStatusRequest
-		if err := c.Bind(&reqBody); err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
 			return
 		}
 
-		adID, err := strconv.Atoi(c.Param("ad_id"))
-		if err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
 			return
 		}
-		//if adID == 0 {
-		//	c.Status(http.StatusBadRequest)
-		//	c.JSON(http.StatusBadRequest, AdErrorResponse(fmt.Errorf("empty field ad_id")))
-		//}
-
-		if _, err := a.FindUser(c, reqBody.UserID); errors.Is(err, errs.UserNotFoundError) {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		version, ok := ifMatch(c)
+		if !ok {
 			return
 		}
-
-		ad, err := a.PublishAd(c, int64(adID), reqBody.UserID, reqBody.Published)
-
-		if errors.Is(err, errs.AccessError) {
-			c.Status(http.StatusForbidden)
-			c.JSON(http.StatusForbidden, AdErrorResponse(err))
+		if !actorExists(c, a, reqBody.UserID) {
 			return
 		}
 
+		ad, err := a.ChangeAdStatus(c, adID, reqBody.UserID, reqBody.Published, reqBody.PublishAt, reqBody.UnpublishAt, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, ad.Version)
 		c.JSON(http.StatusOK, AdSuccessResponse(ad))
 	}
 }
@@ -146,33 +126,29 @@ func changeAdStatus(a app.App) gin.HandlerFunc {
 func updateAd(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var reqBody updateAdRequest
-		if err := c.Bind(&reqBody); err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
 			return
 		}
 
-		adID := c.GetInt64("ad_id")
-		if _, err := a.FindUser(c, reqBody.UserID); errors.Is(err, errs.UserNotFoundError) {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
 			return
 		}
-
-		ad, err := a.UpdateAd(c, adID, reqBody.UserID, reqBody.Title, reqBody.Text)
-
-		if errors.Is(err, errs.ValidationError) {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		version, ok := ifMatch(c)
+		if !ok {
 			return
 		}
-
-		if errors.Is(err, errs.AccessError) {
-			c.Status(http.StatusForbidden)
-			c.JSON(http.StatusForbidden, AdErrorResponse(err))
+		if !actorExists(c, a, reqBody.UserID) {
 			return
 		}
 
+		ad, err := a.UpdateAd(c, adID, reqBody.UserID, reqBody.Title, reqBody.Text, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, ad.Version)
 		c.JSON(http.StatusOK, AdSuccessResponse(ad))
 	}
 
@@ -182,12 +158,288 @@ func updateAd(a app.App) gin.HandlerFunc {
 func filterAds(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		params := c.Request.URL.Query()
-		if allAds, err := a.Filter(c, params); err != nil {
-			c.Status(http.StatusBadRequest)
-			c.JSON(http.StatusBadRequest, AdErrorResponse(err))
+		allAds, err := a.Filter(c, params)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AdsSuccessResponse(allAds))
+	}
+}
+
+// deleteAd handles route to delete ad of the user
+func deleteAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody deleteAdRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		if err := a.DeleteAd(c, adID, reqBody.UserID, version); err != nil {
+			respondError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// getUser handles route to get user by ID given
+func getUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
 			return
-		} else {
-			c.JSON(http.StatusOK, AdsSuccessResponse(allAds))
 		}
+
+		user, err := a.FindUser(c, id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if notModified(c, user.Version) {
+			return
+		}
+		setETag(c, user.Version)
+		c.JSON(http.StatusOK, UserSuccessResponse(user))
+	}
+}
+
+// updateUser handles route to replace name and email of the user
+func updateUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+
+		var reqBody userRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+
+		user, err := a.UpdateUser(c, id, reqBody.Name, reqBody.Email, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, user.Version)
+		c.JSON(http.StatusOK, UserSuccessResponse(user))
+	}
+}
+
+// patchUser handles route to change only the user fields given
+func patchUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+
+		var reqBody patchUserRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+
+		user, err := a.FindUser(c, id)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		// fields not given are taken from the version read, so a concurrent change of them isn't lost
+		if version == 0 {
+			version = user.Version
+		}
+		name, email := user.Name, user.Email
+		if reqBody.Name != nil {
+			name = *reqBody.Name
+		}
+		if reqBody.Email != nil {
+			email = *reqBody.Email
+		}
+
+		user, err = a.UpdateUser(c, id, name, email, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, user.Version)
+		c.JSON(http.StatusOK, UserSuccessResponse(user))
+	}
+}
+
+// deleteUser handles route to delete user by ID given, the response reports what happened to its ads
+func deleteUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+
+		report, err := a.DeleteUser(c, id, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, DeletionSuccessResponse(report))
+	}
+}
+
+// listUserAds handles route to return ads of the user, unpublished ones only to the user itself and moderators
+func listUserAds(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		actorID, ok := optionalQueryID(c, "user_id")
+		if !ok || actorID != nil && !actorExists(c, a, *actorID) {
+			return
+		}
+
+		userAds, err := a.ListUserAds(c, id, actorID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AdsSuccessResponse(userAds))
+	}
+}
+
+// confirmEmail handles route to verify email of the user with the token sent to it
+func confirmEmail(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody confirmEmailRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		user, err := a.ConfirmEmail(c, reqBody.Token)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, user.Version)
+		c.JSON(http.StatusOK, UserSuccessResponse(user))
+	}
+}
+
+// resendVerification handles route to send a new verification email to the user
+func resendVerification(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+
+		if err := a.ResendVerification(c, id); err != nil {
+			respondError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listTrash handles route to return deleted ads of the user to the user itself or an admin
+func listTrash(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		actorID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, actorID) {
+			return
+		}
+
+		trash, err := a.ListTrash(c, id, actorID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AdsSuccessResponse(trash))
+	}
+}
+
+// restoreAd handles route to take the ad out of trash
+func restoreAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		ad, err := a.RestoreAd(c, adID, reqBody.UserID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, ad.Version)
+		c.JSON(http.StatusOK, AdSuccessResponse(ad))
+	}
+}
+
+// restoreUser handles route to take the user out of trash, a deleted user may restore itself
+func restoreUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+		if reqBody.UserID != id && !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		user, err := a.RestoreUser(c, id, reqBody.UserID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, user.Version)
+		c.JSON(http.StatusOK, UserSuccessResponse(user))
 	}
 }
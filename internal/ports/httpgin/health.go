@@ -0,0 +1,39 @@
+package httpgin
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+
+	"ads-server/internal/app"
+)
+
+// probes serves liveness and readiness checks for orchestrators and load balancers
+type probes struct {
+	app      app.App
+	draining atomic.Bool
+}
+
+// drain makes readiness fail so load balancers stop routing new requests to the server
+func (p *probes) drain() {
+	p.draining.Store(true)
+}
+
+// healthz handles liveness probe: the process is up and able to serve HTTP
+func (p *probes) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyz handles readiness probe: repositories are available and shutdown has not begun
+func (p *probes) readyz(c *gin.Context) {
+	if p.draining.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "draining"})
+		return
+	}
+	if err := p.app.Ready(c); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
@@ -0,0 +1,61 @@
+package httpgin
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unavailableAdRepo is an ad repository whose storage can't be reached
+type unavailableAdRepo struct {
+	app.AdRepository
+}
+
+func (unavailableAdRepo) Ping(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func probe(t *testing.T, h http.Handler, path string) int {
+	req, err := http.NewRequest(http.MethodGet, path, nil)
+	assert.NoError(t, err)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestProbes(t *testing.T) {
+	s, p := newHTTPServer(":18080", app.NewApp(repo.NewAd(), repo.NewUser()))
+
+	assert.Equal(t, http.StatusOK, probe(t, s.Handler, "/healthz"))
+	assert.Equal(t, http.StatusOK, probe(t, s.Handler, "/readyz"))
+
+	p.drain()
+	assert.Equal(t, http.StatusOK, probe(t, s.Handler, "/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(t, s.Handler, "/readyz"))
+}
+
+func TestReadinessReflectsRepositories(t *testing.T) {
+	s := NewHTTPServer(":18080", app.NewApp(unavailableAdRepo{repo.NewAd()}, repo.NewUser()))
+
+	assert.Equal(t, http.StatusOK, probe(t, s.Handler, "/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, probe(t, s.Handler, "/readyz"))
+}
+
+func TestRunFailingToListen(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer busy.Close()
+
+	start := time.Now()
+	err = Run(context.Background(), repo.NewAd(), repo.NewUser(), busy.Addr().String())()
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), shutdownDrainDelay, "nothing to drain when the server didn't start")
+}
@@ -0,0 +1,108 @@
+package httpgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"ads-server/internal/idempotency"
+	"ads-server/internal/ports/presenter"
+	"ads-server/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// replayedHeaders are headers of a response replayed along with its body
+var replayedHeaders = []string{"Content-Type", "ETag", presenter.MergedIntoHeader}
+
+// bodyRecorder keeps a copy of the response body
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// canonicalJSON makes JSON bodies differing in spacing and order of keys the same, other bodies are kept as is
+func canonicalJSON(body []byte) []byte {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return body
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return canonical
+}
+
+// idempotencyMW replays the first successful response of the route to requests of the same client retrying it
+// with the same Idempotency-Key header and body, replayed responses carry the Idempotent-Replayed header.
+// Requests without the header and a nil store are passed through.
+func idempotencyMW(s *idempotency.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotency.Header)
+		if s == nil || key == "" {
+			c.Next()
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotency.Scope(c.Request.Method+" "+c.FullPath(),
+			c.GetHeader(ratelimit.APIKeyHeader), c.GetHeader(ratelimit.UserHeader), c.ClientIP())
+		replay, err := s.Begin(scope, key, canonicalJSON(body))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		if replay != nil {
+			for name, v := range replay.Header {
+				c.Header(name, v)
+			}
+			c.Header(idempotency.ReplayedHeader, "true")
+			c.Data(replay.Status, replay.Header["Content-Type"], replay.Body)
+			c.Abort()
+			return
+		}
+
+		finished := false
+		// a panicking handler must not keep the key busy
+		defer func() {
+			if !finished {
+				s.Release(scope, key)
+			}
+		}()
+		w := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.Status() >= http.StatusBadRequest {
+			return
+		}
+		header := make(map[string]string)
+		for _, name := range replayedHeaders {
+			if v := w.Header().Get(name); v != "" {
+				header[name] = v
+			}
+		}
+		s.Finish(scope, key, idempotency.Response{Status: w.Status(), Header: header, Body: w.body.Bytes()})
+		finished = true
+	}
+}
@@ -0,0 +1,283 @@
+package httpgin
+
+import (
+	"net/http"
+	"time"
+
+	"ads-server/internal/app"
+	"ads-server/internal/messages"
+	"github.com/gin-gonic/gin"
+)
+
+type sendMessageRequest struct {
+	UserID int64  `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+type conversationResponse struct {
+	ID       int64 `json:"id"`
+	AdID     int64 `json:"ad_id"`
+	SellerID int64 `json:"seller_id"`
+	BuyerID  int64 `json:"buyer_id"`
+	// Unread counts messages the acting user has not read yet
+	Unread        int        `json:"unread"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+}
+
+type messageResponse struct {
+	ID             int64     `json:"id"`
+	ConversationID int64     `json:"conversation_id"`
+	SenderID       int64     `json:"sender_id"`
+	Text           string    `json:"text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type blockResponse struct {
+	UserID    int64     `json:"user_id"`
+	BlockedID int64     `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newConversationResponse(c *messages.Conversation, uID int64) conversationResponse {
+	res := conversationResponse{
+		ID:        c.ID,
+		AdID:      c.AdID,
+		SellerID:  c.SellerID,
+		BuyerID:   c.BuyerID,
+		Unread:    c.Unread(uID),
+		CreatedAt: c.CreatedAt,
+	}
+	if !c.LastMessageAt.IsZero() {
+		res.LastMessageAt = &c.LastMessageAt
+	}
+	return res
+}
+
+func newMessageResponse(m *messages.Message) messageResponse {
+	return messageResponse{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Text:           m.Text,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+func ConversationSuccessResponse(c *messages.Conversation, uID int64) *gin.H {
+	return &gin.H{
+		"data":  newConversationResponse(c, uID),
+		"error": nil,
+	}
+}
+
+func ConversationsSuccessResponse(list []*messages.Conversation, uID int64) *gin.H {
+	res := make([]conversationResponse, 0, len(list))
+	for _, c := range list {
+		res = append(res, newConversationResponse(c, uID))
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+func MessageSuccessResponse(m *messages.Message) *gin.H {
+	return &gin.H{
+		"data":  newMessageResponse(m),
+		"error": nil,
+	}
+}
+
+func MessagesSuccessResponse(list []*messages.Message) *gin.H {
+	res := make([]messageResponse, 0, len(list))
+	for _, m := range list {
+		res = append(res, newMessageResponse(m))
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+func BlocksSuccessResponse(list []*messages.Block) *gin.H {
+	res := make([]blockResponse, 0, len(list))
+	for _, b := range list {
+		res = append(res, blockResponse{UserID: b.UserID, BlockedID: b.BlockedID, CreatedAt: b.CreatedAt})
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+// startConversation handles route to open a conversation of the acting buyer with the author of the ad
+func startConversation(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		conversation, err := a.StartConversation(c, adID, reqBody.UserID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ConversationSuccessResponse(conversation, reqBody.UserID))
+	}
+}
+
+// listConversations handles route to return conversations of the acting user
+func listConversations(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		list, err := a.ListConversations(c, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ConversationsSuccessResponse(list, uID))
+	}
+}
+
+// sendMessage handles route to send a message to the other participant of the conversation
+func sendMessage(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody sendMessageRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		conversationID, ok := pathID(c, "conversation_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		m, err := a.SendMessage(c, conversationID, reqBody.UserID, reqBody.Text)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, MessageSuccessResponse(m))
+	}
+}
+
+// listMessages handles route to return a page of messages of the conversation to a participant, newest first
+func listMessages(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conversationID, ok := pathID(c, "conversation_id")
+		if !ok {
+			return
+		}
+		uID, ok := queryID(c, "user_id")
+		if !ok {
+			return
+		}
+		var p messages.Page
+		before, ok := optionalQueryID(c, "before")
+		if !ok {
+			return
+		}
+		if before != nil {
+			p.Before = *before
+		}
+		limit, ok := optionalQueryID(c, "limit")
+		if !ok {
+			return
+		}
+		if limit != nil {
+			p.Limit = int(*limit)
+		}
+		if !actorExists(c, a, uID) {
+			return
+		}
+
+		list, err := a.ListMessages(c, conversationID, uID, p)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, MessagesSuccessResponse(list))
+	}
+}
+
+// blockUser handles route to forbid messaging between the acting user and the user given
+func blockUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		blockedID, ok := pathID(c, "id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		b, err := a.BlockUser(c, reqBody.UserID, blockedID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, &gin.H{
+			"data":  blockResponse{UserID: b.UserID, BlockedID: b.BlockedID, CreatedAt: b.CreatedAt},
+			"error": nil,
+		})
+	}
+}
+
+// unblockUser handles route to allow messaging with the user blocked before
+func unblockUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		blockedID, ok := pathID(c, "id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		if err := a.UnblockUser(c, reqBody.UserID, blockedID); err != nil {
+			respondError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listBlocked handles route to return users blocked by the user to the user itself or an admin
+func listBlocked(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		actorID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, actorID) {
+			return
+		}
+
+		list, err := a.ListBlocked(c, uID, actorID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, BlocksSuccessResponse(list))
+	}
+}
@@ -0,0 +1,28 @@
+package httpgin
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the OpenAPI 3 document of the HTTP API, it has to be updated together with routes
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// docsPage browses openAPISpec without external assets, so it works offline
+//
+//go:embed docs.html
+var docsPage []byte
+
+// getOpenAPI handles route to return the OpenAPI document of the API
+func getOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+}
+
+// getDocs handles route to return interactive documentation of the API
+func getDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,189 @@
+package httpgin
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/ports/presenter"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func loadOpenAPI(t *testing.T) openAPIDocument {
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json is malformed: %v", err)
+	}
+	return doc
+}
+
+var routeParam = regexp.MustCompile(`:([a-z_]+)`)
+
+// registeredRoutes lists routes of the server as OpenAPI operations, e.g. "get /api/v1/ads/{ad_id}/info"
+func registeredRoutes(t *testing.T) []string {
+	s := NewHTTPServer(":18080", app.NewApp(repo.NewAd(), repo.NewUser()))
+	engine := s.Handler.(*gin.Engine)
+	var routes []string
+	for _, r := range engine.Routes() {
+		routes = append(routes, strings.ToLower(r.Method)+" "+routeParam.ReplaceAllString(r.Path, "{$1}"))
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc := loadOpenAPI(t)
+	routes := registeredRoutes(t)
+	registered := make(map[string]bool, len(routes))
+	for _, r := range routes {
+		registered[r] = true
+		method, path, _ := strings.Cut(r, " ")
+		_, ok := doc.Paths[path][method]
+		assert.True(t, ok, "route %s is missing from openapi.json", r)
+	}
+	for path, item := range doc.Paths {
+		for method := range item {
+			assert.True(t, registered[method+" "+path], "openapi.json documents %s %s which is not registered", method, path)
+		}
+	}
+}
+
+// jsonFields lists JSON names of fields of the struct
+func jsonFields(v interface{}) []string {
+	var names []string
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestOpenAPISchemasMatchTypes(t *testing.T) {
+	doc := loadOpenAPI(t)
+	for name, v := range map[string]interface{}{
+		"createAdRequest":           createAdRequest{},
+		"updateAdRequest":           updateAdRequest{},
+		"changeAdStatusRequest":     changeAdStatusRequest{},
+		"deleteAdRequest":           deleteAdRequest{},
+		"actorRequest":              actorRequest{},
+		"extendAdRequest":           extendAdRequest{},
+		"userRequest":               userRequest{},
+		"patchUserRequest":          patchUserRequest{},
+		"confirmEmailRequest":       confirmEmailRequest{},
+		"sendMessageRequest":        sendMessageRequest{},
+		"reviewSellerRequest":       reviewSellerRequest{},
+		"replyToReviewRequest":      replyToReviewRequest{},
+		"hideReviewRequest":         hideReviewRequest{},
+		"reportAdRequest":           reportAdRequest{},
+		"resolveReportsRequest":     resolveReportsRequest{},
+		"appealTakedownRequest":     appealTakedownRequest{},
+		"resolveAppealRequest":      resolveAppealRequest{},
+		"Ad":                        presenter.Ad{},
+		"ContentFlag":               presenter.ContentFlag{},
+		"userResponse":              userResponse{},
+		"reputationResponse":        reputationResponse{},
+		"deletionResponse":          deletionResponse{},
+		"fieldChangeResponse":       fieldChangeResponse{},
+		"revisionResponse":          revisionResponse{},
+		"approvalResponse":          approvalResponse{},
+		"changesResponse":           changesResponse{},
+		"transitionResponse":        transitionResponse{},
+		"favoriteResponse":          favoriteResponse{},
+		"favoriteAdResponse":        favoriteAdResponse{},
+		"favoriteCountResponse":     favoriteCountResponse{},
+		"conversationResponse":      conversationResponse{},
+		"messageResponse":           messageResponse{},
+		"blockResponse":             blockResponse{},
+		"reviewResponse":            reviewResponse{},
+		"reportResponse":            reportResponse{},
+		"reportedAdResponse":        reportedAdResponse{},
+		"appealResponse":            appealResponse{},
+		"duplicateClusterResponse":  duplicateClusterResponse{},
+		"auditEntryResponse":        auditEntryResponse{},
+		"auditVerificationResponse": auditVerificationResponse{},
+		"problem":                   problem{},
+		"fieldViolation":            fieldViolation{},
+		"problemResource":           problemResource{},
+	} {
+		schema, ok := doc.Components.Schemas[name]
+		if !assert.True(t, ok, "schema %s is missing from openapi.json", name) {
+			continue
+		}
+		var props []string
+		for p := range schema.Properties {
+			props = append(props, p)
+		}
+		sort.Strings(props)
+		assert.Equal(t, jsonFields(v), props, "properties of schema %s", name)
+	}
+}
+
+// refs collects $ref values of the document
+func refs(v interface{}, found map[string]bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if s, ok := child.(string); ok && k == "$ref" {
+				found[s] = true
+			}
+			refs(child, found)
+		}
+	case []interface{}:
+		for _, child := range v {
+			refs(child, found)
+		}
+	}
+}
+
+func TestOpenAPIRefsResolve(t *testing.T) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json is malformed: %v", err)
+	}
+	found := make(map[string]bool)
+	refs(doc, found)
+	for ref := range found {
+		var node interface{} = doc
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			m, _ := node.(map[string]interface{})
+			node = m[key]
+		}
+		assert.NotNil(t, node, "%s doesn't resolve", ref)
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	s := NewHTTPServer(":18080", app.NewApp(repo.NewAd(), repo.NewUser()))
+
+	rec := httptest.NewRecorder()
+	s.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, json.Valid(rec.Body.Bytes()))
+
+	rec = httptest.NewRecorder()
+	s.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.NotContains(t, rec.Body.String(), "https://", "the docs page must not load external assets")
+}
@@ -1,6 +1,8 @@
 package httpgin
 
 import (
+	"ads-server/internal/app"
+	"ads-server/internal/ports/presenter"
 	"ads-server/internal/users"
 	"github.com/gin-gonic/gin"
 	"time"
@@ -9,25 +11,33 @@ import (
 )
 
 type createAdRequest struct {
-	Title  string `json:"title"`
-	Text   string `json:"text"`
-	UserID int64  `json:"user_id"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+	UserID   int64  `json:"user_id"`
+	Category string `json:"category"`
 }
 
-type adResponse struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Text      string    `json:"text"`
-	AuthorID  int64     `json:"author_id"`
-	Published bool      `json:"published"`
-	CDate     time.Time `json:"create"`
-	UDate     time.Time `json:"update"`
+type userResponse struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Verified bool   `json:"verified"`
+	Version  int64  `json:"version"`
+	Role     string `json:"role"`
+	// Reputation aggregates visible reviews of the user as a seller
+	Reputation reputationResponse `json:"reputation"`
 }
 
-type userResponse struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+type reputationResponse struct {
+	Rating  float64 `json:"rating"`
+	Reviews int     `json:"reviews"`
+}
+
+type deletionResponse struct {
+	UserID        int64   `json:"user_id"`
+	Policy        string  `json:"policy"`
+	DeletedAds    []int64 `json:"deleted_ads"`
+	AnonymizedAds []int64 `json:"anonymized_ads"`
 }
 
 type userRequest struct {
@@ -36,9 +46,25 @@ type userRequest struct {
 	Email string `json:"email"`
 }
 
+type confirmEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type patchUserRequest struct {
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
+}
+
+type deleteAdRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
 type changeAdStatusRequest struct {
 	Published bool  `json:"published"`
 	UserID    int64 `json:"user_id"`
+	// PublishAt schedules publication instead of publishing now, UnpublishAt schedules unpublishing
+	PublishAt   time.Time `json:"publish_at"`
+	UnpublishAt time.Time `json:"unpublish_at"`
 }
 
 type updateAdRequest struct {
@@ -49,30 +75,15 @@ type updateAdRequest struct {
 
 func AdSuccessResponse(ad *ads.Ad) *gin.H {
 	return &gin.H{
-		"data": adResponse{
-			ID:        ad.ID,
-			Title:     ad.Title,
-			Text:      ad.Text,
-			AuthorID:  ad.AuthorID,
-			Published: ad.Published,
-		},
+		"data":  presenter.NewAd(ad),
 		"error": nil,
 	}
 }
 
 func AdsSuccessResponse(allAds []*ads.Ad) *gin.H {
-	var res []adResponse
-	for _, val := range allAds {
-		res = append(res,
-			adResponse{
-				ID:        val.ID,
-				Title:     val.Title,
-				Text:      val.Text,
-				AuthorID:  val.AuthorID,
-				Published: val.Published,
-				CDate:     val.CDate,
-				UDate:     val.UDate,
-			})
+	var res []presenter.Ad
+	if len(allAds) > 0 {
+		res = presenter.NewAds(allAds)
 	}
 	return &gin.H{
 		"data":  res,
@@ -83,17 +94,37 @@ func AdsSuccessResponse(allAds []*ads.Ad) *gin.H {
 func UserSuccessResponse(user *users.User) *gin.H {
 	return &gin.H{
 		"data": userResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
+			ID:       user.ID,
+			Name:     user.Name,
+			Email:    user.Email,
+			Verified: user.Verified,
+			Version:  user.Version,
+			Role:     string(user.Role),
+			Reputation: reputationResponse{
+				Rating:  user.Reputation.Rating(),
+				Reviews: user.Reputation.Reviews,
+			},
 		},
 		"error": nil,
 	}
 }
 
-func AdErrorResponse(err error) *gin.H {
+func DeletionSuccessResponse(report app.DeletionReport) *gin.H {
+	res := deletionResponse{
+		UserID:        report.UserID,
+		Policy:        string(report.Policy),
+		DeletedAds:    report.DeletedAds,
+		AnonymizedAds: report.AnonymizedAds,
+	}
+	// empty lists are reported as [] rather than null
+	if res.DeletedAds == nil {
+		res.DeletedAds = []int64{}
+	}
+	if res.AnonymizedAds == nil {
+		res.AnonymizedAds = []int64{}
+	}
 	return &gin.H{
-		"data":  nil,
-		"error": err.Error(),
+		"data":  res,
+		"error": nil,
 	}
 }
@@ -0,0 +1,29 @@
+package httpgin
+
+import (
+	"ads-server/internal/errs"
+	"ads-server/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitMW spends a token of the client for the route, requests over the quota are rejected with 429.
+// Responses of limited routes carry RateLimit-* headers, a nil limiter doesn't limit anything.
+func rateLimitMW(l *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+		key := l.Key(c.GetHeader(ratelimit.APIKeyHeader), c.GetHeader(ratelimit.UserHeader), c.ClientIP())
+		d := l.Allow(c.Request.Method+" "+c.FullPath(), key)
+		for name, v := range d.Headers() {
+			c.Header(name, v)
+		}
+		if !d.Allowed {
+			respondError(c, errs.RateLimitError)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,271 @@
+package httpgin
+
+import (
+	"net/http"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/app"
+	"ads-server/internal/ports/presenter"
+	"github.com/gin-gonic/gin"
+)
+
+type reportAdRequest struct {
+	UserID  int64  `json:"user_id"`
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+type resolveReportsRequest struct {
+	UserID int64 `json:"user_id"`
+	// Takedown upholds the reports taking the ad down, otherwise they are dismissed lifting the takedown
+	Takedown bool `json:"takedown"`
+}
+
+type appealTakedownRequest struct {
+	UserID  int64  `json:"user_id"`
+	Comment string `json:"comment"`
+}
+
+type resolveAppealRequest struct {
+	UserID int64 `json:"user_id"`
+	Accept bool  `json:"accept"`
+}
+
+type reportResponse struct {
+	ID         int64      `json:"id"`
+	AdID       int64      `json:"ad_id"`
+	ReporterID int64      `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Comment    string     `json:"comment,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Outcome    string     `json:"outcome,omitempty"`
+	ResolvedBy int64      `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type reportedAdResponse struct {
+	Ad      presenter.Ad     `json:"ad"`
+	Reports []reportResponse `json:"reports"`
+}
+
+type appealResponse struct {
+	ID          int64      `json:"id"`
+	AdID        int64      `json:"ad_id"`
+	AuthorID    int64      `json:"author_id"`
+	Comment     string     `json:"comment"`
+	Status      string     `json:"status"`
+	ModeratorID int64      `json:"moderator_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+func newReportResponse(r *ads.Report) reportResponse {
+	res := reportResponse{
+		ID:         r.ID,
+		AdID:       r.AdID,
+		ReporterID: r.ReporterID,
+		Reason:     string(r.Reason),
+		Comment:    r.Comment,
+		CreatedAt:  r.CreatedAt,
+		Outcome:    string(r.Outcome),
+		ResolvedBy: r.ResolvedBy,
+	}
+	if !r.Open() {
+		res.ResolvedAt = &r.ResolvedAt
+	}
+	return res
+}
+
+func newReportsResponse(list []*ads.Report) []reportResponse {
+	res := make([]reportResponse, 0, len(list))
+	for _, r := range list {
+		res = append(res, newReportResponse(r))
+	}
+	return res
+}
+
+func newAppealResponse(ap *ads.Appeal) appealResponse {
+	res := appealResponse{
+		ID:          ap.ID,
+		AdID:        ap.AdID,
+		AuthorID:    ap.AuthorID,
+		Comment:     ap.Comment,
+		Status:      string(ap.Status),
+		ModeratorID: ap.ModeratorID,
+		CreatedAt:   ap.CreatedAt,
+	}
+	if ap.Status != ads.AppealPending {
+		res.ResolvedAt = &ap.ResolvedAt
+	}
+	return res
+}
+
+func ReportSuccessResponse(r *ads.Report) *gin.H {
+	return &gin.H{
+		"data":  newReportResponse(r),
+		"error": nil,
+	}
+}
+
+func ReportsSuccessResponse(list []*ads.Report) *gin.H {
+	return &gin.H{
+		"data":  newReportsResponse(list),
+		"error": nil,
+	}
+}
+
+func ReportInboxSuccessResponse(inbox []app.ReportedAd) *gin.H {
+	res := make([]reportedAdResponse, 0, len(inbox))
+	for _, r := range inbox {
+		res = append(res, reportedAdResponse{Ad: presenter.NewAd(r.Ad), Reports: newReportsResponse(r.Reports)})
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+func AppealSuccessResponse(ap *ads.Appeal) *gin.H {
+	return &gin.H{
+		"data":  newAppealResponse(ap),
+		"error": nil,
+	}
+}
+
+func AppealsSuccessResponse(list []*ads.Appeal) *gin.H {
+	res := make([]appealResponse, 0, len(list))
+	for _, ap := range list {
+		res = append(res, newAppealResponse(ap))
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+// reportAd handles route to report the ad to moderators on behalf of the acting user
+func reportAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody reportAdRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		r, err := a.ReportAd(c, adID, reqBody.UserID, ads.ReportReason(reqBody.Reason), reqBody.Comment)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ReportSuccessResponse(r))
+	}
+}
+
+// listReportedAds handles route to return ads with open reports to a moderator, the most reported first
+func listReportedAds(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		inbox, err := a.ReportInbox(c, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ReportInboxSuccessResponse(inbox))
+	}
+}
+
+// resolveReports handles route to uphold or dismiss open reports of the ad by a moderator
+func resolveReports(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody resolveReportsRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		resolved, err := a.ResolveReports(c, adID, reqBody.UserID, reqBody.Takedown)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ReportsSuccessResponse(resolved))
+	}
+}
+
+// appealTakedown handles route to appeal the takedown of the ad by its author
+func appealTakedown(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody appealTakedownRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		ap, err := a.AppealTakedown(c, adID, reqBody.UserID, reqBody.Comment)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AppealSuccessResponse(ap))
+	}
+}
+
+// listAppeals handles route to return pending appeals to a moderator
+func listAppeals(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		list, err := a.ListAppeals(c, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AppealsSuccessResponse(list))
+	}
+}
+
+// resolveAppeal handles route to accept or reject the appeal by a moderator
+func resolveAppeal(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody resolveAppealRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		appealID, ok := pathID(c, "appeal_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		ap, err := a.ResolveAppeal(c, appealID, reqBody.UserID, reqBody.Accept)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, AppealSuccessResponse(ap))
+	}
+}
@@ -0,0 +1,164 @@
+package httpgin
+
+import (
+	"net/http"
+	"time"
+
+	"ads-server/internal/app"
+	"ads-server/internal/users"
+	"github.com/gin-gonic/gin"
+)
+
+type reviewSellerRequest struct {
+	UserID int64  `json:"user_id"`
+	AdID   int64  `json:"ad_id"`
+	Rating int    `json:"rating"`
+	Text   string `json:"text"`
+}
+
+type replyToReviewRequest struct {
+	UserID int64  `json:"user_id"`
+	Text   string `json:"text"`
+}
+
+type hideReviewRequest struct {
+	UserID int64 `json:"user_id"`
+	Hidden bool  `json:"hidden"`
+}
+
+type reviewResponse struct {
+	ID        int64      `json:"id"`
+	SellerID  int64      `json:"seller_id"`
+	AuthorID  int64      `json:"author_id"`
+	AdID      int64      `json:"ad_id"`
+	Rating    int        `json:"rating"`
+	Text      string     `json:"text"`
+	Reply     string     `json:"reply,omitempty"`
+	RepliedAt *time.Time `json:"replied_at,omitempty"`
+	Hidden    bool       `json:"hidden"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func newReviewResponse(r *users.Review) reviewResponse {
+	res := reviewResponse{
+		ID:        r.ID,
+		SellerID:  r.SellerID,
+		AuthorID:  r.AuthorID,
+		AdID:      r.AdID,
+		Rating:    r.Rating,
+		Text:      r.Text,
+		Reply:     r.Reply,
+		Hidden:    r.Hidden,
+		CreatedAt: r.CreatedAt,
+	}
+	if r.Replied() {
+		res.RepliedAt = &r.RepliedAt
+	}
+	return res
+}
+
+func ReviewSuccessResponse(r *users.Review) *gin.H {
+	return &gin.H{
+		"data":  newReviewResponse(r),
+		"error": nil,
+	}
+}
+
+func ReviewsSuccessResponse(list []*users.Review) *gin.H {
+	res := make([]reviewResponse, 0, len(list))
+	for _, r := range list {
+		res = append(res, newReviewResponse(r))
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+// reviewSeller handles route to rate the author of the ad the acting buyer messaged about
+func reviewSeller(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody reviewSellerRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		r, err := a.ReviewSeller(c, reqBody.AdID, reqBody.UserID, reqBody.Rating, reqBody.Text)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ReviewSuccessResponse(r))
+	}
+}
+
+// listReviews handles route to return reviews of the seller, moderators get hidden reviews too
+func listReviews(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sellerID, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		actorID, ok := optionalQueryID(c, "user_id")
+		if !ok || actorID != nil && !actorExists(c, a, *actorID) {
+			return
+		}
+
+		list, err := a.ListReviews(c, sellerID, actorID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ReviewsSuccessResponse(list))
+	}
+}
+
+// replyToReview handles route to store the only reply of the seller to a review
+func replyToReview(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody replyToReviewRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		reviewID, ok := pathID(c, "review_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		r, err := a.ReplyToReview(c, reviewID, reqBody.UserID, reqBody.Text)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ReviewSuccessResponse(r))
+	}
+}
+
+// hideReview handles route to hide an abusive review or show it again by a moderator
+func hideReview(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody hideReviewRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		reviewID, ok := pathID(c, "review_id")
+		if !ok || !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		r, err := a.HideReview(c, reviewID, reqBody.UserID, reqBody.Hidden)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ReviewSuccessResponse(r))
+	}
+}
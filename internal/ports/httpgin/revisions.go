@@ -0,0 +1,249 @@
+package httpgin
+
+import (
+	"net/http"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/app"
+	"github.com/gin-gonic/gin"
+)
+
+type fieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type revisionResponse struct {
+	AdID         int64                 `json:"ad_id"`
+	Number       int64                 `json:"number"`
+	Action       string                `json:"action"`
+	EditorID     int64                 `json:"editor_id"`
+	CreatedAt    time.Time             `json:"created_at"`
+	RestoredFrom int64                 `json:"restored_from,omitempty"`
+	Title        string                `json:"title"`
+	Text         string                `json:"text"`
+	AuthorID     int64                 `json:"author_id"`
+	Published    bool                  `json:"published"`
+	Changes      []fieldChangeResponse `json:"changes"`
+}
+
+type approvalResponse struct {
+	AdID        int64     `json:"ad_id"`
+	Revision    int64     `json:"revision"`
+	ModeratorID int64     `json:"moderator_id"`
+	ApprovedAt  time.Time `json:"approved_at"`
+}
+
+type changesResponse struct {
+	AdID     int64                 `json:"ad_id"`
+	Approval *approvalResponse     `json:"approval"`
+	Revision int64                 `json:"revision"`
+	Changes  []fieldChangeResponse `json:"changes"`
+}
+
+type actorRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+func fieldChanges(changes []ads.FieldChange) []fieldChangeResponse {
+	// empty lists are reported as [] rather than null
+	res := make([]fieldChangeResponse, 0, len(changes))
+	for _, c := range changes {
+		res = append(res, fieldChangeResponse{Field: c.Field, Old: c.Old, New: c.New})
+	}
+	return res
+}
+
+func newRevisionResponse(r *ads.Revision) revisionResponse {
+	return revisionResponse{
+		AdID:         r.AdID,
+		Number:       r.Number,
+		Action:       string(r.Action),
+		EditorID:     r.EditorID,
+		CreatedAt:    r.CreatedAt,
+		RestoredFrom: r.RestoredFrom,
+		Title:        r.Title,
+		Text:         r.Text,
+		AuthorID:     r.AuthorID,
+		Published:    r.Published,
+		Changes:      fieldChanges(r.Changes),
+	}
+}
+
+func newApprovalResponse(ap *ads.Approval) *approvalResponse {
+	if ap == nil {
+		return nil
+	}
+	return &approvalResponse{AdID: ap.AdID, Revision: ap.Revision, ModeratorID: ap.ModeratorID, ApprovedAt: ap.At}
+}
+
+func RevisionSuccessResponse(r *ads.Revision) *gin.H {
+	return &gin.H{
+		"data":  newRevisionResponse(r),
+		"error": nil,
+	}
+}
+
+func RevisionsSuccessResponse(history []*ads.Revision) *gin.H {
+	res := make([]revisionResponse, 0, len(history))
+	for _, r := range history {
+		res = append(res, newRevisionResponse(r))
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+func ApprovalSuccessResponse(ap *ads.Approval) *gin.H {
+	return &gin.H{
+		"data":  newApprovalResponse(ap),
+		"error": nil,
+	}
+}
+
+func ChangesSuccessResponse(changes app.AdChanges) *gin.H {
+	return &gin.H{
+		"data": changesResponse{
+			AdID:     changes.AdID,
+			Approval: newApprovalResponse(changes.Approval),
+			Revision: changes.Revision,
+			Changes:  fieldChanges(changes.Changes),
+		},
+		"error": nil,
+	}
+}
+
+// listRevisions handles route to return history of the ad to its author or a moderator
+func listRevisions(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		history, err := a.ListRevisions(c, adID, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, RevisionsSuccessResponse(history))
+	}
+}
+
+// getRevision handles route to return a single revision of the ad
+func getRevision(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		number, ok := pathID(c, "number")
+		if !ok {
+			return
+		}
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		r, err := a.GetRevision(c, adID, uID, number)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, RevisionSuccessResponse(r))
+	}
+}
+
+// rollbackAd handles route to restore title and text of the ad from a revision
+func rollbackAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		number, ok := pathID(c, "number")
+		if !ok {
+			return
+		}
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		ad, err := a.RollbackAd(c, adID, reqBody.UserID, number, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		setETag(c, ad.Version)
+		c.JSON(http.StatusOK, AdSuccessResponse(ad))
+	}
+}
+
+// approveAd handles route for a moderator to approve the current revision of the ad
+func approveAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		version, ok := ifMatch(c)
+		if !ok {
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		ap, err := a.ApproveAd(c, adID, reqBody.UserID, version)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ApprovalSuccessResponse(ap))
+	}
+}
+
+// adChanges handles route to show what changed in the ad since the last approval
+func adChanges(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		changes, err := a.AdChangesSinceApproval(c, adID, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, ChangesSuccessResponse(changes))
+	}
+}
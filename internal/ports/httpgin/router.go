@@ -6,11 +6,67 @@ import (
 )
 
 func AppRouter(r gin.IRouter, a app.App) {
-	r.GET("/ads/:ad_id/info", getAdByID(a))        // Метод для получения объявления по ID
-	r.POST("/user", createUser(a))                 // Метод для создания пользователя (user)
-	r.POST("/ads", createAd(a))                    // Метод для создания объявления (ad)
-	r.PUT("/ads/:ad_id/status", changeAdStatus(a)) // Метод для изменения статуса объявления (опубликовано - Published = true или снято с публикации Published = false)
-	r.PUT("/ads/:ad_id", updateAd(a))              // Метод для обновления текста(Text) или заголовка(Title) объявления
-	r.GET("ads/find/:title", getAdsByName(a))      // Метод для получения списка объявлений по имени
-	r.GET("ads/filter", filterAds(a))              // Метод для фильтрации объявлений по query-параметрам
+	r.GET("/ads/:ad_id/info", getAdByID(a))                     // Метод для получения объявления по ID
+	r.POST("/user", createUser(a))                              // Метод для создания пользователя (user)
+	r.POST("/ads", idempotencyMW(a.Idempotency()), createAd(a)) // Метод для создания объявления (ad)
+	r.PUT("/ads/:ad_id/status", changeAdStatus(a))              // Метод для изменения статуса объявления (опубликовано - Published = true или снято с публикации Published = false)
+	r.PUT("/ads/:ad_id", updateAd(a))                           // Метод для обновления текста(Text) или заголовка(Title) объявления
+	r.GET("ads/find/:title", getAdsByName(a))                   // Метод для получения списка объявлений по имени
+	r.GET("ads/filter", filterAds(a))                           // Метод для фильтрации объявлений по query-параметрам
+	r.DELETE("/ads/:ad_id", deleteAd(a))                        // Метод для удаления объявления его автором
+
+	r.GET("/ads/:ad_id/revisions", listRevisions(a))                     // Метод для получения истории изменений объявления (автору и модераторам)
+	r.GET("/ads/:ad_id/revisions/:number", getRevision(a))               // Метод для получения отдельной ревизии объявления
+	r.POST("/ads/:ad_id/revisions/:number/rollback", rollbackAd(a))      // Метод для отката заголовка и текста объявления к ревизии
+	r.POST("/ads/:ad_id/approve", approveAd(a))                          // Метод для одобрения текущей ревизии объявления модератором
+	r.GET("/ads/:ad_id/changes", adChanges(a))                           // Метод для получения изменений объявления с последнего одобрения
+	r.POST("/ads/:ad_id/restore", restoreAd(a))                          // Метод для восстановления объявления из корзины автором или администратором
+	r.POST("/ads/:ad_id/renew", renewAd(a))                              // Метод для продления срока жизни объявления автором (в том числе из архива)
+	r.POST("/ads/:ad_id/extend", extendAd(a))                            // Метод для переноса даты истечения объявления на несколько дней
+	r.GET("/ads/:ad_id/schedule", listTransitions(a))                    // Метод для получения запланированных публикаций и снятий объявления с публикации
+	r.DELETE("/ads/:ad_id/schedule/:transition_id", cancelTransition(a)) // Метод для отмены запланированной публикации или снятия с публикации
+	r.POST("/ads/:ad_id/favorite", addFavorite(a))                       // Метод для добавления объявления в избранное пользователя
+	r.DELETE("/ads/:ad_id/favorite", removeFavorite(a))                  // Метод для удаления объявления из избранного пользователя
+	r.GET("/ads/:ad_id/favorites/count", countFavorites(a))              // Метод для получения числа пользователей, добавивших объявление в избранное (автору и администраторам)
+	r.POST("/ads/:ad_id/conversations", startConversation(a))            // Метод для начала переписки покупателя с автором объявления
+	r.POST("/ads/:ad_id/reports", reportAd(a))                           // Метод для жалобы пользователя на объявление (причина и комментарий)
+	r.POST("/ads/:ad_id/reports/resolve", resolveReports(a))             // Метод для рассмотрения жалоб на объявление модератором (снятие или отклонение жалоб)
+	r.POST("/ads/:ad_id/appeals", appealTakedown(a))                     // Метод для обжалования снятия объявления его автором
+
+	r.POST("/users", createUser(a))                          // Метод для создания пользователя (user)
+	r.GET("/users/:id", getUser(a))                          // Метод для получения пользователя по ID
+	r.PUT("/users/:id", updateUser(a))                       // Метод для замены имени (Name) и почты (Email) пользователя
+	r.PATCH("/users/:id", patchUser(a))                      // Метод для изменения только переданных полей пользователя
+	r.DELETE("/users/:id", deleteUser(a))                    // Метод для удаления пользователя
+	r.GET("/users/:id/ads", listUserAds(a))                  // Метод для получения объявлений пользователя (неопубликованные видят только автор и модераторы)
+	r.POST("/users/verify", confirmEmail(a))                 // Метод для подтверждения почты пользователя токеном из письма
+	r.POST("/users/:id/verification", resendVerification(a)) // Метод для повторной отправки письма с подтверждением почты
+	r.POST("/users/:id/restore", restoreUser(a))             // Метод для восстановления пользователя из корзины им самим или администратором
+	r.GET("/users/:id/trash", listTrash(a))                  // Метод для получения удалённых объявлений пользователя
+	r.GET("/users/:id/favorites", listFavorites(a))          // Метод для получения избранных объявлений пользователя
+	r.POST("/users/:id/block", blockUser(a))                 // Метод для блокировки переписки с пользователем
+	r.DELETE("/users/:id/block", unblockUser(a))             // Метод для снятия блокировки переписки с пользователем
+	r.GET("/users/:id/blocked", listBlocked(a))              // Метод для получения пользователей, заблокированных пользователем
+	r.GET("/users/:id/reviews", listReviews(a))              // Метод для получения отзывов о продавце (скрытые видны только модераторам)
+
+	r.POST("/reviews", reviewSeller(a))                   // Метод для отзыва покупателя о продавце по объявлению, о котором они переписывались
+	r.POST("/reviews/:review_id/reply", replyToReview(a)) // Метод для единственного ответа продавца на отзыв
+	r.PUT("/reviews/:review_id/hidden", hideReview(a))    // Метод для скрытия оскорбительного отзыва модератором или его возврата
+
+	r.GET("/conversations", listConversations(a))                      // Метод для получения переписок пользователя с числом непрочитанных сообщений
+	r.GET("/conversations/:conversation_id/messages", listMessages(a)) // Метод для получения сообщений переписки постранично (только участникам)
+	r.POST("/conversations/:conversation_id/messages", sendMessage(a)) // Метод для отправки сообщения в переписку
+
+	r.GET("/reports", listReportedAds(a))                   // Метод для получения модератором объявлений с открытыми жалобами, сгруппированными по объявлению
+	r.GET("/appeals", listAppeals(a))                       // Метод для получения модератором необработанных апелляций
+	r.POST("/appeals/:appeal_id/resolve", resolveAppeal(a)) // Метод для принятия или отклонения апелляции модератором
+
+	r.GET("/held", listHeldAds(a))                 // Метод для получения модератором объявлений, задержанных фильтрами содержимого до одобрения
+	r.GET("/duplicates", listDuplicateClusters(a)) // Метод для получения модератором групп похожих друг на друга объявлений
+
+	r.GET("/audit", listAuditEntries(a))      // Метод для получения журнала аудита администратором (фильтры по автору, объекту и времени)
+	r.GET("/audit/verify", verifyAuditLog(a)) // Метод для проверки целостности цепочки хешей журнала аудита
+
+	r.GET("/openapi.json", getOpenAPI) // Метод для получения OpenAPI-описания HTTP API
+	r.GET("/docs", getDocs)            // Метод для получения интерактивной документации HTTP API (работает без сети)
 }
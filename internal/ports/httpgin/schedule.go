@@ -0,0 +1,87 @@
+package httpgin
+
+import (
+	"net/http"
+	"time"
+
+	"ads-server/internal/ads"
+	"ads-server/internal/app"
+	"github.com/gin-gonic/gin"
+)
+
+type transitionResponse struct {
+	ID        int64     `json:"id"`
+	AdID      int64     `json:"ad_id"`
+	UserID    int64     `json:"user_id"`
+	Published bool      `json:"published"`
+	At        time.Time `json:"at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func TransitionsSuccessResponse(transitions []*ads.Transition) *gin.H {
+	res := make([]transitionResponse, 0, len(transitions))
+	for _, t := range transitions {
+		res = append(res, transitionResponse{
+			ID:        t.ID,
+			AdID:      t.AdID,
+			UserID:    t.UserID,
+			Published: t.Publish,
+			At:        t.At,
+			CreatedAt: t.CreatedAt,
+		})
+	}
+	return &gin.H{
+		"data":  res,
+		"error": nil,
+	}
+}
+
+// listTransitions handles route to return pending status changes of the ad to its author or an admin
+func listTransitions(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		uID, ok := queryID(c, "user_id")
+		if !ok || !actorExists(c, a, uID) {
+			return
+		}
+
+		transitions, err := a.ListTransitions(c, adID, uID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, TransitionsSuccessResponse(transitions))
+	}
+}
+
+// cancelTransition handles route to drop a pending status change of the ad
+func cancelTransition(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody actorRequest
+		if err := c.ShouldBind(&reqBody); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+
+		adID, ok := pathID(c, "ad_id")
+		if !ok {
+			return
+		}
+		transitionID, ok := pathID(c, "transition_id")
+		if !ok {
+			return
+		}
+		if !actorExists(c, a, reqBody.UserID) {
+			return
+		}
+
+		if err := a.CancelTransition(c, adID, transitionID, reqBody.UserID); err != nil {
+			respondError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
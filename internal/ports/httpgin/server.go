@@ -9,10 +9,21 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"ads-server/internal/app"
+	"ads-server/internal/audit"
 )
 
+var tracer = otel.Tracer("ads-server/internal/ports/httpgin")
+
+// shutdownDrainDelay is how long the server keeps serving after readiness starts failing
+const shutdownDrainDelay = 5 * time.Second
+
 type Server struct {
 	port string
 	app  *gin.Engine
@@ -32,16 +43,70 @@ func loggerMW() gin.HandlerFunc {
 	}
 }
 
+// tracingMW starts a server span for every request continuing the W3C trace context sent by the client
+func tracingMW() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			))
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// requestIDMW tags the request with the ID sent by the client or a generated one, so audited changes can be traced back
+func requestIDMW() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(audit.RequestIDHeader)
+		if id == "" {
+			id = audit.NewRequestID()
+		}
+		c.Header(audit.RequestIDHeader, id)
+		c.Request = c.Request.WithContext(audit.WithRequest(c.Request.Context(), audit.Request{ID: id, Transport: audit.TransportHTTP}))
+		c.Next()
+	}
+}
+
 func NewHTTPServer(port string, a app.App) *http.Server {
+	s, _ := newHTTPServer(port, a)
+	return s
+}
+
+// newHTTPServer builds HTTP server and returns probes controlling its readiness
+func newHTTPServer(port string, a app.App) (*http.Server, *probes) {
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
+	// handlers pass *gin.Context to the app, so it has to expose the request context (and its span)
+	router.ContextWithFallback = true
+
+	p := &probes{app: a}
+	router.GET("/healthz", p.healthz)
+	router.GET("/readyz", p.readyz)
+
 	api := router.Group("api/v1")
 	s := &http.Server{Addr: port, Handler: router}
 	//api := s.Handler.Group("/api/v1")
-	api.Use(loggerMW(), gin.Recovery())
+	api.Use(tracingMW(), requestIDMW(), loggerMW(), gin.Recovery(), rateLimitMW(a.Limiter()))
 	AppRouter(api, a)
-	return s
-
+	return s, p
 }
 
 func (s *Server) Listen() error {
@@ -53,9 +118,9 @@ func (s *Server) Handler() http.Handler {
 }
 
 // Run returns function to start HTTP server on a port given and implements graceful shutdown principle
-func Run(ctx context.Context, a app.AdRepository, u app.UserRepository, httpPort string) func() error {
+func Run(ctx context.Context, a app.AdRepository, u app.UserRepository, httpPort string, opts ...app.Option) func() error {
 	return func() error {
-		httpServer := NewHTTPServer(httpPort, app.NewApp(a, u))
+		httpServer, p := newHTTPServer(httpPort, app.NewApp(a, u, opts...))
 
 		errCh := make(chan error)
 
@@ -78,6 +143,10 @@ func Run(ctx context.Context, a app.AdRepository, u app.UserRepository, httpPort
 
 		select {
 		case <-ctx.Done():
+			// fail readiness first and give load balancers time to stop sending traffic,
+			// a server that failed to start has no traffic to drain
+			p.drain()
+			time.Sleep(shutdownDrainDelay)
 			return ctx.Err()
 		case err := <-errCh:
 			return fmt.Errorf("http server can't listen and serve requests: %w", err)
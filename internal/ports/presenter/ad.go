@@ -0,0 +1,150 @@
+// Package presenter maps domain entities to their representations shared by all transports.
+//
+// HTTP responds with the presentation itself encoded to JSON, gRPC converts it to protobuf,
+// so a field added here reaches both transports.
+package presenter
+
+import (
+	"time"
+
+	"ads-server/internal/ads"
+	proto "ads-server/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MergedIntoHeader names the ID of the existing ad a new one was merged into,
+// HTTP sends it as a response header and gRPC as header metadata.
+const MergedIntoHeader = "Merged-Into"
+
+// Ad is the public representation of an ad, unset moments are omitted.
+// Creation and update times are always set for stored ads, JSON keeps their historical names.
+type Ad struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	AuthorID  int64     `json:"author_id"`
+	Published bool      `json:"published"`
+	CreatedAt time.Time `json:"create"`
+	UpdatedAt time.Time `json:"update"`
+	Version   int64     `json:"version"`
+	// DeletedAt is set while the ad is in trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Category  string     `json:"category,omitempty"`
+	// ExpiresAt is unset for ads that never expire
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// ArchivedAt is set once the ad expired until it is renewed
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// TakenDownAt is set while the ad is taken down because of abuse reports
+	TakenDownAt *time.Time `json:"taken_down_at,omitempty"`
+	// ContentFlags are objections of content filters, unmasked ones hold the ad until a moderator approves it
+	ContentFlags []ContentFlag `json:"content_flags,omitempty"`
+}
+
+// ContentFlag is an objection of a content filter to the title or text of an ad
+type ContentFlag struct {
+	Checker string `json:"checker"`
+	Field   string `json:"field"`
+	Reason  string `json:"reason"`
+	Masked  bool   `json:"masked"`
+}
+
+// optionalTime omits unset moments (e.g. deletion time of live items)
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// optionalTimestamp converts optional moment to protobuf timestamp, unset moments stay unset
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// NewAd presents the ad
+func NewAd(ad *ads.Ad) Ad {
+	return Ad{
+		ID:           ad.ID,
+		Title:        ad.Title,
+		Text:         ad.Text,
+		AuthorID:     ad.AuthorID,
+		Published:    ad.Published,
+		CreatedAt:    ad.CDate,
+		UpdatedAt:    ad.UDate,
+		Version:      ad.Version,
+		DeletedAt:    optionalTime(ad.DeletedAt),
+		Category:     ad.Category,
+		ExpiresAt:    optionalTime(ad.ExpiresAt),
+		ArchivedAt:   optionalTime(ad.ArchivedAt),
+		TakenDownAt:  optionalTime(ad.TakenDownAt),
+		ContentFlags: newContentFlags(ad.Screening.Flags),
+	}
+}
+
+// newContentFlags presents the flags, no flags are omitted
+func newContentFlags(flags []ads.ContentFlag) []ContentFlag {
+	if len(flags) == 0 {
+		return nil
+	}
+	res := make([]ContentFlag, 0, len(flags))
+	for _, f := range flags {
+		res = append(res, ContentFlag{Checker: f.Checker, Field: f.Field, Reason: f.Reason, Masked: f.Masked})
+	}
+	return res
+}
+
+// Proto converts the presentation to its protobuf shape
+func (a Ad) Proto() *proto.AdResponse {
+	return &proto.AdResponse{
+		Id:           a.ID,
+		Title:        a.Title,
+		Text:         a.Text,
+		AuthorId:     a.AuthorID,
+		Published:    a.Published,
+		CreatedAt:    optionalTimestamp(optionalTime(a.CreatedAt)),
+		UpdatedAt:    optionalTimestamp(optionalTime(a.UpdatedAt)),
+		Version:      a.Version,
+		DeletedAt:    optionalTimestamp(a.DeletedAt),
+		Category:     a.Category,
+		ExpiresAt:    optionalTimestamp(a.ExpiresAt),
+		ArchivedAt:   optionalTimestamp(a.ArchivedAt),
+		TakenDownAt:  optionalTimestamp(a.TakenDownAt),
+		ContentFlags: contentFlagsProto(a.ContentFlags),
+	}
+}
+
+// contentFlagsProto converts the flags to protobuf
+func contentFlagsProto(flags []ContentFlag) []*proto.ContentFlag {
+	var res []*proto.ContentFlag
+	for _, f := range flags {
+		res = append(res, &proto.ContentFlag{Checker: f.Checker, Field: f.Field, Reason: f.Reason, Masked: f.Masked})
+	}
+	return res
+}
+
+// NewAds presents the ads keeping their order
+func NewAds(list []*ads.Ad) []Ad {
+	res := make([]Ad, 0, len(list))
+	for _, ad := range list {
+		res = append(res, NewAd(ad))
+	}
+	return res
+}
+
+// AdProto presents the ad in protobuf shape
+func AdProto(ad *ads.Ad) *proto.AdResponse {
+	return NewAd(ad).Proto()
+}
+
+// AdsProto presents the ads in protobuf shape keeping their order
+func AdsProto(list []*ads.Ad) []*proto.AdResponse {
+	res := make([]*proto.AdResponse, 0, len(list))
+	for _, ad := range list {
+		res = append(res, AdProto(ad))
+	}
+	return res
+}
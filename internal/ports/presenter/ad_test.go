@@ -0,0 +1,107 @@
+package presenter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"ads-server/internal/ads"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// internalAdFields are domain fields deliberately kept out of responses
+var internalAdFields = map[string]bool{
+	"DeletedWithAuthor": true,
+	"ExpiryWarned":      true,
+}
+
+// fullAd returns an ad with every field set
+func fullAd() *ads.Ad {
+	at := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	return &ads.Ad{
+		ID:                7,
+		Title:             "hello",
+		Text:              "world",
+		AuthorID:          3,
+		CDate:             at,
+		UDate:             at.Add(time.Hour),
+		Published:         true,
+		Version:           5,
+		DeletedAt:         at.Add(2 * time.Hour),
+		DeletedWithAuthor: true,
+		Category:          "pets",
+		ExpiresAt:         at.Add(3 * time.Hour),
+		ArchivedAt:        at.Add(4 * time.Hour),
+		ExpiryWarned:      true,
+		TakenDownAt:       at.Add(5 * time.Hour),
+		Screening: ads.Screening{Revision: 3, Flags: []ads.ContentFlag{
+			{Checker: "links", Field: "text", Reason: `contains link "example.com"`},
+		}},
+	}
+}
+
+func TestEveryAdFieldPresented(t *testing.T) {
+	domain := reflect.TypeOf(ads.Ad{}).NumField()
+	presented := reflect.TypeOf(Ad{}).NumField()
+	assert.Equal(t, domain-len(internalAdFields), presented,
+		"a field of ads.Ad must be either presented or listed in internalAdFields")
+}
+
+func TestTransportsAgree(t *testing.T) {
+	ad := fullAd()
+
+	data, err := json.Marshal(NewAd(ad))
+	assert.NoError(t, err)
+	var fields map[string]any
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	for name, v := range fields {
+		assert.NotEmpty(t, v, "JSON field %s is empty", name)
+	}
+
+	msg := AdProto(ad).ProtoReflect()
+	descriptors := msg.Descriptor().Fields()
+	assert.Equal(t, len(fields), descriptors.Len(), "JSON and protobuf must have the same fields")
+	for i := 0; i < descriptors.Len(); i++ {
+		assert.True(t, msg.Has(descriptors.Get(i)), "protobuf field %s is unset", descriptors.Get(i).Name())
+	}
+}
+
+func TestAdProto(t *testing.T) {
+	ad := fullAd()
+	p := AdProto(ad)
+	assert.Equal(t, ad.ID, p.Id)
+	assert.Equal(t, ad.AuthorID, p.AuthorId)
+	assert.Equal(t, ad.CDate, p.CreatedAt.AsTime())
+	assert.Equal(t, ad.UDate, p.UpdatedAt.AsTime())
+	assert.Equal(t, ad.DeletedAt, p.DeletedAt.AsTime())
+	assert.Equal(t, ad.ExpiresAt, p.ExpiresAt.AsTime())
+	assert.Equal(t, ad.ArchivedAt, p.ArchivedAt.AsTime())
+	assert.Equal(t, ad.TakenDownAt, p.TakenDownAt.AsTime())
+	if assert.Len(t, p.ContentFlags, 1) {
+		assert.Equal(t, "links", p.ContentFlags[0].Checker)
+		assert.Equal(t, "text", p.ContentFlags[0].Field)
+		assert.False(t, p.ContentFlags[0].Masked)
+	}
+
+	live := &ads.Ad{ID: 1, CDate: ad.CDate, UDate: ad.UDate}
+	p = AdProto(live)
+	assert.Nil(t, p.DeletedAt)
+	assert.Nil(t, p.ExpiresAt)
+	assert.Nil(t, p.ArchivedAt)
+	assert.Nil(t, p.TakenDownAt)
+	assert.Empty(t, p.ContentFlags)
+
+	data, err := json.Marshal(NewAd(live))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "deleted_at")
+	assert.Contains(t, string(data), `"create":"2024-03-04T09:00:00Z"`)
+}
+
+func TestNewAds(t *testing.T) {
+	list := []*ads.Ad{{ID: 2}, {ID: 1}}
+	assert.Equal(t, []int64{2, 1}, []int64{NewAds(list)[0].ID, NewAds(list)[1].ID})
+	assert.Len(t, AdsProto(list), 2)
+	assert.Empty(t, NewAds(nil))
+}
@@ -0,0 +1,265 @@
+// Package ratelimit limits request rates of clients with token buckets shared by all transports.
+//
+// Every client has a bucket per route with its own quota and one bucket for all other routes.
+// Clients are told by a configured API key, by the user asserted by a trusted authenticating proxy
+// or by IP address. Unknown keys and untrusted user headers are ignored, so they can't buy fresh buckets.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ads-server/internal/clock"
+)
+
+const (
+	// APIKeyHeader identifies clients using API keys, gRPC metadata uses its lowercase form
+	APIKeyHeader = "X-API-Key"
+	// UserHeader carries the ID of the user authenticated by a proxy in front of the server
+	UserHeader = "X-User-ID"
+)
+
+// Quota allows Burst requests at once, spent requests are refilled evenly over Period
+type Quota struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Enabled reports whether the quota limits anything
+func (q Quota) Enabled() bool {
+	return q.Burst > 0 && q.Period > 0
+}
+
+func (q Quota) String() string {
+	if !q.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", q.Burst, q.Period)
+}
+
+// ParseQuota parses quotas like "100/1m", "off" disables limiting
+func ParseQuota(s string) (Quota, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" {
+		return Quota{}, nil
+	}
+	burst, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Quota{}, fmt.Errorf("quota %q must look like 100/1m or be off", s)
+	}
+	var (
+		q   Quota
+		err error
+	)
+	if q.Burst, err = strconv.Atoi(burst); err != nil || q.Burst <= 0 {
+		return Quota{}, fmt.Errorf("quota %q must allow a positive number of requests", s)
+	}
+	if q.Period, err = time.ParseDuration(period); err != nil || q.Period <= 0 {
+		return Quota{}, fmt.Errorf("quota %q must have a positive period", s)
+	}
+	return q, nil
+}
+
+// Config defines quotas of routes
+type Config struct {
+	// Default is shared by routes without their own quota
+	Default Quota
+	// Routes are quotas of HTTP routes like "POST /api/v1/ads" and gRPC methods like "/ad.AdService/CreateAd"
+	Routes map[string]Quota
+	// APIKeys are keys issued to clients, other keys are ignored
+	APIKeys map[string]bool
+	// TrustUserHeader tells the user header is set by an authenticating proxy rather than by clients
+	TrustUserHeader bool
+}
+
+// DefaultConfig allows 100 requests a minute per client
+var DefaultConfig = Config{Default: Quota{Burst: 100, Period: time.Minute}}
+
+// ParseRoutes parses quotas of routes separated by semicolons, e.g. "POST /api/v1/ads=10/1h;/ad.AdService/CreateAd=10/1h"
+func ParseRoutes(s string) (map[string]Quota, error) {
+	routes := make(map[string]Quota)
+	for _, item := range strings.Split(s, ";") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		route, quota, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("route quota %q must look like ROUTE=QUOTA", item)
+		}
+		q, err := ParseQuota(quota)
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.TrimSpace(route)] = q
+	}
+	return routes, nil
+}
+
+// ConfigFromEnv reads RATE_LIMIT, RATE_LIMIT_ROUTES, comma separated RATE_LIMIT_API_KEYS
+// and RATE_LIMIT_TRUST_USER_HEADER, unset variables keep default values
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig
+	if v := os.Getenv("RATE_LIMIT"); v != "" {
+		q, err := ParseQuota(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("RATE_LIMIT: %w", err)
+		}
+		cfg.Default = q
+	}
+	if v := os.Getenv("RATE_LIMIT_ROUTES"); v != "" {
+		routes, err := ParseRoutes(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+		}
+		cfg.Routes = routes
+	}
+	for _, key := range strings.Split(os.Getenv("RATE_LIMIT_API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			if cfg.APIKeys == nil {
+				cfg.APIKeys = make(map[string]bool)
+			}
+			cfg.APIKeys[key] = true
+		}
+	}
+	if v := os.Getenv("RATE_LIMIT_TRUST_USER_HEADER"); v != "" {
+		trust, err := strconv.ParseBool(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("RATE_LIMIT_TRUST_USER_HEADER must be a boolean, got %q", v)
+		}
+		cfg.TrustUserHeader = trust
+	}
+	return cfg, nil
+}
+
+// Decision tells whether a request is allowed and how much of the quota is left
+type Decision struct {
+	Allowed bool
+	Quota   Quota
+	// Remaining is how many requests can be made right now
+	Remaining int
+	// Reset is when the quota is fully refilled
+	Reset time.Duration
+	// RetryAfter is when the next request is allowed, zero for allowed requests
+	RetryAfter time.Duration
+}
+
+// seconds rounds the duration up to whole seconds
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// Headers returns RateLimit-* headers describing the decision and Retry-After for rejected requests,
+// nothing for unlimited routes
+func (d Decision) Headers() map[string]string {
+	if !d.Quota.Enabled() {
+		return nil
+	}
+	h := map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(d.Quota.Burst),
+		"RateLimit-Remaining": strconv.Itoa(d.Remaining),
+		"RateLimit-Reset":     seconds(d.Reset),
+		"RateLimit-Policy":    fmt.Sprintf("%d;w=%s", d.Quota.Burst, seconds(d.Quota.Period)),
+	}
+	if !d.Allowed {
+		h["Retry-After"] = seconds(d.RetryAfter)
+	}
+	return h
+}
+
+// bucket holds tokens of a client for a route, it is refilled lazily
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// sweepInterval is how often buckets refilled completely are forgotten
+const sweepInterval = time.Minute
+
+// Limiter keeps token buckets of clients, it is safe for concurrent use
+type Limiter struct {
+	cfg   Config
+	clock clock.Clock
+
+	mx        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// New creates a limiter enforcing the configuration
+func New(cfg Config, c clock.Clock) *Limiter {
+	return &Limiter{cfg: cfg, clock: c, buckets: make(map[string]*bucket)}
+}
+
+// Key identifies the client by the API key if it was issued, by the user if the header is trusted
+// and by the IP address otherwise
+func (l *Limiter) Key(apiKey, userID, ip string) string {
+	switch {
+	case apiKey != "" && l.cfg.APIKeys[apiKey]:
+		return "key:" + apiKey
+	case userID != "" && l.cfg.TrustUserHeader:
+		return "user:" + userID
+	}
+	return "ip:" + ip
+}
+
+// quota returns the quota of the route and the name of its bucket
+func (l *Limiter) quota(route string) (Quota, string) {
+	if q, ok := l.cfg.Routes[route]; ok {
+		return q, route
+	}
+	return l.cfg.Default, ""
+}
+
+// Allow spends a token of the client for the route if there is one
+func (l *Limiter) Allow(route, key string) Decision {
+	q, name := l.quota(route)
+	if !q.Enabled() {
+		return Decision{Allowed: true}
+	}
+	now := l.clock.Now()
+	rate := float64(q.Burst) / q.Period.Seconds()
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.sweep(now)
+
+	id := name + "\x00" + key
+	b, ok := l.buckets[id]
+	if !ok {
+		b = &bucket{tokens: float64(q.Burst), at: now}
+		l.buckets[id] = b
+	}
+	b.tokens = math.Min(float64(q.Burst), b.tokens+now.Sub(b.at).Seconds()*rate)
+	b.at = now
+
+	d := Decision{Quota: q}
+	if b.tokens >= 1 {
+		b.tokens--
+		d.Allowed = true
+	} else {
+		d.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = time.Duration((float64(q.Burst) - b.tokens) / rate * float64(time.Second))
+	return d
+}
+
+// sweep forgets buckets that would be full by now, so they don't pile up
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for id, b := range l.buckets {
+		route, _, _ := strings.Cut(id, "\x00")
+		q, _ := l.quota(route)
+		if !q.Enabled() || now.Sub(b.at) >= q.Period {
+			delete(l.buckets, id)
+		}
+	}
+}
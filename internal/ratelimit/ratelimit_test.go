@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"ads-server/internal/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	l := New(Config{APIKeys: map[string]bool{"abc": true}, TrustUserHeader: true}, c)
+	assert.Equal(t, "key:abc", l.Key("abc", "7", "10.0.0.1"))
+	assert.Equal(t, "user:7", l.Key("forged", "7", "10.0.0.1"), "unknown keys are ignored")
+	assert.Equal(t, "ip:10.0.0.1", l.Key("", "", "10.0.0.1"))
+
+	l = New(Config{}, c)
+	assert.Equal(t, "ip:10.0.0.1", l.Key("abc", "7", "10.0.0.1"), "without a proxy the user header is set by clients")
+}
+
+func TestParseQuota(t *testing.T) {
+	q, err := ParseQuota(" 10/1m")
+	assert.NoError(t, err)
+	assert.Equal(t, Quota{Burst: 10, Period: time.Minute}, q)
+	assert.Equal(t, "10/1m0s", q.String())
+
+	q, err = ParseQuota("off")
+	assert.NoError(t, err)
+	assert.False(t, q.Enabled())
+
+	for _, s := range []string{"10", "0/1m", "10/0s", "ten/1m", "10/minute"} {
+		_, err = ParseQuota(s)
+		assert.Error(t, err, s)
+	}
+
+	routes, err := ParseRoutes("POST /api/v1/ads=10/1h; /ad.AdService/CreateAd=off;")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Quota{
+		"POST /api/v1/ads":       {Burst: 10, Period: time.Hour},
+		"/ad.AdService/CreateAd": {},
+	}, routes)
+	_, err = ParseRoutes("POST /api/v1/ads")
+	assert.Error(t, err)
+}
+
+func TestLimiter(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	l := New(Config{
+		Default: Quota{Burst: 2, Period: time.Minute},
+		Routes: map[string]Quota{
+			"POST /api/v1/ads": {Burst: 1, Period: time.Hour},
+			"GET /healthz":     {},
+		},
+	}, c)
+
+	d := l.Allow("GET /api/v1/ads/:ad_id/info", "ip:1")
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 1, d.Remaining)
+	assert.Equal(t, 30*time.Second, d.Reset)
+	d = l.Allow("GET /api/v1/users/:id", "ip:1")
+	assert.True(t, d.Allowed, "routes without own quota share the default bucket")
+	assert.Equal(t, 0, d.Remaining)
+	d = l.Allow("GET /api/v1/users/:id", "ip:1")
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 30*time.Second, d.RetryAfter)
+	assert.Equal(t, map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"RateLimit-Policy":    "2;w=60",
+		"Retry-After":         "30",
+	}, d.Headers())
+
+	assert.True(t, l.Allow("GET /api/v1/users/:id", "ip:2").Allowed, "clients have own buckets")
+	assert.True(t, l.Allow("POST /api/v1/ads", "ip:1").Allowed, "routes with own quota have own buckets")
+	assert.False(t, l.Allow("POST /api/v1/ads", "ip:1").Allowed)
+	d = l.Allow("GET /healthz", "ip:1")
+	assert.True(t, d.Allowed)
+	assert.Empty(t, d.Headers(), "unlimited routes")
+
+	c.Advance(30 * time.Second)
+	assert.True(t, l.Allow("GET /api/v1/users/:id", "ip:1").Allowed, "a token is refilled")
+	assert.False(t, l.Allow("GET /api/v1/users/:id", "ip:1").Allowed)
+
+	c.Advance(2 * time.Minute)
+	l.Allow("GET /api/v1/users/:id", "ip:3")
+	assert.Len(t, l.buckets, 2, "full buckets are forgotten")
+	d = l.Allow("GET /api/v1/users/:id", "ip:1")
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 1, d.Remaining, "forgotten buckets start full")
+}
+
+func TestDisabled(t *testing.T) {
+	l := New(Config{}, clock.System)
+	d := l.Allow("POST /api/v1/ads", "ip:1")
+	assert.True(t, d.Allowed)
+	assert.Nil(t, d.Headers())
+}
@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporters supported by Setup
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+const defaultServiceName = "ads-server"
+
+// Config describes where spans are exported
+type Config struct {
+	// Exporter is one of ExporterNone, ExporterOTLP, ExporterStdout or ExporterFile
+	Exporter string
+	// FilePath is the file spans are appended to when Exporter is ExporterFile
+	FilePath string
+	// ServiceName is reported as service.name resource attribute
+	ServiceName string
+}
+
+// ConfigFromEnv reads TRACES_EXPORTER, TRACES_FILE and OTEL_SERVICE_NAME.
+// The OTLP exporter itself is configured by the standard OTEL_EXPORTER_OTLP_* variables.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Exporter:    os.Getenv("TRACES_EXPORTER"),
+		FilePath:    os.Getenv("TRACES_FILE"),
+		ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+	}
+	if cfg.FilePath == "" {
+		cfg.FilePath = "traces.json"
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = defaultServiceName
+	}
+	return cfg
+}
+
+// Setup installs the global tracer provider and W3C trace context propagator.
+// The returned function flushes pending spans and releases exporter resources.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   func() error
+		err      error
+	)
+	switch cfg.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracegrpc.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		var f *os.File
+		f, err = os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("can't open traces file: %w", err)
+		}
+		closer = f.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		if closer != nil {
+			_ = closer()
+		}
+		return nil, fmt.Errorf("can't create %s exporter: %w", cfg.Exporter, err)
+	}
+
+	name := cfg.ServiceName
+	if name == "" {
+		name = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(name)))
+	if err != nil {
+		// nothing was exported yet, the exporter only has to release its connection or file
+		_ = exporter.Shutdown(ctx)
+		if closer != nil {
+			_ = closer()
+		}
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			if cErr := closer(); err == nil {
+				err = cErr
+			}
+		}
+		return err
+	}, nil
+}
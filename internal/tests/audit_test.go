@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/audit"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/ports/grpc/pkg/interceptors"
+	"ads-server/internal/users"
+	grpc2 "ads-server/proto"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAuditLog(t *testing.T) {
+	client := getTestClient(admins, app.WithAuditLog(repo.NewAudit()))
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	admin, err := client.createUser(1, "Root", "admin@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	resp, err := client.send(http.MethodPut, fmt.Sprintf("/api/v1/ads/%d", ad.Data.ID),
+		map[string]any{"user_id": author.Data.ID, "title": "hello", "text": "there"},
+		map[string]string{"X-Request-ID": "req-42"})
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "req-42", resp.Header.Get("X-Request-ID"))
+
+	entries, err := client.auditEntries(admin.Data.ID, fmt.Sprintf("target_type=ad&target_id=%d", ad.Data.ID))
+	assert.NoError(t, err)
+	assert.Len(t, entries.Data, 2)
+	assert.Equal(t, "ad.create", entries.Data[0].Action)
+	assert.Nil(t, entries.Data[0].Before)
+
+	update := entries.Data[1]
+	assert.Equal(t, "ad.update", update.Action)
+	assert.Equal(t, author.Data.ID, update.ActorID)
+	assert.Equal(t, "req-42", update.RequestID)
+	assert.Equal(t, "http", update.Transport)
+	var before, after struct{ Text string }
+	assert.NoError(t, json.Unmarshal(update.Before, &before))
+	assert.NoError(t, json.Unmarshal(update.After, &after))
+	assert.Equal(t, "world", before.Text)
+	assert.Equal(t, "there", after.Text)
+
+	// registration, verification, ad creation and update of the author
+	entries, err = client.auditEntries(admin.Data.ID, fmt.Sprintf("actor_id=%d", author.Data.ID))
+	assert.NoError(t, err)
+	var actions []string
+	for _, e := range entries.Data {
+		actions = append(actions, e.Action)
+	}
+	assert.Equal(t, []string{"user.create", "user.verify", "ad.create", "ad.update"}, actions)
+
+	entries, err = client.auditEntries(admin.Data.ID, "from="+url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339)))
+	assert.NoError(t, err)
+	assert.Empty(t, entries.Data)
+
+	_, err = client.auditEntries(admin.Data.ID, "from=yesterday")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.auditEntries(author.Data.ID, "")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	v, err := client.verifyAudit(admin.Data.ID)
+	assert.NoError(t, err)
+	assert.True(t, v.Data.Valid)
+	assert.Equal(t, int64(6), v.Data.Entries)
+	_, err = client.verifyAudit(author.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestAuditLogRollback(t *testing.T) {
+	ctx := context.Background()
+	log := repo.NewAudit()
+	userRepo := failingUserRepo{repo.NewUser()}
+	a := app.NewApp(repo.NewAd(), userRepo, app.WithDeletionPolicy(app.DeleteCascade), app.WithAuditLog(log))
+
+	user := users.New("James", "james@example.com")
+	_, err := userRepo.Create(ctx, user)
+	assert.NoError(t, err)
+	_, _, err = a.CreateAd(ctx, user.ID, "hello", "world", "")
+	assert.NoError(t, err)
+
+	_, err = a.DeleteUser(ctx, user.ID, 0)
+	assert.Error(t, err)
+
+	entries, err := log.Query(ctx, audit.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "entries of the failed deletion are rolled back")
+	assert.Equal(t, audit.ActionAdCreate, entries[0].Action)
+	assert.Equal(t, audit.TransportInternal, entries[0].Transport)
+	assert.True(t, audit.Verify(entries).Valid)
+}
+
+func TestAuditLogCascadeDeletion(t *testing.T) {
+	ctx := context.Background()
+	log := repo.NewAudit()
+	userRepo := repo.NewUser()
+	a := app.NewApp(repo.NewAd(), userRepo, app.WithDeletionPolicy(app.DeleteCascade), app.WithAuditLog(log))
+
+	user := users.New("James", "james@example.com")
+	_, err := userRepo.Create(ctx, user)
+	assert.NoError(t, err)
+	ad, _, err := a.CreateAd(ctx, user.ID, "hello", "world", "")
+	assert.NoError(t, err)
+
+	_, err = a.DeleteUser(ctx, user.ID, 0)
+	assert.NoError(t, err)
+
+	entries, err := log.Query(ctx, audit.Filter{TargetType: "ad", TargetID: &ad.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, audit.ActionAdDelete, entries[1].Action)
+		assert.Contains(t, string(entries[1].Before), `"world"`, "the state of the ad before the deletion is recorded")
+	}
+}
+
+func TestGRPCAuditLog(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(interceptors.RequestID))
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	mb := &mailbox{}
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithMailSender(mb), admins, app.WithAuditLog(repo.NewAudit())))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	admin, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Olga", Email: "admin@example.com"})
+	assert.NoError(t, err)
+	_, err = client.ConfirmEmail(ctx, &grpc2.ConfirmEmailRequest{Token: mb.token("admin@example.com")})
+	assert.NoError(t, err)
+
+	var header metadata.MD
+	_, err = client.CreateAd(metadata.AppendToOutgoingContext(ctx, "x-request-id", "req-7"),
+		&grpc2.CreateAdRequest{UserId: user.Id, Title: "hello", Text: "world"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"req-7"}, header.Get("x-request-id"))
+
+	list, err := client.ListAuditEntries(ctx, &grpc2.ListAuditEntriesRequest{UserId: admin.Id, ActorId: &user.Id, TargetType: "ad"})
+	assert.NoError(t, err)
+	assert.Len(t, list.List, 1)
+	assert.Equal(t, "ad.create", list.List[0].Action)
+	assert.Equal(t, "req-7", list.List[0].RequestId)
+	assert.Equal(t, "grpc", list.List[0].Transport)
+	assert.Empty(t, list.List[0].Before)
+	assert.NotEmpty(t, list.List[0].After)
+
+	_, err = client.ListAuditEntries(ctx, &grpc2.ListAuditEntriesRequest{UserId: user.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	v, err := client.VerifyAuditLog(ctx, &grpc2.VerifyAuditLogRequest{UserId: admin.Id})
+	assert.NoError(t, err)
+	assert.True(t, v.Valid)
+	assert.Equal(t, int64(4), v.Entries)
+}
@@ -52,7 +52,7 @@ func BenchmarkGRPCRequest(b *testing.B) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
@@ -67,7 +67,7 @@ func BenchmarkGRPCRequest(b *testing.B) {
 
 func BenchmarkHTTPRequest(b *testing.B) {
 	client := getTestClient()
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"testing"
+
+	"ads-server/internal/app"
+	"ads-server/internal/content"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// filters masks banned words and flags links
+func filters() app.Option {
+	return app.WithContentFilters(content.NewPipeline(
+		content.NewWords(content.Mask, "idiot", "дурак"),
+		content.NewLinks(content.Flag),
+	))
+}
+
+func TestContentFilters(t *testing.T) {
+	client := getTestClient(moderators, filters(), app.WithDuplicates(app.DefaultDuplicateConfig))
+	seller, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	moderator, err := client.createUser(1, "Maria", "moderator@example.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(seller.Data.ID, "bike", "only an Idiot would miss it, продам дураку? нет, дурак")
+	assert.NoError(t, err)
+	assert.Equal(t, "only an ***** would miss it, продам дураку? нет, *****", ad.Data.Text)
+	if assert.Len(t, ad.Data.ContentFlags, 2) {
+		assert.Equal(t, "words", ad.Data.ContentFlags[0].Checker)
+		assert.True(t, ad.Data.ContentFlags[0].Masked)
+	}
+	_, err = client.changeAdStatus(seller.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err, "masked content doesn't hold the ad")
+
+	_, err = client.createAd(seller.Data.ID, "Bike", "only an  ***** would miss it, продам дураку? нет, *****")
+	assert.ErrorIs(t, err, ErrConflict, "the author has the same ad")
+
+	ad, err = client.updateAd(seller.Data.ID, ad.Data.ID, "bike", "details at example.com/bike")
+	assert.NoError(t, err)
+	assert.False(t, ad.Data.Published, "held ads are unpublished")
+	if assert.Len(t, ad.Data.ContentFlags, 1) {
+		assert.Equal(t, "links", ad.Data.ContentFlags[0].Checker)
+		assert.False(t, ad.Data.ContentFlags[0].Masked)
+	}
+	_, err = client.changeAdStatus(seller.Data.ID, ad.Data.ID, true)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity)
+
+	_, err = client.listHeldAds(seller.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	held, err := client.listHeldAds(moderator.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, held.Data, 1) {
+		assert.Equal(t, ad.Data.ID, held.Data[0].ID)
+	}
+
+	_, err = client.approveAd(moderator.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	ad, err = client.changeAdStatus(seller.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published)
+	held, err = client.listHeldAds(moderator.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, held.Data)
+
+	ad, err = client.updateAd(seller.Data.ID, ad.Data.ID, "bike", "red bike")
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published)
+	assert.Empty(t, ad.Data.ContentFlags, "flags of earlier revisions are cleared")
+}
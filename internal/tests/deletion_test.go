@@ -0,0 +1,264 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/ads"
+	"ads-server/internal/app"
+	"ads-server/internal/errs"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/users"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// userWithAds creates a verified user owning a published and an unpublished ad
+func userWithAds(t *testing.T, client *testClient) (userID int64, published int64, draft int64) {
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+
+	first, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(user.Data.ID, first.Data.ID, true)
+	assert.NoError(t, err)
+
+	second, err := client.createAd(user.Data.ID, "draft", "text")
+	assert.NoError(t, err)
+
+	return user.Data.ID, first.Data.ID, second.Data.ID
+}
+
+func TestDeleteUserAnonymize(t *testing.T) {
+	client := getTestClient(app.WithDeletionPolicy(app.DeleteAnonymize))
+	userID, published, draft := userWithAds(t, client)
+
+	report, err := client.deleteUser(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "anonymize", report.Data.Policy)
+	assert.ElementsMatch(t, []int64{published, draft}, report.Data.AnonymizedAds)
+	assert.Empty(t, report.Data.DeletedAds)
+
+	ad, err := client.getAdByID(published)
+	assert.NoError(t, err)
+	assert.False(t, ad.Data.Published)
+	assert.Equal(t, ads.AnonymousAuthorID, ad.Data.AuthorID)
+
+	list, err := client.listAds()
+	assert.NoError(t, err)
+	assert.Empty(t, list.Data)
+}
+
+func TestDeleteUserCascade(t *testing.T) {
+	client := getTestClient(app.WithDeletionPolicy(app.DeleteCascade))
+	userID, published, draft := userWithAds(t, client)
+
+	other, err := client.createUser(1, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+	kept, err := client.createAd(other.Data.ID, "keep", "me")
+	assert.NoError(t, err)
+
+	trashed, err := client.createAd(userID, "old", "ad")
+	assert.NoError(t, err)
+	assert.NoError(t, client.deleteAd(userID, trashed.Data.ID))
+
+	report, err := client.deleteUser(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cascade", report.Data.Policy)
+	assert.ElementsMatch(t, []int64{published, draft, trashed.Data.ID}, report.Data.DeletedAds, "ads already in trash are reported")
+
+	for _, id := range []int64{published, draft} {
+		_, err = client.getAdByID(id)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	_, err = client.getAdByID(kept.Data.ID)
+	assert.NoError(t, err)
+}
+
+func TestDeleteUserBlock(t *testing.T) {
+	client := getTestClient(app.WithDeletionPolicy(app.DeleteBlock))
+	userID, published, draft := userWithAds(t, client)
+
+	_, err := client.deleteUser(userID)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity)
+
+	_, err = client.getUser(userID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, client.deleteAd(userID, published))
+	assert.NoError(t, client.deleteAd(userID, draft))
+	_, err = client.deleteUser(userID)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "ads in trash block the deletion too")
+
+	other, err := client.createUser(1, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+	report, err := client.deleteUser(other.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "block", report.Data.Policy)
+}
+
+// failingUserRepo can't delete users, so the deletion has to be rolled back
+type failingUserRepo struct {
+	app.UserRepository
+}
+
+func (failingUserRepo) Delete(context.Context, int64, int64) error {
+	return errs.New(errs.Unavailable, "storage is unavailable")
+}
+
+func TestDeleteUserRollback(t *testing.T) {
+	for _, policy := range []app.DeletionPolicy{app.DeleteCascade, app.DeleteAnonymize} {
+		t.Run(string(policy), func(t *testing.T) {
+			ctx := context.Background()
+			adRepo := repo.NewAd()
+			userRepo := failingUserRepo{repo.NewUser()}
+			a := app.NewApp(adRepo, userRepo, app.WithDeletionPolicy(policy))
+
+			user := users.New("James", "james@example.com")
+			_, err := userRepo.Create(ctx, user)
+			assert.NoError(t, err)
+			ad, _, err := a.CreateAd(ctx, user.ID, "hello", "world", "")
+			assert.NoError(t, err)
+
+			_, err = a.DeleteUser(ctx, user.ID, 0)
+			assert.Error(t, err)
+
+			stored, err := a.GetAdByID(ctx, ad.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, user.ID, stored.AuthorID)
+		})
+	}
+}
+
+func TestGRPCDeleteUserReport(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithDeletionPolicy(app.DeleteCascade)))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: user.Id, Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+
+	res, err := client.DeleteUser(ctx, &grpc2.DeleteUserRequest{Id: user.Id})
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, "cascade", res.Policy)
+	assert.Equal(t, []int64{ad.Id}, res.DeletedAdIds)
+
+	_, err = client.DeleteUser(ctx, &grpc2.DeleteUserRequest{Id: user.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCDeleteUserBlocked(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithDeletionPolicy(app.DeleteBlock)))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	_, err = client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: user.Id, Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+
+	res, err := client.DeleteUser(ctx, &grpc2.DeleteUserRequest{Id: user.Id})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.False(t, res.GetSuccess())
+
+	_, err = client.GetUser(ctx, &grpc2.GetUserRequest{Id: &user.Id})
+	assert.NoError(t, err)
+}
+
+// failingTokenRepo can't store verification tokens, so registration has to be rolled back
+type failingTokenRepo struct {
+	app.UserRepository
+}
+
+func (failingTokenRepo) SaveToken(context.Context, *users.VerificationToken) error {
+	return errs.New(errs.Unavailable, "storage is unavailable")
+}
+
+func TestCreateUserRollback(t *testing.T) {
+	ctx := context.Background()
+	userRepo := repo.NewUser()
+	a := app.NewApp(repo.NewAd(), failingTokenRepo{userRepo})
+
+	_, err := a.CreateUser(ctx, "James", "james@example.com")
+	assert.Error(t, err)
+
+	_, err = userRepo.GetByEmail(ctx, "james@example.com")
+	assert.ErrorIs(t, err, errs.UserNotFoundError)
+
+	// the email must be free again
+	_, err = app.NewApp(repo.NewAd(), userRepo).CreateUser(ctx, "James", "james@example.com")
+	assert.NoError(t, err)
+}
@@ -1,8 +1,14 @@
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
+
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/ads"
+	"ads-server/internal/users"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -10,12 +16,12 @@ import (
 func TestChangeStatusAdOfAnotherUser(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
 
-	_, err = client.createUser(1, "Name", "Mas")
+	_, err = client.createUser(1, "Name", "mas@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -30,12 +36,12 @@ func TestChangeStatusAdOfAnotherUser(t *testing.T) {
 func TestUpdateAdOfAnotherUser(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
 
-	_, err = client.createUser(1, "Name", "Mas")
+	_, err = client.createUser(1, "Name", "mas@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -50,7 +56,7 @@ func TestUpdateAdOfAnotherUser(t *testing.T) {
 func TestCreateAd_ID(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -67,3 +73,38 @@ func TestCreateAd_ID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, resp.Data.ID, int64(2))
 }
+
+func TestRepositoriesReturnCopies(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	u := users.New("James", "ostin@example.com")
+	_, err := userRepo.Create(ctx, u)
+	assert.NoError(t, err)
+	id, err := adRepo.Create(ctx, ads.New(u.ID, "hello", "world", time.Now()))
+	assert.NoError(t, err)
+
+	for _, get := range []func() (*ads.Ad, error){
+		func() (*ads.Ad, error) { return adRepo.GetByID(ctx, id) },
+		func() (*ads.Ad, error) { return adRepo.Update(ctx, id, u.ID, "hello", "world", 0) },
+		func() (*ads.Ad, error) { return adRepo.Publish(ctx, id, u.ID, true, 0) },
+	} {
+		ad, err := get()
+		assert.NoError(t, err)
+		ad.Title = "changed"
+		stored, err := adRepo.GetByID(ctx, id)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", stored.Title, "changes of the ad returned don't reach the storage")
+	}
+
+	for _, get := range []func() (*users.User, error){
+		func() (*users.User, error) { return userRepo.Get(ctx, u.ID) },
+		func() (*users.User, error) { return userRepo.Update(ctx, u.ID, "James", "ostin@example.com", 0) },
+	} {
+		user, err := get()
+		assert.NoError(t, err)
+		user.Name = "changed"
+		stored, err := userRepo.Get(ctx, u.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "James", stored.Name, "changes of the user returned don't reach the storage")
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"ads-server/internal/app"
+	"ads-server/internal/ports/presenter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	bikeText  = "Selling a red mountain bike in good condition, 21 gears, new tyres, pick up in the city centre"
+	bikeAgain = "Selling red mountain bike in good condition, 21 gears, new tyres, pick up in the city center"
+	flatText  = "Renting a two room flat near the railway station, furnished, long term only, no pets"
+)
+
+func TestDuplicateMerge(t *testing.T) {
+	client := getTestClient(moderators, app.WithDuplicates(app.DuplicateConfig{
+		MaxDistance: 10,
+		Author:      app.DuplicatesMerge,
+		Global:      app.DuplicatesFlag,
+	}))
+	seller, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Anna", "anna@example.com")
+	assert.NoError(t, err)
+	moderator, err := client.createUser(2, "Maria", "moderator@example.com")
+	assert.NoError(t, err)
+
+	bike, err := client.createAd(seller.Data.ID, "Mountain bike", bikeText)
+	assert.NoError(t, err)
+	resp, err := client.send(http.MethodPost, "/api/v1/ads",
+		map[string]any{"user_id": seller.Data.ID, "title": "Mountain bike!", "text": bikeAgain}, nil)
+	assert.NoError(t, err)
+	var again adResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&again))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, strconv.FormatInt(bike.Data.ID, 10), resp.Header.Get(presenter.MergedIntoHeader), "the merge is reported")
+	assert.Equal(t, bike.Data.ID, again.Data.ID, "the repeated ad is merged into the first one")
+	assert.Equal(t, bikeAgain, again.Data.Text)
+	own, err := client.listUserAds(seller.Data.ID, seller.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, own.Data, 1)
+
+	elsewhere, err := client.createAdInCategory(seller.Data.ID, "Mountain bike", bikeAgain, "sport")
+	assert.NoError(t, err)
+	assert.NotEqual(t, bike.Data.ID, elsewhere.Data.ID, "ads of other categories are not merged")
+	if assert.Len(t, elsewhere.Data.ContentFlags, 1) {
+		assert.Equal(t, "near-duplicates", elsewhere.Data.ContentFlags[0].Checker)
+	}
+	assert.NoError(t, client.deleteAd(seller.Data.ID, elsewhere.Data.ID))
+
+	flat, err := client.createAd(seller.Data.ID, "Flat", flatText)
+	assert.NoError(t, err)
+	assert.NotEqual(t, bike.Data.ID, flat.Data.ID)
+	_, err = client.updateAd(seller.Data.ID, flat.Data.ID, "Mountain bike", bikeText)
+	assert.ErrorIs(t, err, ErrConflict, "updates are not merged")
+	_, err = client.updateAd(seller.Data.ID, bike.Data.ID, "Mountain bike", bikeText)
+	assert.NoError(t, err, "an ad doesn't duplicate itself")
+
+	copied, err := client.createAd(other.Data.ID, "Mountain bike", bikeAgain)
+	assert.NoError(t, err)
+	if assert.Len(t, copied.Data.ContentFlags, 1) {
+		assert.Equal(t, "near-duplicates", copied.Data.ContentFlags[0].Checker)
+		assert.False(t, copied.Data.ContentFlags[0].Masked)
+	}
+	_, err = client.changeAdStatus(other.Data.ID, copied.Data.ID, true)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "flagged ads wait for a moderator")
+
+	_, err = client.listDuplicateClusters(seller.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	clusters, err := client.listDuplicateClusters(moderator.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, clusters.Data, 1) && assert.Len(t, clusters.Data[0].Ads, 2) {
+		assert.Equal(t, bike.Data.ID, clusters.Data[0].Ads[0].ID)
+		assert.Equal(t, copied.Data.ID, clusters.Data[0].Ads[1].ID)
+	}
+}
+
+func TestDuplicateDefault(t *testing.T) {
+	client := getTestClient(app.WithDuplicates(app.DefaultDuplicateConfig))
+	seller, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Anna", "anna@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.createAd(seller.Data.ID, "Mountain bike", bikeText)
+	assert.NoError(t, err)
+	_, err = client.createAd(seller.Data.ID, "Mountain bike", bikeAgain)
+	assert.ErrorIs(t, err, ErrConflict, "the author's repeats are rejected unless merging is enabled")
+	copied, err := client.createAd(other.Data.ID, "Mountain bike", bikeAgain)
+	assert.NoError(t, err)
+	assert.Len(t, copied.Data.ContentFlags, 1, "repeats of other authors are flagged")
+}
+
+func TestDuplicateReject(t *testing.T) {
+	client := getTestClient(app.WithDuplicates(app.DuplicateConfig{
+		MaxDistance: 10,
+		Author:      app.DuplicatesReject,
+		Global:      app.DuplicatesReject,
+	}))
+	seller, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Anna", "anna@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.createAd(seller.Data.ID, "Mountain bike", bikeText)
+	assert.NoError(t, err)
+	_, err = client.createAd(seller.Data.ID, "Mountain bike", bikeAgain)
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = client.createAd(other.Data.ID, "Mountain bike", bikeAgain)
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = client.createAd(other.Data.ID, "Flat", flatText)
+	assert.NoError(t, err)
+}
+
+func TestDuplicatePolicyDecidesRepeats(t *testing.T) {
+	client := getTestClient(filters(), app.WithDuplicates(app.DuplicateConfig{
+		MaxDistance: 10,
+		Author:      app.DuplicatesFlag,
+		Global:      app.DuplicatesIgnore,
+	}))
+	seller, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.createAd(seller.Data.ID, "Mountain bike", bikeText)
+	assert.NoError(t, err)
+	again, err := client.createAd(seller.Data.ID, "mountain  bike", bikeText)
+	assert.NoError(t, err, "content filters don't reject repeats on their own")
+	if assert.Len(t, again.Data.ContentFlags, 1) {
+		assert.Equal(t, "near-duplicates", again.Data.ContentFlags[0].Checker, "the author policy applies to an exact repeat")
+	}
+}
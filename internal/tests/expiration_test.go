@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/ads"
+	"ads-server/internal/app"
+	"ads-server/internal/clock"
+	"ads-server/internal/errs"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/users"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAdExpiration(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	c := clock.NewFake(start)
+	adRepo, userRepo := repo.NewAd(repo.WithClock(c)), repo.NewUser(repo.WithClock(c))
+	mb := &mailbox{}
+	a := app.NewApp(adRepo, userRepo, app.WithClock(c), app.WithMailSender(mb), app.WithExpiration(app.ExpirationConfig{
+		Lifetime:      time.Hour,
+		Categories:    map[string]time.Duration{"jobs": 2 * time.Hour},
+		WarnBefore:    30 * time.Minute,
+		CheckInterval: time.Minute,
+	}))
+
+	author := users.New("James", "james@example.com")
+	author.Verified = true
+	_, err := userRepo.Create(ctx, author)
+	assert.NoError(t, err)
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "hello", "world", "")
+	assert.NoError(t, err)
+	assert.Equal(t, start, ad.CDate)
+	assert.Equal(t, start.Add(time.Hour), ad.ExpiresAt)
+	job, _, err := a.CreateAd(ctx, author.ID, "hiring", "gophers", " Jobs ")
+	assert.NoError(t, err)
+	assert.Equal(t, "jobs", job.Category)
+	assert.Equal(t, start.Add(2*time.Hour), job.ExpiresAt)
+	_, err = a.PublishAd(ctx, ad.ID, author.ID, true, 0)
+	assert.NoError(t, err)
+
+	report, err := a.Expire(ctx, start.Add(45*time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, report.Archived)
+	assert.Equal(t, []int64{ad.ID}, report.Warned)
+	assert.Equal(t, 1, mb.count(author.Email))
+
+	report, err = a.Expire(ctx, start.Add(50*time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, report.Warned, "authors are warned once")
+
+	report, err = a.Expire(ctx, start.Add(61*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{ad.ID}, report.Archived)
+
+	archived, err := a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.True(t, archived.Archived())
+	assert.False(t, archived.Published)
+
+	_, err = a.PublishAd(ctx, ad.ID, author.ID, true, 0)
+	assert.ErrorIs(t, err, errs.AdArchivedError)
+	_, err = a.ExtendAd(ctx, ad.ID, author.ID, time.Hour, 0)
+	assert.ErrorIs(t, err, errs.AdArchivedError)
+
+	now := c.Advance(2 * time.Hour)
+	renewed, err := a.RenewAd(ctx, ad.ID, author.ID, 0)
+	assert.NoError(t, err)
+	assert.False(t, renewed.Archived())
+	assert.Equal(t, now.Add(time.Hour), renewed.ExpiresAt)
+	_, err = a.PublishAd(ctx, ad.ID, author.ID, true, 0)
+	assert.NoError(t, err)
+
+	history, err := a.ListRevisions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	var actions []string
+	for _, r := range history {
+		actions = append(actions, string(r.Action))
+	}
+	assert.Equal(t, []string{"create", "publish", "archive", "renew", "publish"}, actions)
+}
+
+func TestExtendAd(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	short := app.NewApp(adRepo, userRepo, app.WithExpiration(app.ExpirationConfig{Lifetime: time.Hour, CheckInterval: time.Minute}))
+	long := app.NewApp(adRepo, userRepo, app.WithExpiration(app.ExpirationConfig{Lifetime: 10 * time.Hour, CheckInterval: time.Minute}))
+
+	author, err := short.CreateUser(ctx, "James", "james@example.com")
+	assert.NoError(t, err)
+	ad, _, err := short.CreateAd(ctx, author.ID, "hello", "world", "")
+	assert.NoError(t, err)
+
+	_, err = short.ExtendAd(ctx, ad.ID, author.ID, time.Hour, 0)
+	assert.ErrorIs(t, err, errs.ValidationError, "can't be extended beyond a lifetime from now")
+	_, err = short.ExtendAd(ctx, ad.ID, author.ID, 0, 0)
+	assert.ErrorIs(t, err, errs.ValidationError)
+
+	expiresAt := ad.ExpiresAt
+	extended, err := long.ExtendAd(ctx, ad.ID, author.ID, 2*time.Hour, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, expiresAt.Add(2*time.Hour), extended.ExpiresAt)
+	history, err := long.ListRevisions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, ads.ActionExtend, history[1].Action)
+	}
+
+	_, err = long.ExtendAd(ctx, ad.ID, author.ID+1, time.Hour, 0)
+	assert.Error(t, err)
+}
+
+func TestRenewAdHTTP(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAdInCategory(author.Data.ID, "hello", "world", "pets")
+	assert.NoError(t, err)
+	assert.Equal(t, "pets", ad.Data.Category)
+	assert.NotEmpty(t, ad.Data.ExpiresAt)
+	assert.Empty(t, ad.Data.ArchivedAt)
+
+	renewed, err := client.renewAd(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Data.Version+1, renewed.Data.Version)
+	_, err = client.renewAd(other.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.extendAd(author.Data.ID, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrBadRequest, "a fresh ad already has a full lifetime")
+	_, err = client.extendAd(author.Data.ID, ad.Data.ID, -1)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestGRPCRenewAd(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser()))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: user.Id, Title: "hello", Text: "world", Category: "cars"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cars", ad.Category)
+	assert.NotNil(t, ad.CreatedAt)
+	assert.Equal(t, ad.CreatedAt.AsTime(), ad.UpdatedAt.AsTime())
+	assert.NotNil(t, ad.ExpiresAt)
+	assert.Nil(t, ad.ArchivedAt)
+
+	renewed, err := client.RenewAd(ctx, &grpc2.RenewAdRequest{AdId: ad.Id, UserId: user.Id, ExpectedVersion: ad.Version})
+	assert.NoError(t, err)
+	assert.False(t, renewed.ExpiresAt.AsTime().Before(ad.ExpiresAt.AsTime()))
+	assert.Equal(t, ad.CreatedAt.AsTime(), renewed.CreatedAt.AsTime())
+	assert.False(t, renewed.UpdatedAt.AsTime().Before(ad.UpdatedAt.AsTime()))
+
+	_, err = client.RenewAd(ctx, &grpc2.RenewAdRequest{AdId: ad.Id, UserId: user.Id, ExpectedVersion: ad.Version})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = client.ExtendAd(ctx, &grpc2.ExtendAdRequest{AdId: ad.Id, UserId: user.Id, Days: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
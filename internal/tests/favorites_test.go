@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/clock"
+	"ads-server/internal/errs"
+	grpcPort "ads-server/internal/ports/grpc"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestFavorites(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	buyer, err := client.createUser(1, "Anna", "anna@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(2, "Ivan", "ivan@example.com")
+	assert.NoError(t, err)
+
+	first, err := client.createAd(author.Data.ID, "bike", "red bike")
+	assert.NoError(t, err)
+	second, err := client.createAd(author.Data.ID, "car", "old car")
+	assert.NoError(t, err)
+
+	err = client.addFavorite(buyer.Data.ID, first.Data.ID)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "drafts can't be saved")
+
+	_, err = client.changeAdStatus(author.Data.ID, first.Data.ID, true)
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.ID, second.Data.ID, true)
+	assert.NoError(t, err)
+
+	err = client.addFavorite(author.Data.ID, first.Data.ID)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "own ads can't be saved")
+
+	assert.NoError(t, client.addFavorite(buyer.Data.ID, first.Data.ID))
+	assert.NoError(t, client.addFavorite(buyer.Data.ID, first.Data.ID), "saving again is a no-op")
+	assert.NoError(t, client.addFavorite(buyer.Data.ID, second.Data.ID))
+	assert.NoError(t, client.addFavorite(other.Data.ID, first.Data.ID))
+
+	favorites, err := client.listFavorites(buyer.Data.ID, buyer.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, favorites.Data, 2) {
+		assert.Equal(t, second.Data.ID, favorites.Data[0].Ad.ID, "latest first")
+		assert.Equal(t, first.Data.ID, favorites.Data[1].Ad.ID)
+	}
+
+	_, err = client.listFavorites(other.Data.ID, buyer.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	count, err := client.countFavorites(author.Data.ID, first.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count.Data.Count)
+
+	_, err = client.countFavorites(buyer.Data.ID, first.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden, "only the author sees the count")
+
+	assert.NoError(t, client.removeFavorite(other.Data.ID, first.Data.ID))
+	assert.ErrorIs(t, client.removeFavorite(other.Data.ID, first.Data.ID), ErrNotFound)
+	count, err = client.countFavorites(author.Data.ID, first.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count.Data.Count)
+
+	// deleted ads leave favorites
+	assert.NoError(t, client.deleteAd(author.Data.ID, second.Data.ID))
+	favorites, err = client.listFavorites(buyer.Data.ID, buyer.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, favorites.Data, 1) {
+		assert.Equal(t, first.Data.ID, favorites.Data[0].Ad.ID)
+	}
+}
+
+func TestFavoriteNotifications(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	buyer, err := client.createUser(1, "Anna", "anna@example.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.ID, "bike", "red bike")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.NoError(t, client.addFavorite(buyer.Data.ID, ad.Data.ID))
+
+	before := client.mailbox.count("anna@example.com")
+
+	_, err = client.updateAd(author.Data.ID, ad.Data.ID, "bike", "red bike, now cheaper")
+	assert.NoError(t, err)
+	assert.Equal(t, before+1, client.mailbox.count("anna@example.com"))
+
+	_, err = client.updateAd(author.Data.ID, ad.Data.ID, "bike", "red bike, now cheaper")
+	assert.NoError(t, err)
+	assert.Equal(t, before+1, client.mailbox.count("anna@example.com"), "nothing changed")
+
+	_, err = client.changeAdStatus(author.Data.ID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, before+2, client.mailbox.count("anna@example.com"))
+
+	_, err = client.changeAdStatus(author.Data.ID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, before+2, client.mailbox.count("anna@example.com"), "already unpublished")
+}
+
+func TestFavoritesExpiryAndUserDeletion(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	c := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	mb := &mailbox{}
+	a := app.NewApp(adRepo, userRepo, app.WithClock(c), app.WithMailSender(mb),
+		app.WithExpiration(app.ExpirationConfig{Lifetime: time.Hour}))
+
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	buyer := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "bike", "red bike", "")
+	assert.NoError(t, err)
+	_, err = a.PublishAd(ctx, ad.ID, author.ID, true, 0)
+	assert.NoError(t, err)
+	_, err = a.AddFavorite(ctx, buyer.ID, ad.ID)
+	assert.NoError(t, err)
+
+	c.Advance(2 * time.Hour)
+	report, err := a.Expire(ctx, c.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{ad.ID}, report.Archived)
+	assert.Equal(t, 1, mb.count("anna@example.com"))
+
+	_, err = a.DeleteUser(ctx, buyer.ID, 0)
+	assert.NoError(t, err)
+	watchers, err := adRepo.Watchers(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, watchers)
+
+	_, err = a.AddFavorite(ctx, buyer.ID, ad.ID)
+	assert.ErrorIs(t, err, errs.UserNotFoundError)
+}
+
+func TestGRPCFavorites(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	svc := grpcPort.NewAdService(app.NewApp(adRepo, userRepo))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	buyer := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "bike", Text: "red bike"})
+	assert.NoError(t, err)
+	_, err = client.AddFavorite(ctx, &grpc2.FavoriteRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: author.ID, Published: true})
+	assert.NoError(t, err)
+
+	f, err := client.AddFavorite(ctx, &grpc2.FavoriteRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Id, f.AdId)
+	assert.NotNil(t, f.CreatedAt)
+
+	list, err := client.ListFavorites(ctx, &grpc2.ListFavoritesRequest{Id: buyer.ID, UserId: buyer.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, list.List, 1) {
+		assert.Equal(t, ad.Id, list.List[0].Ad.Id)
+		assert.Equal(t, f.CreatedAt.AsTime(), list.List[0].SavedAt.AsTime())
+	}
+
+	count, err := client.CountFavorites(ctx, &grpc2.CountFavoritesRequest{AdId: ad.Id, UserId: author.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count.Count)
+
+	_, err = client.CountFavorites(ctx, &grpc2.CountFavoritesRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.RemoveFavorite(ctx, &grpc2.FavoriteRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.NoError(t, err)
+	_, err = client.RemoveFavorite(ctx, &grpc2.FavoriteRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
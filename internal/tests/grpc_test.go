@@ -51,7 +51,7 @@ func TestGRPCCreateUser(t *testing.T) {
 	})
 
 	client := grpc2.NewAdServiceClient(conn)
-	res, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	res, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	assert.NoError(t, err, "client.GetUser")
 
 	assert.Equal(t, "Oleg", res.Name)
@@ -92,7 +92,7 @@ func TestGRPCUpdateUser(t *testing.T) {
 	})
 
 	client := grpc2.NewAdServiceClient(conn)
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	assert.NoError(t, err, "client.GetUser")
 
 	res, err := client.UpdateUser(ctx, &grpc2.UpdateUserRequest{
@@ -142,7 +142,7 @@ func TestGRPCGetExistingUser(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
@@ -232,7 +232,7 @@ func TestGRPCDeleteUser(t *testing.T) {
 	})
 
 	client := grpc2.NewAdServiceClient(conn)
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
@@ -283,7 +283,7 @@ func TestGRPCCreateAd(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
@@ -311,7 +311,8 @@ func TestGRPCChangeAdStatus(t *testing.T) {
 		srv.Stop()
 	})
 
-	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser()))
+	mb := &mailbox{}
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithMailSender(mb)))
 	grpc2.RegisterAdServiceServer(srv, svc)
 
 	go func() {
@@ -336,11 +337,14 @@ func TestGRPCChangeAdStatus(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
 
+	_, err = client.ConfirmEmail(ctx, &grpc2.ConfirmEmailRequest{Token: mb.token("oleg@example.com")})
+	assert.NoError(t, err)
+
 	_, err = client.CreateAd(ctx, &grpc2.CreateAdRequest{
 		Title:  "Hello",
 		Text:   "World",
@@ -397,12 +401,12 @@ func TestGRPCChangeAdStatusFromAnotherUser(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Anton"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Anton", Email: "anton@example.com"})
 	if err != nil {
 		return
 	}
@@ -462,7 +466,7 @@ func TestGRPCUpdateAd(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
@@ -524,7 +528,7 @@ func TestGRPCDeleteAd(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
@@ -562,7 +566,8 @@ func TestGRPCListAdsNoTitle(t *testing.T) {
 		srv.Stop()
 	})
 
-	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser()))
+	mb := &mailbox{}
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithMailSender(mb)))
 	grpc2.RegisterAdServiceServer(srv, svc)
 
 	go func() {
@@ -587,11 +592,14 @@ func TestGRPCListAdsNoTitle(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
 
+	_, err = client.ConfirmEmail(ctx, &grpc2.ConfirmEmailRequest{Token: mb.token("oleg@example.com")})
+	assert.NoError(t, err)
+
 	_, err = client.CreateAd(ctx, &grpc2.CreateAdRequest{
 		Title:  "Hello",
 		Text:   "World",
@@ -618,7 +626,7 @@ func TestGRPCListAdsNoTitle(t *testing.T) {
 		return
 	}
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Misha"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Misha", Email: "misha@example.com"})
 	if err != nil {
 		return
 	}
@@ -671,7 +679,8 @@ func TestGRPCListAdsWithTitle(t *testing.T) {
 		srv.Stop()
 	})
 
-	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser()))
+	mb := &mailbox{}
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithMailSender(mb)))
 	grpc2.RegisterAdServiceServer(srv, svc)
 
 	go func() {
@@ -696,11 +705,14 @@ func TestGRPCListAdsWithTitle(t *testing.T) {
 
 	client := grpc2.NewAdServiceClient(conn)
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
 	if err != nil {
 		return
 	}
 
+	_, err = client.ConfirmEmail(ctx, &grpc2.ConfirmEmailRequest{Token: mb.token("oleg@example.com")})
+	assert.NoError(t, err)
+
 	_, err = client.CreateAd(ctx, &grpc2.CreateAdRequest{
 		Title:  "Hello",
 		Text:   "World",
@@ -727,7 +739,7 @@ func TestGRPCListAdsWithTitle(t *testing.T) {
 		return
 	}
 
-	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Misha"})
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Misha", Email: "misha@example.com"})
 	if err != nil {
 		return
 	}
@@ -4,22 +4,34 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"log"
+	"net/http"
 	"testing"
 	"time"
 )
 
 func TestCreateUser(t *testing.T) {
 	client := getTestClient()
-	user, err := client.createUser(0, "James", "Ostin")
+	user, err := client.createUser(0, "James", "ostin@example.com")
 
 	assert.NoError(t, err)
 	assert.Equal(t, "James", user.Data.Name)
-	assert.Equal(t, "Ostin", user.Data.Email)
+	assert.Equal(t, "ostin@example.com", user.Data.Email)
+}
+
+func TestCreateUserStatus(t *testing.T) {
+	client := getTestClient()
+	for i, path := range []string{"/api/v1/users", "/api/v1/user"} {
+		resp, err := client.send(http.MethodPost, path,
+			map[string]any{"name": "James", "email": fmt.Sprintf("ostin%d@example.com", i)}, nil)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusCreated, resp.StatusCode, path)
+	}
 }
 
 func TestCreateAd(t *testing.T) {
 	client := getTestClient()
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -35,11 +47,11 @@ func TestCreateAd(t *testing.T) {
 func TestChangeAdStatus(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
-	_, err = client.createUser(1, "James2", "Ostin2")
+	_, err = client.createUser(1, "James2", "ostin2@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -70,7 +82,7 @@ func TestChangeAdStatus(t *testing.T) {
 func TestUpdateAd(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -96,14 +108,14 @@ func TestUpdateAdNoUser(t *testing.T) {
 	response, err := client.updateAd(0, 0, "привет", "мир")
 	fmt.Println(response)
 	fmt.Println(err)
-	assert.ErrorIs(t, err, ErrBadRequest)
+	assert.ErrorIs(t, err, ErrUnauthorized)
 	assert.Empty(t, response)
 }
 
 func TestListAds(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -131,7 +143,7 @@ func TestListAds(t *testing.T) {
 func TestAdBYID(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -150,7 +162,7 @@ func TestAdBYID(t *testing.T) {
 func TestAdsByTitle(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -179,12 +191,12 @@ func TestAdsByTitle(t *testing.T) {
 func TestAuthorFilter(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
 
-	_, err = client.createUser(1, "Jeremy", "gmail")
+	_, err = client.createUser(1, "Jeremy", "jeremy@gmail.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -212,7 +224,7 @@ func TestAuthorFilter(t *testing.T) {
 func TestDateFilter(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		log.Println(err)
 	}
@@ -238,7 +250,7 @@ func TestDateFilter(t *testing.T) {
 func TestNoFilters(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		log.Println(err)
 	}
@@ -261,12 +273,12 @@ func TestNoFilters(t *testing.T) {
 func TestAllFilters(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		log.Println(err)
 	}
 
-	_, err = client.createUser(1, "Jeremy", "mail")
+	_, err = client.createUser(1, "Jeremy", "jeremy@mail.com")
 	if err != nil {
 		log.Println(err)
 	}
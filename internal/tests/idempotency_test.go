@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/clock"
+	"ads-server/internal/idempotency"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/ports/grpc/pkg/interceptors"
+	"ads-server/internal/ports/presenter"
+	"ads-server/internal/ratelimit"
+	grpc2 "ads-server/proto"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestHTTPIdempotency(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	client := getTestClient(app.WithIdempotency(idempotency.New(time.Hour, c)))
+	_, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+
+	post := func(key, body string) (*http.Response, adResponse) {
+		req, err := http.NewRequest(http.MethodPost, client.baseURL+"/api/v1/ads", strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(idempotency.Header, key)
+		}
+		resp, err := client.client.Do(req)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		var ad adResponse
+		_ = json.Unmarshal(data, &ad)
+		return resp, ad
+	}
+
+	body := `{"user_id": 0, "title": "bike", "text": "red bike"}`
+	resp, first := post("k1", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(idempotency.ReplayedHeader))
+	etag := resp.Header.Get("ETag")
+
+	resp, retry := post("k1", `{"text":"red bike","title":"bike","user_id":0}`)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "spacing and order of keys don't matter")
+	assert.Equal(t, "true", resp.Header.Get(idempotency.ReplayedHeader))
+	assert.Equal(t, etag, resp.Header.Get("ETag"))
+	assert.Equal(t, first.Data.ID, retry.Data.ID)
+
+	resp, _ = post("k1", `{"user_id": 0, "title": "car", "text": "red car"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode, "the key is bound to the first payload")
+	resp, _ = post(strings.Repeat("k", idempotency.MaxKeyLen+1), body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	unknown := `{"user_id": 9, "title": "car", "text": "red car"}`
+	resp, _ = post("k2", unknown)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, _ = post("k2", unknown)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "failed responses are not stored, so retries are processed again")
+
+	resp, err = client.send(http.MethodPost, "/api/v1/ads",
+		map[string]any{"user_id": 0, "title": "car", "text": "red car"},
+		map[string]string{idempotency.Header: "k1", ratelimit.APIKeyHeader: "partner"})
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "keys of other clients don't collide")
+	assert.Empty(t, resp.Header.Get(idempotency.ReplayedHeader))
+
+	resp, third := post("", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "requests without keys are not deduplicated")
+	assert.NotEqual(t, first.Data.ID, third.Data.ID)
+
+	c.Advance(time.Hour)
+	resp, fourth := post("k1", body)
+	assert.Empty(t, resp.Header.Get(idempotency.ReplayedHeader), "keys expire after the window")
+	assert.NotEqual(t, first.Data.ID, fourth.Data.ID)
+}
+
+func TestGRPCIdempotency(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo, app.WithIdempotency(idempotency.New(time.Hour, c)))
+	srv := grpc.NewServer(grpc.UnaryInterceptor(interceptors.Idempotency(a.Idempotency(), grpc2.AdService_CreateAd_FullMethodName)))
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+	grpc2.RegisterAdServiceServer(srv, grpcPort.NewAdService(a))
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	keyCtx := metadata.AppendToOutgoingContext(ctx, "idempotency-key", "k1")
+
+	var header metadata.MD
+	first, err := client.CreateAd(keyCtx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "bike", Text: "red bike"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Empty(t, header.Get("idempotent-replayed"))
+
+	retry, err := client.CreateAd(keyCtx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "bike", Text: "red bike"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"true"}, header.Get("idempotent-replayed"))
+	assert.Equal(t, first.Id, retry.Id)
+
+	_, err = client.CreateAd(keyCtx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "car", Text: "red car"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "the key is bound to the first request")
+
+	partnerCtx := metadata.AppendToOutgoingContext(keyCtx, "x-api-key", "partner")
+	car, err := client.CreateAd(partnerCtx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "car", Text: "red car"}, grpc.Header(&header))
+	assert.NoError(t, err, "keys of other clients don't collide")
+	assert.Empty(t, header.Get("idempotent-replayed"))
+	assert.NotEqual(t, first.Id, car.Id)
+
+	other, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "bike", Text: "red bike"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.Id, other.Id, "calls without keys are not deduplicated")
+}
+
+// mergeDuplicates merges repeated ads of an author into the first one
+var mergeDuplicates = app.WithDuplicates(app.DuplicateConfig{MaxDistance: 10, Author: app.DuplicatesMerge, Global: app.DuplicatesFlag})
+
+func TestHTTPIdempotentMerge(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	client := getTestClient(mergeDuplicates, app.WithIdempotency(idempotency.New(time.Hour, c)))
+	seller, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	bike, err := client.createAd(seller.Data.ID, "Mountain bike", bikeText)
+	assert.NoError(t, err)
+
+	for _, replayed := range []string{"", "true"} {
+		resp, err := client.send(http.MethodPost, "/api/v1/ads",
+			map[string]any{"user_id": seller.Data.ID, "title": "Mountain bike!", "text": bikeAgain},
+			map[string]string{idempotency.Header: "k1"})
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, replayed, resp.Header.Get(idempotency.ReplayedHeader))
+		assert.Equal(t, strconv.FormatInt(bike.Data.ID, 10), resp.Header.Get(presenter.MergedIntoHeader),
+			"replays report the merge")
+	}
+}
+
+func TestGRPCIdempotentMerge(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo, mergeDuplicates, app.WithIdempotency(idempotency.New(time.Hour, c)))
+	srv := grpc.NewServer(grpc.UnaryInterceptor(interceptors.Idempotency(a.Idempotency(), grpc2.AdService_CreateAd_FullMethodName)))
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+	grpc2.RegisterAdServiceServer(srv, grpcPort.NewAdService(a))
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	bike, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "Mountain bike", Text: bikeText})
+	assert.NoError(t, err)
+
+	keyCtx := metadata.AppendToOutgoingContext(ctx, "idempotency-key", "k1")
+	for _, replayed := range [][]string{nil, {"true"}} {
+		var header metadata.MD
+		again, err := client.CreateAd(keyCtx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "Mountain bike!", Text: bikeAgain}, grpc.Header(&header))
+		assert.NoError(t, err)
+		assert.Equal(t, bike.Id, again.Id)
+		assert.Equal(t, replayed, header.Get("idempotent-replayed"))
+		assert.Equal(t, []string{strconv.FormatInt(bike.Id, 10)}, header.Get("merged-into"), "replays report the merge")
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/clock"
+	"ads-server/internal/ids"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrozenClockAndSnowflakeIDs(t *testing.T) {
+	now := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	c := clock.NewFake(now)
+	adIDs, err := ids.NewSnowflake(1, c)
+	assert.NoError(t, err)
+	userIDs, err := ids.NewSnowflake(2, c)
+	assert.NoError(t, err)
+	client := getTestClientWithRepos(
+		repo.NewAd(repo.WithClock(c), repo.WithIDs(adIDs)),
+		repo.NewUser(repo.WithClock(c), repo.WithIDs(userIDs)),
+		app.WithClock(c),
+	)
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	ms := now.Sub(ids.SnowflakeEpoch).Milliseconds()
+	assert.Equal(t, ms, user.Data.ID>>22)
+	assert.Equal(t, ms, ad.Data.ID>>22)
+	assert.Equal(t, user.Data.ID, ad.Data.AuthorID)
+	assert.Equal(t, "2024-03-04T09:00:00Z", ad.Data.CreatedAt, "single ad responses have timestamps")
+	assert.Equal(t, ad.Data.CreatedAt, ad.Data.UpdatedAt)
+
+	got, err := client.getAdByID(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", got.Data.Title)
+
+	c.Advance(time.Hour)
+	updated, err := client.updateAd(user.Data.ID, ad.Data.ID, "hello", "gophers")
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Data.CreatedAt, updated.Data.CreatedAt)
+	assert.Equal(t, "2024-03-04T10:00:00Z", updated.Data.UpdatedAt)
+
+	later, err := client.createAd(user.Data.ID, "hello", "again")
+	assert.NoError(t, err)
+	assert.Equal(t, ms+time.Hour.Milliseconds(), later.Data.ID>>22)
+
+	reporter, err := client.createUser(1, "Anna", "anna@example.com")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(user.Data.ID, later.Data.ID, true)
+	assert.NoError(t, err)
+	report, err := client.reportAd(reporter.Data.ID, later.Data.ID, "spam", "")
+	assert.NoError(t, err)
+	assert.Equal(t, ms+time.Hour.Milliseconds(), report.Data.ID>>22, "reports get IDs of the generator too")
+	assert.NotEqual(t, later.Data.ID, report.Data.ID)
+}
+
+func TestUUIDv7IDs(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	client := getTestClientWithRepos(
+		repo.NewAd(repo.WithIDs(ids.NewUUIDv7(c))),
+		repo.NewUser(repo.WithIDs(ids.NewUUIDv7(c))),
+	)
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	first, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	second, err := client.createAd(user.Data.ID, "hello", "again")
+	assert.NoError(t, err)
+
+	assert.Equal(t, c.Now().UnixMilli(), first.Data.ID>>16)
+	assert.Greater(t, second.Data.ID, first.Data.ID)
+
+	got, err := client.getAdByID(second.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "again", got.Data.Text)
+}
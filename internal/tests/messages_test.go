@@ -0,0 +1,264 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/errs"
+	"ads-server/internal/messages"
+	grpcPort "ads-server/internal/ports/grpc"
+	grpc2 "ads-server/proto"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// messaging enables conversations with a fresh repository and hub
+func messaging() app.Option {
+	return app.WithMessaging(repo.NewMessages(), messages.NewHub())
+}
+
+// publishedAd creates users and a published ad of the first one
+func publishedAd(t *testing.T, client *testClient) (seller, buyer, other int64, adID int64) {
+	s, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	b, err := client.createUser(1, "Anna", "anna@example.com")
+	assert.NoError(t, err)
+	o, err := client.createUser(2, "Ivan", "ivan@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(s.Data.ID, "bike", "red bike")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(s.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	return s.Data.ID, b.Data.ID, o.Data.ID, ad.Data.ID
+}
+
+func TestConversation(t *testing.T) {
+	client := getTestClient(messaging())
+	seller, buyer, other, adID := publishedAd(t, client)
+
+	_, err := client.startConversation(seller, adID)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "authors can't message themselves")
+
+	conversation, err := client.startConversation(buyer, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, seller, conversation.Data.SellerID)
+	assert.Equal(t, buyer, conversation.Data.BuyerID)
+	assert.Empty(t, conversation.Data.LastMessageAt)
+
+	again, err := client.startConversation(buyer, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, conversation.Data.ID, again.Data.ID, "a buyer has one conversation per ad")
+
+	id := conversation.Data.ID
+	for i := 0; i < 5; i++ {
+		_, err = client.sendMessage(buyer, id, fmt.Sprintf("message %d", i))
+		assert.NoError(t, err)
+	}
+	_, err = client.sendMessage(seller, id, "reply")
+	assert.NoError(t, err)
+
+	_, err = client.sendMessage(buyer, id, " ")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.sendMessage(other, id, "hello")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.listMessages(other, id, "")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	list, err := client.listConversations(seller)
+	assert.NoError(t, err)
+	if assert.Len(t, list.Data, 1) {
+		assert.Equal(t, 5, list.Data[0].Unread)
+		assert.NotEmpty(t, list.Data[0].LastMessageAt)
+	}
+	list, err = client.listConversations(buyer)
+	assert.NoError(t, err)
+	if assert.Len(t, list.Data, 1) {
+		assert.Equal(t, 1, list.Data[0].Unread)
+	}
+	list, err = client.listConversations(other)
+	assert.NoError(t, err)
+	assert.Empty(t, list.Data)
+
+	page, err := client.listMessages(seller, id, "limit=4")
+	assert.NoError(t, err)
+	if assert.Len(t, page.Data, 4) {
+		assert.Equal(t, "reply", page.Data[0].Text, "newest first")
+		assert.Equal(t, "message 2", page.Data[3].Text)
+	}
+	list, err = client.listConversations(seller)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, list.Data[0].Unread, "the latest page was read")
+
+	older, err := client.listMessages(seller, id, fmt.Sprintf("limit=4&before=%d", page.Data[3].ID))
+	assert.NoError(t, err)
+	if assert.Len(t, older.Data, 2) {
+		assert.Equal(t, "message 1", older.Data[0].Text)
+		assert.Equal(t, "message 0", older.Data[1].Text)
+	}
+
+	_, err = client.listMessages(seller, id, "limit=101")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.listMessages(seller, id, "before=100500")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestBlockUser(t *testing.T) {
+	client := getTestClient(messaging())
+	seller, buyer, other, adID := publishedAd(t, client)
+
+	conversation, err := client.startConversation(buyer, adID)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, client.blockUser(seller, seller), ErrBadRequest)
+	assert.NoError(t, client.blockUser(seller, buyer))
+	assert.NoError(t, client.blockUser(seller, buyer), "blocking again is a no-op")
+
+	_, err = client.sendMessage(buyer, conversation.Data.ID, "hello")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.sendMessage(seller, conversation.Data.ID, "hello")
+	assert.ErrorIs(t, err, ErrForbidden, "blocking works both ways")
+	_, err = client.startConversation(buyer, adID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	blocked, err := client.listBlocked(seller, seller)
+	assert.NoError(t, err)
+	if assert.Len(t, blocked.Data, 1) {
+		assert.Equal(t, buyer, blocked.Data[0].BlockedID)
+	}
+	_, err = client.listBlocked(other, seller)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	assert.ErrorIs(t, client.unblockUser(buyer, seller), ErrNotFound, "only the user who blocked can unblock")
+	assert.NoError(t, client.unblockUser(seller, buyer))
+	_, err = client.sendMessage(buyer, conversation.Data.ID, "hello")
+	assert.NoError(t, err)
+}
+
+func TestMessagingDisabled(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo)
+	u := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+
+	_, err := a.ListConversations(ctx, u.ID)
+	assert.ErrorIs(t, err, errs.MessagingDisabledError)
+}
+
+func TestGRPCChat(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	svc := grpcPort.NewAdService(app.NewApp(adRepo, userRepo, messaging()))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	seller := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	buyer := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+	other := verifiedUser(t, userRepo, "Ivan", "ivan@example.com")
+
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: seller.ID, Title: "bike", Text: "red bike"})
+	assert.NoError(t, err)
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: seller.ID, Published: true})
+	assert.NoError(t, err)
+
+	conversation, err := client.StartConversation(ctx, &grpc2.StartConversationRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.NoError(t, err)
+
+	// senders receive their own messages, so the echo tells the stream is subscribed
+	sellerChat, err := client.Chat(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, sellerChat.Send(&grpc2.ChatRequest{UserId: seller.ID, ConversationId: conversation.Id, Text: "hello"}))
+	echo, err := sellerChat.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, seller.ID, echo.SenderId)
+
+	buyerChat, err := client.Chat(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, buyerChat.Send(&grpc2.ChatRequest{UserId: buyer.ID, ConversationId: conversation.Id, Text: "is it available?"}))
+	echo, err = buyerChat.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "is it available?", echo.Text)
+
+	received, err := sellerChat.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, conversation.Id, received.ConversationId)
+	assert.Equal(t, buyer.ID, received.SenderId)
+	assert.Equal(t, "is it available?", received.Text)
+
+	// messages sent by unary calls reach streams too
+	_, err = client.SendMessage(ctx, &grpc2.SendMessageRequest{ConversationId: conversation.Id, UserId: seller.ID, Text: "yes"})
+	assert.NoError(t, err)
+	reply, err := buyerChat.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "yes", reply.Text)
+
+	list, err := client.ListMessages(ctx, &grpc2.ListMessagesRequest{ConversationId: conversation.Id, UserId: buyer.ID, Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, list.List, 1) {
+		assert.Equal(t, "yes", list.List[0].Text)
+	}
+
+	conversations, err := client.ListConversations(ctx, &grpc2.ListConversationsRequest{UserId: seller.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, conversations.List, 1) {
+		assert.Equal(t, int64(1), conversations.List[0].Unread)
+		assert.NotNil(t, conversations.List[0].LastMessageAt)
+	}
+
+	// only participants may send to the conversation, the stream ends with the error
+	otherChat, err := client.Chat(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, otherChat.Send(&grpc2.ChatRequest{UserId: other.ID, ConversationId: conversation.Id, Text: "hi"}))
+	_, err = otherChat.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.BlockUser(ctx, &grpc2.BlockUserRequest{Id: buyer.ID, UserId: seller.ID})
+	assert.NoError(t, err)
+	assert.NoError(t, buyerChat.Send(&grpc2.ChatRequest{UserId: buyer.ID, ConversationId: conversation.Id, Text: "hello?"}))
+	_, err = buyerChat.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	blocked, err := client.ListBlockedUsers(ctx, &grpc2.ListBlockedUsersRequest{Id: seller.ID, UserId: seller.ID})
+	assert.NoError(t, err)
+	assert.Len(t, blocked.List, 1)
+	_, err = client.UnblockUser(ctx, &grpc2.BlockUserRequest{Id: buyer.ID, UserId: seller.ID})
+	assert.NoError(t, err)
+}
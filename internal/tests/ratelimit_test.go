@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/clock"
+	"ads-server/internal/errs"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/ports/grpc/pkg/interceptors"
+	"ads-server/internal/ratelimit"
+	grpc2 "ads-server/proto"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// rateLimits allows 3 requests a minute, 1 new ad an hour over HTTP and 2 over gRPC.
+// The partner API key is issued and the user header is trusted.
+func rateLimits(c clock.Clock) app.Option {
+	return app.WithRateLimits(ratelimit.New(ratelimit.Config{
+		Default: ratelimit.Quota{Burst: 3, Period: time.Minute},
+		Routes: map[string]ratelimit.Quota{
+			"POST /api/v1/ads":       {Burst: 1, Period: time.Hour},
+			"/ad.AdService/CreateAd": {Burst: 2, Period: time.Hour},
+		},
+		APIKeys:         map[string]bool{"partner": true},
+		TrustUserHeader: true,
+	}, c))
+}
+
+func TestHTTPRateLimit(t *testing.T) {
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	client := getTestClient(rateLimits(c))
+
+	get := func(apiKey string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, client.baseURL+"/api/v1/ads/filter", nil)
+		assert.NoError(t, err)
+		if apiKey != "" {
+			req.Header.Set(ratelimit.APIKeyHeader, apiKey)
+		}
+		resp, err := client.client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := get("")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "3", resp.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "2", resp.Header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "3;w=60", resp.Header.Get("RateLimit-Policy"))
+	get("")
+	get("")
+	resp = get("")
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "20", resp.Header.Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	assert.Equal(t, http.StatusTooManyRequests, get("random").StatusCode, "unknown API keys don't get own quotas")
+	assert.Equal(t, http.StatusOK, get("partner").StatusCode, "API keys have own quotas")
+
+	user, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.ErrorIs(t, err, ErrTooManyRequests, "the default quota is shared by routes")
+	c.Advance(time.Minute)
+	user, err = client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	_, err = client.createAd(user.Data.ID, "bike", "red bike")
+	assert.NoError(t, err)
+	_, err = client.createAd(user.Data.ID, "car", "red car")
+	assert.ErrorIs(t, err, ErrTooManyRequests, "the route has its own quota")
+	_, err = client.getAdByID(0)
+	assert.NoError(t, err)
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo, rateLimits(c))
+	srv := grpc.NewServer(grpc.UnaryInterceptor(interceptors.RateLimit(a.Limiter())))
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+	grpc2.RegisterAdServiceServer(srv, grpcPort.NewAdService(a))
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+
+	var header metadata.MD
+	_, err = client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "bike", Text: "red bike"}, grpc.Header(&header))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, header.Get("ratelimit-limit"))
+	assert.Equal(t, []string{"1"}, header.Get("ratelimit-remaining"))
+	_, err = client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "car", Text: "red car"})
+	assert.NoError(t, err)
+	_, err = client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "van", Text: "red van"}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"1800"}, header.Get("retry-after"))
+
+	userCtx := metadata.AppendToOutgoingContext(ctx, "x-user-id", "7")
+	_, err = client.CreateAd(userCtx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "van", Text: "red van"})
+	assert.NoError(t, err, "authenticated users have own quotas")
+}
+
+func TestDailyAdQuota(t *testing.T) {
+	ctx := context.Background()
+	c := clock.NewFake(time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC))
+	adRepo, userRepo := repo.NewAd(repo.WithClock(c)), repo.NewUser(repo.WithClock(c))
+	a := app.NewApp(adRepo, userRepo, app.WithClock(c), app.WithDailyAdQuota(2))
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	other := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "bike", "red bike", "")
+	assert.NoError(t, err)
+	assert.NoError(t, a.DeleteAd(ctx, ad.ID, author.ID, 0))
+	_, _, err = a.CreateAd(ctx, author.ID, "car", "red car", "")
+	assert.NoError(t, err)
+	_, _, err = a.CreateAd(ctx, author.ID, "van", "red van", "")
+	assert.ErrorIs(t, err, errs.AdQuotaError, "deleted ads count")
+	_, _, err = a.CreateAd(ctx, other.ID, "van", "red van", "")
+	assert.NoError(t, err)
+
+	c.Advance(2 * time.Hour)
+	_, _, err = a.CreateAd(ctx, author.ID, "van", "red van", "")
+	assert.NoError(t, err, "the quota is renewed at midnight UTC")
+}
+
+func TestDailyAdQuotaConcurrent(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo, app.WithDailyAdQuota(2))
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, _, err := a.CreateAd(ctx, author.ID, fmt.Sprintf("ad %d", i), fmt.Sprintf("text number %d", i), ""); err == nil {
+				created.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), created.Load(), "concurrent creates must not exceed the quota")
+}
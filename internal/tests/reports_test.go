@@ -0,0 +1,308 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/ads"
+	"ads-server/internal/app"
+	"ads-server/internal/audit"
+	"ads-server/internal/errs"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/users"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// verifiedModerator stores a moderator who confirmed its email
+func verifiedModerator(t *testing.T, userRepo app.UserRepository, name, email string) *users.User {
+	u := users.New(name, email)
+	u.Verified = true
+	u.Role = users.RoleModerator
+	_, err := userRepo.Create(context.Background(), u)
+	assert.NoError(t, err)
+	return u
+}
+
+func TestReportTakedown(t *testing.T) {
+	client := getTestClient(moderators, app.WithReports(app.ReportConfig{TakedownThreshold: 2}))
+	seller, buyer, other, adID := publishedAd(t, client)
+	moderator, err := client.createUser(3, "Maria", "moderator@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.reportAd(seller, adID, "spam", "")
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "authors can't report own ads")
+	_, err = client.reportAd(buyer, adID, "boring", "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.reportAd(buyer, adID, "other", " ")
+	assert.ErrorIs(t, err, ErrBadRequest, "other reason needs a comment")
+
+	report, err := client.reportAd(buyer, adID, "fraud", "asks for prepayment")
+	assert.NoError(t, err)
+	assert.Equal(t, buyer, report.Data.ReporterID)
+	assert.Empty(t, report.Data.Outcome)
+	_, err = client.reportAd(buyer, adID, "spam", "")
+	assert.ErrorIs(t, err, ErrConflict, "one open report per reporter")
+
+	ad, err := client.getAdByID(adID)
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published, "one reporter is below the threshold")
+
+	before := client.mailbox.count("oleg@example.com")
+	_, err = client.reportAd(other, adID, "spam", "")
+	assert.NoError(t, err)
+	ad, err = client.getAdByID(adID)
+	assert.NoError(t, err)
+	assert.False(t, ad.Data.Published)
+	assert.NotEmpty(t, ad.Data.TakenDownAt)
+	assert.Equal(t, before+1, client.mailbox.count("oleg@example.com"), "the author is notified")
+
+	_, err = client.changeAdStatus(seller, adID, true)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "taken down ads can't be published")
+
+	_, err = client.listReportedAds(seller)
+	assert.ErrorIs(t, err, ErrForbidden)
+	inbox, err := client.listReportedAds(moderator.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, inbox.Data, 1)
+	assert.Equal(t, adID, inbox.Data[0].Ad.ID)
+	assert.Len(t, inbox.Data[0].Reports, 2)
+
+	_, err = client.appealTakedown(buyer, adID, "please")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.appealTakedown(seller, adID, "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	appeal, err := client.appealTakedown(seller, adID, "it is a real bike")
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", appeal.Data.Status)
+	_, err = client.appealTakedown(seller, adID, "please")
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "one pending appeal per ad")
+
+	appeals, err := client.listAppeals(moderator.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, appeals.Data, 1)
+
+	accepted, err := client.resolveAppeal(moderator.Data.ID, appeal.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "accepted", accepted.Data.Status)
+	assert.Equal(t, moderator.Data.ID, accepted.Data.ModeratorID)
+	_, err = client.resolveAppeal(moderator.Data.ID, appeal.Data.ID, false)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity)
+
+	ad, err = client.getAdByID(adID)
+	assert.NoError(t, err)
+	assert.Empty(t, ad.Data.TakenDownAt)
+	assert.False(t, ad.Data.Published, "the author publishes the ad again")
+	inbox, err = client.listReportedAds(moderator.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, inbox.Data, "reports are dismissed")
+
+	_, err = client.changeAdStatus(seller, adID, true)
+	assert.NoError(t, err)
+	_, err = client.reportAd(buyer, adID, "spam", "")
+	assert.NoError(t, err, "dismissed reports don't prevent new ones")
+}
+
+func TestResolveReports(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo, app.WithReports(app.ReportConfig{}))
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	reporter := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+	moderator := verifiedModerator(t, userRepo, "Maria", "moderator@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "bike", "red bike", "")
+	assert.NoError(t, err)
+	_, err = a.ReportAd(ctx, ad.ID, reporter.ID, ads.ReasonSpam, "")
+	assert.ErrorIs(t, err, errs.AdNotPublishedError)
+	_, err = a.PublishAd(ctx, ad.ID, author.ID, true, 0)
+	assert.NoError(t, err)
+	_, err = a.ResolveReports(ctx, ad.ID, moderator.ID, true)
+	assert.ErrorIs(t, err, errs.ReportsNotFoundError)
+
+	_, err = a.ReportAd(ctx, ad.ID, reporter.ID, ads.ReasonSpam, "")
+	assert.NoError(t, err)
+	ad, err = a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.True(t, ad.Published, "automatic takedowns are disabled")
+
+	_, err = a.ResolveReports(ctx, ad.ID, reporter.ID, true)
+	assert.ErrorIs(t, err, errs.AccessError)
+	resolved, err := a.ResolveReports(ctx, ad.ID, moderator.ID, true)
+	assert.NoError(t, err)
+	assert.Len(t, resolved, 1)
+	assert.Equal(t, ads.OutcomeTakenDown, resolved[0].Outcome)
+	assert.Equal(t, moderator.ID, resolved[0].ResolvedBy)
+
+	ad, err = a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.False(t, ad.Published)
+	assert.True(t, ad.TakenDown())
+	_, err = a.ChangeAdStatus(ctx, ad.ID, author.ID, true, time.Now().Add(time.Hour), time.Time{}, 0)
+	assert.ErrorIs(t, err, errs.AdTakenDownError, "taken down ads can't be scheduled")
+
+	appeal, err := a.AppealTakedown(ctx, ad.ID, author.ID, "it is a real bike")
+	assert.NoError(t, err)
+	rejected, err := a.ResolveAppeal(ctx, appeal.ID, moderator.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, ads.AppealRejected, rejected.Status)
+	ad, err = a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.True(t, ad.TakenDown())
+
+	history, err := a.ListRevisions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	var actions []string
+	for _, r := range history {
+		actions = append(actions, string(r.Action))
+	}
+	assert.Equal(t, []string{"create", "publish", "unpublish", "takedown"}, actions)
+}
+
+// failingResolution fails to resolve reports after the ad was taken down in the same unit of work
+type failingResolution struct {
+	app.AdRepository
+}
+
+func (failingResolution) ResolveReports(context.Context, int64, int64, ads.ReportOutcome, time.Time) ([]*ads.Report, error) {
+	return nil, errs.New(errs.Unavailable, "storage is unavailable")
+}
+
+func TestTakedownActor(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo, log, mb := repo.NewAd(), repo.NewUser(), repo.NewAudit(), &mailbox{}
+	a := app.NewApp(adRepo, userRepo, app.WithReports(app.ReportConfig{}), app.WithAuditLog(log), app.WithMailSender(mb))
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	reporter := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+	moderator := verifiedModerator(t, userRepo, "Maria", "moderator@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "bike", "red bike", "")
+	assert.NoError(t, err)
+	_, err = a.PublishAd(ctx, ad.ID, author.ID, true, 0)
+	assert.NoError(t, err)
+	_, err = a.AddFavorite(ctx, reporter.ID, ad.ID)
+	assert.NoError(t, err)
+	_, err = a.ReportAd(ctx, ad.ID, reporter.ID, ads.ReasonSpam, "")
+	assert.NoError(t, err)
+
+	failing := app.NewApp(failingResolution{adRepo}, userRepo, app.WithReports(app.ReportConfig{}),
+		app.WithAuditLog(log), app.WithMailSender(mb))
+	_, err = failing.ResolveReports(ctx, ad.ID, moderator.ID, true)
+	assert.Error(t, err)
+	ad, err = a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.True(t, ad.Published, "the takedown is rolled back")
+	assert.Zero(t, mb.count("anna@example.com"), "watchers aren't notified of rolled back changes")
+
+	_, err = a.ResolveReports(ctx, ad.ID, moderator.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, mb.count("anna@example.com"))
+
+	history, err := a.ListRevisions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 4) {
+		assert.Equal(t, ads.ActionUnpublish, history[2].Action)
+		assert.Equal(t, moderator.ID, history[2].EditorID, "the moderator unpublished the ad")
+	}
+	entries, err := log.Query(ctx, audit.Filter{TargetType: errs.ResourceAd, TargetID: &ad.ID})
+	assert.NoError(t, err)
+	unpublished := 0
+	for _, e := range entries {
+		if e.Action == audit.ActionAdUnpublish {
+			unpublished++
+			assert.Equal(t, moderator.ID, e.ActorID)
+		}
+	}
+	assert.Equal(t, 1, unpublished)
+}
+
+func TestGRPCReports(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	svc := grpcPort.NewAdService(app.NewApp(adRepo, userRepo, app.WithReports(app.ReportConfig{TakedownThreshold: 1})))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	seller := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	buyer := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+	moderator := verifiedModerator(t, userRepo, "Maria", "moderator@example.com")
+
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: seller.ID, Title: "bike", Text: "red bike"})
+	assert.NoError(t, err)
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: seller.ID, Published: true})
+	assert.NoError(t, err)
+
+	_, err = client.ReportAd(ctx, &grpc2.ReportAdRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "the reason is required")
+	report, err := client.ReportAd(ctx, &grpc2.ReportAdRequest{AdId: ad.Id, UserId: buyer.ID, Reason: grpc2.ReportReason_REPORT_REASON_PROHIBITED})
+	assert.NoError(t, err)
+	assert.Equal(t, grpc2.ReportReason_REPORT_REASON_PROHIBITED, report.Reason)
+	assert.Nil(t, report.ResolvedAt)
+
+	_, err = client.ListReportedAds(ctx, &grpc2.ListReportedAdsRequest{UserId: buyer.ID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	inbox, err := client.ListReportedAds(ctx, &grpc2.ListReportedAdsRequest{UserId: moderator.ID})
+	assert.NoError(t, err)
+	assert.Len(t, inbox.List, 1)
+	assert.NotNil(t, inbox.List[0].Ad.TakenDownAt)
+
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: seller.ID, Published: true})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	appeal, err := client.AppealTakedown(ctx, &grpc2.AppealTakedownRequest{AdId: ad.Id, UserId: seller.ID, Comment: "legal"})
+	assert.NoError(t, err)
+	appeals, err := client.ListAppeals(ctx, &grpc2.ListAppealsRequest{UserId: moderator.ID})
+	assert.NoError(t, err)
+	assert.Len(t, appeals.List, 1)
+
+	resolved, err := client.ResolveReports(ctx, &grpc2.ResolveReportsRequest{AdId: ad.Id, UserId: moderator.ID})
+	assert.NoError(t, err)
+	assert.Len(t, resolved.List, 1)
+	assert.Equal(t, "dismissed", resolved.List[0].Outcome)
+
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: seller.ID, Published: true})
+	assert.NoError(t, err, "dismissing reports lifts the takedown")
+
+	accepted, err := client.ResolveAppeal(ctx, &grpc2.ResolveAppealRequest{AppealId: appeal.Id, UserId: moderator.ID, Accept: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "accepted", accepted.Status)
+	assert.NotNil(t, accepted.ResolvedAt)
+}
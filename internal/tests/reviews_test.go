@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	grpcPort "ads-server/internal/ports/grpc"
+	grpc2 "ads-server/proto"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestReviewSeller(t *testing.T) {
+	client := getTestClient(messaging(), moderators)
+	seller, buyer, other, adID := publishedAd(t, client)
+	moderator, err := client.createUser(3, "Maria", "moderator@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.reviewSeller(buyer, adID, 5, "great")
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "no conversation yet")
+
+	conversation, err := client.startConversation(buyer, adID)
+	assert.NoError(t, err)
+	_, err = client.reviewSeller(buyer, adID, 5, "great")
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "the conversation is empty")
+
+	_, err = client.sendMessage(buyer, conversation.Data.ID, "is it available?")
+	assert.NoError(t, err)
+	_, err = client.reviewSeller(buyer, adID, 6, "great")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.reviewSeller(buyer, adID, 5, "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	review, err := client.reviewSeller(buyer, adID, 5, "great")
+	assert.NoError(t, err)
+	assert.Equal(t, seller, review.Data.SellerID)
+	assert.Equal(t, buyer, review.Data.AuthorID)
+
+	_, err = client.reviewSeller(buyer, adID, 1, "changed my mind")
+	assert.ErrorIs(t, err, ErrConflict, "one review per ad")
+
+	conversation, err = client.startConversation(other, adID)
+	assert.NoError(t, err)
+	_, err = client.sendMessage(other, conversation.Data.ID, "hi")
+	assert.NoError(t, err)
+	abusive, err := client.reviewSeller(other, adID, 2, "meh")
+	assert.NoError(t, err)
+
+	user, err := client.getUser(seller)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, user.Data.Reputation.Reviews)
+	assert.Equal(t, 3.5, user.Data.Reputation.Rating)
+
+	_, err = client.replyToReview(buyer, review.Data.ID, "thanks")
+	assert.ErrorIs(t, err, ErrForbidden, "only the seller replies")
+	reply, err := client.replyToReview(seller, review.Data.ID, "thanks")
+	assert.NoError(t, err)
+	assert.Equal(t, "thanks", reply.Data.Reply)
+	_, err = client.replyToReview(seller, review.Data.ID, "thanks again")
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "sellers reply once")
+
+	_, err = client.hideReview(seller, abusive.Data.ID, true)
+	assert.ErrorIs(t, err, ErrForbidden)
+	hidden, err := client.hideReview(moderator.Data.ID, abusive.Data.ID, true)
+	assert.NoError(t, err)
+	assert.True(t, hidden.Data.Hidden)
+
+	user, err = client.getUser(seller)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, user.Data.Reputation.Reviews)
+	assert.Equal(t, 5.0, user.Data.Reputation.Rating)
+
+	public, err := client.listReviews(seller, "")
+	assert.NoError(t, err)
+	if assert.Len(t, public.Data, 1) {
+		assert.Equal(t, review.Data.ID, public.Data[0].ID)
+		assert.Equal(t, "thanks", public.Data[0].Reply)
+	}
+	all, err := client.listReviews(seller, fmt.Sprintf("user_id=%d", moderator.Data.ID))
+	assert.NoError(t, err)
+	assert.Len(t, all.Data, 2)
+
+	_, err = client.hideReview(moderator.Data.ID, abusive.Data.ID, false)
+	assert.NoError(t, err)
+	user, err = client.getUser(seller)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, user.Data.Reputation.Reviews)
+}
+
+func TestGRPCReviews(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	svc := grpcPort.NewAdService(app.NewApp(adRepo, userRepo, messaging()))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	seller := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	buyer := verifiedUser(t, userRepo, "Anna", "anna@example.com")
+
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: seller.ID, Title: "bike", Text: "red bike"})
+	assert.NoError(t, err)
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: seller.ID, Published: true})
+	assert.NoError(t, err)
+	conversation, err := client.StartConversation(ctx, &grpc2.StartConversationRequest{AdId: ad.Id, UserId: buyer.ID})
+	assert.NoError(t, err)
+	_, err = client.SendMessage(ctx, &grpc2.SendMessageRequest{ConversationId: conversation.Id, UserId: buyer.ID, Text: "hi"})
+	assert.NoError(t, err)
+
+	review, err := client.ReviewSeller(ctx, &grpc2.ReviewSellerRequest{AdId: ad.Id, UserId: buyer.ID, Rating: 4, Text: "fine"})
+	assert.NoError(t, err)
+	assert.Nil(t, review.RepliedAt)
+
+	reply, err := client.ReplyToReview(ctx, &grpc2.ReplyToReviewRequest{ReviewId: review.Id, UserId: seller.ID, Text: "thanks"})
+	assert.NoError(t, err)
+	assert.NotNil(t, reply.RepliedAt)
+
+	_, err = client.HideReview(ctx, &grpc2.HideReviewRequest{ReviewId: review.Id, UserId: seller.ID, Hidden: true})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	list, err := client.ListReviews(ctx, &grpc2.ListReviewsRequest{SellerId: seller.ID})
+	assert.NoError(t, err)
+	assert.Len(t, list.List, 1)
+
+	user, err := client.GetUser(ctx, &grpc2.GetUserRequest{Id: &seller.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), user.Reputation.Reviews)
+	assert.Equal(t, 4.0, user.Reputation.Rating)
+}
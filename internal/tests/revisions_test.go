@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/users"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var moderators = app.WithStaff(map[string]users.Role{"moderator@example.com": users.RoleModerator})
+
+func TestRevisionHistory(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.updateAd(author.Data.ID, ad.Data.ID, "hello", "there")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	history, err := client.listRevisions(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, history.Data, 3)
+	for i, action := range []string{"create", "update", "publish"} {
+		assert.Equal(t, int64(i+1), history.Data[i].Number)
+		assert.Equal(t, action, history.Data[i].Action)
+		assert.Equal(t, author.Data.ID, history.Data[i].EditorID)
+	}
+	assert.Equal(t, []fieldChange{{Field: "text", Old: "world", New: "there"}}, history.Data[1].Changes)
+	assert.Equal(t, []fieldChange{{Field: "published", Old: "false", New: "true"}}, history.Data[2].Changes)
+
+	first, err := client.getRevision(author.Data.ID, ad.Data.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", first.Data.Text)
+
+	_, err = client.getRevision(author.Data.ID, ad.Data.ID, 42)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRollbackAd(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	_, err = client.updateAd(author.Data.ID, ad.Data.ID, "bye", "there")
+	assert.NoError(t, err)
+
+	_, err = client.listRevisions(other.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.rollbackAd(other.Data.ID, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	restored, err := client.rollbackAd(author.Data.ID, ad.Data.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", restored.Data.Title)
+	assert.Equal(t, "world", restored.Data.Text)
+	assert.True(t, restored.Data.Published, "publication status is kept")
+	assert.Equal(t, int64(4), restored.Data.Version)
+
+	last, err := client.getRevision(author.Data.ID, ad.Data.ID, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "rollback", last.Data.Action)
+	assert.Equal(t, int64(1), last.Data.RestoredFrom)
+}
+
+func TestChangesSinceApproval(t *testing.T) {
+	client := getTestClient(moderators)
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	moderator, err := client.createUser(1, "Mary", "moderator@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", moderator.Data.Role)
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	changes, err := client.adChanges(moderator.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, changes.Data.Approval)
+	assert.Equal(t, []fieldChange{{Field: "title", Old: "", New: "hello"}, {Field: "text", Old: "", New: "world"}}, changes.Data.Changes,
+		"never approved ad is compared with an empty one")
+
+	_, err = client.approveAd(author.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	approval, err := client.approveAd(moderator.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), approval.Data.Revision)
+
+	_, err = client.updateAd(author.Data.ID, ad.Data.ID, "hello", "there")
+	assert.NoError(t, err)
+	_, err = client.updateAd(author.Data.ID, ad.Data.ID, "hello", "everyone")
+	assert.NoError(t, err)
+
+	changes, err = client.adChanges(moderator.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), changes.Data.Approval.Revision)
+	assert.Equal(t, int64(3), changes.Data.Revision)
+	assert.Equal(t, []fieldChange{{Field: "text", Old: "world", New: "everyone"}}, changes.Data.Changes)
+
+	// moderators can read history of any ad
+	history, err := client.listRevisions(moderator.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, history.Data, 3)
+}
+
+func TestUnverifiedModeratorCantApprove(t *testing.T) {
+	client := getTestClient(moderators)
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	moderator, err := client.registerUser(1, "Mary", "moderator@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	_, err = client.approveAd(moderator.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestGRPCRevisions(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	mb := &mailbox{}
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithMailSender(mb), moderators))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	author, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	moderator, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Olga", Email: "moderator@example.com"})
+	assert.NoError(t, err)
+	_, err = client.ConfirmEmail(ctx, &grpc2.ConfirmEmailRequest{Token: mb.token("moderator@example.com")})
+	assert.NoError(t, err)
+
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.Id, Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+
+	approval, err := client.ApproveAd(ctx, &grpc2.ApproveAdRequest{AdId: ad.Id, UserId: moderator.Id, ExpectedVersion: ad.Version})
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Version, approval.Revision)
+
+	_, err = client.UpdateAd(ctx, &grpc2.UpdateAdRequest{AdId: ad.Id, UserId: author.Id, Title: "bye", Text: "world"})
+	assert.NoError(t, err)
+
+	_, err = client.ApproveAd(ctx, &grpc2.ApproveAdRequest{AdId: ad.Id, UserId: moderator.Id, ExpectedVersion: ad.Version})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	changes, err := client.GetAdChanges(ctx, &grpc2.GetAdChangesRequest{AdId: ad.Id, UserId: moderator.Id})
+	assert.NoError(t, err)
+	assert.Len(t, changes.Changes, 1)
+	assert.Equal(t, "title", changes.Changes[0].Field)
+
+	list, err := client.ListAdRevisions(ctx, &grpc2.ListAdRevisionsRequest{AdId: ad.Id, UserId: author.Id})
+	assert.NoError(t, err)
+	assert.Len(t, list.List, 2)
+	assert.False(t, list.List[1].CreatedAt.AsTime().IsZero())
+
+	restored, err := client.RollbackAd(ctx, &grpc2.RollbackAdRequest{AdId: ad.Id, UserId: author.Id, Number: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", restored.Title)
+
+	_, err = client.GetAdRevision(ctx, &grpc2.GetAdRevisionRequest{AdId: ad.Id, UserId: author.Id, Number: 9})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
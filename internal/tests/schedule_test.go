@@ -0,0 +1,302 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/errs"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/users"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// verifiedUser creates a user allowed to publish ads
+func verifiedUser(t *testing.T, userRepo app.UserRepository, name, email string) *users.User {
+	u := users.New(name, email)
+	u.Verified = true
+	_, err := userRepo.Create(context.Background(), u)
+	assert.NoError(t, err)
+	return u
+}
+
+func TestScheduledPublication(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo)
+	author := verifiedUser(t, userRepo, "James", "james@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "hello", "world", "")
+	assert.NoError(t, err)
+
+	now := time.Now().UTC()
+	scheduled, err := a.ChangeAdStatus(ctx, ad.ID, author.ID, true, now.Add(time.Hour), now.Add(2*time.Hour), ad.Version)
+	assert.NoError(t, err)
+	assert.False(t, scheduled.Published, "publication is scheduled, not made")
+	assert.Equal(t, ad.Version, scheduled.Version)
+
+	// a new publication time replaces the pending one
+	_, err = a.ChangeAdStatus(ctx, ad.ID, author.ID, true, now.Add(30*time.Minute), time.Time{}, 0)
+	assert.NoError(t, err)
+
+	pending, err := a.ListTransitions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 2) {
+		assert.True(t, pending[0].Publish)
+		assert.Equal(t, now.Add(30*time.Minute), pending[0].At)
+		assert.False(t, pending[1].Publish)
+		assert.Equal(t, now.Add(2*time.Hour), pending[1].At)
+	}
+
+	report, err := a.MakeDueTransitions(ctx, now.Add(10*time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, report.Done)
+
+	report, err = a.MakeDueTransitions(ctx, now.Add(31*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{pending[0].ID}, report.Done)
+	published, err := a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.True(t, published.Published)
+
+	report, err = a.MakeDueTransitions(ctx, now.Add(3*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{pending[1].ID}, report.Done)
+	unpublished, err := a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.False(t, unpublished.Published)
+
+	pending, err = a.ListTransitions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestScheduledUnpublishing(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo)
+	author := verifiedUser(t, userRepo, "James", "james@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "hello", "world", "")
+	assert.NoError(t, err)
+
+	now := time.Now().UTC()
+	published, err := a.ChangeAdStatus(ctx, ad.ID, author.ID, true, time.Time{}, now.Add(time.Hour), 0)
+	assert.NoError(t, err)
+	assert.True(t, published.Published, "the ad is published now")
+
+	report, err := a.MakeDueTransitions(ctx, now.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Len(t, report.Done, 1)
+	unpublished, err := a.GetAdByID(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.False(t, unpublished.Published)
+}
+
+func TestScheduleValidation(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo)
+	author := verifiedUser(t, userRepo, "James", "james@example.com")
+	unverified, err := a.CreateUser(ctx, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "hello", "world", "")
+	assert.NoError(t, err)
+	draft, _, err := a.CreateAd(ctx, unverified.ID, "hello", "world", "")
+	assert.NoError(t, err)
+
+	now := time.Now().UTC()
+	tests := []struct {
+		name        string
+		adID        int64
+		uID         int64
+		published   bool
+		publishAt   time.Time
+		unpublishAt time.Time
+		version     int64
+		err         error
+	}{
+		{"past publication", ad.ID, author.ID, true, now.Add(-time.Minute), time.Time{}, 0, errs.ValidationError},
+		{"publication of unpublished", ad.ID, author.ID, false, now.Add(time.Hour), time.Time{}, 0, errs.ValidationError},
+		{"unpublishing before publication", ad.ID, author.ID, true, now.Add(2 * time.Hour), now.Add(time.Hour), 0, errs.ValidationError},
+		{"unpublishing of unpublished", ad.ID, author.ID, false, time.Time{}, now.Add(time.Hour), 0, errs.ValidationError},
+		{"another author", ad.ID, unverified.ID, true, now.Add(time.Hour), time.Time{}, 0, errs.AccessError},
+		{"stale version", ad.ID, author.ID, true, now.Add(time.Hour), time.Time{}, ad.Version + 1, errs.VersionConflictError},
+		{"unverified author", draft.ID, unverified.ID, true, now.Add(time.Hour), time.Time{}, 0, errs.EmailNotVerifiedError},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := a.ChangeAdStatus(ctx, tc.adID, tc.uID, tc.published, tc.publishAt, tc.unpublishAt, tc.version)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	pending, err := a.ListTransitions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, pending, "rejected transitions are not scheduled")
+}
+
+func TestCancelTransition(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo)
+	author := verifiedUser(t, userRepo, "James", "james@example.com")
+	other := verifiedUser(t, userRepo, "Mary", "mary@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "hello", "world", "")
+	assert.NoError(t, err)
+	_, err = a.ChangeAdStatus(ctx, ad.ID, author.ID, true, time.Now().Add(time.Hour), time.Time{}, 0)
+	assert.NoError(t, err)
+	pending, err := a.ListTransitions(ctx, ad.ID, author.ID)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	_, err = a.ListTransitions(ctx, ad.ID, other.ID)
+	assert.ErrorIs(t, err, errs.AccessError)
+	assert.ErrorIs(t, a.CancelTransition(ctx, ad.ID, pending[0].ID, other.ID), errs.AccessError)
+
+	assert.NoError(t, a.CancelTransition(ctx, ad.ID, pending[0].ID, author.ID))
+	assert.ErrorIs(t, a.CancelTransition(ctx, ad.ID, pending[0].ID, author.ID), errs.TransitionNotFoundError)
+
+	report, err := a.MakeDueTransitions(ctx, time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, report.Done)
+}
+
+func TestFailedTransitionDropped(t *testing.T) {
+	ctx := context.Background()
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	a := app.NewApp(adRepo, userRepo)
+	author := verifiedUser(t, userRepo, "James", "james@example.com")
+
+	ad, _, err := a.CreateAd(ctx, author.ID, "hello", "world", "")
+	assert.NoError(t, err)
+	_, err = a.ChangeAdStatus(ctx, ad.ID, author.ID, true, time.Now().Add(time.Hour), time.Time{}, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, a.DeleteAd(ctx, ad.ID, author.ID, 0))
+
+	report, err := a.MakeDueTransitions(ctx, time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, report.Done)
+	assert.Len(t, report.Failed, 1)
+
+	due, err := adRepo.DueTransitions(ctx, time.Now().Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, due, "failed transitions are not retried")
+}
+
+func TestScheduleHTTP(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	publishAt, unpublishAt := time.Now().Add(time.Hour).UTC(), time.Now().Add(2*time.Hour).UTC()
+	scheduled, err := client.scheduleAdStatus(author.Data.ID, ad.Data.ID, true, &publishAt, &unpublishAt)
+	assert.NoError(t, err)
+	assert.False(t, scheduled.Data.Published)
+
+	_, err = client.scheduleAdStatus(author.Data.ID, ad.Data.ID, false, &publishAt, nil)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	pending, err := client.listTransitions(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, pending.Data, 2) {
+		assert.True(t, pending.Data[0].Published)
+		assert.True(t, publishAt.Equal(pending.Data[0].At))
+		assert.False(t, pending.Data[1].Published)
+	}
+
+	_, err = client.listTransitions(other.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, client.cancelTransition(other.Data.ID, ad.Data.ID, pending.Data[0].ID), ErrForbidden)
+
+	assert.NoError(t, client.cancelTransition(author.Data.ID, ad.Data.ID, pending.Data[0].ID))
+	assert.ErrorIs(t, client.cancelTransition(author.Data.ID, ad.Data.ID, pending.Data[0].ID), ErrNotFound)
+
+	pending, err = client.listTransitions(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, pending.Data, 1)
+}
+
+func TestGRPCSchedule(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	adRepo, userRepo := repo.NewAd(), repo.NewUser()
+	svc := grpcPort.NewAdService(app.NewApp(adRepo, userRepo))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	author := verifiedUser(t, userRepo, "Oleg", "oleg@example.com")
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: author.ID, Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+
+	publishAt := time.Now().Add(time.Hour)
+	scheduled, err := client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{
+		AdId: ad.Id, UserId: author.ID, Published: true, PublishAt: timestamppb.New(publishAt),
+	})
+	assert.NoError(t, err)
+	assert.False(t, scheduled.Published)
+
+	pending, err := client.ListScheduledTransitions(ctx, &grpc2.ListScheduledTransitionsRequest{AdId: ad.Id, UserId: author.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, pending.List, 1) {
+		assert.True(t, pending.List[0].Published)
+		assert.True(t, publishAt.Equal(pending.List[0].At.AsTime()))
+	}
+
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{
+		AdId: ad.Id, UserId: author.ID, Published: true, PublishAt: timestamppb.New(time.Now().Add(-time.Hour)),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CancelScheduledTransition(ctx, &grpc2.CancelScheduledTransitionRequest{AdId: ad.Id, TransitionId: pending.List[0].Id, UserId: author.ID})
+	assert.NoError(t, err)
+	_, err = client.CancelScheduledTransition(ctx, &grpc2.CancelScheduledTransitionRequest{AdId: ad.Id, TransitionId: pending.List[0].Id, UserId: author.ID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	grpcPort "ads-server/internal/ports/grpc"
+	grpc2 "ads-server/proto"
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	spanRecorder     *tracetest.SpanRecorder
+	spanRecorderOnce sync.Once
+)
+
+// recordSpans installs a tracer provider keeping finished spans in memory.
+// Package level tracers are bound to the first global provider, so it is installed only once.
+func recordSpans() *tracetest.SpanRecorder {
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return spanRecorder
+}
+
+// traceParent returns W3C traceparent header value for the trace ID given
+func traceParent(traceID string) string {
+	return "00-" + traceID + "-00f067aa0ba902b7-01"
+}
+
+// spansByName indexes ended spans of the trace given by their names
+func spansByName(sr *tracetest.SpanRecorder, traceID string) map[string]sdktrace.ReadOnlySpan {
+	res := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range sr.Ended() {
+		if s.SpanContext().TraceID().String() == traceID {
+			res[s.Name()] = s
+		}
+	}
+	return res
+}
+
+func TestHTTPTracePropagation(t *testing.T) {
+	sr := recordSpans()
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	client := getTestClient()
+
+	req, err := http.NewRequest(http.MethodPost, client.baseURL+"/api/v1/user",
+		bytes.NewReader([]byte(`{"name": "James", "email": "ostin@example.com"}`)))
+	assert.NoError(t, err)
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("traceparent", traceParent(traceID))
+
+	var response userResponse
+	assert.NoError(t, client.getResponse(req, &response))
+
+	spans := spansByName(sr, traceID)
+	for _, name := range []string{"POST /api/v1/user", "App.CreateUser", "UnitOfWork.Do", "UsersRepo.Create"} {
+		assert.Containsf(t, spans, name, "span %s not recorded", name)
+	}
+	assert.Equal(t, spans["POST /api/v1/user"].SpanContext().SpanID(), spans["App.CreateUser"].Parent().SpanID())
+	assert.Equal(t, spans["App.CreateUser"].SpanContext().SpanID(), spans["UnitOfWork.Do"].Parent().SpanID())
+	assert.Equal(t, spans["UnitOfWork.Do"].SpanContext().SpanID(), spans["UsersRepo.Create"].Parent().SpanID())
+}
+
+func TestGRPCTracePropagation(t *testing.T) {
+	sr := recordSpans()
+	traceID := "5bf92f3577b34da6a3ce929d0e0e4737"
+
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpcPort.NewGRPCServer(repo.NewAd(), repo.NewUser())
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+	_, err = client.CreateUser(metadata.AppendToOutgoingContext(ctx, "traceparent", traceParent(traceID)),
+		&grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err, "client.CreateUser")
+
+	spans := spansByName(sr, traceID)
+	for _, name := range []string{"ad.AdService/CreateUser", "App.CreateUser", "UsersRepo.Create"} {
+		assert.Containsf(t, spans, name, "span %s not recorded", name)
+	}
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	grpcPort "ads-server/internal/ports/grpc"
+	"ads-server/internal/users"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var admins = app.WithStaff(map[string]users.Role{"admin@example.com": users.RoleAdmin})
+
+func TestRestoreAd(t *testing.T) {
+	client := getTestClient(admins)
+
+	author, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+	admin, err := client.createUser(2, "Root", "admin@example.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	assert.NoError(t, client.deleteAd(author.Data.ID, ad.Data.ID))
+
+	trash, err := client.listTrash(author.Data.ID, author.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, trash.Data, 1)
+	assert.Equal(t, ad.Data.ID, trash.Data[0].ID)
+	assert.NotEmpty(t, trash.Data[0].DeletedAt)
+
+	_, err = client.listTrash(other.Data.ID, author.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.restoreAd(other.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	restored, err := client.restoreAd(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, restored.Data.DeletedAt)
+	assert.Equal(t, ad.Data.Version+2, restored.Data.Version, "deletion and restore bump the version")
+
+	_, err = client.getAdByID(ad.Data.ID)
+	assert.NoError(t, err)
+	_, err = client.restoreAd(author.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "live ad is not in trash")
+
+	// admins can look into trash of anyone and restore any ad
+	assert.NoError(t, client.deleteAd(author.Data.ID, ad.Data.ID))
+	trash, err = client.listTrash(admin.Data.ID, author.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, trash.Data, 1)
+	_, err = client.restoreAd(admin.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+
+	history, err := client.listRevisions(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	var actions []string
+	for _, r := range history.Data {
+		actions = append(actions, r.Action)
+	}
+	assert.Equal(t, []string{"create", "delete", "restore", "delete", "restore"}, actions)
+}
+
+func TestRestoreUserWithAds(t *testing.T) {
+	client := getTestClient(app.WithDeletionPolicy(app.DeleteCascade))
+	userID, published, draft := userWithAds(t, client)
+
+	_, err := client.deleteUser(userID)
+	assert.NoError(t, err)
+	_, err = client.getUser(userID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.registerUser(1, "James", "james@example.com")
+	assert.Error(t, err, "email stays reserved while the user is in trash")
+
+	user, err := client.restoreUser(userID, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, userID, user.Data.ID)
+
+	for _, id := range []int64{published, draft} {
+		_, err = client.getAdByID(id)
+		assert.NoError(t, err)
+	}
+	ad, err := client.getAdByID(published)
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published)
+
+	_, err = client.restoreUser(userID, userID)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity, "live user is not in trash")
+}
+
+func TestRestoreUserForbidden(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	other, err := client.createUser(1, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+	_, err = client.deleteUser(user.Data.ID)
+	assert.NoError(t, err)
+
+	_, err = client.restoreUser(other.Data.ID, user.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	retention := time.Hour
+	a := app.NewApp(repo.NewAd(), repo.NewUser(), app.WithTrash(app.TrashConfig{Retention: retention, PurgeInterval: time.Minute}))
+
+	user, err := a.CreateUser(ctx, "James", "james@example.com")
+	assert.NoError(t, err)
+	ad, _, err := a.CreateAd(ctx, user.ID, "hello", "world", "")
+	assert.NoError(t, err)
+	assert.NoError(t, a.DeleteAd(ctx, ad.ID, user.ID, 0))
+	_, err = a.DeleteUser(ctx, user.ID, 0)
+	assert.NoError(t, err)
+
+	report, err := a.Purge(ctx, time.Now().UTC())
+	assert.NoError(t, err)
+	assert.Empty(t, report.Ads, "items are kept for the retention period")
+	assert.Empty(t, report.Users)
+
+	report, err = a.Purge(ctx, time.Now().UTC().Add(retention+time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{ad.ID}, report.Ads)
+	assert.Equal(t, []int64{user.ID}, report.Users)
+
+	_, err = a.RestoreAd(ctx, ad.ID, user.ID)
+	assert.Error(t, err)
+	_, err = a.RestoreUser(ctx, user.ID, user.ID)
+	assert.Error(t, err)
+
+	// the email is free again once the user is purged
+	_, err = a.CreateUser(ctx, "James", "james@example.com")
+	assert.NoError(t, err)
+}
+
+func TestGRPCTrash(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser()))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	other, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Olga", Email: "olga@example.com"})
+	assert.NoError(t, err)
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: user.Id, Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+	_, err = client.DeleteAd(ctx, &grpc2.DeleteAdRequest{AdId: ad.Id, AuthorId: user.Id})
+	assert.NoError(t, err)
+
+	trash, err := client.ListTrash(ctx, &grpc2.ListTrashRequest{UserId: user.Id, ActorId: user.Id})
+	assert.NoError(t, err)
+	assert.Len(t, trash.List, 1)
+	assert.NotNil(t, trash.List[0].DeletedAt)
+
+	_, err = client.RestoreAd(ctx, &grpc2.RestoreAdRequest{AdId: ad.Id, UserId: other.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	restored, err := client.RestoreAd(ctx, &grpc2.RestoreAdRequest{AdId: ad.Id, UserId: user.Id})
+	assert.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+
+	_, err = client.DeleteUser(ctx, &grpc2.DeleteUserRequest{Id: user.Id})
+	assert.NoError(t, err)
+	_, err = client.RestoreUser(ctx, &grpc2.RestoreUserRequest{Id: user.Id, ActorId: other.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	restoredUser, err := client.RestoreUser(ctx, &grpc2.RestoreUserRequest{Id: user.Id, ActorId: user.Id})
+	assert.NoError(t, err)
+	assert.Nil(t, restoredUser.DeletedAt)
+}
@@ -25,10 +25,10 @@ type table struct {
 func TestCreateUsers(t *testing.T) {
 
 	var testTable = [...]table{
-		{0, "Jonh", "os"},
-		{1, "Mary", "tos"},
-		{2, "Craig", "nos"},
-		{3, "Kate", "ros"},
+		{0, "Jonh", "os@example.com"},
+		{1, "Mary", "tos@example.com"},
+		{2, "Craig", "nos@example.com"},
+		{3, "Kate", "ros@example.com"},
 	}
 
 	lis := bufconn.Listen(1024 * 1024)
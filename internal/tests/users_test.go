@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUser(t *testing.T) {
+	client := getTestClient()
+
+	created, err := client.createUser(0, "James", "ostin@example.com")
+	assert.NoError(t, err)
+
+	user, err := client.getUser(created.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, created.Data, user.Data)
+
+	_, err = client.getUser(42)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpdateUser(t *testing.T) {
+	client := getTestClient()
+
+	created, err := client.createUser(0, "James", "ostin@example.com")
+	assert.NoError(t, err)
+
+	user, err := client.updateUser(created.Data.ID, "Mary", "poppins@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Mary", user.Data.Name)
+	assert.Equal(t, "poppins@example.com", user.Data.Email)
+
+	_, err = client.updateUser(42, "Mary", "poppins@example.com")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPatchUser(t *testing.T) {
+	client := getTestClient()
+
+	created, err := client.createUser(0, "James", "ostin@example.com")
+	assert.NoError(t, err)
+
+	user, err := client.patchUser(created.Data.ID, map[string]any{"email": "bond@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "James", user.Data.Name)
+	assert.Equal(t, "bond@example.com", user.Data.Email)
+
+	_, err = client.patchUser(42, map[string]any{"name": "Mary"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeleteUser(t *testing.T) {
+	client := getTestClient()
+
+	created, err := client.createUser(0, "James", "ostin@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.deleteUser(created.Data.ID)
+	assert.NoError(t, err)
+
+	_, err = client.getUser(created.Data.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.deleteUser(created.Data.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeleteAd(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createUser(0, "James", "ostin@example.com")
+	assert.NoError(t, err)
+	_, err = client.createUser(1, "Name", "mas@example.com")
+	assert.NoError(t, err)
+
+	resp, err := client.createAd(0, "hello", "world")
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, client.deleteAd(1, resp.Data.ID), ErrForbidden)
+	assert.NoError(t, client.deleteAd(0, resp.Data.ID))
+
+	_, err = client.getAdByID(resp.Data.ID)
+	assert.Error(t, err)
+	assert.ErrorIs(t, client.deleteAd(0, resp.Data.ID), ErrNotFound)
+}
+
+func TestListUserAds(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createUser(0, "James", "ostin@example.com")
+	assert.NoError(t, err)
+	_, err = client.createUser(1, "Name", "mas@example.com")
+	assert.NoError(t, err)
+
+	published, err := client.createAd(0, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(0, published.Data.ID, true)
+	assert.NoError(t, err)
+
+	_, err = client.createAd(0, "best cat", "not for sale")
+	assert.NoError(t, err)
+	_, err = client.createAd(1, "best dog", "not for sale")
+	assert.NoError(t, err)
+
+	ads, err := client.listUserAds(0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 2)
+	for _, ad := range ads.Data {
+		assert.Equal(t, int64(0), ad.AuthorID)
+	}
+
+	ads, err = client.listUserAds(0, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, ads.Data, 1, "other users see published ads only") {
+		assert.Equal(t, published.Data.ID, ads.Data[0].ID)
+	}
+	var anonymous adsResponse
+	assert.NoError(t, client.call(http.MethodGet, "/api/v1/users/0/ads", nil, &anonymous))
+	assert.Len(t, anonymous.Data, 1, "so do anonymous visitors")
+
+	_, err = client.listUserAds(42, 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.listUserAds(0, 42)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestListUserAdsModerator(t *testing.T) {
+	client := getTestClient(moderators)
+	seller, err := client.createUser(0, "Oleg", "oleg@example.com")
+	assert.NoError(t, err)
+	moderator, err := client.createUser(1, "Maria", "moderator@example.com")
+	assert.NoError(t, err)
+	_, err = client.createAd(seller.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	ads, err := client.listUserAds(seller.Data.ID, moderator.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 1, "moderators see unpublished ads")
+}
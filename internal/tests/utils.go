@@ -5,25 +5,52 @@ import (
 	"ads-server/internal/app"
 	"ads-server/internal/ports/httpgin"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"sync"
+	"time"
 )
 
 type userData struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	Verified   bool   `json:"verified"`
+	Version    int64  `json:"version"`
+	Role       string `json:"role"`
+	Reputation struct {
+		Rating  float64 `json:"rating"`
+		Reviews int     `json:"reviews"`
+	} `json:"reputation"`
 }
 
 type adData struct {
-	ID        int64  `json:"id"`
-	Title     string `json:"title"`
-	Text      string `json:"text"`
-	AuthorID  int64  `json:"author_id"`
-	Published bool   `json:"published"`
+	ID           int64             `json:"id"`
+	Title        string            `json:"title"`
+	Text         string            `json:"text"`
+	AuthorID     int64             `json:"author_id"`
+	Published    bool              `json:"published"`
+	Version      int64             `json:"version"`
+	DeletedAt    string            `json:"deleted_at"`
+	Category     string            `json:"category"`
+	ExpiresAt    string            `json:"expires_at"`
+	ArchivedAt   string            `json:"archived_at"`
+	TakenDownAt  string            `json:"taken_down_at"`
+	CreatedAt    string            `json:"create"`
+	UpdatedAt    string            `json:"update"`
+	ContentFlags []contentFlagData `json:"content_flags"`
+}
+
+type contentFlagData struct {
+	Checker string `json:"checker"`
+	Field   string `json:"field"`
+	Reason  string `json:"reason"`
+	Masked  bool   `json:"masked"`
 }
 
 type adResponse struct {
@@ -34,27 +61,177 @@ type userResponse struct {
 	Data userData `json:"data"`
 }
 
+type deletionResponse struct {
+	Data struct {
+		UserID        int64   `json:"user_id"`
+		Policy        string  `json:"policy"`
+		DeletedAds    []int64 `json:"deleted_ads"`
+		AnonymizedAds []int64 `json:"anonymized_ads"`
+	} `json:"data"`
+}
+
+type fieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type revisionData struct {
+	AdID         int64         `json:"ad_id"`
+	Number       int64         `json:"number"`
+	Action       string        `json:"action"`
+	EditorID     int64         `json:"editor_id"`
+	RestoredFrom int64         `json:"restored_from"`
+	Title        string        `json:"title"`
+	Text         string        `json:"text"`
+	Published    bool          `json:"published"`
+	Changes      []fieldChange `json:"changes"`
+}
+
+type revisionResponse struct {
+	Data revisionData `json:"data"`
+}
+
+type revisionsResponse struct {
+	Data []revisionData `json:"data"`
+}
+
+type approvalData struct {
+	AdID        int64 `json:"ad_id"`
+	Revision    int64 `json:"revision"`
+	ModeratorID int64 `json:"moderator_id"`
+}
+
+type approvalResponse struct {
+	Data approvalData `json:"data"`
+}
+
+type changesResponse struct {
+	Data struct {
+		AdID     int64         `json:"ad_id"`
+		Approval *approvalData `json:"approval"`
+		Revision int64         `json:"revision"`
+		Changes  []fieldChange `json:"changes"`
+	} `json:"data"`
+}
+
+type auditEntryData struct {
+	Seq        int64           `json:"seq"`
+	ActorID    int64           `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   int64           `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	Transport  string          `json:"transport"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+type transitionData struct {
+	ID        int64     `json:"id"`
+	AdID      int64     `json:"ad_id"`
+	UserID    int64     `json:"user_id"`
+	Published bool      `json:"published"`
+	At        time.Time `json:"at"`
+}
+
+type transitionsResponse struct {
+	Data []transitionData `json:"data"`
+}
+
+type auditEntriesResponse struct {
+	Data []auditEntryData `json:"data"`
+}
+
+type auditVerificationResponse struct {
+	Data struct {
+		Entries  int64  `json:"entries"`
+		Head     string `json:"head"`
+		Valid    bool   `json:"valid"`
+		BrokenAt int64  `json:"broken_at"`
+	} `json:"data"`
+}
+
 type adsResponse struct {
 	Data []adData `json:"data"`
 }
 
 var (
-	ErrBadRequest = fmt.Errorf("bad request")
-	ErrForbidden  = fmt.Errorf("forbidden")
+	ErrBadRequest          = fmt.Errorf("bad request")
+	ErrUnauthorized        = fmt.Errorf("unauthorized")
+	ErrForbidden           = fmt.Errorf("forbidden")
+	ErrNotFound            = fmt.Errorf("not found")
+	ErrConflict            = fmt.Errorf("conflict")
+	ErrUnprocessableEntity = fmt.Errorf("unprocessable entity")
+	ErrTooManyRequests     = fmt.Errorf("too many requests")
+	ErrPreconditionFailed  = fmt.Errorf("precondition failed")
 )
 
+// mailbox keeps emails sent by the app instead of delivering them
+type mailbox struct {
+	mx    sync.Mutex
+	mails []app.Mail
+}
+
+func (m *mailbox) Send(_ context.Context, mail app.Mail) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+var confirmationCode = regexp.MustCompile(`confirmation code: (\w+)`)
+
+// token returns verification token from the last email sent to the address given
+func (m *mailbox) token(email string) string {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	for i := len(m.mails) - 1; i >= 0; i-- {
+		if m.mails[i].To == email {
+			if match := confirmationCode.FindStringSubmatch(m.mails[i].Body); match != nil {
+				return match[1]
+			}
+		}
+	}
+	return ""
+}
+
+// count returns the number of emails sent to the address given
+func (m *mailbox) count(email string) int {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	n := 0
+	for _, mail := range m.mails {
+		if mail.To == email {
+			n++
+		}
+	}
+	return n
+}
+
 type testClient struct {
 	client  *http.Client
 	baseURL string
+	mailbox *mailbox
 }
 
-func getTestClient() *testClient {
-	server := httpgin.NewHTTPServer(":18080", app.NewApp(repo.NewAd(), repo.NewUser()))
+func getTestClient(opts ...app.Option) *testClient {
+	return getTestClientWithRepos(repo.NewAd(), repo.NewUser(), opts...)
+}
+
+// getTestClientWithRepos serves the app over the repositories given
+func getTestClientWithRepos(adRepo app.AdRepository, userRepo app.UserRepository, opts ...app.Option) *testClient {
+	mb := &mailbox{}
+	opts = append([]app.Option{app.WithMailSender(mb)}, opts...)
+	server := httpgin.NewHTTPServer(":18080", app.NewApp(adRepo, userRepo, opts...))
 	testServer := httptest.NewServer(server.Handler)
 
 	return &testClient{
 		client:  testServer.Client(),
 		baseURL: testServer.URL,
+		mailbox: mb,
 	}
 }
 
@@ -63,13 +240,34 @@ func (tc *testClient) getResponse(req *http.Request, out any) error {
 	if err != nil {
 		return fmt.Errorf("unexpected error: %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		if resp.StatusCode == http.StatusBadRequest {
 			return ErrBadRequest
 		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return ErrUnauthorized
+		}
 		if resp.StatusCode == http.StatusForbidden {
 			return ErrForbidden
 		}
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrNotFound
+		}
+		if resp.StatusCode == http.StatusConflict {
+			return ErrConflict
+		}
+		if resp.StatusCode == http.StatusUnprocessableEntity {
+			return ErrUnprocessableEntity
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return ErrTooManyRequests
+		}
+		if resp.StatusCode == http.StatusPreconditionFailed {
+			return ErrPreconditionFailed
+		}
 		return fmt.Errorf("unexpected status code: %s", resp.Status)
 	}
 
@@ -86,6 +284,35 @@ func (tc *testClient) getResponse(req *http.Request, out any) error {
 	return nil
 }
 
+// send makes a request with JSON body (if any) and extra headers, returning the raw response
+func (tc *testClient) send(method string, path string, body any, headers map[string]string) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, tc.baseURL+path, r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
+
+	resp, err := tc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+	return resp, nil
+}
+
 func (tc *testClient) getAdByID(adID int64) (adResponse, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/ads/%d/info", tc.baseURL, adID), nil)
 	if err != nil {
@@ -129,7 +356,17 @@ func (tc *testClient) createAd(userID int64, title string, text string) (adRespo
 	return response, nil
 }
 
+// createUser registers a user and confirms its email, so the user can publish ads
 func (tc *testClient) createUser(userID int64, name string, email string) (userResponse, error) {
+	if _, err := tc.registerUser(userID, name, email); err != nil {
+		return userResponse{}, err
+	}
+
+	return tc.confirmEmail(tc.mailbox.token(email))
+}
+
+// registerUser creates a user leaving its email unverified
+func (tc *testClient) registerUser(userID int64, name string, email string) (userResponse, error) {
 	body := map[string]any{
 		"id":    userID,
 		"name":  name,
@@ -243,3 +480,528 @@ func (tc *testClient) adsWithFilters(query string) (adsResponse, error) {
 
 	return response, nil
 }
+
+func (tc *testClient) getUser(userID int64) (userResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/users/%d", tc.baseURL, userID), nil)
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response userResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return userResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) changeUser(method string, userID int64, body map[string]any) (userResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(method, fmt.Sprintf("%s/api/v1/users/%d", tc.baseURL, userID), bytes.NewReader(data))
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	var response userResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return userResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) updateUser(userID int64, name string, email string) (userResponse, error) {
+	return tc.changeUser(http.MethodPut, userID, map[string]any{
+		"name":  name,
+		"email": email,
+	})
+}
+
+func (tc *testClient) patchUser(userID int64, fields map[string]any) (userResponse, error) {
+	return tc.changeUser(http.MethodPatch, userID, fields)
+}
+
+func (tc *testClient) deleteUser(userID int64) (deletionResponse, error) {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/users/%d", tc.baseURL, userID), nil)
+	if err != nil {
+		return deletionResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response deletionResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return deletionResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) deleteAd(userID int64, adID int64) error {
+	data, err := json.Marshal(map[string]any{"user_id": userID})
+	if err != nil {
+		return fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/api/v1/ads/%d", tc.baseURL, adID), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	return tc.getResponse(req, nil)
+}
+
+// listUserAds lists ads of the user as the actor given
+func (tc *testClient) listUserAds(userID int64, actorID int64) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/users/%d/ads?user_id=%d", tc.baseURL, userID, actorID), nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) confirmEmail(token string) (userResponse, error) {
+	data, err := json.Marshal(map[string]any{"token": token})
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tc.baseURL+"/api/v1/users/verify", bytes.NewReader(data))
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	var response userResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return userResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) resendVerification(userID int64) error {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/users/%d/verification", tc.baseURL, userID), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	return tc.getResponse(req, nil)
+}
+
+// call makes a request with JSON body (if any) decoding successful response into out
+func (tc *testClient) call(method string, path string, body any, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("unable to marshal: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, tc.baseURL+path, r)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+
+	return tc.getResponse(req, out)
+}
+
+func (tc *testClient) listRevisions(userID int64, adID int64) (revisionsResponse, error) {
+	var response revisionsResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/ads/%d/revisions?user_id=%d", adID, userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) getRevision(userID int64, adID int64, number int64) (revisionResponse, error) {
+	var response revisionResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/ads/%d/revisions/%d?user_id=%d", adID, number, userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) rollbackAd(userID int64, adID int64, number int64) (adResponse, error) {
+	var response adResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/revisions/%d/rollback", adID, number),
+		map[string]any{"user_id": userID}, &response)
+	return response, err
+}
+
+func (tc *testClient) approveAd(userID int64, adID int64) (approvalResponse, error) {
+	var response approvalResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/approve", adID), map[string]any{"user_id": userID}, &response)
+	return response, err
+}
+
+func (tc *testClient) adChanges(userID int64, adID int64) (changesResponse, error) {
+	var response changesResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/ads/%d/changes?user_id=%d", adID, userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) listTrash(actorID int64, userID int64) (adsResponse, error) {
+	var response adsResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/users/%d/trash?user_id=%d", userID, actorID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) restoreAd(userID int64, adID int64) (adResponse, error) {
+	var response adResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/restore", adID), map[string]any{"user_id": userID}, &response)
+	return response, err
+}
+
+func (tc *testClient) restoreUser(actorID int64, userID int64) (userResponse, error) {
+	var response userResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/restore", userID), map[string]any{"user_id": actorID}, &response)
+	return response, err
+}
+
+// auditEntries queries the audit log, query holds filters in URL form
+func (tc *testClient) auditEntries(userID int64, query string) (auditEntriesResponse, error) {
+	var response auditEntriesResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/audit?user_id=%d&%s", userID, query), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) verifyAudit(userID int64) (auditVerificationResponse, error) {
+	var response auditVerificationResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/audit/verify?user_id=%d", userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) createAdInCategory(userID int64, title string, text string, category string) (adResponse, error) {
+	var response adResponse
+	err := tc.call(http.MethodPost, "/api/v1/ads",
+		map[string]any{"user_id": userID, "title": title, "text": text, "category": category}, &response)
+	return response, err
+}
+
+func (tc *testClient) renewAd(userID int64, adID int64) (adResponse, error) {
+	var response adResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/renew", adID), map[string]any{"user_id": userID}, &response)
+	return response, err
+}
+
+func (tc *testClient) extendAd(userID int64, adID int64, days int) (adResponse, error) {
+	var response adResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/extend", adID),
+		map[string]any{"user_id": userID, "days": days}, &response)
+	return response, err
+}
+
+func (tc *testClient) scheduleAdStatus(userID int64, adID int64, published bool, publishAt, unpublishAt *time.Time) (adResponse, error) {
+	body := map[string]any{"user_id": userID, "published": published}
+	if publishAt != nil {
+		body["publish_at"] = publishAt
+	}
+	if unpublishAt != nil {
+		body["unpublish_at"] = unpublishAt
+	}
+	var response adResponse
+	err := tc.call(http.MethodPut, fmt.Sprintf("/api/v1/ads/%d/status", adID), body, &response)
+	return response, err
+}
+
+func (tc *testClient) listTransitions(userID int64, adID int64) (transitionsResponse, error) {
+	var response transitionsResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/ads/%d/schedule?user_id=%d", adID, userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) cancelTransition(userID int64, adID int64, transitionID int64) error {
+	return tc.call(http.MethodDelete, fmt.Sprintf("/api/v1/ads/%d/schedule/%d", adID, transitionID), map[string]any{"user_id": userID}, nil)
+}
+
+type favoriteAdData struct {
+	Ad      adData    `json:"ad"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+type favoritesResponse struct {
+	Data []favoriteAdData `json:"data"`
+}
+
+type favoriteCountResponse struct {
+	Data struct {
+		AdID  int64 `json:"ad_id"`
+		Count int   `json:"count"`
+	} `json:"data"`
+}
+
+type favoriteResponse struct {
+	Data struct {
+		UserID    int64     `json:"user_id"`
+		AdID      int64     `json:"ad_id"`
+		CreatedAt time.Time `json:"created_at"`
+	} `json:"data"`
+}
+
+func (tc *testClient) addFavorite(userID int64, adID int64) error {
+	var response favoriteResponse
+	return tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/favorite", adID), map[string]any{"user_id": userID}, &response)
+}
+
+func (tc *testClient) removeFavorite(userID int64, adID int64) error {
+	return tc.call(http.MethodDelete, fmt.Sprintf("/api/v1/ads/%d/favorite", adID), map[string]any{"user_id": userID}, nil)
+}
+
+func (tc *testClient) listFavorites(actorID int64, userID int64) (favoritesResponse, error) {
+	var response favoritesResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/users/%d/favorites?user_id=%d", userID, actorID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) countFavorites(userID int64, adID int64) (favoriteCountResponse, error) {
+	var response favoriteCountResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/ads/%d/favorites/count?user_id=%d", adID, userID), nil, &response)
+	return response, err
+}
+
+type conversationData struct {
+	ID            int64  `json:"id"`
+	AdID          int64  `json:"ad_id"`
+	SellerID      int64  `json:"seller_id"`
+	BuyerID       int64  `json:"buyer_id"`
+	Unread        int    `json:"unread"`
+	LastMessageAt string `json:"last_message_at"`
+}
+
+type conversationResponse struct {
+	Data conversationData `json:"data"`
+}
+
+type conversationsResponse struct {
+	Data []conversationData `json:"data"`
+}
+
+type messageData struct {
+	ID             int64  `json:"id"`
+	ConversationID int64  `json:"conversation_id"`
+	SenderID       int64  `json:"sender_id"`
+	Text           string `json:"text"`
+}
+
+type messageResponse struct {
+	Data messageData `json:"data"`
+}
+
+type messagesResponse struct {
+	Data []messageData `json:"data"`
+}
+
+type blocksResponse struct {
+	Data []struct {
+		UserID    int64 `json:"user_id"`
+		BlockedID int64 `json:"blocked_id"`
+	} `json:"data"`
+}
+
+func (tc *testClient) startConversation(userID int64, adID int64) (conversationResponse, error) {
+	var response conversationResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/conversations", adID), map[string]any{"user_id": userID}, &response)
+	return response, err
+}
+
+func (tc *testClient) listConversations(userID int64) (conversationsResponse, error) {
+	var response conversationsResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/conversations?user_id=%d", userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) sendMessage(userID int64, conversationID int64, text string) (messageResponse, error) {
+	var response messageResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/conversations/%d/messages", conversationID),
+		map[string]any{"user_id": userID, "text": text}, &response)
+	return response, err
+}
+
+// listMessages returns a page of messages, query holds before and limit in URL form
+func (tc *testClient) listMessages(userID int64, conversationID int64, query string) (messagesResponse, error) {
+	var response messagesResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/conversations/%d/messages?user_id=%d&%s", conversationID, userID, query), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) blockUser(userID int64, blockedID int64) error {
+	var response map[string]any
+	return tc.call(http.MethodPost, fmt.Sprintf("/api/v1/users/%d/block", blockedID), map[string]any{"user_id": userID}, &response)
+}
+
+func (tc *testClient) unblockUser(userID int64, blockedID int64) error {
+	return tc.call(http.MethodDelete, fmt.Sprintf("/api/v1/users/%d/block", blockedID), map[string]any{"user_id": userID}, nil)
+}
+
+func (tc *testClient) listBlocked(actorID int64, userID int64) (blocksResponse, error) {
+	var response blocksResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/users/%d/blocked?user_id=%d", userID, actorID), nil, &response)
+	return response, err
+}
+
+type reviewData struct {
+	ID       int64  `json:"id"`
+	SellerID int64  `json:"seller_id"`
+	AuthorID int64  `json:"author_id"`
+	AdID     int64  `json:"ad_id"`
+	Rating   int    `json:"rating"`
+	Text     string `json:"text"`
+	Reply    string `json:"reply"`
+	Hidden   bool   `json:"hidden"`
+}
+
+type reviewResponse struct {
+	Data reviewData `json:"data"`
+}
+
+type reviewsResponse struct {
+	Data []reviewData `json:"data"`
+}
+
+func (tc *testClient) reviewSeller(userID int64, adID int64, rating int, text string) (reviewResponse, error) {
+	var response reviewResponse
+	err := tc.call(http.MethodPost, "/api/v1/reviews",
+		map[string]any{"user_id": userID, "ad_id": adID, "rating": rating, "text": text}, &response)
+	return response, err
+}
+
+// listReviews lists reviews of the seller, query holds the optional acting user in URL form
+func (tc *testClient) listReviews(sellerID int64, query string) (reviewsResponse, error) {
+	var response reviewsResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/users/%d/reviews?%s", sellerID, query), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) replyToReview(userID int64, reviewID int64, text string) (reviewResponse, error) {
+	var response reviewResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/reviews/%d/reply", reviewID),
+		map[string]any{"user_id": userID, "text": text}, &response)
+	return response, err
+}
+
+func (tc *testClient) hideReview(userID int64, reviewID int64, hidden bool) (reviewResponse, error) {
+	var response reviewResponse
+	err := tc.call(http.MethodPut, fmt.Sprintf("/api/v1/reviews/%d/hidden", reviewID),
+		map[string]any{"user_id": userID, "hidden": hidden}, &response)
+	return response, err
+}
+
+type reportData struct {
+	ID         int64  `json:"id"`
+	AdID       int64  `json:"ad_id"`
+	ReporterID int64  `json:"reporter_id"`
+	Reason     string `json:"reason"`
+	Comment    string `json:"comment"`
+	Outcome    string `json:"outcome"`
+	ResolvedBy int64  `json:"resolved_by"`
+}
+
+type reportResponse struct {
+	Data reportData `json:"data"`
+}
+
+type reportsResponse struct {
+	Data []reportData `json:"data"`
+}
+
+type reportInboxResponse struct {
+	Data []struct {
+		Ad      adData       `json:"ad"`
+		Reports []reportData `json:"reports"`
+	} `json:"data"`
+}
+
+type appealData struct {
+	ID          int64  `json:"id"`
+	AdID        int64  `json:"ad_id"`
+	AuthorID    int64  `json:"author_id"`
+	Comment     string `json:"comment"`
+	Status      string `json:"status"`
+	ModeratorID int64  `json:"moderator_id"`
+}
+
+type appealResponse struct {
+	Data appealData `json:"data"`
+}
+
+type appealsResponse struct {
+	Data []appealData `json:"data"`
+}
+
+func (tc *testClient) reportAd(userID int64, adID int64, reason, comment string) (reportResponse, error) {
+	var response reportResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/reports", adID),
+		map[string]any{"user_id": userID, "reason": reason, "comment": comment}, &response)
+	return response, err
+}
+
+func (tc *testClient) listReportedAds(userID int64) (reportInboxResponse, error) {
+	var response reportInboxResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/reports?user_id=%d", userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) resolveReports(userID int64, adID int64, takedown bool) (reportsResponse, error) {
+	var response reportsResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/reports/resolve", adID),
+		map[string]any{"user_id": userID, "takedown": takedown}, &response)
+	return response, err
+}
+
+func (tc *testClient) appealTakedown(userID int64, adID int64, comment string) (appealResponse, error) {
+	var response appealResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/appeals", adID),
+		map[string]any{"user_id": userID, "comment": comment}, &response)
+	return response, err
+}
+
+func (tc *testClient) listAppeals(userID int64) (appealsResponse, error) {
+	var response appealsResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/appeals?user_id=%d", userID), nil, &response)
+	return response, err
+}
+
+type duplicateClustersResponse struct {
+	Data []struct {
+		Ads []adData `json:"ads"`
+	} `json:"data"`
+}
+
+func (tc *testClient) listDuplicateClusters(userID int64) (duplicateClustersResponse, error) {
+	var response duplicateClustersResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/duplicates?user_id=%d", userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) listHeldAds(userID int64) (adsResponse, error) {
+	var response adsResponse
+	err := tc.call(http.MethodGet, fmt.Sprintf("/api/v1/held?user_id=%d", userID), nil, &response)
+	return response, err
+}
+
+func (tc *testClient) resolveAppeal(userID int64, appealID int64, accept bool) (appealResponse, error) {
+	var response appealResponse
+	err := tc.call(http.MethodPost, fmt.Sprintf("/api/v1/appeals/%d/resolve", appealID),
+		map[string]any{"user_id": userID, "accept": accept}, &response)
+	return response, err
+}
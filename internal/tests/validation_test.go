@@ -1,17 +1,31 @@
 package tests
 
 import (
+	"ads-server/internal/adapters/repo"
+	grpcPort "ads-server/internal/ports/grpc"
+	grpc2 "ads-server/proto"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestCreateAd_EmptyTitle(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -25,7 +39,7 @@ func TestCreateAd_TooLongTitle(t *testing.T) {
 
 	title := strings.Repeat("a", 101)
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -37,7 +51,7 @@ func TestCreateAd_TooLongTitle(t *testing.T) {
 func TestCreateAd_EmptyText(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -51,7 +65,7 @@ func TestCreateAd_TooLongText(t *testing.T) {
 
 	text := strings.Repeat("a", 501)
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -62,7 +76,7 @@ func TestCreateAd_TooLongText(t *testing.T) {
 func TestUpdateAd_EmptyTitle(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -77,7 +91,7 @@ func TestUpdateAd_EmptyTitle(t *testing.T) {
 func TestUpdateAd_TooLongTitle(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -94,7 +108,7 @@ func TestUpdateAd_TooLongTitle(t *testing.T) {
 func TestUpdateAd_EmptyText(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -111,7 +125,7 @@ func TestUpdateAd_TooLongText(t *testing.T) {
 
 	text := strings.Repeat("a", 501)
 
-	_, err := client.createUser(0, "James", "Ostin")
+	_, err := client.createUser(0, "James", "ostin@example.com")
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -122,3 +136,90 @@ func TestUpdateAd_TooLongText(t *testing.T) {
 	_, err = client.updateAd(0, resp.Data.ID, "title", text)
 	assert.ErrorIs(t, err, ErrBadRequest)
 }
+
+type violationsResponse struct {
+	Errors []struct {
+		Field       string `json:"field"`
+		Description string `json:"description"`
+	} `json:"errors"`
+}
+
+func TestCreateUser_InvalidFieldsHTTP(t *testing.T) {
+	client := getTestClient()
+
+	req, err := http.NewRequest(http.MethodPost, client.baseURL+"/api/v1/user",
+		strings.NewReader(`{"name": "R2-D2", "email": "Ostin"}`))
+	assert.NoError(t, err)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := client.client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var body violationsResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	fields := make([]string, 0, len(body.Errors))
+	for _, v := range body.Errors {
+		fields = append(fields, v.Field)
+	}
+	assert.Equal(t, []string{"name", "email"}, fields)
+}
+
+func TestCreateUser_UnicodeName(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser(0, "Анна-Мария", "anna@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "Анна-Мария", user.Data.Name)
+}
+
+func TestUpdateUser_InvalidEmailGRPC(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpcPort.NewGRPCServer(repo.NewAd(), repo.NewUser())
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+
+	_, err = client.UpdateUser(ctx, &grpc2.UpdateUserRequest{Id: 0, Name: "Oleg", Email: "Oleg <oleg@example.com>"})
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		br, ok := st.Details()[0].(*errdetails.BadRequest)
+		assert.True(t, ok)
+		assert.Equal(t, "email", br.GetFieldViolations()[0].GetField())
+	}
+
+	id := int64(0)
+	user, err := client.GetUser(ctx, &grpc2.GetUserRequest{Id: &id})
+	assert.NoError(t, err)
+	assert.Equal(t, "oleg@example.com", user.Email, "invalid update must not change stored data")
+}
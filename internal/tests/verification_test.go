@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	"ads-server/internal/clock"
+	grpcPort "ads-server/internal/ports/grpc"
+	grpc2 "ads-server/proto"
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestUniqueEmail(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.registerUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.registerUser(0, "Jimmy", "James@Example.com")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	other, err := client.registerUser(0, "Mary", "mary@example.com")
+	assert.NoError(t, err)
+	_, err = client.updateUser(other.Data.ID, "Mary", "JAMES@example.com")
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestUnverifiedUserCantPublish(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.registerUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	assert.False(t, user.Data.Verified)
+
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	_, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+	assert.ErrorIs(t, err, ErrUnprocessableEntity)
+
+	verified, err := client.confirmEmail(client.mailbox.token("james@example.com"))
+	assert.NoError(t, err)
+	assert.True(t, verified.Data.Verified)
+
+	published, err := client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.True(t, published.Data.Published)
+}
+
+func TestConfirmEmailWrongToken(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.registerUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+
+	_, err = client.confirmEmail("0123456789abcdef")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	// tokens are single use
+	token := client.mailbox.token("james@example.com")
+	_, err = client.confirmEmail(token)
+	assert.NoError(t, err)
+	_, err = client.confirmEmail(token)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestConfirmEmailExpiredToken(t *testing.T) {
+	client := getTestClient(app.WithVerification(app.VerificationConfig{TokenTTL: time.Nanosecond}))
+
+	_, err := client.registerUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+	_, err = client.confirmEmail(client.mailbox.token("james@example.com"))
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestResendVerification(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.registerUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, client.mailbox.count("james@example.com"))
+
+	assert.ErrorIs(t, client.resendVerification(user.Data.ID), ErrTooManyRequests)
+	assert.Equal(t, 1, client.mailbox.count("james@example.com"))
+
+	assert.ErrorIs(t, client.resendVerification(42), ErrNotFound)
+}
+
+func TestResendVerificationConcurrent(t *testing.T) {
+	ctx := context.Background()
+	c := clock.NewFake(time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC))
+	mb := &mailbox{}
+	a := app.NewApp(repo.NewAd(), repo.NewUser(), app.WithClock(c), app.WithMailSender(mb))
+	user, err := a.CreateUser(ctx, "James", "james@example.com")
+	assert.NoError(t, err)
+	c.Advance(time.Hour)
+
+	var (
+		wg   sync.WaitGroup
+		sent atomic.Int32
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if a.ResendVerification(ctx, user.ID) == nil {
+				sent.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), sent.Load(), "concurrent requests must respect the resend interval")
+	assert.Equal(t, 2, mb.count("james@example.com"))
+}
+
+func TestResendVerificationReplacesToken(t *testing.T) {
+	client := getTestClient(app.WithVerification(app.VerificationConfig{TokenTTL: time.Hour}))
+
+	user, err := client.registerUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	first := client.mailbox.token("james@example.com")
+
+	assert.NoError(t, client.resendVerification(user.Data.ID))
+	second := client.mailbox.token("james@example.com")
+	assert.NotEqual(t, first, second)
+
+	_, err = client.confirmEmail(first)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.confirmEmail(second)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, client.resendVerification(user.Data.ID), ErrUnprocessableEntity)
+}
+
+func TestChangedEmailMustBeVerified(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	assert.True(t, user.Data.Verified)
+
+	changed, err := client.patchUser(user.Data.ID, map[string]any{"email": "bond@example.com"})
+	assert.NoError(t, err)
+	assert.False(t, changed.Data.Verified)
+	assert.NotEmpty(t, client.mailbox.token("bond@example.com"))
+
+	renamed, err := client.patchUser(user.Data.ID, map[string]any{"name": "Jim"})
+	assert.NoError(t, err)
+	assert.False(t, renamed.Data.Verified)
+	assert.Equal(t, 1, client.mailbox.count("bond@example.com"), "name change must not send verification")
+}
+
+func TestGRPCEmailVerification(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	mb := &mailbox{}
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser(), app.WithMailSender(mb)))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	assert.False(t, user.Verified)
+
+	_, err = client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "OLEG@example.com"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: user.Id, Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: user.Id, Published: true})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.ResendVerification(ctx, &grpc2.ResendVerificationRequest{UserId: user.Id})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.ConfirmEmail(ctx, &grpc2.ConfirmEmailRequest{Token: "bad"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	verified, err := client.ConfirmEmail(ctx, &grpc2.ConfirmEmailRequest{Token: mb.token("oleg@example.com")})
+	assert.NoError(t, err)
+	assert.True(t, verified.Verified)
+
+	_, err = client.ChangeAdStatus(ctx, &grpc2.ChangeAdStatusRequest{AdId: ad.Id, UserId: user.Id, Published: true})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"ads-server/internal/adapters/repo"
+	"ads-server/internal/app"
+	grpcPort "ads-server/internal/ports/grpc"
+	grpc2 "ads-server/proto"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestVersionIncrements(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), user.Data.Version, "confirmation changes the user")
+
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), ad.Data.Version)
+
+	ad, err = client.updateAd(user.Data.ID, ad.Data.ID, "hello", "there")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), ad.Data.Version)
+
+	ad, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), ad.Data.Version)
+}
+
+func TestUpdateAdIfMatch(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	path := fmt.Sprintf("/api/v1/ads/%d", ad.Data.ID)
+	body := map[string]any{"user_id": user.Data.ID, "title": "hello", "text": "there"}
+
+	resp, err := client.send(http.MethodGet, path+"/info", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+
+	resp, err = client.send(http.MethodPut, path, body, map[string]string{"If-Match": `"1"`})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	// the second editor still has the first version
+	resp, err = client.send(http.MethodPut, path, body, map[string]string{"If-Match": `"1"`})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp, err = client.send(http.MethodPut, path, body, map[string]string{"If-Match": `W/"2"`})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = client.send(http.MethodPut, path, body, map[string]string{"If-Match": "*"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = client.send(http.MethodDelete, path, map[string]any{"user_id": user.Data.ID}, map[string]string{"If-Match": `"2"`})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp, err = client.send(http.MethodDelete, path, map[string]any{"user_id": user.Data.ID}, map[string]string{"If-Match": `"3"`})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestGetIfNoneMatch(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	adPath := fmt.Sprintf("/api/v1/ads/%d/info", ad.Data.ID)
+	resp, err := client.send(http.MethodGet, adPath, nil, map[string]string{"If-None-Match": `"1"`})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, `"1"`, resp.Header.Get("ETag"))
+
+	_, err = client.updateAd(user.Data.ID, ad.Data.ID, "hello", "there")
+	assert.NoError(t, err)
+
+	resp, err = client.send(http.MethodGet, adPath, nil, map[string]string{"If-None-Match": `W/"1", "7"`})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get("ETag"))
+
+	userPath := fmt.Sprintf("/api/v1/users/%d", user.Data.ID)
+	resp, err = client.send(http.MethodGet, userPath, nil, map[string]string{"If-None-Match": fmt.Sprintf(`"%d"`, user.Data.Version)})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+}
+
+func TestUserIfMatch(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser(0, "James", "james@example.com")
+	assert.NoError(t, err)
+	path := fmt.Sprintf("/api/v1/users/%d", user.Data.ID)
+	stale := map[string]string{"If-Match": fmt.Sprintf(`"%d"`, user.Data.Version-1)}
+
+	resp, err := client.send(http.MethodPatch, path, map[string]any{"name": "Jimmy"}, stale)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp, err = client.send(http.MethodPut, path, map[string]any{"name": "Jimmy", "email": "james@example.com"}, stale)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	resp, err = client.send(http.MethodDelete, path, nil, stale)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	patched, err := client.patchUser(user.Data.ID, map[string]any{"name": "Jimmy"})
+	assert.NoError(t, err)
+	assert.Equal(t, user.Data.Version+1, patched.Data.Version)
+
+	resp, err = client.send(http.MethodDelete, path, nil, map[string]string{"If-Match": fmt.Sprintf(`"%d"`, patched.Data.Version)})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGRPCVersionConflict(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	srv := grpc.NewServer()
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	svc := grpcPort.NewAdService(app.NewApp(repo.NewAd(), repo.NewUser()))
+	grpc2.RegisterAdServiceServer(srv, svc)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(ctx, "", grpc.WithContextDialer(dialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	client := grpc2.NewAdServiceClient(conn)
+
+	user, err := client.CreateUser(ctx, &grpc2.CreateUserRequest{Name: "Oleg", Email: "oleg@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), user.Version)
+
+	ad, err := client.CreateAd(ctx, &grpc2.CreateAdRequest{UserId: user.Id, Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+
+	updated, err := client.UpdateAd(ctx, &grpc2.UpdateAdRequest{AdId: ad.Id, UserId: user.Id, Title: "hello", Text: "there", ExpectedVersion: ad.Version})
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Version+1, updated.Version)
+
+	_, err = client.UpdateAd(ctx, &grpc2.UpdateAdRequest{AdId: ad.Id, UserId: user.Id, Title: "hi", Text: "there", ExpectedVersion: ad.Version})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = client.DeleteAd(ctx, &grpc2.DeleteAdRequest{AdId: ad.Id, AuthorId: user.Id, ExpectedVersion: ad.Version})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = client.UpdateUser(ctx, &grpc2.UpdateUserRequest{Id: user.Id, Name: "Olga", Email: "oleg@example.com", ExpectedVersion: user.Version + 1})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = client.DeleteUser(ctx, &grpc2.DeleteUserRequest{Id: user.Id, ExpectedVersion: user.Version + 1})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = client.DeleteUser(ctx, &grpc2.DeleteUserRequest{Id: user.Id, ExpectedVersion: user.Version})
+	assert.NoError(t, err)
+}
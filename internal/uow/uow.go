@@ -0,0 +1,95 @@
+// Package uow implements unit of work for in-memory repositories with an undo log.
+//
+// The transaction travels in the context passed to the unit of work callback.
+// Repositories register compensating actions with OnRollback after every change,
+// they are run in reverse order if the callback fails. Persistent adapters are expected
+// to provide their own app.UnitOfWork keeping a native transaction in the context instead.
+package uow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("ads-server/internal/uow")
+
+type txKey struct{}
+
+// undoLog collects compensating actions of a transaction
+type undoLog struct {
+	mx   sync.Mutex
+	undo []func()
+}
+
+func (l *undoLog) rollback() {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	for i := len(l.undo) - 1; i >= 0; i-- {
+		l.undo[i]()
+	}
+	l.undo = nil
+}
+
+// OnRollback registers f to be run if the transaction carried by ctx is rolled back.
+// Changes made outside of a transaction are applied immediately, so f is dropped.
+func OnRollback(ctx context.Context, f func()) {
+	l, ok := ctx.Value(txKey{}).(*undoLog)
+	if !ok {
+		return
+	}
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.undo = append(l.undo, f)
+}
+
+// InTx reports whether ctx carries a transaction
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*undoLog)
+	return ok
+}
+
+// UndoLog runs units of work one at a time and rolls back changes of failed ones.
+// Changes are visible to calls made outside of units of work before they are committed.
+type UndoLog struct {
+	mx sync.Mutex
+}
+
+// New creates unit of work for in-memory repositories
+func New() *UndoLog {
+	return &UndoLog{}
+}
+
+// Do runs fn in a transaction, it is committed if fn returns nil and rolled back if fn fails or panics.
+// Nested calls join the transaction already carried by ctx.
+func (u *UndoLog) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+
+	ctx, span := tracer.Start(ctx, "UnitOfWork.Do")
+	defer span.End()
+
+	u.mx.Lock()
+	defer u.mx.Unlock()
+
+	l := &undoLog{}
+	defer func() {
+		if p := recover(); p != nil {
+			l.rollback()
+			span.SetStatus(codes.Error, fmt.Sprint(p))
+			panic(p)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, l)); err != nil {
+		l.rollback()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "rolled back")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package uow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommit(t *testing.T) {
+	var undone bool
+	err := New().Do(context.Background(), func(ctx context.Context) error {
+		OnRollback(ctx, func() { undone = true })
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.False(t, undone)
+}
+
+func TestRollbackOrder(t *testing.T) {
+	fail := errors.New("fail")
+	var order []int
+	err := New().Do(context.Background(), func(ctx context.Context) error {
+		for i := 0; i < 3; i++ {
+			i := i
+			OnRollback(ctx, func() { order = append(order, i) })
+		}
+		return fail
+	})
+	assert.ErrorIs(t, err, fail)
+	assert.Equal(t, []int{2, 1, 0}, order)
+}
+
+func TestNestedJoinsOuter(t *testing.T) {
+	u := New()
+	fail := errors.New("fail")
+	var undone []string
+	err := u.Do(context.Background(), func(ctx context.Context) error {
+		OnRollback(ctx, func() { undone = append(undone, "outer") })
+		assert.NoError(t, u.Do(ctx, func(ctx context.Context) error {
+			OnRollback(ctx, func() { undone = append(undone, "inner") })
+			return nil
+		}))
+		assert.Empty(t, undone, "nested unit of work must not commit on its own")
+		return fail
+	})
+	assert.ErrorIs(t, err, fail)
+	assert.Equal(t, []string{"inner", "outer"}, undone)
+}
+
+func TestRollbackOnPanic(t *testing.T) {
+	var undone bool
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = New().Do(context.Background(), func(ctx context.Context) error {
+			OnRollback(ctx, func() { undone = true })
+			panic("boom")
+		})
+	})
+	assert.True(t, undone)
+}
+
+func TestOutsideTransaction(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, InTx(ctx))
+	OnRollback(ctx, func() { t.Fatal("compensation outside of transaction must be dropped") })
+}
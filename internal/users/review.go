@@ -0,0 +1,58 @@
+package users
+
+import "time"
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review is a rating of a seller left by a buyer who talked to the seller about an ad
+type Review struct {
+	ID       int64
+	SellerID int64
+	// AuthorID is the buyer who left the review
+	AuthorID int64
+	AdID     int64
+	Rating   int
+	Text     string
+	// Reply is the only answer of the seller, empty until the seller replies
+	Reply     string
+	RepliedAt time.Time
+	// Hidden reviews are shown to moderators only and don't count in the reputation
+	Hidden    bool
+	HiddenBy  int64
+	CreatedAt time.Time
+}
+
+// Replied reports whether the seller has already answered the review
+func (r *Review) Replied() bool {
+	return !r.RepliedAt.IsZero()
+}
+
+// Reputation aggregates visible reviews of a seller
+type Reputation struct {
+	Reviews int
+	// RatingSum is the sum of ratings of the reviews, the average is derived from it
+	RatingSum int
+}
+
+// Rating returns the average rating or zero if the seller has no reviews
+func (r Reputation) Rating() float64 {
+	if r.Reviews == 0 {
+		return 0
+	}
+	return float64(r.RatingSum) / float64(r.Reviews)
+}
+
+// Add counts the rating in the reputation
+func (r *Reputation) Add(rating int) {
+	r.Reviews++
+	r.RatingSum += rating
+}
+
+// Remove stops counting the rating in the reputation
+func (r *Reputation) Remove(rating int) {
+	r.Reviews--
+	r.RatingSum -= rating
+}
@@ -1,27 +1,105 @@
 package users
 
 import (
-	"regexp"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
 )
 
+// Role defines what a user is allowed to do besides managing its own ads
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// ParseRole converts role name to Role
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
 type User struct {
 	ID    int64
 	Name  string
 	Email string
+	// Verified is set once the user confirms the email belongs to them
+	Verified bool
+	// Version is incremented by every change of the user, it is 1 once the user is stored
+	Version int64
+	Role    Role
+	// DeletedAt is set when the user is moved to trash, zero for live users
+	DeletedAt time.Time
+	// Reputation is maintained by the repository as reviews are added or hidden
+	Reputation Reputation
 }
 
-var IsLetter = regexp.MustCompile(`^[a-zA-Z]+$`).MatchString
+// Deleted reports whether the user is in trash
+func (u *User) Deleted() bool {
+	return !u.DeletedAt.IsZero()
+}
 
+// New creates a user, inputs are validated by the app layer and stored as given
 func New(name string, email string) *User {
-	if !IsLetter(name) {
-		name = "auto-generated-string"
-	}
-	if !IsLetter(email) {
-		email = "auto-generated-email"
-	}
 	return &User{
 		ID:    0,
 		Name:  name,
 		Email: email,
+		Role:  RoleUser,
+	}
+}
+
+// CanModerate reports whether the user may review ads of others, staff must have verified their email
+func (u *User) CanModerate() bool {
+	return u.Verified && (u.Role == RoleModerator || u.Role == RoleAdmin)
+}
+
+// IsAdmin reports whether the user may manage other users
+func (u *User) IsAdmin() bool {
+	return u.Verified && u.Role == RoleAdmin
+}
+
+// VerificationToken is an email confirmation request sent to a user,
+// only the hash of the token is stored so a storage leak can't be used to verify emails
+type VerificationToken struct {
+	Hash      string
+	UserID    int64
+	Email     string
+	SentAt    time.Time
+	ExpiresAt time.Time
+}
+
+// NewVerificationToken generates a random token for the user and email given,
+// the plain token is returned to be sent and must not be stored
+func NewVerificationToken(uID int64, email string, now time.Time, ttl time.Duration) (string, *VerificationToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
 	}
+	token := hex.EncodeToString(b)
+	return token, &VerificationToken{
+		Hash:      HashToken(token),
+		UserID:    uID,
+		Email:     email,
+		SentAt:    now,
+		ExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// HashToken returns the value a plain token is stored and looked up by
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Expired reports whether the token can't be used anymore at the moment given
+func (t *VerificationToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
 }
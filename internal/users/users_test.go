@@ -4,17 +4,16 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/suite"
 	"testing"
-	"unicode/utf8"
 )
 
 func FuzzNew(f *testing.F) {
 	f.Fuzz(func(t *testing.T, name string, email string) {
 		u := New(name, email)
-		if !utf8.ValidString(u.Name) {
-			t.Fatalf("Wrong name format %s", u.Name)
+		if u.Name != name {
+			t.Fatalf("Name rewritten: %q, want %q", u.Name, name)
 		}
-		if !utf8.ValidString(u.Email) {
-			t.Fatalf("Wrong email format %s", u.Name)
+		if u.Email != email {
+			t.Fatalf("Email rewritten: %q, want %q", u.Email, email)
 		}
 	})
 }
@@ -39,7 +38,7 @@ func (n *NewTestSuite) TestExample() {
 		},
 
 		{New("Привет", n.baseEmail).Name,
-			"auto-generated-string",
+			"Привет",
 		},
 	}
 	n.Equal("example@gmail.com", n.baseEmail)
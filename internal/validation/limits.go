@@ -0,0 +1,107 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Limits configures constraints applied to domain inputs
+type Limits struct {
+	TitleMaxLen int
+	TextMaxLen  int
+	NameMaxLen  int
+	EmailMaxLen int
+	// CategoryMaxLen limits optional ad categories, they are short labels rather than free text
+	CategoryMaxLen int
+	// MessageMaxLen limits texts of messages between authors and buyers
+	MessageMaxLen int
+	// ReviewMaxLen limits texts of reviews of sellers and their replies
+	ReviewMaxLen int
+	// ReportMaxLen limits comments of abuse reports and appeals against takedowns
+	ReportMaxLen int
+	// ForbiddenChars lists characters rejected in ad titles, texts and user names
+	ForbiddenChars string
+}
+
+// DefaultLimits keeps the limits the service has always used for ads
+var DefaultLimits = Limits{
+	TitleMaxLen: 100,
+	TextMaxLen:  500,
+	NameMaxLen:  100,
+	// RFC 5321 limits a forward path to 256 octets including angle brackets
+	EmailMaxLen:    254,
+	CategoryMaxLen: 50,
+	MessageMaxLen:  1000,
+	ReviewMaxLen:   1000,
+	ReportMaxLen:   500,
+}
+
+// Ad returns rules for title and text of an ad
+func (l Limits) Ad(title, text string) []Field {
+	return []Field{
+		Check("title", title, ValidUTF8(), Required(), Length(1, l.TitleMaxLen), NoControl(), NoneOf(l.ForbiddenChars)),
+		Check("text", text, ValidUTF8(), Required(), Length(1, l.TextMaxLen), NoControl('\n', '\r', '\t'), NoneOf(l.ForbiddenChars)),
+	}
+}
+
+// Category returns rules for the optional category of an ad
+func (l Limits) Category(category string) Field {
+	return Check("category", category, ValidUTF8(), Length(0, l.CategoryMaxLen), NoControl(), NoneOf(l.ForbiddenChars))
+}
+
+// Message returns rules for the text of a message
+func (l Limits) Message(text string) Field {
+	return Check("text", text, ValidUTF8(), Required(), Length(1, l.MessageMaxLen), NoControl('\n', '\r', '\t'))
+}
+
+// Review returns rules for the text of a review or a reply to it
+func (l Limits) Review(field, text string) Field {
+	return Check(field, text, ValidUTF8(), Required(), Length(1, l.ReviewMaxLen), NoControl('\n', '\r', '\t'), NoneOf(l.ForbiddenChars))
+}
+
+// Report returns rules for the comment of an abuse report or an appeal, the comment may be optional
+func (l Limits) Report(comment string, required bool) Field {
+	rules := []Rule{ValidUTF8()}
+	if required {
+		rules = append(rules, Required())
+	}
+	return Check("comment", comment, append(rules, Length(0, l.ReportMaxLen), NoControl('\n', '\r', '\t'))...)
+}
+
+// User returns rules for name and email of a user
+func (l Limits) User(name, email string) []Field {
+	return []Field{
+		Check("name", name, ValidUTF8(), Required(), Length(1, l.NameMaxLen), NoneOf(l.ForbiddenChars), PersonName()),
+		Check("email", email, ValidUTF8(), Required(), Length(3, l.EmailMaxLen), Email()),
+	}
+}
+
+// LimitsFromEnv reads limits from environment, unset variables keep default values
+func LimitsFromEnv() (Limits, error) {
+	l := DefaultLimits
+	for env, dst := range map[string]*int{
+		"AD_TITLE_MAX_LEN":    &l.TitleMaxLen,
+		"AD_TEXT_MAX_LEN":     &l.TextMaxLen,
+		"USER_NAME_MAX_LEN":   &l.NameMaxLen,
+		"USER_EMAIL_MAX_LEN":  &l.EmailMaxLen,
+		"AD_CATEGORY_MAX_LEN": &l.CategoryMaxLen,
+		"MESSAGE_MAX_LEN":     &l.MessageMaxLen,
+		"REVIEW_MAX_LEN":      &l.ReviewMaxLen,
+		"REPORT_MAX_LEN":      &l.ReportMaxLen,
+	} {
+		v, ok := os.LookupEnv(env)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return Limits{}, fmt.Errorf("%s must be a positive integer, got %q", env, v)
+		}
+		*dst = n
+	}
+	if v, ok := os.LookupEnv("FORBIDDEN_CHARS"); ok {
+		l.ForbiddenChars = v
+	}
+	return l, nil
+}
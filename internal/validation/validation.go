@@ -0,0 +1,154 @@
+// Package validation declares rules domain inputs are checked against
+// and collects their violations into a single errs.ValidationError
+package validation
+
+import (
+	"ads-server/internal/errs"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule checks a value and returns description of the violation or empty string if the value is valid
+type Rule func(value string) string
+
+// Field is a named input value with the rules it must satisfy
+type Field struct {
+	Name  string
+	Value string
+	Rules []Rule
+}
+
+// Check is a shorthand to declare a field
+func Check(name, value string, rules ...Rule) Field {
+	return Field{Name: name, Value: value, Rules: rules}
+}
+
+// Validate checks every field and returns errs.ValidationError listing all violations,
+// only the first violated rule of each field is reported
+func Validate(fields ...Field) error {
+	var violations []errs.FieldViolation
+	for _, f := range fields {
+		for _, rule := range f.Rules {
+			if d := rule(f.Value); d != "" {
+				violations = append(violations, errs.FieldViolation{Field: f.Name, Description: d})
+				break
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return errs.ValidationError.WithFields(violations...)
+}
+
+// ValidUTF8 rejects values that are not valid UTF-8
+func ValidUTF8() Rule {
+	return func(value string) string {
+		if !utf8.ValidString(value) {
+			return "must be valid UTF-8"
+		}
+		return ""
+	}
+}
+
+// Required rejects empty and whitespace only values
+func Required() Rule {
+	return func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "must not be empty"
+		}
+		return ""
+	}
+}
+
+// Length limits the number of characters (not bytes) of the value, zero max means no limit
+func Length(min, max int) Rule {
+	return func(value string) string {
+		n := utf8.RuneCountInString(value)
+		if n < min {
+			return fmt.Sprintf("must be at least %d characters long", min)
+		}
+		if max > 0 && n > max {
+			return fmt.Sprintf("must be at most %d characters long", max)
+		}
+		return ""
+	}
+}
+
+// NoControl rejects control characters except the ones allowed
+func NoControl(allowed ...rune) Rule {
+	return func(value string) string {
+		for _, r := range value {
+			if unicode.IsControl(r) && !containsRune(allowed, r) {
+				return fmt.Sprintf("must not contain control character %U", r)
+			}
+		}
+		return ""
+	}
+}
+
+// NoneOf rejects values containing any of the characters given
+func NoneOf(chars string) Rule {
+	return func(value string) string {
+		if i := strings.IndexAny(value, chars); i >= 0 {
+			r, _ := utf8.DecodeRuneInString(value[i:])
+			return fmt.Sprintf("must not contain %q", r)
+		}
+		return ""
+	}
+}
+
+// Email accepts a bare RFC 5322 address (addr-spec) without display name or comments
+func Email() Rule {
+	return func(value string) string {
+		addr, err := mail.ParseAddress(value)
+		// mail.ParseAddress also accepts name-addr forms ("John <john@example.com>")
+		// and surrounding whitespace, only bare addresses are stored
+		if err != nil || addr.Name != "" || strings.ContainsAny(value, "<>") || value != strings.TrimSpace(value) {
+			return "must be a valid email address"
+		}
+		return ""
+	}
+}
+
+// PersonName accepts names of letters in any script, combining marks,
+// inner spaces, hyphens, apostrophes and dots, starting and ending with a letter
+func PersonName() Rule {
+	return func(value string) string {
+		runes := []rune(value)
+		if len(runes) == 0 {
+			return ""
+		}
+		if !unicode.IsLetter(runes[0]) {
+			return "must start with a letter"
+		}
+		last := runes[len(runes)-1]
+		if !unicode.IsLetter(last) && !unicode.IsMark(last) && last != '.' {
+			return "must end with a letter"
+		}
+		for i, r := range runes {
+			switch {
+			case unicode.IsLetter(r), unicode.IsMark(r):
+			case r == ' ' || r == '-' || r == '\'' || r == '’' || r == '.':
+				if i > 0 && runes[i-1] == r {
+					return fmt.Sprintf("must not contain repeated %q", r)
+				}
+			default:
+				return fmt.Sprintf("must not contain %q", r)
+			}
+		}
+		return ""
+	}
+}
+
+func containsRune(rs []rune, r rune) bool {
+	for _, x := range rs {
+		if x == r {
+			return true
+		}
+	}
+	return false
+}
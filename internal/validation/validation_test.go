@@ -0,0 +1,73 @@
+package validation
+
+import (
+	"ads-server/internal/errs"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmail(t *testing.T) {
+	valid := []string{
+		"user@example.com",
+		"first.last+tag@sub.example.org",
+		`"quoted local"@example.com`,
+		"user@localhost",
+		"почта@пример.рф",
+	}
+	invalid := []string{
+		"Ostin",
+		"user@",
+		"@example.com",
+		"a@b@c",
+		"John <john@example.com>",
+		"user@example.com (comment)",
+		" user@example.com",
+	}
+	for _, v := range valid {
+		assert.Emptyf(t, Email()(v), "%q must be valid", v)
+	}
+	for _, v := range invalid {
+		assert.NotEmptyf(t, Email()(v), "%q must be invalid", v)
+	}
+}
+
+func TestPersonName(t *testing.T) {
+	valid := []string{"John", "Анна-Мария", "O'Brien", "José", "李小龙", "J. R. R. Tolkien", "Zoë"}
+	invalid := []string{"John1", " John", "Mary--Ann", "<script>", "-Bob", "Bob_"}
+	for _, v := range valid {
+		assert.Emptyf(t, PersonName()(v), "%q must be valid", v)
+	}
+	for _, v := range invalid {
+		assert.NotEmptyf(t, PersonName()(v), "%q must be invalid", v)
+	}
+}
+
+func TestValidateCollectsAllFields(t *testing.T) {
+	err := Validate(DefaultLimits.User("", "not-an-email")...)
+	assert.True(t, errors.Is(err, errs.ValidationError))
+
+	var e *errs.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, []errs.FieldViolation{
+		{Field: "name", Description: "must not be empty"},
+		{Field: "email", Description: "must be a valid email address"},
+	}, e.Fields)
+
+	assert.NoError(t, Validate(DefaultLimits.User("Анна", "anna@example.com")...))
+}
+
+func TestAdLimits(t *testing.T) {
+	l := DefaultLimits
+	assert.NoError(t, Validate(l.Ad(strings.Repeat("я", 100), "multi\nline\ttext")...))
+	assert.Error(t, Validate(l.Ad(strings.Repeat("я", 101), "text")...))
+	assert.Error(t, Validate(l.Ad("title\x00", "text")...))
+	assert.Error(t, Validate(l.Ad("title", string([]byte{0xff}))...))
+
+	l.TitleMaxLen = 10
+	l.ForbiddenChars = "<>"
+	assert.Error(t, Validate(l.Ad(strings.Repeat("a", 11), "text")...))
+	assert.Error(t, Validate(l.Ad("title", "<b>text</b>")...))
+}
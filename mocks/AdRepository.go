@@ -9,6 +9,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	url "net/url"
 )
 
@@ -17,6 +19,188 @@ type AdRepository struct {
 	mock.Mock
 }
 
+// AddAppeal provides a mock function with given fields: ctx, ap
+func (_m *AdRepository) AddAppeal(ctx context.Context, ap *ads.Appeal) (*ads.Appeal, error) {
+	ret := _m.Called(ctx, ap)
+
+	var r0 *ads.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Appeal) (*ads.Appeal, error)); ok {
+		return rf(ctx, ap)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Appeal) *ads.Appeal); ok {
+		r0 = rf(ctx, ap)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Appeal) error); ok {
+		r1 = rf(ctx, ap)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddFavorite provides a mock function with given fields: ctx, f
+func (_m *AdRepository) AddFavorite(ctx context.Context, f *ads.Favorite) (*ads.Favorite, error) {
+	ret := _m.Called(ctx, f)
+
+	var r0 *ads.Favorite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Favorite) (*ads.Favorite, error)); ok {
+		return rf(ctx, f)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Favorite) *ads.Favorite); ok {
+		r0 = rf(ctx, f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Favorite)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Favorite) error); ok {
+		r1 = rf(ctx, f)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddReport provides a mock function with given fields: ctx, r
+func (_m *AdRepository) AddReport(ctx context.Context, r *ads.Report) (*ads.Report, error) {
+	ret := _m.Called(ctx, r)
+
+	var r0 *ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Report) (*ads.Report, error)); ok {
+		return rf(ctx, r)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Report) *ads.Report); ok {
+		r0 = rf(ctx, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Report) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddRevision provides a mock function with given fields: ctx, r
+func (_m *AdRepository) AddRevision(ctx context.Context, r *ads.Revision) error {
+	ret := _m.Called(ctx, r)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Revision) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AnonymizeByAuthor provides a mock function with given fields: ctx, uID
+func (_m *AdRepository) AnonymizeByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Approve provides a mock function with given fields: ctx, ap
+func (_m *AdRepository) Approve(ctx context.Context, ap *ads.Approval) error {
+	ret := _m.Called(ctx, ap)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Approval) error); ok {
+		r0 = rf(ctx, ap)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Archive provides a mock function with given fields: ctx, now
+func (_m *AdRepository) Archive(ctx context.Context, now time.Time) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*ads.Ad, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*ads.Ad); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountCreated provides a mock function with given fields: ctx, uID, since
+func (_m *AdRepository) CountCreated(ctx context.Context, uID int64, since time.Time) (int, error) {
+	ret := _m.Called(ctx, uID, since)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) (int, error)); ok {
+		return rf(ctx, uID, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) int); ok {
+		r0 = rf(ctx, uID, since)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time) error); ok {
+		r1 = rf(ctx, uID, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: _a0, _a1
 func (_m *AdRepository) Create(_a0 context.Context, _a1 *ads.Ad) (int64, error) {
 	ret := _m.Called(_a0, _a1)
@@ -41,13 +225,13 @@ func (_m *AdRepository) Create(_a0 context.Context, _a1 *ads.Ad) (int64, error)
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: _a0, _a1, _a2
-func (_m *AdRepository) Delete(_a0 context.Context, _a1 int64, _a2 int64) error {
-	ret := _m.Called(_a0, _a1, _a2)
+// Delete provides a mock function with given fields: ctx, adID, uID, version
+func (_m *AdRepository) Delete(ctx context.Context, adID int64, uID int64, version int64) error {
+	ret := _m.Called(ctx, adID, uID, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
-		r0 = rf(_a0, _a1, _a2)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) error); ok {
+		r0 = rf(ctx, adID, uID, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -55,6 +239,110 @@ func (_m *AdRepository) Delete(_a0 context.Context, _a1 int64, _a2 int64) error
 	return r0
 }
 
+// DeleteByAuthor provides a mock function with given fields: ctx, uID
+func (_m *AdRepository) DeleteByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DueTransitions provides a mock function with given fields: ctx, now
+func (_m *AdRepository) DueTransitions(ctx context.Context, now time.Time) ([]*ads.Transition, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []*ads.Transition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*ads.Transition, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*ads.Transition); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Transition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Expiring provides a mock function with given fields: ctx, before
+func (_m *AdRepository) Expiring(ctx context.Context, before time.Time) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*ads.Ad, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*ads.Ad); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Favorites provides a mock function with given fields: ctx, uID
+func (_m *AdRepository) Favorites(ctx context.Context, uID int64) ([]*ads.Favorite, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []*ads.Favorite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Favorite, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Favorite); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Favorite)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Filter provides a mock function with given fields: ctx, params
 func (_m *AdRepository) Filter(ctx context.Context, params url.Values) ([]*ads.Ad, error) {
 	ret := _m.Called(ctx, params)
@@ -81,6 +369,32 @@ func (_m *AdRepository) Filter(ctx context.Context, params url.Values) ([]*ads.A
 	return r0, r1
 }
 
+// GetAppeal provides a mock function with given fields: ctx, id
+func (_m *AdRepository) GetAppeal(ctx context.Context, id int64) (*ads.Appeal, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *ads.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Appeal, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Appeal); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: _a0, _a1
 func (_m *AdRepository) GetByID(_a0 context.Context, _a1 int64) (*ads.Ad, error) {
 	ret := _m.Called(_a0, _a1)
@@ -123,25 +437,117 @@ func (_m *AdRepository) GetByName(_a0 context.Context, _a1 string) []*ads.Ad {
 	return r0
 }
 
-// Publish provides a mock function with given fields: _a0, _a1, _a2, _a3
-func (_m *AdRepository) Publish(_a0 context.Context, _a1 int64, _a2 int64, _a3 bool) (*ads.Ad, error) {
-	ret := _m.Called(_a0, _a1, _a2, _a3)
+// GetDeleted provides a mock function with given fields: ctx, id
+func (_m *AdRepository) GetDeleted(ctx context.Context, id int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, id)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) (*ads.Ad, error)); ok {
-		return rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) *ads.Ad); ok {
-		r0 = rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Ad); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool) error); ok {
-		r1 = rf(_a0, _a1, _a2, _a3)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LastApproval provides a mock function with given fields: ctx, adID
+func (_m *AdRepository) LastApproval(ctx context.Context, adID int64) (*ads.Approval, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 *ads.Approval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Approval, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Approval); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Approval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkExpiryWarned provides a mock function with given fields: ctx, adID
+func (_m *AdRepository) MarkExpiryWarned(ctx context.Context, adID int64) error {
+	ret := _m.Called(ctx, adID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// OpenReports provides a mock function with given fields: ctx
+func (_m *AdRepository) OpenReports(ctx context.Context) ([]*ads.Report, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*ads.Report, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*ads.Report); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PendingAppeals provides a mock function with given fields: ctx
+func (_m *AdRepository) PendingAppeals(ctx context.Context) ([]*ads.Appeal, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []*ads.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*ads.Appeal, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*ads.Appeal); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -149,25 +555,481 @@ func (_m *AdRepository) Publish(_a0 context.Context, _a1 int64, _a2 int64, _a3 b
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
-func (_m *AdRepository) Update(_a0 context.Context, _a1 int64, _a2 int64, _a3 string, _a4 string) (*ads.Ad, error) {
-	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+// Publish provides a mock function with given fields: ctx, adID, uID, action, version
+func (_m *AdRepository) Publish(ctx context.Context, adID int64, uID int64, action bool, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, action, version)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string) (*ads.Ad, error)); ok {
-		return rf(_a0, _a1, _a2, _a3, _a4)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, action, version)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string) *ads.Ad); ok {
-		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, action, version)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string, string) error); ok {
-		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool, int64) error); ok {
+		r1 = rf(ctx, adID, uID, action, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *AdRepository) Purge(ctx context.Context, before time.Time) ([]int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveAdFavorites provides a mock function with given fields: ctx, adID
+func (_m *AdRepository) RemoveAdFavorites(ctx context.Context, adID int64) (int, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (int, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) int); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveFavorite provides a mock function with given fields: ctx, uID, adID
+func (_m *AdRepository) RemoveFavorite(ctx context.Context, uID int64, adID int64) error {
+	ret := _m.Called(ctx, uID, adID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, uID, adID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveTransition provides a mock function with given fields: ctx, adID, id
+func (_m *AdRepository) RemoveTransition(ctx context.Context, adID int64, id int64) (*ads.Transition, error) {
+	ret := _m.Called(ctx, adID, id)
+
+	var r0 *ads.Transition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*ads.Transition, error)); ok {
+		return rf(ctx, adID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *ads.Transition); ok {
+		r0 = rf(ctx, adID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Transition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveUserFavorites provides a mock function with given fields: ctx, uID
+func (_m *AdRepository) RemoveUserFavorites(ctx context.Context, uID int64) error {
+	ret := _m.Called(ctx, uID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reports provides a mock function with given fields: ctx, adID
+func (_m *AdRepository) Reports(ctx context.Context, adID int64) ([]*ads.Report, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 []*ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Report, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Report); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveAppeal provides a mock function with given fields: ctx, id, moderatorID, accept, at
+func (_m *AdRepository) ResolveAppeal(ctx context.Context, id int64, moderatorID int64, accept bool, at time.Time) (*ads.Appeal, error) {
+	ret := _m.Called(ctx, id, moderatorID, accept, at)
+
+	var r0 *ads.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, time.Time) (*ads.Appeal, error)); ok {
+		return rf(ctx, id, moderatorID, accept, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, time.Time) *ads.Appeal); ok {
+		r0 = rf(ctx, id, moderatorID, accept, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool, time.Time) error); ok {
+		r1 = rf(ctx, id, moderatorID, accept, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveReports provides a mock function with given fields: ctx, adID, moderatorID, outcome, at
+func (_m *AdRepository) ResolveReports(ctx context.Context, adID int64, moderatorID int64, outcome ads.ReportOutcome, at time.Time) ([]*ads.Report, error) {
+	ret := _m.Called(ctx, adID, moderatorID, outcome, at)
+
+	var r0 []*ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, ads.ReportOutcome, time.Time) ([]*ads.Report, error)); ok {
+		return rf(ctx, adID, moderatorID, outcome, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, ads.ReportOutcome, time.Time) []*ads.Report); ok {
+		r0 = rf(ctx, adID, moderatorID, outcome, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, ads.ReportOutcome, time.Time) error); ok {
+		r1 = rf(ctx, adID, moderatorID, outcome, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *AdRepository) Restore(ctx context.Context, id int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Ad); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreByAuthor provides a mock function with given fields: ctx, uID
+func (_m *AdRepository) RestoreByAuthor(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Revisions provides a mock function with given fields: ctx, adID
+func (_m *AdRepository) Revisions(ctx context.Context, adID int64) ([]*ads.Revision, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 []*ads.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Revision, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Revision); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ScheduleTransition provides a mock function with given fields: ctx, t
+func (_m *AdRepository) ScheduleTransition(ctx context.Context, t *ads.Transition) error {
+	ret := _m.Called(ctx, t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Transition) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetExpiry provides a mock function with given fields: ctx, adID, uID, expiresAt, version
+func (_m *AdRepository) SetExpiry(ctx context.Context, adID int64, uID int64, expiresAt time.Time, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, expiresAt, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, time.Time, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, expiresAt, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, time.Time, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, expiresAt, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, time.Time, int64) error); ok {
+		r1 = rf(ctx, adID, uID, expiresAt, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetScreening provides a mock function with given fields: ctx, adID, s
+func (_m *AdRepository) SetScreening(ctx context.Context, adID int64, s ads.Screening) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, s)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Screening) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, s)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Screening) *ads.Ad); ok {
+		r0 = rf(ctx, adID, s)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.Screening) error); ok {
+		r1 = rf(ctx, adID, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetTakenDown provides a mock function with given fields: ctx, adID, at
+func (_m *AdRepository) SetTakenDown(ctx context.Context, adID int64, at time.Time) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, at)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) *ads.Ad); ok {
+		r0 = rf(ctx, adID, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time) error); ok {
+		r1 = rf(ctx, adID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Transitions provides a mock function with given fields: ctx, adID
+func (_m *AdRepository) Transitions(ctx context.Context, adID int64) ([]*ads.Transition, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 []*ads.Transition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Transition, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Transition); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Transition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Trash provides a mock function with given fields: ctx, uID
+func (_m *AdRepository) Trash(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, adID, uID, title, text, version
+func (_m *AdRepository) Update(ctx context.Context, adID int64, uID int64, title string, text string, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, title, text, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, title, text, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, title, text, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string, string, int64) error); ok {
+		r1 = rf(ctx, adID, uID, title, text, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Watchers provides a mock function with given fields: ctx, adID
+func (_m *AdRepository) Watchers(ctx context.Context, adID int64) ([]int64, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]int64, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []int64); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
 	} else {
 		r1 = ret.Error(1)
 	}
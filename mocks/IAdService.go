@@ -3,9 +3,10 @@
 package mocks
 
 import (
-	grpc "ads-server/proto"
 	context "context"
 
+	grpc "ads-server/proto"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -14,6 +15,136 @@ type IAdService struct {
 	mock.Mock
 }
 
+// AddFavorite provides a mock function with given fields: ctx, request
+func (_m *IAdService) AddFavorite(ctx context.Context, request *grpc.FavoriteRequest) (*grpc.FavoriteResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.FavoriteResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.FavoriteRequest) (*grpc.FavoriteResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.FavoriteRequest) *grpc.FavoriteResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.FavoriteResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.FavoriteRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AppealTakedown provides a mock function with given fields: ctx, request
+func (_m *IAdService) AppealTakedown(ctx context.Context, request *grpc.AppealTakedownRequest) (*grpc.Appeal, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.AppealTakedownRequest) (*grpc.Appeal, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.AppealTakedownRequest) *grpc.Appeal); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.AppealTakedownRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApproveAd provides a mock function with given fields: ctx, request
+func (_m *IAdService) ApproveAd(ctx context.Context, request *grpc.ApproveAdRequest) (*grpc.AdApproval, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdApproval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ApproveAdRequest) (*grpc.AdApproval, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ApproveAdRequest) *grpc.AdApproval); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdApproval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ApproveAdRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BlockUser provides a mock function with given fields: ctx, request
+func (_m *IAdService) BlockUser(ctx context.Context, request *grpc.BlockUserRequest) (*grpc.UserBlock, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.UserBlock
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.BlockUserRequest) (*grpc.UserBlock, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.BlockUserRequest) *grpc.UserBlock); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.UserBlock)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.BlockUserRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelScheduledTransition provides a mock function with given fields: ctx, request
+func (_m *IAdService) CancelScheduledTransition(ctx context.Context, request *grpc.CancelScheduledTransitionRequest) (*grpc.CancelScheduledTransitionResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.CancelScheduledTransitionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.CancelScheduledTransitionRequest) (*grpc.CancelScheduledTransitionResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.CancelScheduledTransitionRequest) *grpc.CancelScheduledTransitionResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.CancelScheduledTransitionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.CancelScheduledTransitionRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ChangeAdStatus provides a mock function with given fields: ctx, request
 func (_m *IAdService) ChangeAdStatus(ctx context.Context, request *grpc.ChangeAdStatusRequest) (*grpc.AdResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -40,6 +171,72 @@ func (_m *IAdService) ChangeAdStatus(ctx context.Context, request *grpc.ChangeAd
 	return r0, r1
 }
 
+// Chat provides a mock function with given fields: stream
+func (_m *IAdService) Chat(stream grpc.AdService_ChatServer) error {
+	ret := _m.Called(stream)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(grpc.AdService_ChatServer) error); ok {
+		r0 = rf(stream)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConfirmEmail provides a mock function with given fields: ctx, request
+func (_m *IAdService) ConfirmEmail(ctx context.Context, request *grpc.ConfirmEmailRequest) (*grpc.UserResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ConfirmEmailRequest) (*grpc.UserResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ConfirmEmailRequest) *grpc.UserResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ConfirmEmailRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountFavorites provides a mock function with given fields: ctx, request
+func (_m *IAdService) CountFavorites(ctx context.Context, request *grpc.CountFavoritesRequest) (*grpc.CountFavoritesResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.CountFavoritesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.CountFavoritesRequest) (*grpc.CountFavoritesResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.CountFavoritesRequest) *grpc.CountFavoritesResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.CountFavoritesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.CountFavoritesRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateAd provides a mock function with given fields: ctx, request
 func (_m *IAdService) CreateAd(ctx context.Context, request *grpc.CreateAdRequest) (*grpc.AdResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -118,6 +315,84 @@ func (_m *IAdService) DeleteAd(ctx context.Context, request *grpc.DeleteAdReques
 	return r0, r1
 }
 
+// ExtendAd provides a mock function with given fields: ctx, request
+func (_m *IAdService) ExtendAd(ctx context.Context, request *grpc.ExtendAdRequest) (*grpc.AdResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ExtendAdRequest) (*grpc.AdResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ExtendAdRequest) *grpc.AdResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ExtendAdRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAdChanges provides a mock function with given fields: ctx, request
+func (_m *IAdService) GetAdChanges(ctx context.Context, request *grpc.GetAdChangesRequest) (*grpc.AdChangesResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdChangesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.GetAdChangesRequest) (*grpc.AdChangesResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.GetAdChangesRequest) *grpc.AdChangesResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdChangesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.GetAdChangesRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAdRevision provides a mock function with given fields: ctx, request
+func (_m *IAdService) GetAdRevision(ctx context.Context, request *grpc.GetAdRevisionRequest) (*grpc.AdRevision, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdRevision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.GetAdRevisionRequest) (*grpc.AdRevision, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.GetAdRevisionRequest) *grpc.AdRevision); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdRevision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.GetAdRevisionRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUser provides a mock function with given fields: ctx, request
 func (_m *IAdService) GetUser(ctx context.Context, request *grpc.GetUserRequest) (*grpc.UserResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -144,6 +419,58 @@ func (_m *IAdService) GetUser(ctx context.Context, request *grpc.GetUserRequest)
 	return r0, r1
 }
 
+// HideReview provides a mock function with given fields: ctx, request
+func (_m *IAdService) HideReview(ctx context.Context, request *grpc.HideReviewRequest) (*grpc.Review, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.HideReviewRequest) (*grpc.Review, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.HideReviewRequest) *grpc.Review); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.HideReviewRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAdRevisions provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListAdRevisions(ctx context.Context, request *grpc.ListAdRevisionsRequest) (*grpc.ListAdRevisionsResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListAdRevisionsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListAdRevisionsRequest) (*grpc.ListAdRevisionsResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListAdRevisionsRequest) *grpc.ListAdRevisionsResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListAdRevisionsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListAdRevisionsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListAds provides a mock function with given fields: ctx, request
 func (_m *IAdService) ListAds(ctx context.Context, request *grpc.ListAdRequest) (*grpc.ListAdResponse, error) {
 	ret := _m.Called(ctx, request)
@@ -170,24 +497,24 @@ func (_m *IAdService) ListAds(ctx context.Context, request *grpc.ListAdRequest)
 	return r0, r1
 }
 
-// UpdateAd provides a mock function with given fields: ctx, request
-func (_m *IAdService) UpdateAd(ctx context.Context, request *grpc.UpdateAdRequest) (*grpc.AdResponse, error) {
+// ListAppeals provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListAppeals(ctx context.Context, request *grpc.ListAppealsRequest) (*grpc.ListAppealsResponse, error) {
 	ret := _m.Called(ctx, request)
 
-	var r0 *grpc.AdResponse
+	var r0 *grpc.ListAppealsResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateAdRequest) (*grpc.AdResponse, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListAppealsRequest) (*grpc.ListAppealsResponse, error)); ok {
 		return rf(ctx, request)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateAdRequest) *grpc.AdResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListAppealsRequest) *grpc.ListAppealsResponse); ok {
 		r0 = rf(ctx, request)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*grpc.AdResponse)
+			r0 = ret.Get(0).(*grpc.ListAppealsResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *grpc.UpdateAdRequest) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListAppealsRequest) error); ok {
 		r1 = rf(ctx, request)
 	} else {
 		r1 = ret.Error(1)
@@ -196,24 +523,726 @@ func (_m *IAdService) UpdateAd(ctx context.Context, request *grpc.UpdateAdReques
 	return r0, r1
 }
 
-// UpdateUser provides a mock function with given fields: ctx, request
-func (_m *IAdService) UpdateUser(ctx context.Context, request *grpc.UpdateUserRequest) (*grpc.UserResponse, error) {
+// ListAuditEntries provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListAuditEntries(ctx context.Context, request *grpc.ListAuditEntriesRequest) (*grpc.ListAuditEntriesResponse, error) {
 	ret := _m.Called(ctx, request)
 
-	var r0 *grpc.UserResponse
+	var r0 *grpc.ListAuditEntriesResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateUserRequest) (*grpc.UserResponse, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListAuditEntriesRequest) (*grpc.ListAuditEntriesResponse, error)); ok {
 		return rf(ctx, request)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateUserRequest) *grpc.UserResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListAuditEntriesRequest) *grpc.ListAuditEntriesResponse); ok {
 		r0 = rf(ctx, request)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*grpc.UserResponse)
+			r0 = ret.Get(0).(*grpc.ListAuditEntriesResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *grpc.UpdateUserRequest) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListAuditEntriesRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListBlockedUsers provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListBlockedUsers(ctx context.Context, request *grpc.ListBlockedUsersRequest) (*grpc.ListBlockedUsersResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListBlockedUsersResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListBlockedUsersRequest) (*grpc.ListBlockedUsersResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListBlockedUsersRequest) *grpc.ListBlockedUsersResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListBlockedUsersResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListBlockedUsersRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListConversations provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListConversations(ctx context.Context, request *grpc.ListConversationsRequest) (*grpc.ListConversationsResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListConversationsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListConversationsRequest) (*grpc.ListConversationsResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListConversationsRequest) *grpc.ListConversationsResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListConversationsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListConversationsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDuplicateClusters provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListDuplicateClusters(ctx context.Context, request *grpc.ListDuplicateClustersRequest) (*grpc.ListDuplicateClustersResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListDuplicateClustersResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListDuplicateClustersRequest) (*grpc.ListDuplicateClustersResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListDuplicateClustersRequest) *grpc.ListDuplicateClustersResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListDuplicateClustersResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListDuplicateClustersRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFavorites provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListFavorites(ctx context.Context, request *grpc.ListFavoritesRequest) (*grpc.ListFavoritesResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListFavoritesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListFavoritesRequest) (*grpc.ListFavoritesResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListFavoritesRequest) *grpc.ListFavoritesResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListFavoritesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListFavoritesRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListHeldAds provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListHeldAds(ctx context.Context, request *grpc.ListHeldAdsRequest) (*grpc.ListAdResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListAdResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListHeldAdsRequest) (*grpc.ListAdResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListHeldAdsRequest) *grpc.ListAdResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListAdResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListHeldAdsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMessages provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListMessages(ctx context.Context, request *grpc.ListMessagesRequest) (*grpc.ListMessagesResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListMessagesResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListMessagesRequest) (*grpc.ListMessagesResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListMessagesRequest) *grpc.ListMessagesResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListMessagesResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListMessagesRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListReportedAds provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListReportedAds(ctx context.Context, request *grpc.ListReportedAdsRequest) (*grpc.ListReportedAdsResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListReportedAdsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListReportedAdsRequest) (*grpc.ListReportedAdsResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListReportedAdsRequest) *grpc.ListReportedAdsResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListReportedAdsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListReportedAdsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListReviews provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListReviews(ctx context.Context, request *grpc.ListReviewsRequest) (*grpc.ListReviewsResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListReviewsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListReviewsRequest) (*grpc.ListReviewsResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListReviewsRequest) *grpc.ListReviewsResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListReviewsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListReviewsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListScheduledTransitions provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListScheduledTransitions(ctx context.Context, request *grpc.ListScheduledTransitionsRequest) (*grpc.ListScheduledTransitionsResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListScheduledTransitionsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListScheduledTransitionsRequest) (*grpc.ListScheduledTransitionsResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListScheduledTransitionsRequest) *grpc.ListScheduledTransitionsResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListScheduledTransitionsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListScheduledTransitionsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTrash provides a mock function with given fields: ctx, request
+func (_m *IAdService) ListTrash(ctx context.Context, request *grpc.ListTrashRequest) (*grpc.ListAdResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ListAdResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListTrashRequest) (*grpc.ListAdResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ListTrashRequest) *grpc.ListAdResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ListAdResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ListTrashRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveFavorite provides a mock function with given fields: ctx, request
+func (_m *IAdService) RemoveFavorite(ctx context.Context, request *grpc.FavoriteRequest) (*grpc.RemoveFavoriteResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.RemoveFavoriteResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.FavoriteRequest) (*grpc.RemoveFavoriteResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.FavoriteRequest) *grpc.RemoveFavoriteResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.RemoveFavoriteResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.FavoriteRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenewAd provides a mock function with given fields: ctx, request
+func (_m *IAdService) RenewAd(ctx context.Context, request *grpc.RenewAdRequest) (*grpc.AdResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RenewAdRequest) (*grpc.AdResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RenewAdRequest) *grpc.AdResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.RenewAdRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplyToReview provides a mock function with given fields: ctx, request
+func (_m *IAdService) ReplyToReview(ctx context.Context, request *grpc.ReplyToReviewRequest) (*grpc.Review, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ReplyToReviewRequest) (*grpc.Review, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ReplyToReviewRequest) *grpc.Review); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ReplyToReviewRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportAd provides a mock function with given fields: ctx, request
+func (_m *IAdService) ReportAd(ctx context.Context, request *grpc.ReportAdRequest) (*grpc.AdReport, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ReportAdRequest) (*grpc.AdReport, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ReportAdRequest) *grpc.AdReport); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ReportAdRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResendVerification provides a mock function with given fields: ctx, request
+func (_m *IAdService) ResendVerification(ctx context.Context, request *grpc.ResendVerificationRequest) (*grpc.ResendVerificationResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ResendVerificationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ResendVerificationRequest) (*grpc.ResendVerificationResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ResendVerificationRequest) *grpc.ResendVerificationResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ResendVerificationResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ResendVerificationRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveAppeal provides a mock function with given fields: ctx, request
+func (_m *IAdService) ResolveAppeal(ctx context.Context, request *grpc.ResolveAppealRequest) (*grpc.Appeal, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ResolveAppealRequest) (*grpc.Appeal, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ResolveAppealRequest) *grpc.Appeal); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ResolveAppealRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveReports provides a mock function with given fields: ctx, request
+func (_m *IAdService) ResolveReports(ctx context.Context, request *grpc.ResolveReportsRequest) (*grpc.ResolveReportsResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ResolveReportsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ResolveReportsRequest) (*grpc.ResolveReportsResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ResolveReportsRequest) *grpc.ResolveReportsResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ResolveReportsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ResolveReportsRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreAd provides a mock function with given fields: ctx, request
+func (_m *IAdService) RestoreAd(ctx context.Context, request *grpc.RestoreAdRequest) (*grpc.AdResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RestoreAdRequest) (*grpc.AdResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RestoreAdRequest) *grpc.AdResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.RestoreAdRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreUser provides a mock function with given fields: ctx, request
+func (_m *IAdService) RestoreUser(ctx context.Context, request *grpc.RestoreUserRequest) (*grpc.UserResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RestoreUserRequest) (*grpc.UserResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RestoreUserRequest) *grpc.UserResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.RestoreUserRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewSeller provides a mock function with given fields: ctx, request
+func (_m *IAdService) ReviewSeller(ctx context.Context, request *grpc.ReviewSellerRequest) (*grpc.Review, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ReviewSellerRequest) (*grpc.Review, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.ReviewSellerRequest) *grpc.Review); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.ReviewSellerRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RollbackAd provides a mock function with given fields: ctx, request
+func (_m *IAdService) RollbackAd(ctx context.Context, request *grpc.RollbackAdRequest) (*grpc.AdResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RollbackAdRequest) (*grpc.AdResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.RollbackAdRequest) *grpc.AdResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.RollbackAdRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendMessage provides a mock function with given fields: ctx, request
+func (_m *IAdService) SendMessage(ctx context.Context, request *grpc.SendMessageRequest) (*grpc.ChatMessage, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.ChatMessage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.SendMessageRequest) (*grpc.ChatMessage, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.SendMessageRequest) *grpc.ChatMessage); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.ChatMessage)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.SendMessageRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartConversation provides a mock function with given fields: ctx, request
+func (_m *IAdService) StartConversation(ctx context.Context, request *grpc.StartConversationRequest) (*grpc.Conversation, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.StartConversationRequest) (*grpc.Conversation, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.StartConversationRequest) *grpc.Conversation); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.StartConversationRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnblockUser provides a mock function with given fields: ctx, request
+func (_m *IAdService) UnblockUser(ctx context.Context, request *grpc.BlockUserRequest) (*grpc.UnblockUserResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.UnblockUserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.BlockUserRequest) (*grpc.UnblockUserResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.BlockUserRequest) *grpc.UnblockUserResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.UnblockUserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.BlockUserRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAd provides a mock function with given fields: ctx, request
+func (_m *IAdService) UpdateAd(ctx context.Context, request *grpc.UpdateAdRequest) (*grpc.AdResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AdResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateAdRequest) (*grpc.AdResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateAdRequest) *grpc.AdResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AdResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.UpdateAdRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateUser provides a mock function with given fields: ctx, request
+func (_m *IAdService) UpdateUser(ctx context.Context, request *grpc.UpdateUserRequest) (*grpc.UserResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.UserResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateUserRequest) (*grpc.UserResponse, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.UpdateUserRequest) *grpc.UserResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.UserResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.UpdateUserRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyAuditLog provides a mock function with given fields: ctx, request
+func (_m *IAdService) VerifyAuditLog(ctx context.Context, request *grpc.VerifyAuditLogRequest) (*grpc.AuditVerification, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *grpc.AuditVerification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.VerifyAuditLogRequest) (*grpc.AuditVerification, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *grpc.VerifyAuditLogRequest) *grpc.AuditVerification); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*grpc.AuditVerification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *grpc.VerifyAuditLogRequest) error); ok {
 		r1 = rf(ctx, request)
 	} else {
 		r1 = ret.Error(1)
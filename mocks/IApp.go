@@ -5,10 +5,18 @@ package mocks
 import (
 	ads "ads-server/internal/ads"
 
+	app "ads-server/internal/app"
+
+	audit "ads-server/internal/audit"
+
 	context "context"
 
+	messages "ads-server/internal/messages"
+
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	url "net/url"
 
 	users "ads-server/internal/users"
@@ -19,25 +27,219 @@ type IApp struct {
 	mock.Mock
 }
 
-// CreateAd provides a mock function with given fields: ctx, uID, title, text
-func (_m *IApp) CreateAd(ctx context.Context, uID int64, title string, text string) (*ads.Ad, error) {
-	ret := _m.Called(ctx, uID, title, text)
+// AdChangesSinceApproval provides a mock function with given fields: ctx, adID, uID
+func (_m *IApp) AdChangesSinceApproval(ctx context.Context, adID int64, uID int64) (app.AdChanges, error) {
+	ret := _m.Called(ctx, adID, uID)
+
+	var r0 app.AdChanges
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (app.AdChanges, error)); ok {
+		return rf(ctx, adID, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) app.AdChanges); ok {
+		r0 = rf(ctx, adID, uID)
+	} else {
+		r0 = ret.Get(0).(app.AdChanges)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddFavorite provides a mock function with given fields: ctx, uID, adID
+func (_m *IApp) AddFavorite(ctx context.Context, uID int64, adID int64) (*ads.Favorite, error) {
+	ret := _m.Called(ctx, uID, adID)
+
+	var r0 *ads.Favorite
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*ads.Favorite, error)); ok {
+		return rf(ctx, uID, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *ads.Favorite); ok {
+		r0 = rf(ctx, uID, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Favorite)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, uID, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AppealTakedown provides a mock function with given fields: ctx, adID, uID, comment
+func (_m *IApp) AppealTakedown(ctx context.Context, adID int64, uID int64, comment string) (*ads.Appeal, error) {
+	ret := _m.Called(ctx, adID, uID, comment)
+
+	var r0 *ads.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) (*ads.Appeal, error)); ok {
+		return rf(ctx, adID, uID, comment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) *ads.Appeal); ok {
+		r0 = rf(ctx, adID, uID, comment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string) error); ok {
+		r1 = rf(ctx, adID, uID, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApproveAd provides a mock function with given fields: ctx, adID, uID, version
+func (_m *IApp) ApproveAd(ctx context.Context, adID int64, uID int64, version int64) (*ads.Approval, error) {
+	ret := _m.Called(ctx, adID, uID, version)
+
+	var r0 *ads.Approval
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*ads.Approval, error)); ok {
+		return rf(ctx, adID, uID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *ads.Approval); ok {
+		r0 = rf(ctx, adID, uID, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Approval)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuditLog provides a mock function with given fields: ctx, uID, f
+func (_m *IApp) AuditLog(ctx context.Context, uID int64, f audit.Filter) ([]*audit.Entry, error) {
+	ret := _m.Called(ctx, uID, f)
+
+	var r0 []*audit.Entry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, audit.Filter) ([]*audit.Entry, error)); ok {
+		return rf(ctx, uID, f)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, audit.Filter) []*audit.Entry); ok {
+		r0 = rf(ctx, uID, f)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*audit.Entry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, audit.Filter) error); ok {
+		r1 = rf(ctx, uID, f)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BlockUser provides a mock function with given fields: ctx, uID, blockedID
+func (_m *IApp) BlockUser(ctx context.Context, uID int64, blockedID int64) (*messages.Block, error) {
+	ret := _m.Called(ctx, uID, blockedID)
+
+	var r0 *messages.Block
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*messages.Block, error)); ok {
+		return rf(ctx, uID, blockedID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *messages.Block); ok {
+		r0 = rf(ctx, uID, blockedID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*messages.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, uID, blockedID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CancelTransition provides a mock function with given fields: ctx, adID, transitionID, uID
+func (_m *IApp) CancelTransition(ctx context.Context, adID int64, transitionID int64, uID int64) error {
+	ret := _m.Called(ctx, adID, transitionID, uID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) error); ok {
+		r0 = rf(ctx, adID, transitionID, uID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ChangeAdStatus provides a mock function with given fields: ctx, adID, uID, published, publishAt, unpublishAt, version
+func (_m *IApp) ChangeAdStatus(ctx context.Context, adID int64, uID int64, published bool, publishAt time.Time, unpublishAt time.Time, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, published, publishAt, unpublishAt, version)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) (*ads.Ad, error)); ok {
-		return rf(ctx, uID, title, text)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, time.Time, time.Time, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, published, publishAt, unpublishAt, version)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) *ads.Ad); ok {
-		r0 = rf(ctx, uID, title, text)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, time.Time, time.Time, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, published, publishAt, unpublishAt, version)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
-		r1 = rf(ctx, uID, title, text)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool, time.Time, time.Time, int64) error); ok {
+		r1 = rf(ctx, adID, uID, published, publishAt, unpublishAt, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmEmail provides a mock function with given fields: ctx, token
+func (_m *IApp) ConfirmEmail(ctx context.Context, token string) (*users.User, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 *users.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*users.User, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *users.User); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -45,6 +247,39 @@ func (_m *IApp) CreateAd(ctx context.Context, uID int64, title string, text stri
 	return r0, r1
 }
 
+// CreateAd provides a mock function with given fields: ctx, uID, title, text, category
+func (_m *IApp) CreateAd(ctx context.Context, uID int64, title string, text string, category string) (*ads.Ad, bool, error) {
+	ret := _m.Called(ctx, uID, title, text, category)
+
+	var r0 *ads.Ad
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, string) (*ads.Ad, bool, error)); ok {
+		return rf(ctx, uID, title, text, category)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, string) *ads.Ad); ok {
+		r0 = rf(ctx, uID, title, text, category)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, string) bool); ok {
+		r1 = rf(ctx, uID, title, text, category)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, string, string, string) error); ok {
+		r2 = rf(ctx, uID, title, text, category)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // CreateUser provides a mock function with given fields: ctx, name, email
 func (_m *IApp) CreateUser(ctx context.Context, name string, email string) (*users.User, error) {
 	ret := _m.Called(ctx, name, email)
@@ -71,13 +306,13 @@ func (_m *IApp) CreateUser(ctx context.Context, name string, email string) (*use
 	return r0, r1
 }
 
-// DeleteAd provides a mock function with given fields: ctx, adID, uID
-func (_m *IApp) DeleteAd(ctx context.Context, adID int64, uID int64) error {
-	ret := _m.Called(ctx, adID, uID)
+// DeleteAd provides a mock function with given fields: ctx, adID, uID, version
+func (_m *IApp) DeleteAd(ctx context.Context, adID int64, uID int64, version int64) error {
+	ret := _m.Called(ctx, adID, uID, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
-		r0 = rf(ctx, adID, uID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) error); ok {
+		r0 = rf(ctx, adID, uID, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -85,18 +320,104 @@ func (_m *IApp) DeleteAd(ctx context.Context, adID int64, uID int64) error {
 	return r0
 }
 
-// DeleteUser provides a mock function with given fields: ctx, id
-func (_m *IApp) DeleteUser(ctx context.Context, id int64) error {
-	ret := _m.Called(ctx, id)
+// DeleteUser provides a mock function with given fields: ctx, id, version
+func (_m *IApp) DeleteUser(ctx context.Context, id int64, version int64) (app.DeletionReport, error) {
+	ret := _m.Called(ctx, id, version)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(ctx, id)
+	var r0 app.DeletionReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (app.DeletionReport, error)); ok {
+		return rf(ctx, id, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) app.DeletionReport); ok {
+		r0 = rf(ctx, id, version)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(app.DeletionReport)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, id, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DuplicateClusters provides a mock function with given fields: ctx, uID
+func (_m *IApp) DuplicateClusters(ctx context.Context, uID int64) ([]app.DuplicateCluster, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []app.DuplicateCluster
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]app.DuplicateCluster, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []app.DuplicateCluster); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]app.DuplicateCluster)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExtendAd provides a mock function with given fields: ctx, adID, uID, by, version
+func (_m *IApp) ExtendAd(ctx context.Context, adID int64, uID int64, by time.Duration, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, by, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, time.Duration, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, by, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, time.Duration, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, by, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, time.Duration, int64) error); ok {
+		r1 = rf(ctx, adID, uID, by, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FavoriteCount provides a mock function with given fields: ctx, adID, uID
+func (_m *IApp) FavoriteCount(ctx context.Context, adID int64, uID int64) (int, error) {
+	ret := _m.Called(ctx, adID, uID)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (int, error)); ok {
+		return rf(ctx, adID, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) int); ok {
+		r0 = rf(ctx, adID, uID)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Filter provides a mock function with given fields: ctx, params
@@ -193,25 +514,77 @@ func (_m *IApp) GetAdByName(ctx context.Context, title string) []*ads.Ad {
 	return r0
 }
 
-// PublishAd provides a mock function with given fields: ctx, adID, uID, action
-func (_m *IApp) PublishAd(ctx context.Context, adID int64, uID int64, action bool) (*ads.Ad, error) {
-	ret := _m.Called(ctx, adID, uID, action)
+// GetRevision provides a mock function with given fields: ctx, adID, uID, number
+func (_m *IApp) GetRevision(ctx context.Context, adID int64, uID int64, number int64) (*ads.Revision, error) {
+	ret := _m.Called(ctx, adID, uID, number)
 
-	var r0 *ads.Ad
+	var r0 *ads.Revision
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) (*ads.Ad, error)); ok {
-		return rf(ctx, adID, uID, action)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*ads.Revision, error)); ok {
+		return rf(ctx, adID, uID, number)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) *ads.Ad); ok {
-		r0 = rf(ctx, adID, uID, action)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *ads.Revision); ok {
+		r0 = rf(ctx, adID, uID, number)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ads.Ad)
+			r0 = ret.Get(0).(*ads.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HeldAds provides a mock function with given fields: ctx, uID
+func (_m *IApp) HeldAds(ctx context.Context, uID int64) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HideReview provides a mock function with given fields: ctx, reviewID, uID, hidden
+func (_m *IApp) HideReview(ctx context.Context, reviewID int64, uID int64, hidden bool) (*users.Review, error) {
+	ret := _m.Called(ctx, reviewID, uID, hidden)
+
+	var r0 *users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) (*users.Review, error)); ok {
+		return rf(ctx, reviewID, uID, hidden)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) *users.Review); ok {
+		r0 = rf(ctx, reviewID, uID, hidden)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Review)
 		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool) error); ok {
-		r1 = rf(ctx, adID, uID, action)
+		r1 = rf(ctx, reviewID, uID, hidden)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -219,25 +592,25 @@ func (_m *IApp) PublishAd(ctx context.Context, adID int64, uID int64, action boo
 	return r0, r1
 }
 
-// UpdateAd provides a mock function with given fields: ctx, adID, uID, title, text
-func (_m *IApp) UpdateAd(ctx context.Context, adID int64, uID int64, title string, text string) (*ads.Ad, error) {
-	ret := _m.Called(ctx, adID, uID, title, text)
+// ListAppeals provides a mock function with given fields: ctx, uID
+func (_m *IApp) ListAppeals(ctx context.Context, uID int64) ([]*ads.Appeal, error) {
+	ret := _m.Called(ctx, uID)
 
-	var r0 *ads.Ad
+	var r0 []*ads.Appeal
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string) (*ads.Ad, error)); ok {
-		return rf(ctx, adID, uID, title, text)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Appeal, error)); ok {
+		return rf(ctx, uID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string) *ads.Ad); ok {
-		r0 = rf(ctx, adID, uID, title, text)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Appeal); ok {
+		r0 = rf(ctx, uID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*ads.Ad)
+			r0 = ret.Get(0).([]*ads.Appeal)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string, string) error); ok {
-		r1 = rf(ctx, adID, uID, title, text)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -245,25 +618,724 @@ func (_m *IApp) UpdateAd(ctx context.Context, adID int64, uID int64, title strin
 	return r0, r1
 }
 
-// UpdateUser provides a mock function with given fields: ctx, id, name, email
-func (_m *IApp) UpdateUser(ctx context.Context, id int64, name string, email string) (*users.User, error) {
-	ret := _m.Called(ctx, id, name, email)
+// ListBlocked provides a mock function with given fields: ctx, uID, actorID
+func (_m *IApp) ListBlocked(ctx context.Context, uID int64, actorID int64) ([]*messages.Block, error) {
+	ret := _m.Called(ctx, uID, actorID)
 
-	var r0 *users.User
+	var r0 []*messages.Block
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) (*users.User, error)); ok {
-		return rf(ctx, id, name, email)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) ([]*messages.Block, error)); ok {
+		return rf(ctx, uID, actorID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) *users.User); ok {
-		r0 = rf(ctx, id, name, email)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*messages.Block); ok {
+		r0 = rf(ctx, uID, actorID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*users.User)
+			r0 = ret.Get(0).([]*messages.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, uID, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListConversations provides a mock function with given fields: ctx, uID
+func (_m *IApp) ListConversations(ctx context.Context, uID int64) ([]*messages.Conversation, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []*messages.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*messages.Conversation, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*messages.Conversation); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*messages.Conversation)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
-		r1 = rf(ctx, id, name, email)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFavorites provides a mock function with given fields: ctx, uID, actorID
+func (_m *IApp) ListFavorites(ctx context.Context, uID int64, actorID int64) ([]app.FavoriteAd, error) {
+	ret := _m.Called(ctx, uID, actorID)
+
+	var r0 []app.FavoriteAd
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) ([]app.FavoriteAd, error)); ok {
+		return rf(ctx, uID, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []app.FavoriteAd); ok {
+		r0 = rf(ctx, uID, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]app.FavoriteAd)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, uID, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMessages provides a mock function with given fields: ctx, conversationID, uID, p
+func (_m *IApp) ListMessages(ctx context.Context, conversationID int64, uID int64, p messages.Page) ([]*messages.Message, error) {
+	ret := _m.Called(ctx, conversationID, uID, p)
+
+	var r0 []*messages.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, messages.Page) ([]*messages.Message, error)); ok {
+		return rf(ctx, conversationID, uID, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, messages.Page) []*messages.Message); ok {
+		r0 = rf(ctx, conversationID, uID, p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*messages.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, messages.Page) error); ok {
+		r1 = rf(ctx, conversationID, uID, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListReviews provides a mock function with given fields: ctx, sellerID, actorID
+func (_m *IApp) ListReviews(ctx context.Context, sellerID int64, actorID *int64) ([]*users.Review, error) {
+	ret := _m.Called(ctx, sellerID, actorID)
+
+	var r0 []*users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) ([]*users.Review, error)); ok {
+		return rf(ctx, sellerID, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) []*users.Review); ok {
+		r0 = rf(ctx, sellerID, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *int64) error); ok {
+		r1 = rf(ctx, sellerID, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRevisions provides a mock function with given fields: ctx, adID, uID
+func (_m *IApp) ListRevisions(ctx context.Context, adID int64, uID int64) ([]*ads.Revision, error) {
+	ret := _m.Called(ctx, adID, uID)
+
+	var r0 []*ads.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) ([]*ads.Revision, error)); ok {
+		return rf(ctx, adID, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*ads.Revision); ok {
+		r0 = rf(ctx, adID, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTransitions provides a mock function with given fields: ctx, adID, uID
+func (_m *IApp) ListTransitions(ctx context.Context, adID int64, uID int64) ([]*ads.Transition, error) {
+	ret := _m.Called(ctx, adID, uID)
+
+	var r0 []*ads.Transition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) ([]*ads.Transition, error)); ok {
+		return rf(ctx, adID, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*ads.Transition); ok {
+		r0 = rf(ctx, adID, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Transition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTrash provides a mock function with given fields: ctx, uID, actorID
+func (_m *IApp) ListTrash(ctx context.Context, uID int64, actorID int64) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, uID, actorID)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) ([]*ads.Ad, error)); ok {
+		return rf(ctx, uID, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) []*ads.Ad); ok {
+		r0 = rf(ctx, uID, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, uID, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUserAds provides a mock function with given fields: ctx, uID, actorID
+func (_m *IApp) ListUserAds(ctx context.Context, uID int64, actorID *int64) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, uID, actorID)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) ([]*ads.Ad, error)); ok {
+		return rf(ctx, uID, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64) []*ads.Ad); ok {
+		r0 = rf(ctx, uID, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *int64) error); ok {
+		r1 = rf(ctx, uID, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublishAd provides a mock function with given fields: ctx, adID, uID, action, version
+func (_m *IApp) PublishAd(ctx context.Context, adID int64, uID int64, action bool, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, action, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, action, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, action, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool, int64) error); ok {
+		r1 = rf(ctx, adID, uID, action, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveFavorite provides a mock function with given fields: ctx, uID, adID
+func (_m *IApp) RemoveFavorite(ctx context.Context, uID int64, adID int64) error {
+	ret := _m.Called(ctx, uID, adID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, uID, adID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenewAd provides a mock function with given fields: ctx, adID, uID, version
+func (_m *IApp) RenewAd(ctx context.Context, adID int64, uID int64, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplyToReview provides a mock function with given fields: ctx, reviewID, uID, text
+func (_m *IApp) ReplyToReview(ctx context.Context, reviewID int64, uID int64, text string) (*users.Review, error) {
+	ret := _m.Called(ctx, reviewID, uID, text)
+
+	var r0 *users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) (*users.Review, error)); ok {
+		return rf(ctx, reviewID, uID, text)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) *users.Review); ok {
+		r0 = rf(ctx, reviewID, uID, text)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string) error); ok {
+		r1 = rf(ctx, reviewID, uID, text)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportAd provides a mock function with given fields: ctx, adID, uID, reason, comment
+func (_m *IApp) ReportAd(ctx context.Context, adID int64, uID int64, reason ads.ReportReason, comment string) (*ads.Report, error) {
+	ret := _m.Called(ctx, adID, uID, reason, comment)
+
+	var r0 *ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, ads.ReportReason, string) (*ads.Report, error)); ok {
+		return rf(ctx, adID, uID, reason, comment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, ads.ReportReason, string) *ads.Report); ok {
+		r0 = rf(ctx, adID, uID, reason, comment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, ads.ReportReason, string) error); ok {
+		r1 = rf(ctx, adID, uID, reason, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportInbox provides a mock function with given fields: ctx, uID
+func (_m *IApp) ReportInbox(ctx context.Context, uID int64) ([]app.ReportedAd, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 []app.ReportedAd
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]app.ReportedAd, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []app.ReportedAd); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]app.ReportedAd)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResendVerification provides a mock function with given fields: ctx, uID
+func (_m *IApp) ResendVerification(ctx context.Context, uID int64) error {
+	ret := _m.Called(ctx, uID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResolveAppeal provides a mock function with given fields: ctx, appealID, uID, accept
+func (_m *IApp) ResolveAppeal(ctx context.Context, appealID int64, uID int64, accept bool) (*ads.Appeal, error) {
+	ret := _m.Called(ctx, appealID, uID, accept)
+
+	var r0 *ads.Appeal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) (*ads.Appeal, error)); ok {
+		return rf(ctx, appealID, uID, accept)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) *ads.Appeal); ok {
+		r0 = rf(ctx, appealID, uID, accept)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Appeal)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool) error); ok {
+		r1 = rf(ctx, appealID, uID, accept)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveReports provides a mock function with given fields: ctx, adID, uID, takedown
+func (_m *IApp) ResolveReports(ctx context.Context, adID int64, uID int64, takedown bool) ([]*ads.Report, error) {
+	ret := _m.Called(ctx, adID, uID, takedown)
+
+	var r0 []*ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) ([]*ads.Report, error)); ok {
+		return rf(ctx, adID, uID, takedown)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) []*ads.Report); ok {
+		r0 = rf(ctx, adID, uID, takedown)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, bool) error); ok {
+		r1 = rf(ctx, adID, uID, takedown)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreAd provides a mock function with given fields: ctx, adID, uID
+func (_m *IApp) RestoreAd(ctx context.Context, adID int64, uID int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreUser provides a mock function with given fields: ctx, id, actorID
+func (_m *IApp) RestoreUser(ctx context.Context, id int64, actorID int64) (*users.User, error) {
+	ret := _m.Called(ctx, id, actorID)
+
+	var r0 *users.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*users.User, error)); ok {
+		return rf(ctx, id, actorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *users.User); ok {
+		r0 = rf(ctx, id, actorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, id, actorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewSeller provides a mock function with given fields: ctx, adID, uID, rating, text
+func (_m *IApp) ReviewSeller(ctx context.Context, adID int64, uID int64, rating int, text string) (*users.Review, error) {
+	ret := _m.Called(ctx, adID, uID, rating, text)
+
+	var r0 *users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int, string) (*users.Review, error)); ok {
+		return rf(ctx, adID, uID, rating, text)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int, string) *users.Review); ok {
+		r0 = rf(ctx, adID, uID, rating, text)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int, string) error); ok {
+		r1 = rf(ctx, adID, uID, rating, text)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RollbackAd provides a mock function with given fields: ctx, adID, uID, number, version
+func (_m *IApp) RollbackAd(ctx context.Context, adID int64, uID int64, number int64, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, number, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, number, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, number, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64, int64) error); ok {
+		r1 = rf(ctx, adID, uID, number, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendMessage provides a mock function with given fields: ctx, conversationID, uID, text
+func (_m *IApp) SendMessage(ctx context.Context, conversationID int64, uID int64, text string) (*messages.Message, error) {
+	ret := _m.Called(ctx, conversationID, uID, text)
+
+	var r0 *messages.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) (*messages.Message, error)); ok {
+		return rf(ctx, conversationID, uID, text)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string) *messages.Message); ok {
+		r0 = rf(ctx, conversationID, uID, text)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*messages.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string) error); ok {
+		r1 = rf(ctx, conversationID, uID, text)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartConversation provides a mock function with given fields: ctx, adID, buyerID
+func (_m *IApp) StartConversation(ctx context.Context, adID int64, buyerID int64) (*messages.Conversation, error) {
+	ret := _m.Called(ctx, adID, buyerID)
+
+	var r0 *messages.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*messages.Conversation, error)); ok {
+		return rf(ctx, adID, buyerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *messages.Conversation); ok {
+		r0 = rf(ctx, adID, buyerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*messages.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, buyerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubscribeMessages provides a mock function with given fields: ctx, uID
+func (_m *IApp) SubscribeMessages(ctx context.Context, uID int64) (<-chan *messages.Message, func(), error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 <-chan *messages.Message
+	var r1 func()
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (<-chan *messages.Message, func(), error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) <-chan *messages.Message); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *messages.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) func()); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(func())
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64) error); ok {
+		r2 = rf(ctx, uID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UnblockUser provides a mock function with given fields: ctx, uID, blockedID
+func (_m *IApp) UnblockUser(ctx context.Context, uID int64, blockedID int64) error {
+	ret := _m.Called(ctx, uID, blockedID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, uID, blockedID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAd provides a mock function with given fields: ctx, adID, uID, title, text, version
+func (_m *IApp) UpdateAd(ctx context.Context, adID int64, uID int64, title string, text string, version int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, uID, title, text, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, uID, title, text, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, string, string, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, uID, title, text, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, string, string, int64) error); ok {
+		r1 = rf(ctx, adID, uID, title, text, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateUser provides a mock function with given fields: ctx, id, name, email, version
+func (_m *IApp) UpdateUser(ctx context.Context, id int64, name string, email string, version int64) (*users.User, error) {
+	ret := _m.Called(ctx, id, name, email, version)
+
+	var r0 *users.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) (*users.User, error)); ok {
+		return rf(ctx, id, name, email, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) *users.User); ok {
+		r0 = rf(ctx, id, name, email, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, int64) error); ok {
+		r1 = rf(ctx, id, name, email, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyAuditLog provides a mock function with given fields: ctx, uID
+func (_m *IApp) VerifyAuditLog(ctx context.Context, uID int64) (audit.Verification, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 audit.Verification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (audit.Verification, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) audit.Verification); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		r0 = ret.Get(0).(audit.Verification)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -4,9 +4,12 @@ package mocks
 
 import (
 	context "context"
-	users "ads-server/internal/users"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	users "ads-server/internal/users"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -14,6 +17,32 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// AddReview provides a mock function with given fields: ctx, r
+func (_m *UserRepository) AddReview(ctx context.Context, r *users.Review) (*users.Review, error) {
+	ret := _m.Called(ctx, r)
+
+	var r0 *users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.Review) (*users.Review, error)); ok {
+		return rf(ctx, r)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *users.Review) *users.Review); ok {
+		r0 = rf(ctx, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *users.Review) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, u
 func (_m *UserRepository) Create(ctx context.Context, u *users.User) (int64, error) {
 	ret := _m.Called(ctx, u)
@@ -38,13 +67,13 @@ func (_m *UserRepository) Create(ctx context.Context, u *users.User) (int64, err
 	return r0, r1
 }
 
-// Delete provides a mock function with given fields: ctx, id
-func (_m *UserRepository) Delete(ctx context.Context, id int64) error {
-	ret := _m.Called(ctx, id)
+// Delete provides a mock function with given fields: ctx, id, version
+func (_m *UserRepository) Delete(ctx context.Context, id int64, version int64) error {
+	ret := _m.Called(ctx, id, version)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, id, version)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -78,25 +107,273 @@ func (_m *UserRepository) Get(ctx context.Context, id int64) (*users.User, error
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, id, name, email
-func (_m *UserRepository) Update(ctx context.Context, id int64, name string, email string) (*users.User, error) {
-	ret := _m.Called(ctx, id, name, email)
+// GetByEmail provides a mock function with given fields: ctx, email
+func (_m *UserRepository) GetByEmail(ctx context.Context, email string) (*users.User, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 *users.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*users.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *users.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReview provides a mock function with given fields: ctx, id
+func (_m *UserRepository) GetReview(ctx context.Context, id int64) (*users.Review, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*users.Review, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *users.Review); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HideReview provides a mock function with given fields: ctx, id, hidden, moderatorID
+func (_m *UserRepository) HideReview(ctx context.Context, id int64, hidden bool, moderatorID int64) (*users.Review, error) {
+	ret := _m.Called(ctx, id, hidden, moderatorID)
+
+	var r0 *users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool, int64) (*users.Review, error)); ok {
+		return rf(ctx, id, hidden, moderatorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool, int64) *users.Review); ok {
+		r0 = rf(ctx, id, hidden, moderatorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, bool, int64) error); ok {
+		r1 = rf(ctx, id, hidden, moderatorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Purge provides a mock function with given fields: ctx, before
+func (_m *UserRepository) Purge(ctx context.Context, before time.Time) ([]int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplyToReview provides a mock function with given fields: ctx, id, reply, at
+func (_m *UserRepository) ReplyToReview(ctx context.Context, id int64, reply string, at time.Time) (*users.Review, error) {
+	ret := _m.Called(ctx, id, reply, at)
+
+	var r0 *users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) (*users.Review, error)); ok {
+		return rf(ctx, id, reply, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) *users.Review); ok {
+		r0 = rf(ctx, id, reply, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, time.Time) error); ok {
+		r1 = rf(ctx, id, reply, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Restore provides a mock function with given fields: ctx, id
+func (_m *UserRepository) Restore(ctx context.Context, id int64) (*users.User, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *users.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*users.User, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *users.User); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Reviews provides a mock function with given fields: ctx, sellerID, withHidden
+func (_m *UserRepository) Reviews(ctx context.Context, sellerID int64, withHidden bool) ([]*users.Review, error) {
+	ret := _m.Called(ctx, sellerID, withHidden)
+
+	var r0 []*users.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool) ([]*users.Review, error)); ok {
+		return rf(ctx, sellerID, withHidden)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool) []*users.Review); ok {
+		r0 = rf(ctx, sellerID, withHidden)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*users.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, bool) error); ok {
+		r1 = rf(ctx, sellerID, withHidden)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveToken provides a mock function with given fields: ctx, t
+func (_m *UserRepository) SaveToken(ctx context.Context, t *users.VerificationToken) error {
+	ret := _m.Called(ctx, t)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *users.VerificationToken) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, id, name, email, version
+func (_m *UserRepository) Update(ctx context.Context, id int64, name string, email string, version int64) (*users.User, error) {
+	ret := _m.Called(ctx, id, name, email, version)
+
+	var r0 *users.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) (*users.User, error)); ok {
+		return rf(ctx, id, name, email, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) *users.User); ok {
+		r0 = rf(ctx, id, name, email, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, int64) error); ok {
+		r1 = rf(ctx, id, name, email, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserToken provides a mock function with given fields: ctx, uID
+func (_m *UserRepository) UserToken(ctx context.Context, uID int64) (*users.VerificationToken, error) {
+	ret := _m.Called(ctx, uID)
+
+	var r0 *users.VerificationToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*users.VerificationToken, error)); ok {
+		return rf(ctx, uID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *users.VerificationToken); ok {
+		r0 = rf(ctx, uID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.VerificationToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, uID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Verify provides a mock function with given fields: ctx, hash, now
+func (_m *UserRepository) Verify(ctx context.Context, hash string, now time.Time) (*users.User, error) {
+	ret := _m.Called(ctx, hash, now)
 
 	var r0 *users.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) (*users.User, error)); ok {
-		return rf(ctx, id, name, email)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (*users.User, error)); ok {
+		return rf(ctx, hash, now)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) *users.User); ok {
-		r0 = rf(ctx, id, name, email)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) *users.User); ok {
+		r0 = rf(ctx, hash, now)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*users.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
-		r1 = rf(ctx, id, name, email)
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, hash, now)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -19,15 +19,55 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	AdService_CreateAd_FullMethodName       = "/ad.AdService/CreateAd"
-	AdService_ChangeAdStatus_FullMethodName = "/ad.AdService/ChangeAdStatus"
-	AdService_UpdateAd_FullMethodName       = "/ad.AdService/UpdateAd"
-	AdService_ListAds_FullMethodName        = "/ad.AdService/ListAds"
-	AdService_CreateUser_FullMethodName     = "/ad.AdService/CreateUser"
-	AdService_GetUser_FullMethodName        = "/ad.AdService/GetUser"
-	AdService_UpdateUser_FullMethodName     = "/ad.AdService/UpdateUser"
-	AdService_DeleteUser_FullMethodName     = "/ad.AdService/DeleteUser"
-	AdService_DeleteAd_FullMethodName       = "/ad.AdService/DeleteAd"
+	AdService_CreateAd_FullMethodName                  = "/ad.AdService/CreateAd"
+	AdService_ChangeAdStatus_FullMethodName            = "/ad.AdService/ChangeAdStatus"
+	AdService_UpdateAd_FullMethodName                  = "/ad.AdService/UpdateAd"
+	AdService_ListAds_FullMethodName                   = "/ad.AdService/ListAds"
+	AdService_CreateUser_FullMethodName                = "/ad.AdService/CreateUser"
+	AdService_GetUser_FullMethodName                   = "/ad.AdService/GetUser"
+	AdService_UpdateUser_FullMethodName                = "/ad.AdService/UpdateUser"
+	AdService_DeleteUser_FullMethodName                = "/ad.AdService/DeleteUser"
+	AdService_DeleteAd_FullMethodName                  = "/ad.AdService/DeleteAd"
+	AdService_ConfirmEmail_FullMethodName              = "/ad.AdService/ConfirmEmail"
+	AdService_ResendVerification_FullMethodName        = "/ad.AdService/ResendVerification"
+	AdService_ListAdRevisions_FullMethodName           = "/ad.AdService/ListAdRevisions"
+	AdService_GetAdRevision_FullMethodName             = "/ad.AdService/GetAdRevision"
+	AdService_RollbackAd_FullMethodName                = "/ad.AdService/RollbackAd"
+	AdService_ApproveAd_FullMethodName                 = "/ad.AdService/ApproveAd"
+	AdService_GetAdChanges_FullMethodName              = "/ad.AdService/GetAdChanges"
+	AdService_ListTrash_FullMethodName                 = "/ad.AdService/ListTrash"
+	AdService_RestoreAd_FullMethodName                 = "/ad.AdService/RestoreAd"
+	AdService_RestoreUser_FullMethodName               = "/ad.AdService/RestoreUser"
+	AdService_ListAuditEntries_FullMethodName          = "/ad.AdService/ListAuditEntries"
+	AdService_VerifyAuditLog_FullMethodName            = "/ad.AdService/VerifyAuditLog"
+	AdService_RenewAd_FullMethodName                   = "/ad.AdService/RenewAd"
+	AdService_ExtendAd_FullMethodName                  = "/ad.AdService/ExtendAd"
+	AdService_ListScheduledTransitions_FullMethodName  = "/ad.AdService/ListScheduledTransitions"
+	AdService_CancelScheduledTransition_FullMethodName = "/ad.AdService/CancelScheduledTransition"
+	AdService_AddFavorite_FullMethodName               = "/ad.AdService/AddFavorite"
+	AdService_RemoveFavorite_FullMethodName            = "/ad.AdService/RemoveFavorite"
+	AdService_ListFavorites_FullMethodName             = "/ad.AdService/ListFavorites"
+	AdService_CountFavorites_FullMethodName            = "/ad.AdService/CountFavorites"
+	AdService_StartConversation_FullMethodName         = "/ad.AdService/StartConversation"
+	AdService_ListConversations_FullMethodName         = "/ad.AdService/ListConversations"
+	AdService_SendMessage_FullMethodName               = "/ad.AdService/SendMessage"
+	AdService_ListMessages_FullMethodName              = "/ad.AdService/ListMessages"
+	AdService_BlockUser_FullMethodName                 = "/ad.AdService/BlockUser"
+	AdService_UnblockUser_FullMethodName               = "/ad.AdService/UnblockUser"
+	AdService_ListBlockedUsers_FullMethodName          = "/ad.AdService/ListBlockedUsers"
+	AdService_Chat_FullMethodName                      = "/ad.AdService/Chat"
+	AdService_ReviewSeller_FullMethodName              = "/ad.AdService/ReviewSeller"
+	AdService_ListReviews_FullMethodName               = "/ad.AdService/ListReviews"
+	AdService_ReplyToReview_FullMethodName             = "/ad.AdService/ReplyToReview"
+	AdService_HideReview_FullMethodName                = "/ad.AdService/HideReview"
+	AdService_ReportAd_FullMethodName                  = "/ad.AdService/ReportAd"
+	AdService_ListReportedAds_FullMethodName           = "/ad.AdService/ListReportedAds"
+	AdService_ResolveReports_FullMethodName            = "/ad.AdService/ResolveReports"
+	AdService_AppealTakedown_FullMethodName            = "/ad.AdService/AppealTakedown"
+	AdService_ListAppeals_FullMethodName               = "/ad.AdService/ListAppeals"
+	AdService_ResolveAppeal_FullMethodName             = "/ad.AdService/ResolveAppeal"
+	AdService_ListHeldAds_FullMethodName               = "/ad.AdService/ListHeldAds"
+	AdService_ListDuplicateClusters_FullMethodName     = "/ad.AdService/ListDuplicateClusters"
 )
 
 // AdServiceClient is the client API for AdService service.
@@ -43,6 +83,48 @@ type AdServiceClient interface {
 	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UserResponse, error)
 	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
 	DeleteAd(ctx context.Context, in *DeleteAdRequest, opts ...grpc.CallOption) (*DeleteAdResponse, error)
+	ConfirmEmail(ctx context.Context, in *ConfirmEmailRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	ResendVerification(ctx context.Context, in *ResendVerificationRequest, opts ...grpc.CallOption) (*ResendVerificationResponse, error)
+	ListAdRevisions(ctx context.Context, in *ListAdRevisionsRequest, opts ...grpc.CallOption) (*ListAdRevisionsResponse, error)
+	GetAdRevision(ctx context.Context, in *GetAdRevisionRequest, opts ...grpc.CallOption) (*AdRevision, error)
+	RollbackAd(ctx context.Context, in *RollbackAdRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	ApproveAd(ctx context.Context, in *ApproveAdRequest, opts ...grpc.CallOption) (*AdApproval, error)
+	GetAdChanges(ctx context.Context, in *GetAdChangesRequest, opts ...grpc.CallOption) (*AdChangesResponse, error)
+	ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListAdResponse, error)
+	RestoreAd(ctx context.Context, in *RestoreAdRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	RestoreUser(ctx context.Context, in *RestoreUserRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error)
+	VerifyAuditLog(ctx context.Context, in *VerifyAuditLogRequest, opts ...grpc.CallOption) (*AuditVerification, error)
+	RenewAd(ctx context.Context, in *RenewAdRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	ExtendAd(ctx context.Context, in *ExtendAdRequest, opts ...grpc.CallOption) (*AdResponse, error)
+	ListScheduledTransitions(ctx context.Context, in *ListScheduledTransitionsRequest, opts ...grpc.CallOption) (*ListScheduledTransitionsResponse, error)
+	CancelScheduledTransition(ctx context.Context, in *CancelScheduledTransitionRequest, opts ...grpc.CallOption) (*CancelScheduledTransitionResponse, error)
+	AddFavorite(ctx context.Context, in *FavoriteRequest, opts ...grpc.CallOption) (*FavoriteResponse, error)
+	RemoveFavorite(ctx context.Context, in *FavoriteRequest, opts ...grpc.CallOption) (*RemoveFavoriteResponse, error)
+	ListFavorites(ctx context.Context, in *ListFavoritesRequest, opts ...grpc.CallOption) (*ListFavoritesResponse, error)
+	CountFavorites(ctx context.Context, in *CountFavoritesRequest, opts ...grpc.CallOption) (*CountFavoritesResponse, error)
+	StartConversation(ctx context.Context, in *StartConversationRequest, opts ...grpc.CallOption) (*Conversation, error)
+	ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error)
+	SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*ChatMessage, error)
+	ListMessages(ctx context.Context, in *ListMessagesRequest, opts ...grpc.CallOption) (*ListMessagesResponse, error)
+	BlockUser(ctx context.Context, in *BlockUserRequest, opts ...grpc.CallOption) (*UserBlock, error)
+	UnblockUser(ctx context.Context, in *BlockUserRequest, opts ...grpc.CallOption) (*UnblockUserResponse, error)
+	ListBlockedUsers(ctx context.Context, in *ListBlockedUsersRequest, opts ...grpc.CallOption) (*ListBlockedUsersResponse, error)
+	// Chat sends messages of the stream and returns messages sent to or by the user in any conversation.
+	// The first request identifies the user, its text may be empty to only receive messages.
+	Chat(ctx context.Context, opts ...grpc.CallOption) (AdService_ChatClient, error)
+	ReviewSeller(ctx context.Context, in *ReviewSellerRequest, opts ...grpc.CallOption) (*Review, error)
+	ListReviews(ctx context.Context, in *ListReviewsRequest, opts ...grpc.CallOption) (*ListReviewsResponse, error)
+	ReplyToReview(ctx context.Context, in *ReplyToReviewRequest, opts ...grpc.CallOption) (*Review, error)
+	HideReview(ctx context.Context, in *HideReviewRequest, opts ...grpc.CallOption) (*Review, error)
+	ReportAd(ctx context.Context, in *ReportAdRequest, opts ...grpc.CallOption) (*AdReport, error)
+	ListReportedAds(ctx context.Context, in *ListReportedAdsRequest, opts ...grpc.CallOption) (*ListReportedAdsResponse, error)
+	ResolveReports(ctx context.Context, in *ResolveReportsRequest, opts ...grpc.CallOption) (*ResolveReportsResponse, error)
+	AppealTakedown(ctx context.Context, in *AppealTakedownRequest, opts ...grpc.CallOption) (*Appeal, error)
+	ListAppeals(ctx context.Context, in *ListAppealsRequest, opts ...grpc.CallOption) (*ListAppealsResponse, error)
+	ResolveAppeal(ctx context.Context, in *ResolveAppealRequest, opts ...grpc.CallOption) (*Appeal, error)
+	ListHeldAds(ctx context.Context, in *ListHeldAdsRequest, opts ...grpc.CallOption) (*ListAdResponse, error)
+	ListDuplicateClusters(ctx context.Context, in *ListDuplicateClustersRequest, opts ...grpc.CallOption) (*ListDuplicateClustersResponse, error)
 }
 
 type adServiceClient struct {
@@ -134,6 +216,388 @@ func (c *adServiceClient) DeleteAd(ctx context.Context, in *DeleteAdRequest, opt
 	return out, nil
 }
 
+func (c *adServiceClient) ConfirmEmail(ctx context.Context, in *ConfirmEmailRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, AdService_ConfirmEmail_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ResendVerification(ctx context.Context, in *ResendVerificationRequest, opts ...grpc.CallOption) (*ResendVerificationResponse, error) {
+	out := new(ResendVerificationResponse)
+	err := c.cc.Invoke(ctx, AdService_ResendVerification_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListAdRevisions(ctx context.Context, in *ListAdRevisionsRequest, opts ...grpc.CallOption) (*ListAdRevisionsResponse, error) {
+	out := new(ListAdRevisionsResponse)
+	err := c.cc.Invoke(ctx, AdService_ListAdRevisions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) GetAdRevision(ctx context.Context, in *GetAdRevisionRequest, opts ...grpc.CallOption) (*AdRevision, error) {
+	out := new(AdRevision)
+	err := c.cc.Invoke(ctx, AdService_GetAdRevision_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RollbackAd(ctx context.Context, in *RollbackAdRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_RollbackAd_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ApproveAd(ctx context.Context, in *ApproveAdRequest, opts ...grpc.CallOption) (*AdApproval, error) {
+	out := new(AdApproval)
+	err := c.cc.Invoke(ctx, AdService_ApproveAd_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) GetAdChanges(ctx context.Context, in *GetAdChangesRequest, opts ...grpc.CallOption) (*AdChangesResponse, error) {
+	out := new(AdChangesResponse)
+	err := c.cc.Invoke(ctx, AdService_GetAdChanges_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListAdResponse, error) {
+	out := new(ListAdResponse)
+	err := c.cc.Invoke(ctx, AdService_ListTrash_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RestoreAd(ctx context.Context, in *RestoreAdRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_RestoreAd_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RestoreUser(ctx context.Context, in *RestoreUserRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, AdService_RestoreUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListAuditEntries(ctx context.Context, in *ListAuditEntriesRequest, opts ...grpc.CallOption) (*ListAuditEntriesResponse, error) {
+	out := new(ListAuditEntriesResponse)
+	err := c.cc.Invoke(ctx, AdService_ListAuditEntries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) VerifyAuditLog(ctx context.Context, in *VerifyAuditLogRequest, opts ...grpc.CallOption) (*AuditVerification, error) {
+	out := new(AuditVerification)
+	err := c.cc.Invoke(ctx, AdService_VerifyAuditLog_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RenewAd(ctx context.Context, in *RenewAdRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_RenewAd_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ExtendAd(ctx context.Context, in *ExtendAdRequest, opts ...grpc.CallOption) (*AdResponse, error) {
+	out := new(AdResponse)
+	err := c.cc.Invoke(ctx, AdService_ExtendAd_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListScheduledTransitions(ctx context.Context, in *ListScheduledTransitionsRequest, opts ...grpc.CallOption) (*ListScheduledTransitionsResponse, error) {
+	out := new(ListScheduledTransitionsResponse)
+	err := c.cc.Invoke(ctx, AdService_ListScheduledTransitions_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) CancelScheduledTransition(ctx context.Context, in *CancelScheduledTransitionRequest, opts ...grpc.CallOption) (*CancelScheduledTransitionResponse, error) {
+	out := new(CancelScheduledTransitionResponse)
+	err := c.cc.Invoke(ctx, AdService_CancelScheduledTransition_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) AddFavorite(ctx context.Context, in *FavoriteRequest, opts ...grpc.CallOption) (*FavoriteResponse, error) {
+	out := new(FavoriteResponse)
+	err := c.cc.Invoke(ctx, AdService_AddFavorite_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) RemoveFavorite(ctx context.Context, in *FavoriteRequest, opts ...grpc.CallOption) (*RemoveFavoriteResponse, error) {
+	out := new(RemoveFavoriteResponse)
+	err := c.cc.Invoke(ctx, AdService_RemoveFavorite_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListFavorites(ctx context.Context, in *ListFavoritesRequest, opts ...grpc.CallOption) (*ListFavoritesResponse, error) {
+	out := new(ListFavoritesResponse)
+	err := c.cc.Invoke(ctx, AdService_ListFavorites_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) CountFavorites(ctx context.Context, in *CountFavoritesRequest, opts ...grpc.CallOption) (*CountFavoritesResponse, error) {
+	out := new(CountFavoritesResponse)
+	err := c.cc.Invoke(ctx, AdService_CountFavorites_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) StartConversation(ctx context.Context, in *StartConversationRequest, opts ...grpc.CallOption) (*Conversation, error) {
+	out := new(Conversation)
+	err := c.cc.Invoke(ctx, AdService_StartConversation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error) {
+	out := new(ListConversationsResponse)
+	err := c.cc.Invoke(ctx, AdService_ListConversations_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) SendMessage(ctx context.Context, in *SendMessageRequest, opts ...grpc.CallOption) (*ChatMessage, error) {
+	out := new(ChatMessage)
+	err := c.cc.Invoke(ctx, AdService_SendMessage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListMessages(ctx context.Context, in *ListMessagesRequest, opts ...grpc.CallOption) (*ListMessagesResponse, error) {
+	out := new(ListMessagesResponse)
+	err := c.cc.Invoke(ctx, AdService_ListMessages_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) BlockUser(ctx context.Context, in *BlockUserRequest, opts ...grpc.CallOption) (*UserBlock, error) {
+	out := new(UserBlock)
+	err := c.cc.Invoke(ctx, AdService_BlockUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) UnblockUser(ctx context.Context, in *BlockUserRequest, opts ...grpc.CallOption) (*UnblockUserResponse, error) {
+	out := new(UnblockUserResponse)
+	err := c.cc.Invoke(ctx, AdService_UnblockUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListBlockedUsers(ctx context.Context, in *ListBlockedUsersRequest, opts ...grpc.CallOption) (*ListBlockedUsersResponse, error) {
+	out := new(ListBlockedUsersResponse)
+	err := c.cc.Invoke(ctx, AdService_ListBlockedUsers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) Chat(ctx context.Context, opts ...grpc.CallOption) (AdService_ChatClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AdService_ServiceDesc.Streams[0], AdService_Chat_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adServiceChatClient{stream}
+	return x, nil
+}
+
+type AdService_ChatClient interface {
+	Send(*ChatRequest) error
+	Recv() (*ChatMessage, error)
+	grpc.ClientStream
+}
+
+type adServiceChatClient struct {
+	grpc.ClientStream
+}
+
+func (x *adServiceChatClient) Send(m *ChatRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *adServiceChatClient) Recv() (*ChatMessage, error) {
+	m := new(ChatMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *adServiceClient) ReviewSeller(ctx context.Context, in *ReviewSellerRequest, opts ...grpc.CallOption) (*Review, error) {
+	out := new(Review)
+	err := c.cc.Invoke(ctx, AdService_ReviewSeller_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListReviews(ctx context.Context, in *ListReviewsRequest, opts ...grpc.CallOption) (*ListReviewsResponse, error) {
+	out := new(ListReviewsResponse)
+	err := c.cc.Invoke(ctx, AdService_ListReviews_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ReplyToReview(ctx context.Context, in *ReplyToReviewRequest, opts ...grpc.CallOption) (*Review, error) {
+	out := new(Review)
+	err := c.cc.Invoke(ctx, AdService_ReplyToReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) HideReview(ctx context.Context, in *HideReviewRequest, opts ...grpc.CallOption) (*Review, error) {
+	out := new(Review)
+	err := c.cc.Invoke(ctx, AdService_HideReview_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ReportAd(ctx context.Context, in *ReportAdRequest, opts ...grpc.CallOption) (*AdReport, error) {
+	out := new(AdReport)
+	err := c.cc.Invoke(ctx, AdService_ReportAd_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListReportedAds(ctx context.Context, in *ListReportedAdsRequest, opts ...grpc.CallOption) (*ListReportedAdsResponse, error) {
+	out := new(ListReportedAdsResponse)
+	err := c.cc.Invoke(ctx, AdService_ListReportedAds_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ResolveReports(ctx context.Context, in *ResolveReportsRequest, opts ...grpc.CallOption) (*ResolveReportsResponse, error) {
+	out := new(ResolveReportsResponse)
+	err := c.cc.Invoke(ctx, AdService_ResolveReports_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) AppealTakedown(ctx context.Context, in *AppealTakedownRequest, opts ...grpc.CallOption) (*Appeal, error) {
+	out := new(Appeal)
+	err := c.cc.Invoke(ctx, AdService_AppealTakedown_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListAppeals(ctx context.Context, in *ListAppealsRequest, opts ...grpc.CallOption) (*ListAppealsResponse, error) {
+	out := new(ListAppealsResponse)
+	err := c.cc.Invoke(ctx, AdService_ListAppeals_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ResolveAppeal(ctx context.Context, in *ResolveAppealRequest, opts ...grpc.CallOption) (*Appeal, error) {
+	out := new(Appeal)
+	err := c.cc.Invoke(ctx, AdService_ResolveAppeal_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListHeldAds(ctx context.Context, in *ListHeldAdsRequest, opts ...grpc.CallOption) (*ListAdResponse, error) {
+	out := new(ListAdResponse)
+	err := c.cc.Invoke(ctx, AdService_ListHeldAds_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adServiceClient) ListDuplicateClusters(ctx context.Context, in *ListDuplicateClustersRequest, opts ...grpc.CallOption) (*ListDuplicateClustersResponse, error) {
+	out := new(ListDuplicateClustersResponse)
+	err := c.cc.Invoke(ctx, AdService_ListDuplicateClusters_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AdServiceServer is the server API for AdService service.
 // All implementations should embed UnimplementedAdServiceServer
 // for forward compatibility
@@ -147,209 +611,1099 @@ type AdServiceServer interface {
 	UpdateUser(context.Context, *UpdateUserRequest) (*UserResponse, error)
 	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
 	DeleteAd(context.Context, *DeleteAdRequest) (*DeleteAdResponse, error)
+	ConfirmEmail(context.Context, *ConfirmEmailRequest) (*UserResponse, error)
+	ResendVerification(context.Context, *ResendVerificationRequest) (*ResendVerificationResponse, error)
+	ListAdRevisions(context.Context, *ListAdRevisionsRequest) (*ListAdRevisionsResponse, error)
+	GetAdRevision(context.Context, *GetAdRevisionRequest) (*AdRevision, error)
+	RollbackAd(context.Context, *RollbackAdRequest) (*AdResponse, error)
+	ApproveAd(context.Context, *ApproveAdRequest) (*AdApproval, error)
+	GetAdChanges(context.Context, *GetAdChangesRequest) (*AdChangesResponse, error)
+	ListTrash(context.Context, *ListTrashRequest) (*ListAdResponse, error)
+	RestoreAd(context.Context, *RestoreAdRequest) (*AdResponse, error)
+	RestoreUser(context.Context, *RestoreUserRequest) (*UserResponse, error)
+	ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error)
+	VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*AuditVerification, error)
+	RenewAd(context.Context, *RenewAdRequest) (*AdResponse, error)
+	ExtendAd(context.Context, *ExtendAdRequest) (*AdResponse, error)
+	ListScheduledTransitions(context.Context, *ListScheduledTransitionsRequest) (*ListScheduledTransitionsResponse, error)
+	CancelScheduledTransition(context.Context, *CancelScheduledTransitionRequest) (*CancelScheduledTransitionResponse, error)
+	AddFavorite(context.Context, *FavoriteRequest) (*FavoriteResponse, error)
+	RemoveFavorite(context.Context, *FavoriteRequest) (*RemoveFavoriteResponse, error)
+	ListFavorites(context.Context, *ListFavoritesRequest) (*ListFavoritesResponse, error)
+	CountFavorites(context.Context, *CountFavoritesRequest) (*CountFavoritesResponse, error)
+	StartConversation(context.Context, *StartConversationRequest) (*Conversation, error)
+	ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error)
+	SendMessage(context.Context, *SendMessageRequest) (*ChatMessage, error)
+	ListMessages(context.Context, *ListMessagesRequest) (*ListMessagesResponse, error)
+	BlockUser(context.Context, *BlockUserRequest) (*UserBlock, error)
+	UnblockUser(context.Context, *BlockUserRequest) (*UnblockUserResponse, error)
+	ListBlockedUsers(context.Context, *ListBlockedUsersRequest) (*ListBlockedUsersResponse, error)
+	// Chat sends messages of the stream and returns messages sent to or by the user in any conversation.
+	// The first request identifies the user, its text may be empty to only receive messages.
+	Chat(AdService_ChatServer) error
+	ReviewSeller(context.Context, *ReviewSellerRequest) (*Review, error)
+	ListReviews(context.Context, *ListReviewsRequest) (*ListReviewsResponse, error)
+	ReplyToReview(context.Context, *ReplyToReviewRequest) (*Review, error)
+	HideReview(context.Context, *HideReviewRequest) (*Review, error)
+	ReportAd(context.Context, *ReportAdRequest) (*AdReport, error)
+	ListReportedAds(context.Context, *ListReportedAdsRequest) (*ListReportedAdsResponse, error)
+	ResolveReports(context.Context, *ResolveReportsRequest) (*ResolveReportsResponse, error)
+	AppealTakedown(context.Context, *AppealTakedownRequest) (*Appeal, error)
+	ListAppeals(context.Context, *ListAppealsRequest) (*ListAppealsResponse, error)
+	ResolveAppeal(context.Context, *ResolveAppealRequest) (*Appeal, error)
+	ListHeldAds(context.Context, *ListHeldAdsRequest) (*ListAdResponse, error)
+	ListDuplicateClusters(context.Context, *ListDuplicateClustersRequest) (*ListDuplicateClustersResponse, error)
+}
+
+// UnimplementedAdServiceServer should be embedded to have forward compatible implementations.
+type UnimplementedAdServiceServer struct {
+}
+
+func (UnimplementedAdServiceServer) CreateAd(context.Context, *CreateAdRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAd not implemented")
+}
+func (UnimplementedAdServiceServer) ChangeAdStatus(context.Context, *ChangeAdStatusRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeAdStatus not implemented")
+}
+func (UnimplementedAdServiceServer) UpdateAd(context.Context, *UpdateAdRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAd not implemented")
+}
+func (UnimplementedAdServiceServer) ListAds(context.Context, *ListAdRequest) (*ListAdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAds not implemented")
+}
+func (UnimplementedAdServiceServer) CreateUser(context.Context, *CreateUserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedAdServiceServer) GetUser(context.Context, *GetUserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedAdServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedAdServiceServer) DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
+}
+func (UnimplementedAdServiceServer) DeleteAd(context.Context, *DeleteAdRequest) (*DeleteAdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAd not implemented")
+}
+func (UnimplementedAdServiceServer) ConfirmEmail(context.Context, *ConfirmEmailRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmEmail not implemented")
+}
+func (UnimplementedAdServiceServer) ResendVerification(context.Context, *ResendVerificationRequest) (*ResendVerificationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResendVerification not implemented")
+}
+func (UnimplementedAdServiceServer) ListAdRevisions(context.Context, *ListAdRevisionsRequest) (*ListAdRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAdRevisions not implemented")
+}
+func (UnimplementedAdServiceServer) GetAdRevision(context.Context, *GetAdRevisionRequest) (*AdRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAdRevision not implemented")
+}
+func (UnimplementedAdServiceServer) RollbackAd(context.Context, *RollbackAdRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackAd not implemented")
+}
+func (UnimplementedAdServiceServer) ApproveAd(context.Context, *ApproveAdRequest) (*AdApproval, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveAd not implemented")
+}
+func (UnimplementedAdServiceServer) GetAdChanges(context.Context, *GetAdChangesRequest) (*AdChangesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAdChanges not implemented")
+}
+func (UnimplementedAdServiceServer) ListTrash(context.Context, *ListTrashRequest) (*ListAdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedAdServiceServer) RestoreAd(context.Context, *RestoreAdRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreAd not implemented")
+}
+func (UnimplementedAdServiceServer) RestoreUser(context.Context, *RestoreUserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreUser not implemented")
+}
+func (UnimplementedAdServiceServer) ListAuditEntries(context.Context, *ListAuditEntriesRequest) (*ListAuditEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEntries not implemented")
+}
+func (UnimplementedAdServiceServer) VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*AuditVerification, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAuditLog not implemented")
+}
+func (UnimplementedAdServiceServer) RenewAd(context.Context, *RenewAdRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewAd not implemented")
+}
+func (UnimplementedAdServiceServer) ExtendAd(context.Context, *ExtendAdRequest) (*AdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendAd not implemented")
+}
+func (UnimplementedAdServiceServer) ListScheduledTransitions(context.Context, *ListScheduledTransitionsRequest) (*ListScheduledTransitionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScheduledTransitions not implemented")
+}
+func (UnimplementedAdServiceServer) CancelScheduledTransition(context.Context, *CancelScheduledTransitionRequest) (*CancelScheduledTransitionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelScheduledTransition not implemented")
+}
+func (UnimplementedAdServiceServer) AddFavorite(context.Context, *FavoriteRequest) (*FavoriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddFavorite not implemented")
+}
+func (UnimplementedAdServiceServer) RemoveFavorite(context.Context, *FavoriteRequest) (*RemoveFavoriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveFavorite not implemented")
+}
+func (UnimplementedAdServiceServer) ListFavorites(context.Context, *ListFavoritesRequest) (*ListFavoritesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFavorites not implemented")
+}
+func (UnimplementedAdServiceServer) CountFavorites(context.Context, *CountFavoritesRequest) (*CountFavoritesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountFavorites not implemented")
+}
+func (UnimplementedAdServiceServer) StartConversation(context.Context, *StartConversationRequest) (*Conversation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartConversation not implemented")
+}
+func (UnimplementedAdServiceServer) ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConversations not implemented")
+}
+func (UnimplementedAdServiceServer) SendMessage(context.Context, *SendMessageRequest) (*ChatMessage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendMessage not implemented")
+}
+func (UnimplementedAdServiceServer) ListMessages(context.Context, *ListMessagesRequest) (*ListMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMessages not implemented")
+}
+func (UnimplementedAdServiceServer) BlockUser(context.Context, *BlockUserRequest) (*UserBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockUser not implemented")
+}
+func (UnimplementedAdServiceServer) UnblockUser(context.Context, *BlockUserRequest) (*UnblockUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnblockUser not implemented")
+}
+func (UnimplementedAdServiceServer) ListBlockedUsers(context.Context, *ListBlockedUsersRequest) (*ListBlockedUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBlockedUsers not implemented")
+}
+func (UnimplementedAdServiceServer) Chat(AdService_ChatServer) error {
+	return status.Errorf(codes.Unimplemented, "method Chat not implemented")
+}
+func (UnimplementedAdServiceServer) ReviewSeller(context.Context, *ReviewSellerRequest) (*Review, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewSeller not implemented")
+}
+func (UnimplementedAdServiceServer) ListReviews(context.Context, *ListReviewsRequest) (*ListReviewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReviews not implemented")
+}
+func (UnimplementedAdServiceServer) ReplyToReview(context.Context, *ReplyToReviewRequest) (*Review, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplyToReview not implemented")
+}
+func (UnimplementedAdServiceServer) HideReview(context.Context, *HideReviewRequest) (*Review, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HideReview not implemented")
+}
+func (UnimplementedAdServiceServer) ReportAd(context.Context, *ReportAdRequest) (*AdReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportAd not implemented")
+}
+func (UnimplementedAdServiceServer) ListReportedAds(context.Context, *ListReportedAdsRequest) (*ListReportedAdsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReportedAds not implemented")
+}
+func (UnimplementedAdServiceServer) ResolveReports(context.Context, *ResolveReportsRequest) (*ResolveReportsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveReports not implemented")
+}
+func (UnimplementedAdServiceServer) AppealTakedown(context.Context, *AppealTakedownRequest) (*Appeal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppealTakedown not implemented")
+}
+func (UnimplementedAdServiceServer) ListAppeals(context.Context, *ListAppealsRequest) (*ListAppealsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAppeals not implemented")
+}
+func (UnimplementedAdServiceServer) ResolveAppeal(context.Context, *ResolveAppealRequest) (*Appeal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveAppeal not implemented")
+}
+func (UnimplementedAdServiceServer) ListHeldAds(context.Context, *ListHeldAdsRequest) (*ListAdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHeldAds not implemented")
+}
+func (UnimplementedAdServiceServer) ListDuplicateClusters(context.Context, *ListDuplicateClustersRequest) (*ListDuplicateClustersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDuplicateClusters not implemented")
+}
+
+// UnsafeAdServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdServiceServer will
+// result in compilation errors.
+type UnsafeAdServiceServer interface {
+	mustEmbedUnimplementedAdServiceServer()
+}
+
+func RegisterAdServiceServer(s grpc.ServiceRegistrar, srv AdServiceServer) {
+	s.RegisterService(&AdService_ServiceDesc, srv)
+}
+
+func _AdService_CreateAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).CreateAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_CreateAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).CreateAd(ctx, req.(*CreateAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ChangeAdStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeAdStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ChangeAdStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ChangeAdStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ChangeAdStatus(ctx, req.(*ChangeAdStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_UpdateAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).UpdateAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_UpdateAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).UpdateAd(ctx, req.(*UpdateAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListAds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListAds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListAds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListAds(ctx, req.(*ListAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_UpdateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).DeleteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_DeleteUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).DeleteUser(ctx, req.(*DeleteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_DeleteAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).DeleteAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_DeleteAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).DeleteAd(ctx, req.(*DeleteAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ConfirmEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ConfirmEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ConfirmEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ConfirmEmail(ctx, req.(*ConfirmEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ResendVerification_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResendVerificationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ResendVerification(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ResendVerification_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ResendVerification(ctx, req.(*ResendVerificationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListAdRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAdRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListAdRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListAdRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListAdRevisions(ctx, req.(*ListAdRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_GetAdRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAdRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).GetAdRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_GetAdRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).GetAdRevision(ctx, req.(*GetAdRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RollbackAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RollbackAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RollbackAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RollbackAd(ctx, req.(*RollbackAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ApproveAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ApproveAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ApproveAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ApproveAd(ctx, req.(*ApproveAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_GetAdChanges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAdChangesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).GetAdChanges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_GetAdChanges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).GetAdChanges(ctx, req.(*GetAdChangesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListTrash(ctx, req.(*ListTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RestoreAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RestoreAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RestoreAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RestoreAd(ctx, req.(*RestoreAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RestoreUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RestoreUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RestoreUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RestoreUser(ctx, req.(*RestoreUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListAuditEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListAuditEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListAuditEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListAuditEntries(ctx, req.(*ListAuditEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_VerifyAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).VerifyAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_VerifyAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).VerifyAuditLog(ctx, req.(*VerifyAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RenewAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RenewAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RenewAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RenewAd(ctx, req.(*RenewAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ExtendAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendAdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ExtendAd(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ExtendAd_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ExtendAd(ctx, req.(*ExtendAdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListScheduledTransitions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduledTransitionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListScheduledTransitions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListScheduledTransitions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListScheduledTransitions(ctx, req.(*ListScheduledTransitionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_CancelScheduledTransition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).CancelScheduledTransition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_CancelScheduledTransition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).CancelScheduledTransition(ctx, req.(*CancelScheduledTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_AddFavorite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FavoriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).AddFavorite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_AddFavorite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).AddFavorite(ctx, req.(*FavoriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_RemoveFavorite_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FavoriteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).RemoveFavorite(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_RemoveFavorite_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).RemoveFavorite(ctx, req.(*FavoriteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListFavorites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFavoritesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListFavorites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListFavorites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListFavorites(ctx, req.(*ListFavoritesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_CountFavorites_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountFavoritesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).CountFavorites(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_CountFavorites_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).CountFavorites(ctx, req.(*CountFavoritesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_StartConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).StartConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_StartConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).StartConversation(ctx, req.(*StartConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConversationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListConversations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListConversations(ctx, req.(*ListConversationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// UnimplementedAdServiceServer should be embedded to have forward compatible implementations.
-type UnimplementedAdServiceServer struct {
+func _AdService_SendMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).SendMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_SendMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).SendMessage(ctx, req.(*SendMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (UnimplementedAdServiceServer) CreateAd(context.Context, *CreateAdRequest) (*AdResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateAd not implemented")
+func _AdService_ListMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListMessages(ctx, req.(*ListMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedAdServiceServer) ChangeAdStatus(context.Context, *ChangeAdStatusRequest) (*AdResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ChangeAdStatus not implemented")
+
+func _AdService_BlockUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).BlockUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_BlockUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).BlockUser(ctx, req.(*BlockUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedAdServiceServer) UpdateAd(context.Context, *UpdateAdRequest) (*AdResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateAd not implemented")
+
+func _AdService_UnblockUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).UnblockUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_UnblockUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).UnblockUser(ctx, req.(*BlockUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedAdServiceServer) ListAds(context.Context, *ListAdRequest) (*ListAdResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method ListAds not implemented")
+
+func _AdService_ListBlockedUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBlockedUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListBlockedUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListBlockedUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListBlockedUsers(ctx, req.(*ListBlockedUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
-func (UnimplementedAdServiceServer) CreateUser(context.Context, *CreateUserRequest) (*UserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+
+func _AdService_Chat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AdServiceServer).Chat(&adServiceChatServer{stream})
 }
-func (UnimplementedAdServiceServer) GetUser(context.Context, *GetUserRequest) (*UserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+
+type AdService_ChatServer interface {
+	Send(*ChatMessage) error
+	Recv() (*ChatRequest, error)
+	grpc.ServerStream
 }
-func (UnimplementedAdServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*UserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+
+type adServiceChatServer struct {
+	grpc.ServerStream
 }
-func (UnimplementedAdServiceServer) DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteUser not implemented")
+
+func (x *adServiceChatServer) Send(m *ChatMessage) error {
+	return x.ServerStream.SendMsg(m)
 }
-func (UnimplementedAdServiceServer) DeleteAd(context.Context, *DeleteAdRequest) (*DeleteAdResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteAd not implemented")
+
+func (x *adServiceChatServer) Recv() (*ChatRequest, error) {
+	m := new(ChatRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-// UnsafeAdServiceServer may be embedded to opt out of forward compatibility for this service.
-// Use of this interface is not recommended, as added methods to AdServiceServer will
-// result in compilation errors.
-type UnsafeAdServiceServer interface {
-	mustEmbedUnimplementedAdServiceServer()
+func _AdService_ReviewSeller_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewSellerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ReviewSeller(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ReviewSeller_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ReviewSeller(ctx, req.(*ReviewSellerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func RegisterAdServiceServer(s grpc.ServiceRegistrar, srv AdServiceServer) {
-	s.RegisterService(&AdService_ServiceDesc, srv)
+func _AdService_ListReviews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReviewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListReviews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListReviews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListReviews(ctx, req.(*ListReviewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_CreateAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateAdRequest)
+func _AdService_ReplyToReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplyToReviewRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).CreateAd(ctx, in)
+		return srv.(AdServiceServer).ReplyToReview(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_CreateAd_FullMethodName,
+		FullMethod: AdService_ReplyToReview_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).CreateAd(ctx, req.(*CreateAdRequest))
+		return srv.(AdServiceServer).ReplyToReview(ctx, req.(*ReplyToReviewRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_ChangeAdStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ChangeAdStatusRequest)
+func _AdService_HideReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HideReviewRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).ChangeAdStatus(ctx, in)
+		return srv.(AdServiceServer).HideReview(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_ChangeAdStatus_FullMethodName,
+		FullMethod: AdService_HideReview_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).ChangeAdStatus(ctx, req.(*ChangeAdStatusRequest))
+		return srv.(AdServiceServer).HideReview(ctx, req.(*HideReviewRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_UpdateAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateAdRequest)
+func _AdService_ReportAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportAdRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).UpdateAd(ctx, in)
+		return srv.(AdServiceServer).ReportAd(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_UpdateAd_FullMethodName,
+		FullMethod: AdService_ReportAd_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).UpdateAd(ctx, req.(*UpdateAdRequest))
+		return srv.(AdServiceServer).ReportAd(ctx, req.(*ReportAdRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_ListAds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListAdRequest)
+func _AdService_ListReportedAds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportedAdsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).ListAds(ctx, in)
+		return srv.(AdServiceServer).ListReportedAds(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_ListAds_FullMethodName,
+		FullMethod: AdService_ListReportedAds_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).ListAds(ctx, req.(*ListAdRequest))
+		return srv.(AdServiceServer).ListReportedAds(ctx, req.(*ListReportedAdsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateUserRequest)
+func _AdService_ResolveReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveReportsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).CreateUser(ctx, in)
+		return srv.(AdServiceServer).ResolveReports(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_CreateUser_FullMethodName,
+		FullMethod: AdService_ResolveReports_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+		return srv.(AdServiceServer).ResolveReports(ctx, req.(*ResolveReportsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetUserRequest)
+func _AdService_AppealTakedown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AppealTakedownRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).GetUser(ctx, in)
+		return srv.(AdServiceServer).AppealTakedown(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_GetUser_FullMethodName,
+		FullMethod: AdService_AppealTakedown_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).GetUser(ctx, req.(*GetUserRequest))
+		return srv.(AdServiceServer).AppealTakedown(ctx, req.(*AppealTakedownRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateUserRequest)
+func _AdService_ListAppeals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAppealsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).UpdateUser(ctx, in)
+		return srv.(AdServiceServer).ListAppeals(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_UpdateUser_FullMethodName,
+		FullMethod: AdService_ListAppeals_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+		return srv.(AdServiceServer).ListAppeals(ctx, req.(*ListAppealsRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_DeleteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteUserRequest)
+func _AdService_ResolveAppeal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveAppealRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).DeleteUser(ctx, in)
+		return srv.(AdServiceServer).ResolveAppeal(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_DeleteUser_FullMethodName,
+		FullMethod: AdService_ResolveAppeal_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).DeleteUser(ctx, req.(*DeleteUserRequest))
+		return srv.(AdServiceServer).ResolveAppeal(ctx, req.(*ResolveAppealRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _AdService_DeleteAd_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteAdRequest)
+func _AdService_ListHeldAds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHeldAdsRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(AdServiceServer).DeleteAd(ctx, in)
+		return srv.(AdServiceServer).ListHeldAds(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: AdService_DeleteAd_FullMethodName,
+		FullMethod: AdService_ListHeldAds_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(AdServiceServer).DeleteAd(ctx, req.(*DeleteAdRequest))
+		return srv.(AdServiceServer).ListHeldAds(ctx, req.(*ListHeldAdsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdService_ListDuplicateClusters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDuplicateClustersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdServiceServer).ListDuplicateClusters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdService_ListDuplicateClusters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdServiceServer).ListDuplicateClusters(ctx, req.(*ListDuplicateClustersRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -397,7 +1751,170 @@ var AdService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteAd",
 			Handler:    _AdService_DeleteAd_Handler,
 		},
+		{
+			MethodName: "ConfirmEmail",
+			Handler:    _AdService_ConfirmEmail_Handler,
+		},
+		{
+			MethodName: "ResendVerification",
+			Handler:    _AdService_ResendVerification_Handler,
+		},
+		{
+			MethodName: "ListAdRevisions",
+			Handler:    _AdService_ListAdRevisions_Handler,
+		},
+		{
+			MethodName: "GetAdRevision",
+			Handler:    _AdService_GetAdRevision_Handler,
+		},
+		{
+			MethodName: "RollbackAd",
+			Handler:    _AdService_RollbackAd_Handler,
+		},
+		{
+			MethodName: "ApproveAd",
+			Handler:    _AdService_ApproveAd_Handler,
+		},
+		{
+			MethodName: "GetAdChanges",
+			Handler:    _AdService_GetAdChanges_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _AdService_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreAd",
+			Handler:    _AdService_RestoreAd_Handler,
+		},
+		{
+			MethodName: "RestoreUser",
+			Handler:    _AdService_RestoreUser_Handler,
+		},
+		{
+			MethodName: "ListAuditEntries",
+			Handler:    _AdService_ListAuditEntries_Handler,
+		},
+		{
+			MethodName: "VerifyAuditLog",
+			Handler:    _AdService_VerifyAuditLog_Handler,
+		},
+		{
+			MethodName: "RenewAd",
+			Handler:    _AdService_RenewAd_Handler,
+		},
+		{
+			MethodName: "ExtendAd",
+			Handler:    _AdService_ExtendAd_Handler,
+		},
+		{
+			MethodName: "ListScheduledTransitions",
+			Handler:    _AdService_ListScheduledTransitions_Handler,
+		},
+		{
+			MethodName: "CancelScheduledTransition",
+			Handler:    _AdService_CancelScheduledTransition_Handler,
+		},
+		{
+			MethodName: "AddFavorite",
+			Handler:    _AdService_AddFavorite_Handler,
+		},
+		{
+			MethodName: "RemoveFavorite",
+			Handler:    _AdService_RemoveFavorite_Handler,
+		},
+		{
+			MethodName: "ListFavorites",
+			Handler:    _AdService_ListFavorites_Handler,
+		},
+		{
+			MethodName: "CountFavorites",
+			Handler:    _AdService_CountFavorites_Handler,
+		},
+		{
+			MethodName: "StartConversation",
+			Handler:    _AdService_StartConversation_Handler,
+		},
+		{
+			MethodName: "ListConversations",
+			Handler:    _AdService_ListConversations_Handler,
+		},
+		{
+			MethodName: "SendMessage",
+			Handler:    _AdService_SendMessage_Handler,
+		},
+		{
+			MethodName: "ListMessages",
+			Handler:    _AdService_ListMessages_Handler,
+		},
+		{
+			MethodName: "BlockUser",
+			Handler:    _AdService_BlockUser_Handler,
+		},
+		{
+			MethodName: "UnblockUser",
+			Handler:    _AdService_UnblockUser_Handler,
+		},
+		{
+			MethodName: "ListBlockedUsers",
+			Handler:    _AdService_ListBlockedUsers_Handler,
+		},
+		{
+			MethodName: "ReviewSeller",
+			Handler:    _AdService_ReviewSeller_Handler,
+		},
+		{
+			MethodName: "ListReviews",
+			Handler:    _AdService_ListReviews_Handler,
+		},
+		{
+			MethodName: "ReplyToReview",
+			Handler:    _AdService_ReplyToReview_Handler,
+		},
+		{
+			MethodName: "HideReview",
+			Handler:    _AdService_HideReview_Handler,
+		},
+		{
+			MethodName: "ReportAd",
+			Handler:    _AdService_ReportAd_Handler,
+		},
+		{
+			MethodName: "ListReportedAds",
+			Handler:    _AdService_ListReportedAds_Handler,
+		},
+		{
+			MethodName: "ResolveReports",
+			Handler:    _AdService_ResolveReports_Handler,
+		},
+		{
+			MethodName: "AppealTakedown",
+			Handler:    _AdService_AppealTakedown_Handler,
+		},
+		{
+			MethodName: "ListAppeals",
+			Handler:    _AdService_ListAppeals_Handler,
+		},
+		{
+			MethodName: "ResolveAppeal",
+			Handler:    _AdService_ResolveAppeal_Handler,
+		},
+		{
+			MethodName: "ListHeldAds",
+			Handler:    _AdService_ListHeldAds_Handler,
+		},
+		{
+			MethodName: "ListDuplicateClusters",
+			Handler:    _AdService_ListDuplicateClusters_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Chat",
+			Handler:       _AdService_Chat_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }